@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -23,6 +25,17 @@ import (
 )
 
 func main() {
+	var migration migrationOptions
+	flag.StringVar(&migration.command, "migrate", "", "run a migration command (status, down, force) and exit without serving")
+	flag.IntVar(&migration.steps, "steps", 1, "migrations to roll back with -migrate=down; 0 rolls back everything")
+	flag.IntVar(&migration.version, "version", -1, "schema version to record with -migrate=force")
+	flag.BoolVar(&migration.confirm, "confirm", false, "confirm destructive migration commands")
+	var seed seedOptions
+	flag.IntVar(&seed.count, "seed-count", 0, "generate this many random entities of each kind for load testing and exit")
+	flag.StringVar(&seed.profile, "seed-profile", "", "JSON file with category weights and amount ranges for -seed-count")
+	flag.Uint64Var(&seed.random, "seed-random", 0, "random seed for -seed-count; 0 picks one from the clock")
+	flag.Parse()
+
 	cfg, err := config.Load()
 	if err != nil {
 		slog.Error("failed to load config", "error", err)
@@ -31,9 +44,25 @@ func main() {
 
 	logger := logging.NewLogger(cfg.LogLevel)
 
+	if migration.command != "" {
+		if err := runMigrationCommand(cfg, logger, migration); err != nil {
+			logger.Error("migration command failed", "command", migration.command, "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	if seed.count != 0 {
+		if err := runSeedCommand(ctx, cfg, logger, seed); err != nil {
+			logger.Error("seed command failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	repo, cleanup, err := initRepository(ctx, cfg, logger)
 	if err != nil {
 		logger.Error("failed to initialize repository", "error", err)
@@ -60,34 +89,105 @@ func main() {
 }
 
 func initRepository(ctx context.Context, cfg config.Config, logger *slog.Logger) (repository.Repository, func(), error) {
-	if cfg.DatabaseURL == "" {
-		logger.Error("DATABASE_URL is required for the finance repository")
-		return nil, func() {}, errors.New("missing DATABASE_URL")
-	}
-
-	db, err := sql.Open("pgx", cfg.DatabaseURL)
+	db, err := openDatabase(cfg, logger)
 	if err != nil {
 		return nil, func() {}, err
 	}
 
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxIdleTime(5 * time.Minute)
+	if err := connectWithRetry(ctx, db.PingContext, cfg.DBConnectRetries, cfg.DBConnectBackoff, logger); err != nil {
+		db.Close()
+		return nil, func() {}, err
+	}
 
 	if err := migrations.Run(db); err != nil {
 		db.Close()
 		return nil, func() {}, err
 	}
+	if err := migrations.Verify(db); err != nil {
+		db.Close()
+		return nil, func() {}, err
+	}
+
+	repoOpts := []pgrepo.Option{
+		pgrepo.WithStatementTimeout(cfg.DBStatementTimeout),
+		pgrepo.WithLogger(logger),
+		pgrepo.WithSlowQueryThreshold(cfg.DBSlowQueryThreshold),
+		pgrepo.WithScenarioCompression(cfg.ScenarioCompression),
+	}
+	repo := pgrepo.New(db, repoOpts...)
+	cleanup := func() {
+		_ = db.Close()
+	}
+
+	if cfg.DatabaseReplicaURL != "" {
+		replica, err := openPool(cfg.DatabaseReplicaURL)
+		if err != nil {
+			db.Close()
+			return nil, func() {}, err
+		}
+		if err := connectWithRetry(ctx, replica.PingContext, cfg.DBConnectRetries, cfg.DBConnectBackoff, logger); err != nil {
+			replica.Close()
+			db.Close()
+			return nil, func() {}, err
+		}
+		logger.Info("routing reads to database replica")
+		repo = pgrepo.NewWithReplica(db, replica, repoOpts...)
+		cleanup = func() {
+			_ = replica.Close()
+			_ = db.Close()
+		}
+	}
 
-	repo := pgrepo.New(db)
 	seedData := finance.DefaultSeedData(time.Now().UTC())
 	if err := repo.SeedDefaults(ctx, seedData, logger); err != nil {
 		logger.Warn("failed to seed finance data", "error", err)
 	}
 
-	cleanup := func() {
-		_ = db.Close()
+	return repo, cleanup, nil
+}
+
+func openDatabase(cfg config.Config, logger *slog.Logger) (*sql.DB, error) {
+	if cfg.DatabaseURL == "" {
+		logger.Error("DATABASE_URL is required for the finance repository")
+		return nil, errors.New("missing DATABASE_URL")
 	}
 
-	return repo, cleanup, nil
+	return openPool(cfg.DatabaseURL)
+}
+
+func openPool(url string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+	return db, nil
+}
+
+const maxConnectBackoff = 30 * time.Second
+
+// connectWithRetry pings the database until it answers, doubling the delay between
+// attempts. sql.Open is lazy, so this is where an unready Postgres is first noticed.
+func connectWithRetry(ctx context.Context, ping func(context.Context) error, retries int, backoff time.Duration, logger *slog.Logger) error {
+	var err error
+	for attempt := 1; attempt <= retries+1; attempt++ {
+		if err = ping(ctx); err == nil {
+			return nil
+		}
+		if attempt > retries {
+			break
+		}
+
+		logger.Warn("database not ready, retrying", "attempt", attempt, "retryIn", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+	return fmt.Errorf("connect to database after %d attempts: %w", retries+1, err)
 }
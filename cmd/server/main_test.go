@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestConnectWithRetrySucceedsAfterFailures(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	calls := 0
+	ping := func(context.Context) error {
+		calls++
+		if calls <= 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	if err := connectWithRetry(context.Background(), ping, 5, time.Millisecond, logger); err != nil {
+		t.Fatalf("expected connection to succeed, got %v", err)
+	}
+	if calls != 4 {
+		t.Fatalf("expected 4 attempts, got %d", calls)
+	}
+}
+
+func TestConnectWithRetryGivesUpAfterBudget(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	calls := 0
+	refused := errors.New("connection refused")
+	ping := func(context.Context) error {
+		calls++
+		return refused
+	}
+
+	err := connectWithRetry(context.Background(), ping, 2, time.Millisecond, logger)
+	if !errors.Is(err, refused) {
+		t.Fatalf("expected wrapped ping error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jcleow/assetra2/internal/config"
+	"github.com/jcleow/assetra2/internal/migrations"
+)
+
+// migrationOptions carries the command-line flags for one-off migration commands.
+type migrationOptions struct {
+	command string
+	steps   int
+	version int
+	confirm bool
+}
+
+// runMigrationCommand executes a one-off migration command instead of starting the server.
+func runMigrationCommand(cfg config.Config, logger *slog.Logger, opts migrationOptions) error {
+	switch opts.command {
+	case "status":
+	case "down":
+		if !opts.confirm {
+			return errors.New("rolling back migrations can drop data; re-run with -confirm")
+		}
+	case "force":
+		if opts.version < 0 {
+			return errors.New("force requires -version")
+		}
+	default:
+		return fmt.Errorf("unknown migration command %q", opts.command)
+	}
+
+	db, err := openDatabase(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch opts.command {
+	case "down":
+		if err := migrations.Down(db, opts.steps); err != nil {
+			return err
+		}
+		logger.Info("rolled back migrations", "steps", opts.steps)
+	case "force":
+		if err := migrations.Force(db, opts.version); err != nil {
+			return err
+		}
+		logger.Info("forced migration version", "version", opts.version)
+	}
+
+	version, dirty, err := migrations.Version(db)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema version: %d\ndirty: %t\n", version, dirty)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/config"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+// seedOptions carries the command-line flags for generating load-test data.
+type seedOptions struct {
+	count   int
+	profile string
+	random  uint64
+}
+
+// runSeedCommand generates synthetic finance data and merges it into the repository
+// through the dataset import path, then exits without serving.
+func runSeedCommand(ctx context.Context, cfg config.Config, logger *slog.Logger, opts seedOptions) error {
+	if opts.count <= 0 {
+		return errors.New("-seed-count must be positive")
+	}
+	profile, err := loadGeneratorProfile(opts.profile)
+	if err != nil {
+		return err
+	}
+
+	repo, cleanup, err := initRepository(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	seed := opts.random
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	data := finance.GenerateSeedData(rand.New(rand.NewPCG(seed, seed)), opts.count, profile, time.Now().UTC())
+
+	start := time.Now()
+	if err := repo.ImportDataset(ctx, data, repository.ImportMerge); err != nil {
+		return err
+	}
+	logger.Info("generated seed data",
+		"per_kind", opts.count,
+		"random_seed", seed,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+	return nil
+}
+
+// loadGeneratorProfile reads a JSON GeneratorProfile, or returns the default when path
+// is empty.
+func loadGeneratorProfile(path string) (finance.GeneratorProfile, error) {
+	if path == "" {
+		return finance.DefaultGeneratorProfile(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return finance.GeneratorProfile{}, err
+	}
+	var profile finance.GeneratorProfile
+	if err := json.Unmarshal(raw, &profile); err != nil {
+		return finance.GeneratorProfile{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := profile.Validate(); err != nil {
+		return finance.GeneratorProfile{}, fmt.Errorf("invalid profile %s: %w", path, err)
+	}
+	return profile, nil
+}
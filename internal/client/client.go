@@ -0,0 +1,297 @@
+// Package client is a typed Go client for the service's HTTP API. It returns the
+// finance types the server stores, maps error responses to *Error values that match
+// ErrNotFound and the other sentinels with errors.Is, retries idempotent requests on
+// transient failures, and honours context cancellation throughout.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerSessionToken = "X-Session-Token"
+	headerRequestID    = "X-Request-ID"
+
+	defaultRetries      = 2
+	defaultRetryBackoff = 100 * time.Millisecond
+	// maxRetryAfter caps a server-requested Retry-After so one response cannot stall a
+	// caller without a deadline for minutes.
+	maxRetryAfter = 30 * time.Second
+)
+
+// Client calls the API at one base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	token   string
+	retries int
+	backoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through hc instead of http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.http = hc
+		}
+	}
+}
+
+// WithSessionToken sends token as the session token, which the /events endpoints
+// require.
+func WithSessionToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries sets how many times an idempotent request (GET, PUT, DELETE) is retried
+// after a network error or a 429, 502, 503 or 504 response. Zero disables retries.
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		if n >= 0 {
+			c.retries = n
+		}
+	}
+}
+
+// WithRetryBackoff sets the delay before the first retry; it doubles for each further
+// attempt. A Retry-After header from the server takes precedence.
+func WithRetryBackoff(backoff time.Duration) Option {
+	return func(c *Client) {
+		if backoff >= 0 {
+			c.backoff = backoff
+		}
+	}
+}
+
+// New returns a client for the API served at baseURL, e.g. "http://127.0.0.1:8080".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base URL %q: %w", baseURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("client: base URL %q must be http or https", baseURL)
+	}
+	c := &Client{
+		baseURL: parsed,
+		http:    http.DefaultClient,
+		retries: defaultRetries,
+		backoff: defaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Sentinel errors matched by *Error through errors.Is, one per class of status.
+var (
+	ErrBadRequest    = errors.New("client: bad request")
+	ErrUnauthorized  = errors.New("client: unauthorized")
+	ErrNotFound      = errors.New("client: not found")
+	ErrConflict      = errors.New("client: conflict")
+	ErrUnprocessable = errors.New("client: unprocessable")
+	ErrUnavailable   = errors.New("client: service unavailable")
+	ErrServer        = errors.New("client: server error")
+)
+
+// Error is a non-2xx response. Code is the server's machine-readable error code, such
+// as "invalid_input" or "duplicate_id".
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("client: %d %s", e.StatusCode, e.Code)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Unwrap maps the status to one of the sentinel errors, or nil for statuses without one.
+func (e *Error) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrUnprocessable
+	case e.StatusCode == http.StatusServiceUnavailable:
+		return ErrUnavailable
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+// errorFromResponse reads an error body in either the default {"error", "code"} shape
+// or problem+json.
+func errorFromResponse(resp *http.Response) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Code:       strings.ToLower(strings.ReplaceAll(http.StatusText(resp.StatusCode), " ", "_")),
+		RequestID:  resp.Header.Get(headerRequestID),
+	}
+	var body struct {
+		Error  string `json:"error"`
+		Detail string `json:"detail"`
+		Code   string `json:"code"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if json.Unmarshal(raw, &body) != nil {
+		apiErr.Message = strings.TrimSpace(string(raw))
+		return apiErr
+	}
+	if body.Code != "" {
+		apiErr.Code = body.Code
+	}
+	apiErr.Message = body.Error
+	if apiErr.Message == "" {
+		apiErr.Message = body.Detail
+	}
+	return apiErr
+}
+
+// do sends a request with body encoded as JSON and decodes a 2xx response into out,
+// either of which may be nil. Idempotent methods are retried; see WithRetries.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("client: encode %s %s: %w", method, path, err)
+		}
+	}
+
+	attempts := 1
+	if idempotent(method) {
+		attempts += c.retries
+	}
+	delay := c.backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, path, query, payload)
+		retryAfter := time.Duration(0)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+		case retryable(resp.StatusCode):
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			err = errorFromResponse(resp)
+			resp.Body.Close()
+		default:
+			defer resp.Body.Close()
+			return decodeResponse(resp, out)
+		}
+		if attempt >= attempts {
+			return err
+		}
+
+		wait := delay
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, payload []byte) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, path, query)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Body = io.NopCloser(bytes.NewReader(payload))
+		req.ContentLength = int64(len(payload))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(payload)), nil }
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.http.Do(req)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values) (*http.Request, error) {
+	target := *c.baseURL
+	target.Path = c.baseURL.Path + path
+	target.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("client: build %s %s: %w", method, path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set(headerSessionToken, c.token)
+	}
+	return req, nil
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errorFromResponse(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: decode %s %s: %w", resp.Request.Method, resp.Request.URL.Path, err)
+	}
+	return nil
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After given in seconds; HTTP dates are ignored.
+func parseRetryAfter(v string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return min(time.Duration(seconds)*time.Second, maxRetryAfter)
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/config"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+	"github.com/jcleow/assetra2/internal/server"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(newTestHandler(t, config.Config{}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// newTestHandler serves the real router over an empty in-memory repository with
+// events enabled.
+func newTestHandler(t *testing.T, cfg config.Config) http.Handler {
+	t.Helper()
+	cfg.BaseCurrency = "USD"
+	cfg.EventsEnabled = true
+	cfg.EventMaxHistory = 16
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	srv := server.New(cfg, logger, memory.NewRepository(finance.SeedData{}))
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+	return srv.Handler()
+}
+
+func newTestClient(t *testing.T, baseURL string, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(baseURL, opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+func TestClientAssetRoundTrip(t *testing.T) {
+	ts := newTestServer(t)
+	assets := newTestClient(t, ts.URL).Assets()
+	ctx := context.Background()
+
+	created, err := assets.Create(ctx, finance.Asset{Name: "Brokerage", Category: "investments", CurrentValue: 1200, Notes: "taxable"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID == "" || created.UpdatedAt.IsZero() {
+		t.Fatalf("expected server-assigned id and updatedAt, got %+v", created)
+	}
+
+	created.CurrentValue = 1500
+	created.Notes = ""
+	updated, err := assets.Update(ctx, created.ID, created)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.CurrentValue != 1500 || updated.Notes != "" {
+		t.Fatalf("expected PUT to replace the asset, got %+v", updated)
+	}
+
+	got, err := assets.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Name != "Brokerage" || got.CurrentValue != 1500 {
+		t.Fatalf("unexpected asset %+v", got)
+	}
+	list, err := assets.List(ctx)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List = %v, %v; want one asset", list, err)
+	}
+
+	if err := assets.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	_, err = assets.Get(ctx, created.ID)
+	var apiErr *Error
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestClientMapsValidationErrors(t *testing.T) {
+	ts := newTestServer(t)
+	c := newTestClient(t, ts.URL)
+
+	_, err := c.Expenses().Create(context.Background(), finance.Expense{Payee: "Rent", Amount: -1, Frequency: finance.FrequencyMonthly, Category: "housing"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if !errors.Is(err, ErrBadRequest) || apiErr.Code != "invalid_input" || apiErr.Message == "" {
+		t.Fatalf("unexpected error %+v", apiErr)
+	}
+}
+
+func TestClientCashFlow(t *testing.T) {
+	ts := newTestServer(t)
+	c := newTestClient(t, ts.URL)
+	ctx := context.Background()
+
+	if _, err := c.Incomes().Create(ctx, finance.Income{Source: "Salary", Amount: 5000, Frequency: finance.FrequencyMonthly, StartDate: time.Now().UTC().AddDate(0, -1, 0), Category: "salary"}); err != nil {
+		t.Fatalf("create income: %v", err)
+	}
+	if _, err := c.Expenses().Create(ctx, finance.Expense{Payee: "Rent", Amount: 2000, Frequency: finance.FrequencyMonthly, Category: "housing"}); err != nil {
+		t.Fatalf("create expense: %v", err)
+	}
+
+	flow, err := c.CashFlow(ctx)
+	if err != nil {
+		t.Fatalf("CashFlow: %v", err)
+	}
+	if len(flow.Incomes) != 1 || len(flow.Expenses) != 1 || flow.Summary.NetMonthly != 3000 {
+		t.Fatalf("unexpected cash flow %+v", flow)
+	}
+	forecast, err := c.Forecast(ctx, 2)
+	if err != nil || len(forecast) != 2 {
+		t.Fatalf("Forecast = %v, %v; want two months", forecast, err)
+	}
+}
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"id":"acct-1","name":"Checking"}]`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts.URL, WithRetryBackoff(time.Millisecond))
+	accounts, err := c.Accounts().List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(accounts) != 1 || calls.Load() != 3 {
+		t.Fatalf("got %v after %d calls", accounts, calls.Load())
+	}
+
+	calls.Store(0)
+	_, err = c.Accounts().Create(context.Background(), finance.Account{Name: "Savings"})
+	if !errors.Is(err, ErrUnavailable) || calls.Load() != 1 {
+		t.Fatalf("expected POST not to be retried, got %v after %d calls", err, calls.Load())
+	}
+}
+
+func TestClientStopsRetryingWhenContextEnds(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := newTestClient(t, ts.URL).Assets().List(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("retry wait ignored the context deadline (took %v)", elapsed)
+	}
+}
+
+func TestClientEventsStream(t *testing.T) {
+	ts := newTestServer(t)
+	c := newTestClient(t, ts.URL, WithSessionToken("test-session"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := c.Events(ctx, "")
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	defer stream.Close()
+
+	created, err := c.Assets().Create(ctx, finance.Asset{Name: "Cash", Category: "cash", CurrentValue: 100})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	evt, err := stream.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if evt.Entity != "asset" || evt.Action != "create" || evt.ResourceID != created.ID {
+		t.Fatalf("unexpected event %+v", evt)
+	}
+	var asset finance.Asset
+	if err := DecodeData(evt, &asset); err != nil || asset.Name != "Cash" {
+		t.Fatalf("Decode = %+v, %v", asset, err)
+	}
+	if stream.Cursor() != evt.Cursor {
+		t.Fatalf("stream cursor %q, want %q", stream.Cursor(), evt.Cursor)
+	}
+}
+
+func TestClientEventsRequireSession(t *testing.T) {
+	ts := newTestServer(t)
+	_, err := newTestClient(t, ts.URL).Events(context.Background(), "")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected unauthorized without a session token, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+)
+
+// DecodeData unmarshals an event's data into v, e.g. a *finance.Asset for an
+// "asset.update" event. Events read by this package carry their data as
+// json.RawMessage.
+func DecodeData(evt events.StreamEvent, v any) error {
+	raw, ok := evt.Data.(json.RawMessage)
+	if !ok || len(raw) == 0 {
+		return fmt.Errorf("client: %s.%s event has no data", evt.Entity, evt.Action)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// wireEvent decodes a frame's data, keeping the event data undecoded.
+type wireEvent struct {
+	events.StreamEvent
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+func (e wireEvent) event() events.StreamEvent {
+	evt := e.StreamEvent
+	evt.Data = nil
+	if len(e.Data) > 0 {
+		evt.Data = e.Data
+	}
+	return evt
+}
+
+// EventStream reads events from one /events connection. It is not safe for concurrent
+// use.
+type EventStream struct {
+	body    io.ReadCloser
+	reader  *bufio.Reader
+	pending []events.StreamEvent
+	cursor  string
+	// retry is the reconnection delay the server last asked for, or zero.
+	retry time.Duration
+}
+
+// Events opens /events. An empty cursor starts live; "0" or "all" replays retained
+// history first, and any other cursor resumes after that event. The stream ends with
+// io.EOF when the server closes it or asks the client to reconnect; resume from
+// Cursor.
+func (c *Client) Events(ctx context.Context, cursor string) (*EventStream, error) {
+	var query url.Values
+	if cursor != "" {
+		query = url.Values{"cursor": {cursor}}
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, "/events", query)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, errorFromResponse(resp)
+	}
+	return &EventStream{body: resp.Body, reader: bufio.NewReader(resp.Body), cursor: cursor}, nil
+}
+
+// Cursor is the id of the last frame read, or the cursor the stream was opened with.
+func (s *EventStream) Cursor() string { return s.cursor }
+
+// Close ends the stream.
+func (s *EventStream) Close() error { return s.body.Close() }
+
+// Next blocks until the next event arrives. Events the server sent as one "batch"
+// frame are returned one at a time. Data is left as json.RawMessage; see DecodeData.
+func (s *EventStream) Next() (events.StreamEvent, error) {
+	for len(s.pending) == 0 {
+		name, data, err := s.readFrame()
+		if err != nil {
+			return events.StreamEvent{}, err
+		}
+		switch name {
+		case "reconnect":
+			return events.StreamEvent{}, io.EOF
+		case "batch":
+			var batch []wireEvent
+			if err := json.Unmarshal(data, &batch); err != nil {
+				return events.StreamEvent{}, fmt.Errorf("client: decode event batch: %w", err)
+			}
+			for _, evt := range batch {
+				s.pending = append(s.pending, evt.event())
+			}
+		default:
+			var evt wireEvent
+			if err := json.Unmarshal(data, &evt); err != nil {
+				return events.StreamEvent{}, fmt.Errorf("client: decode %s event: %w", name, err)
+			}
+			s.pending = append(s.pending, evt.event())
+		}
+	}
+	evt := s.pending[0]
+	s.pending = s.pending[1:]
+	return evt, nil
+}
+
+// readFrame reads up to the next blank line that ends a frame with data, skipping
+// comments such as the heartbeat pings.
+func (s *EventStream) readFrame() (string, []byte, error) {
+	var name string
+	var data []string
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				return "", nil, io.EOF
+			}
+			if err != io.EOF {
+				return "", nil, err
+			}
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if len(data) > 0 {
+				return name, []byte(strings.Join(data, "\n")), nil
+			}
+			name = ""
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			s.cursor = value
+		case "event":
+			name = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/jcleow/assetra2/internal/finance"
+)
+
+// Collection is the typed CRUD surface for one resource, such as /assets.
+type Collection[T any] struct {
+	c    *Client
+	path string
+	// updateMethod is PUT for resources that accept a full replacement and PATCH for
+	// those that only accept a merge.
+	updateMethod string
+}
+
+// List returns every item in the collection.
+func (col Collection[T]) List(ctx context.Context) ([]T, error) {
+	var items []T
+	if err := col.c.do(ctx, http.MethodGet, col.path, nil, nil, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Get returns the item with id, or an error matching ErrNotFound.
+func (col Collection[T]) Get(ctx context.Context, id string) (T, error) {
+	var item T
+	err := col.c.do(ctx, http.MethodGet, col.itemPath(id), nil, nil, &item)
+	return item, err
+}
+
+// Create stores item and returns it as the server saved it. An empty ID is assigned by
+// the server.
+func (col Collection[T]) Create(ctx context.Context, item T) (T, error) {
+	body, err := requestBody(item)
+	if err != nil {
+		return item, err
+	}
+	var created T
+	err = col.c.do(ctx, http.MethodPost, col.path, nil, body, &created)
+	return created, err
+}
+
+// Update writes item over the stored item with id and returns the result. Accounts,
+// goals and transactions are merged with PATCH, so an omitted optional field such as
+// an empty institution keeps its stored value; every other resource is replaced.
+func (col Collection[T]) Update(ctx context.Context, id string, item T) (T, error) {
+	body, err := requestBody(item)
+	if err != nil {
+		return item, err
+	}
+	var updated T
+	err = col.c.do(ctx, col.updateMethod, col.itemPath(id), nil, body, &updated)
+	return updated, err
+}
+
+// Delete removes the item with id.
+func (col Collection[T]) Delete(ctx context.Context, id string) error {
+	return col.c.do(ctx, http.MethodDelete, col.itemPath(id), nil, nil, nil)
+}
+
+func (col Collection[T]) itemPath(id string) string {
+	return col.path + "/" + url.PathEscape(id)
+}
+
+// requestBody re-encodes item without updatedAt: the finance types carry it, but the
+// server sets it and rejects payloads that include it.
+func requestBody(item any) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("client: encode request: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("client: encode request: %w", err)
+	}
+	delete(fields, "updatedAt")
+	return fields, nil
+}
+
+// Accounts is the /accounts collection.
+func (c *Client) Accounts() Collection[finance.Account] {
+	return Collection[finance.Account]{c: c, path: "/accounts", updateMethod: http.MethodPatch}
+}
+
+// Assets is the /assets collection.
+func (c *Client) Assets() Collection[finance.Asset] {
+	return Collection[finance.Asset]{c: c, path: "/assets", updateMethod: http.MethodPut}
+}
+
+// Liabilities is the /liabilities collection.
+func (c *Client) Liabilities() Collection[finance.Liability] {
+	return Collection[finance.Liability]{c: c, path: "/liabilities", updateMethod: http.MethodPut}
+}
+
+// Incomes is the /cashflow/incomes collection.
+func (c *Client) Incomes() Collection[finance.Income] {
+	return Collection[finance.Income]{c: c, path: "/cashflow/incomes", updateMethod: http.MethodPut}
+}
+
+// Expenses is the /cashflow/expenses collection.
+func (c *Client) Expenses() Collection[finance.Expense] {
+	return Collection[finance.Expense]{c: c, path: "/cashflow/expenses", updateMethod: http.MethodPut}
+}
+
+// Goals is the /goals collection.
+func (c *Client) Goals() Collection[finance.Goal] {
+	return Collection[finance.Goal]{c: c, path: "/goals", updateMethod: http.MethodPatch}
+}
+
+// Transactions is the /transactions collection.
+func (c *Client) Transactions() Collection[finance.Transaction] {
+	return Collection[finance.Transaction]{c: c, path: "/transactions", updateMethod: http.MethodPatch}
+}
+
+// PropertyScenarios is the /property-planner/scenarios collection.
+func (c *Client) PropertyScenarios() Collection[finance.PropertyPlannerScenario] {
+	return Collection[finance.PropertyPlannerScenario]{c: c, path: "/property-planner/scenarios", updateMethod: http.MethodPut}
+}
+
+// CashFlow is the GET /cashflow response: every income and expense with their
+// monthly totals in the base currency.
+type CashFlow struct {
+	Incomes  []finance.Income        `json:"incomes"`
+	Expenses []finance.Expense       `json:"expenses"`
+	Summary  finance.CashFlowSummary `json:"summary"`
+}
+
+// CashFlow returns the monthly cash-flow summary.
+func (c *Client) CashFlow(ctx context.Context) (CashFlow, error) {
+	var flow CashFlow
+	err := c.do(ctx, http.MethodGet, "/cashflow", nil, nil, &flow)
+	return flow, err
+}
+
+// Forecast returns the expected cash events grouped by month for the next months
+// months; zero uses the server's default span.
+func (c *Client) Forecast(ctx context.Context, months int) ([]finance.ForecastMonth, error) {
+	var forecast []finance.ForecastMonth
+	err := c.do(ctx, http.MethodGet, "/cashflow/forecast", spanQuery("months", months), nil, &forecast)
+	return forecast, err
+}
+
+// Upcoming returns the cash events due in the next days days; zero uses the server's
+// default window.
+func (c *Client) Upcoming(ctx context.Context, days int) ([]finance.CashEvent, error) {
+	var upcoming []finance.CashEvent
+	err := c.do(ctx, http.MethodGet, "/cashflow/upcoming", spanQuery("days", days), nil, &upcoming)
+	return upcoming, err
+}
+
+// Alerts returns the cash-flow alerts raised against the server's thresholds.
+func (c *Client) Alerts(ctx context.Context) ([]finance.Alert, error) {
+	var alerts []finance.Alert
+	err := c.do(ctx, http.MethodGet, "/cashflow/alerts", nil, nil, &alerts)
+	return alerts, err
+}
+
+func spanQuery(name string, n int) url.Values {
+	if n <= 0 {
+		return nil
+	}
+	return url.Values{name: {strconv.Itoa(n)}}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+)
+
+// maxReconnectBackoff caps the delay between failed reconnection attempts.
+const maxReconnectBackoff = 30 * time.Second
+
+// Subscription delivers /events on a channel, reconnecting from the last cursor
+// whenever the stream drops. Create one with Client.Subscribe.
+type Subscription struct {
+	events chan events.StreamEvent
+
+	mu     sync.Mutex
+	cursor string
+	err    error
+}
+
+// Subscribe streams events until ctx ends or the server refuses the stream outright,
+// e.g. for a missing session token or a malformed cursor. cursor selects the starting
+// point as for Events. After a disconnect it reconnects from the last cursor it saw,
+// so no retained event is missed or repeated. Between attempts it waits the delay the
+// server asked for or else the client's retry backoff, doubling to 30s while
+// connections keep failing.
+//
+// A subscription opened with an empty cursor that drops before any event arrives has
+// no cursor to resume from and reconnects live.
+func (c *Client) Subscribe(ctx context.Context, cursor string) *Subscription {
+	sub := &Subscription{events: make(chan events.StreamEvent), cursor: cursor}
+	go sub.run(ctx, c)
+	return sub
+}
+
+// Events is closed when the subscription ends; Err then reports why.
+func (s *Subscription) Events() <-chan events.StreamEvent { return s.events }
+
+// Cursor is the cursor of the last event delivered, which a later subscription can
+// resume from.
+func (s *Subscription) Cursor() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor
+}
+
+// Err reports why the subscription ended: the context's error, or the *Error the
+// server refused the stream with. It is nil while events are still flowing.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription) run(ctx context.Context, c *Client) {
+	defer close(s.events)
+	initial := c.backoff
+	if initial <= 0 {
+		initial = defaultRetryBackoff
+	}
+	backoff := initial
+	for {
+		var wait time.Duration
+		stream, err := c.Events(ctx, s.Cursor())
+		if err != nil {
+			var apiErr *Error
+			if ctx.Err() == nil && errors.As(err, &apiErr) && !retryable(apiErr.StatusCode) {
+				s.finish(err)
+				return
+			}
+		} else if delivered, retry := s.consume(ctx, stream); delivered || retry > 0 {
+			backoff, wait = initial, retry
+		}
+		if wait == 0 {
+			wait = backoff
+			backoff = min(backoff*2, maxReconnectBackoff)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.finish(ctx.Err())
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// consume delivers events from one connection until it ends. It reports whether any
+// event got through and the reconnection delay the server asked for, if any.
+func (s *Subscription) consume(ctx context.Context, stream *EventStream) (bool, time.Duration) {
+	defer stream.Close()
+	delivered := false
+	for {
+		evt, err := stream.Next()
+		if err != nil {
+			return delivered, stream.retry
+		}
+		// Move the cursor before sending, so a caller reading Cursor after receiving
+		// sees this event's, and put it back if the event never goes out.
+		previous := s.setCursor(evt.Cursor)
+		select {
+		case s.events <- evt:
+		case <-ctx.Done():
+			s.setCursor(previous)
+			return delivered, 0
+		}
+		delivered = true
+	}
+}
+
+func (s *Subscription) setCursor(cursor string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.cursor
+	s.cursor = cursor
+	return previous
+}
+
+func (s *Subscription) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
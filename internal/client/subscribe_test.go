@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/config"
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+)
+
+// streamRecorder notes the cursor of every /events request it passes on, and can end
+// the open streams without touching other connections.
+type streamRecorder struct {
+	next    http.Handler
+	mu      sync.Mutex
+	cursors []string
+	cancels []context.CancelFunc
+}
+
+func (h *streamRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/events" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	h.mu.Lock()
+	h.cursors = append(h.cursors, r.URL.Query().Get("cursor"))
+	h.cancels = append(h.cancels, cancel)
+	h.mu.Unlock()
+	h.next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// drop ends every stream opened so far.
+func (h *streamRecorder) drop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, cancel := range h.cancels {
+		cancel()
+	}
+}
+
+func (h *streamRecorder) seen() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.cursors...)
+}
+
+func nextEvent(t *testing.T, sub *Subscription) events.StreamEvent {
+	t.Helper()
+	select {
+	case evt, ok := <-sub.Events():
+		if !ok {
+			t.Fatalf("subscription ended: %v", sub.Err())
+		}
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return events.StreamEvent{}
+}
+
+func waitForStreams(t *testing.T, rec *streamRecorder, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.seen()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d event stream requests, saw %v", n, rec.seen())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSubscribeResumesFromCursorAfterDisconnect(t *testing.T) {
+	rec := &streamRecorder{next: newTestHandler(t, config.Config{})}
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+	c := newTestClient(t, ts.URL, WithSessionToken("test-session"), WithRetryBackoff(10*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := c.Subscribe(ctx, "")
+	waitForStreams(t, rec, 1)
+	first, err := c.Assets().Create(ctx, finance.Asset{Name: "Cash", Category: "cash", CurrentValue: 100})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	evt := nextEvent(t, sub)
+	if evt.ResourceID != first.ID || sub.Cursor() != evt.Cursor {
+		t.Fatalf("unexpected first event %+v (cursor %q)", evt, sub.Cursor())
+	}
+	firstCursor := evt.Cursor
+
+	// Drop the stream, then publish while the client may still be reconnecting.
+	rec.drop()
+	second, err := c.Assets().Create(ctx, finance.Asset{Name: "Savings", Category: "cash", CurrentValue: 200})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	evt = nextEvent(t, sub)
+	if evt.ResourceID != second.ID {
+		t.Fatalf("expected the event published during the disconnect, got %+v", evt)
+	}
+
+	// The next event after the first is the second: nothing was replayed twice, and the
+	// reconnection asked to resume after the first.
+	if cursors := rec.seen(); len(cursors) < 2 || cursors[0] != "" || cursors[1] != firstCursor {
+		t.Fatalf("expected a live start then a resume from %q, got cursors %v", firstCursor, cursors)
+	}
+
+	cancel()
+	for range sub.Events() {
+	}
+	if !errors.Is(sub.Err(), context.Canceled) {
+		t.Fatalf("expected the subscription to end with the context, got %v", sub.Err())
+	}
+}
+
+func TestSubscribeStopsWhenStreamIsRefused(t *testing.T) {
+	ts := newTestServer(t)
+	sub := newTestClient(t, ts.URL).Subscribe(context.Background(), "")
+
+	select {
+	case _, ok := <-sub.Events():
+		if ok {
+			t.Fatal("expected no events without a session token")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription kept retrying a refused stream")
+	}
+	if !errors.Is(sub.Err(), ErrUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", sub.Err())
+	}
+}
@@ -3,33 +3,139 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	// Embedded so APP_TIMEZONE resolves in images without a system zoneinfo database.
+	_ "time/tzdata"
 )
 
 // Config captures runtime settings for the Go service.
 type Config struct {
-	AppEnv            string
-	Host              string
-	Port              int
+	AppEnv string
+	Host   string
+	Port   int
+	// ListenNetwork is "tcp" (default) or "unix".
+	ListenNetwork string
+	// ListenAddr overrides SERVER_HOST:SERVER_PORT; for unix it is the socket path.
+	ListenAddr        string
 	LogLevel          string
 	ShutdownTimeout   time.Duration
 	ReadHeaderTimeout time.Duration
-	DatabaseURL       string
+	// HealthCheckTimeout bounds each /health/ready run; checks still going are failed.
+	HealthCheckTimeout   time.Duration
+	DatabaseURL          string
+	DatabaseReplicaURL   string
+	DBConnectRetries     int
+	DBConnectBackoff     time.Duration
+	DBStatementTimeout   time.Duration
+	DBSlowQueryThreshold time.Duration
+	// SlowRequestThreshold logs a warning for HTTP requests slower than this, other than
+	// event streams; zero disables it.
+	SlowRequestThreshold time.Duration
+	// EventsEnabled publishes change events to /events subscribers. Bulk jobs turn it
+	// off to run without flooding the stream.
+	EventsEnabled       bool
+	EventMaxHistory     int
+	EventDebounceWindow time.Duration
+	EventBufferSize     int
+	// EventMaxSubscribers caps concurrent SSE connections; zero means no limit.
+	EventMaxSubscribers int
+	// EventStreamMaxLifetime closes SSE streams after this long, asking the client to
+	// reconnect; zero keeps streams open until the client disconnects.
+	EventStreamMaxLifetime time.Duration
+	// NetWorthSnapshotInterval controls how often net worth is recorded; zero disables it.
+	NetWorthSnapshotInterval time.Duration
+	// BaseCurrency is the ISO 4217 code that aggregates are reported in.
+	BaseCurrency string
+	// FXRates maps currency codes to their value in BaseCurrency; empty disables conversion.
+	FXRates map[string]float64
+	// DefaultFrequency is applied to incomes and expenses submitted without a frequency.
+	DefaultFrequency string
+	// Location anchors cash-flow month and day boundaries (APP_TIMEZONE, IANA name).
+	Location *time.Location
+	// TrustedProxies lists the networks allowed to report client IPs via forwarding headers.
+	TrustedProxies []netip.Prefix
+	// AlertMinSavingsRate is the share of income below which /cashflow/alerts warns.
+	AlertMinSavingsRate float64
+	// AlertMinRunwayMonths is how many months of expenses the emergency fund should cover.
+	AlertMinRunwayMonths float64
+	// AlertEmergencyCategory is the asset category counted as the emergency fund.
+	AlertEmergencyCategory string
+	// ScenarioCompression gzips large property scenario documents in Postgres.
+	ScenarioCompression bool
+	// NotesSanitize is "strip" or "escape": how HTML in notes fields is neutralised.
+	NotesSanitize string
+	// AssetCategories, LiabilityCategories, IncomeCategories and ExpenseCategories are
+	// the canonical categories offered by the /categories endpoints in addition to
+	// those in use.
+	AssetCategories     []string
+	LiabilityCategories []string
+	IncomeCategories    []string
+	ExpenseCategories   []string
+	// CategoryStrict rejects categories outside an entity type's canonical list; types
+	// without a list accept any category.
+	CategoryStrict bool
+	// MaxNameLength caps names, categories, payees, sources and institutions (1–200).
+	MaxNameLength int
+	// MaxNotesLength caps notes and memos (1–2000).
+	MaxNotesLength int
+	// AdminToken enables the /admin endpoints, which require it as the session token.
+	AdminToken string
+	// MaintenanceMode is empty for normal service or "readonly" to reject writes.
+	MaintenanceMode string
+	// ServerTiming adds a Server-Timing header splitting each response's time into
+	// store calls and JSON encoding.
+	ServerTiming bool
+	// ScenarioRecomputeInterval is how often stale property scenarios are recomputed;
+	// zero disables the job.
+	ScenarioRecomputeInterval time.Duration
+	// ScenarioRecomputeTolerance is how far a stored payment or total interest may drift
+	// from a fresh calculation before the scenario counts as stale.
+	ScenarioRecomputeTolerance float64
 }
 
 // Load builds a Config from environment variables, applying sensible defaults.
 func Load() (Config, error) {
 	cfg := Config{
-		AppEnv:            getString("APP_ENV", "development"),
-		Host:              getString("SERVER_HOST", "0.0.0.0"),
-		Port:              8080,
-		LogLevel:          strings.ToLower(getString("LOG_LEVEL", "info")),
-		ShutdownTimeout:   10 * time.Second,
-		ReadHeaderTimeout: 5 * time.Second,
-		DatabaseURL:       resolveDatabaseURL(),
+		AppEnv:                     getString("APP_ENV", "development"),
+		Host:                       getString("SERVER_HOST", "0.0.0.0"),
+		Port:                       8080,
+		ListenNetwork:              strings.ToLower(getString("LISTEN_NETWORK", "tcp")),
+		ListenAddr:                 strings.TrimSpace(os.Getenv("LISTEN_ADDR")),
+		LogLevel:                   strings.ToLower(getString("LOG_LEVEL", "info")),
+		ShutdownTimeout:            10 * time.Second,
+		ReadHeaderTimeout:          5 * time.Second,
+		HealthCheckTimeout:         2 * time.Second,
+		DatabaseURL:                resolveDatabaseURL(),
+		DatabaseReplicaURL:         strings.TrimSpace(os.Getenv("DATABASE_REPLICA_URL")),
+		DBConnectRetries:           5,
+		DBConnectBackoff:           500 * time.Millisecond,
+		DBStatementTimeout:         5 * time.Second,
+		DBSlowQueryThreshold:       500 * time.Millisecond,
+		SlowRequestThreshold:       time.Second,
+		EventsEnabled:              true,
+		EventMaxHistory:            256,
+		EventDebounceWindow:        100 * time.Millisecond,
+		EventBufferSize:            32,
+		EventStreamMaxLifetime:     time.Hour,
+		NetWorthSnapshotInterval:   24 * time.Hour,
+		BaseCurrency:               strings.ToUpper(getString("BASE_CURRENCY", "USD")),
+		DefaultFrequency:           strings.ToLower(getString("DEFAULT_FREQUENCY", "monthly")),
+		Location:                   time.UTC,
+		AlertMinSavingsRate:        0.1,
+		AlertMinRunwayMonths:       3,
+		AlertEmergencyCategory:     getString("ALERT_EMERGENCY_CATEGORY", "cash"),
+		ScenarioRecomputeTolerance: 1,
+		AdminToken:                 strings.TrimSpace(os.Getenv("ADMIN_TOKEN")),
+		MaintenanceMode:            strings.ToLower(strings.TrimSpace(os.Getenv("MAINTENANCE_MODE"))),
+		NotesSanitize:              strings.ToLower(getString("NOTES_SANITIZE", "strip")),
+		MaxNameLength:              200,
+		MaxNotesLength:             2000,
 	}
 
 	if v := os.Getenv("SERVER_PORT"); v != "" {
@@ -56,6 +162,211 @@ func Load() (Config, error) {
 		cfg.ReadHeaderTimeout = duration
 	}
 
+	if v := os.Getenv("HEALTH_CHECK_TIMEOUT"); v != "" {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid HEALTH_CHECK_TIMEOUT %q: %w", v, err)
+		}
+		cfg.HealthCheckTimeout = duration
+	}
+
+	if v := os.Getenv("DB_CONNECT_RETRIES"); v != "" {
+		retries, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid DB_CONNECT_RETRIES %q: %w", v, err)
+		}
+		cfg.DBConnectRetries = retries
+	}
+
+	if v := os.Getenv("DB_CONNECT_BACKOFF"); v != "" {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid DB_CONNECT_BACKOFF %q: %w", v, err)
+		}
+		cfg.DBConnectBackoff = duration
+	}
+
+	if v := os.Getenv("DB_STATEMENT_TIMEOUT"); v != "" {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid DB_STATEMENT_TIMEOUT %q: %w", v, err)
+		}
+		cfg.DBStatementTimeout = duration
+	}
+
+	if v := os.Getenv("DB_SLOW_QUERY_THRESHOLD"); v != "" {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid DB_SLOW_QUERY_THRESHOLD %q: %w", v, err)
+		}
+		cfg.DBSlowQueryThreshold = duration
+	}
+
+	if v := os.Getenv("SLOW_REQUEST_THRESHOLD"); v != "" {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid SLOW_REQUEST_THRESHOLD %q: %w", v, err)
+		}
+		cfg.SlowRequestThreshold = duration
+	}
+
+	if v := os.Getenv("EVENTS_ENABLED"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid EVENTS_ENABLED %q: %w", v, err)
+		}
+		cfg.EventsEnabled = enabled
+	}
+
+	if v := os.Getenv("EVENT_MAX_HISTORY"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid EVENT_MAX_HISTORY %q: %w", v, err)
+		}
+		cfg.EventMaxHistory = size
+	}
+
+	if v := os.Getenv("EVENT_DEBOUNCE_WINDOW"); v != "" {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid EVENT_DEBOUNCE_WINDOW %q: %w", v, err)
+		}
+		cfg.EventDebounceWindow = duration
+	}
+
+	if v := os.Getenv("EVENT_BUFFER_SIZE"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid EVENT_BUFFER_SIZE %q: %w", v, err)
+		}
+		cfg.EventBufferSize = size
+	}
+
+	if v := os.Getenv("EVENT_MAX_SUBSCRIBERS"); v != "" {
+		max, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid EVENT_MAX_SUBSCRIBERS %q: %w", v, err)
+		}
+		cfg.EventMaxSubscribers = max
+	}
+
+	if v := os.Getenv("EVENT_STREAM_MAX_LIFETIME"); v != "" {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid EVENT_STREAM_MAX_LIFETIME %q: %w", v, err)
+		}
+		cfg.EventStreamMaxLifetime = duration
+	}
+
+	if v := os.Getenv("NET_WORTH_SNAPSHOT_INTERVAL"); v != "" {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid NET_WORTH_SNAPSHOT_INTERVAL %q: %w", v, err)
+		}
+		cfg.NetWorthSnapshotInterval = duration
+	}
+
+	if v := strings.TrimSpace(os.Getenv("FX_RATES")); v != "" {
+		rates, err := parseRates(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid FX_RATES %q: %w", v, err)
+		}
+		cfg.FXRates = rates
+	}
+
+	if v := strings.TrimSpace(os.Getenv("APP_TIMEZONE")); v != "" {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid APP_TIMEZONE %q: %w", v, err)
+		}
+		cfg.Location = loc
+	}
+
+	if v := strings.TrimSpace(os.Getenv("TRUSTED_PROXIES")); v != "" {
+		prefixes, err := parsePrefixes(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid TRUSTED_PROXIES %q: %w", v, err)
+		}
+		cfg.TrustedProxies = prefixes
+	}
+
+	if v := os.Getenv("ALERT_MIN_SAVINGS_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid ALERT_MIN_SAVINGS_RATE %q: %w", v, err)
+		}
+		cfg.AlertMinSavingsRate = rate
+	}
+
+	if v := os.Getenv("ALERT_MIN_RUNWAY_MONTHS"); v != "" {
+		months, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid ALERT_MIN_RUNWAY_MONTHS %q: %w", v, err)
+		}
+		cfg.AlertMinRunwayMonths = months
+	}
+
+	if v := os.Getenv("SCENARIO_COMPRESSION"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid SCENARIO_COMPRESSION %q: %w", v, err)
+		}
+		cfg.ScenarioCompression = enabled
+	}
+
+	if v := os.Getenv("SERVER_TIMING"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid SERVER_TIMING %q: %w", v, err)
+		}
+		cfg.ServerTiming = enabled
+	}
+
+	cfg.AssetCategories = parseList(os.Getenv("ASSET_CATEGORIES"))
+	cfg.LiabilityCategories = parseList(os.Getenv("LIABILITY_CATEGORIES"))
+	cfg.IncomeCategories = parseList(os.Getenv("INCOME_CATEGORIES"))
+	cfg.ExpenseCategories = parseList(os.Getenv("EXPENSE_CATEGORIES"))
+
+	if v := os.Getenv("CATEGORY_STRICT"); v != "" {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid CATEGORY_STRICT %q: %w", v, err)
+		}
+		cfg.CategoryStrict = strict
+	}
+
+	if v := os.Getenv("MAX_NAME_LENGTH"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid MAX_NAME_LENGTH %q: %w", v, err)
+		}
+		cfg.MaxNameLength = limit
+	}
+
+	if v := os.Getenv("MAX_NOTES_LENGTH"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid MAX_NOTES_LENGTH %q: %w", v, err)
+		}
+		cfg.MaxNotesLength = limit
+	}
+
+	if v := os.Getenv("SCENARIO_RECOMPUTE_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid SCENARIO_RECOMPUTE_INTERVAL %q: %w", v, err)
+		}
+		cfg.ScenarioRecomputeInterval = interval
+	}
+
+	if v := os.Getenv("SCENARIO_RECOMPUTE_TOLERANCE"); v != "" {
+		tolerance, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid SCENARIO_RECOMPUTE_TOLERANCE %q: %w", v, err)
+		}
+		cfg.ScenarioRecomputeTolerance = tolerance
+	}
+
 	if err := validate(cfg); err != nil {
 		return Config{}, err
 	}
@@ -63,8 +374,12 @@ func Load() (Config, error) {
 	return cfg, nil
 }
 
-// Addr returns the host:port pair for HTTP listeners.
+// Addr returns the address for HTTP listeners: LISTEN_ADDR when set, otherwise the
+// host:port pair.
 func (c Config) Addr() string {
+	if c.ListenAddr != "" {
+		return c.ListenAddr
+	}
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
@@ -79,15 +394,160 @@ func validate(cfg Config) error {
 	if cfg.Port <= 0 || cfg.Port > 65535 {
 		return errors.New("SERVER_PORT must be between 1 and 65535")
 	}
+	switch cfg.ListenNetwork {
+	case "tcp":
+	case "unix":
+		if cfg.ListenAddr == "" {
+			return errors.New("LISTEN_ADDR must be set to a socket path when LISTEN_NETWORK=unix")
+		}
+		if err := checkSocketDir(cfg.ListenAddr); err != nil {
+			return fmt.Errorf("LISTEN_ADDR %q is not usable: %w", cfg.ListenAddr, err)
+		}
+	default:
+		return errors.New("LISTEN_NETWORK must be tcp or unix")
+	}
 	if cfg.ShutdownTimeout <= 0 {
 		return errors.New("SHUTDOWN_TIMEOUT must be greater than zero")
 	}
 	if cfg.ReadHeaderTimeout <= 0 {
 		return errors.New("READ_HEADER_TIMEOUT must be greater than zero")
 	}
+	if cfg.HealthCheckTimeout <= 0 {
+		return errors.New("HEALTH_CHECK_TIMEOUT must be greater than zero")
+	}
+	if cfg.DBConnectRetries < 0 {
+		return errors.New("DB_CONNECT_RETRIES must not be negative")
+	}
+	if cfg.DBConnectBackoff <= 0 {
+		return errors.New("DB_CONNECT_BACKOFF must be greater than zero")
+	}
+	if cfg.DBStatementTimeout < 0 {
+		return errors.New("DB_STATEMENT_TIMEOUT must not be negative")
+	}
+	if cfg.DBSlowQueryThreshold < 0 {
+		return errors.New("DB_SLOW_QUERY_THRESHOLD must not be negative")
+	}
+	if cfg.SlowRequestThreshold < 0 {
+		return errors.New("SLOW_REQUEST_THRESHOLD must not be negative")
+	}
+	if cfg.EventMaxHistory < 0 {
+		return errors.New("EVENT_MAX_HISTORY must not be negative")
+	}
+	if cfg.EventDebounceWindow < 0 {
+		return errors.New("EVENT_DEBOUNCE_WINDOW must not be negative")
+	}
+	if cfg.EventBufferSize < 0 {
+		return errors.New("EVENT_BUFFER_SIZE must not be negative")
+	}
+	if cfg.EventMaxSubscribers < 0 {
+		return errors.New("EVENT_MAX_SUBSCRIBERS must not be negative")
+	}
+	if cfg.EventStreamMaxLifetime < 0 {
+		return errors.New("EVENT_STREAM_MAX_LIFETIME must not be negative")
+	}
+	if cfg.NetWorthSnapshotInterval < 0 {
+		return errors.New("NET_WORTH_SNAPSHOT_INTERVAL must not be negative")
+	}
+	if cfg.ScenarioRecomputeInterval < 0 {
+		return errors.New("SCENARIO_RECOMPUTE_INTERVAL must not be negative")
+	}
+	if cfg.ScenarioRecomputeTolerance < 0 {
+		return errors.New("SCENARIO_RECOMPUTE_TOLERANCE must not be negative")
+	}
+	if cfg.AlertMinSavingsRate < 0 || cfg.AlertMinSavingsRate > 1 {
+		return errors.New("ALERT_MIN_SAVINGS_RATE must be between 0 and 1")
+	}
+	if cfg.AlertMinRunwayMonths < 0 {
+		return errors.New("ALERT_MIN_RUNWAY_MONTHS must not be negative")
+	}
+	if len(cfg.BaseCurrency) != 3 {
+		return errors.New("BASE_CURRENCY must be a three-letter currency code")
+	}
+	switch cfg.DefaultFrequency {
+	case "weekly", "biweekly", "monthly", "quarterly", "yearly":
+	default:
+		return errors.New("DEFAULT_FREQUENCY must be weekly, biweekly, monthly, quarterly or yearly")
+	}
+	// The upper bounds are the database column limits from migration 0013.
+	if cfg.MaxNameLength < 1 || cfg.MaxNameLength > 200 {
+		return errors.New("MAX_NAME_LENGTH must be between 1 and 200")
+	}
+	if cfg.MaxNotesLength < 1 || cfg.MaxNotesLength > 2000 {
+		return errors.New("MAX_NOTES_LENGTH must be between 1 and 2000")
+	}
+	if cfg.NotesSanitize != "strip" && cfg.NotesSanitize != "escape" {
+		return errors.New("NOTES_SANITIZE must be strip or escape")
+	}
+	if cfg.MaintenanceMode != "" && cfg.MaintenanceMode != "readonly" {
+		return errors.New("MAINTENANCE_MODE must be empty or readonly")
+	}
 	return nil
 }
 
+// checkSocketDir confirms the socket's directory exists and accepts new files.
+func checkSocketDir(path string) error {
+	probe, err := os.CreateTemp(filepath.Dir(path), ".listen-probe-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// parseRates reads comma-separated CODE=rate pairs, e.g. "EUR=1.08,SGD=0.74".
+func parseRates(raw string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(raw, ",") {
+		code, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected CODE=rate, got %q", pair)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate for %q must be a positive number", code)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates, nil
+}
+
+// parsePrefixes reads comma-separated CIDRs; a bare address is treated as a single host.
+func parsePrefixes(raw string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			addr, err := netip.ParseAddr(part)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(part)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// parseList splits a comma-separated setting, dropping blanks and repeats.
+func parseList(raw string) []string {
+	var items []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" && !slices.Contains(items, part) {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
 func resolveDatabaseURL() string {
 	if v := strings.TrimSpace(os.Getenv("DATABASE_URL")); v != "" {
 		return v
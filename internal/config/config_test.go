@@ -0,0 +1,369 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadParsesEventSettings(t *testing.T) {
+	t.Setenv("EVENT_MAX_HISTORY", "512")
+	t.Setenv("EVENT_DEBOUNCE_WINDOW", "250ms")
+	t.Setenv("EVENT_BUFFER_SIZE", "64")
+	t.Setenv("EVENT_MAX_SUBSCRIBERS", "100")
+	t.Setenv("EVENT_STREAM_MAX_LIFETIME", "15m")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.EventMaxHistory != 512 {
+		t.Fatalf("expected max history 512, got %d", cfg.EventMaxHistory)
+	}
+	if cfg.EventDebounceWindow != 250*time.Millisecond {
+		t.Fatalf("expected debounce window 250ms, got %s", cfg.EventDebounceWindow)
+	}
+	if cfg.EventBufferSize != 64 {
+		t.Fatalf("expected buffer size 64, got %d", cfg.EventBufferSize)
+	}
+	if cfg.EventMaxSubscribers != 100 {
+		t.Fatalf("expected max subscribers 100, got %d", cfg.EventMaxSubscribers)
+	}
+	if cfg.EventStreamMaxLifetime != 15*time.Minute {
+		t.Fatalf("expected stream lifetime 15m, got %s", cfg.EventStreamMaxLifetime)
+	}
+}
+
+func TestLoadEventDefaults(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.EventMaxHistory != 256 || cfg.EventDebounceWindow != 100*time.Millisecond || cfg.EventBufferSize != 32 || cfg.EventStreamMaxLifetime != time.Hour {
+		t.Fatalf("unexpected event defaults: %+v", cfg)
+	}
+}
+
+func TestLoadRejectsInvalidEventSettings(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"negative history", "EVENT_MAX_HISTORY", "-1"},
+		{"malformed history", "EVENT_MAX_HISTORY", "lots"},
+		{"negative debounce", "EVENT_DEBOUNCE_WINDOW", "-5ms"},
+		{"malformed debounce", "EVENT_DEBOUNCE_WINDOW", "soon"},
+		{"negative buffer", "EVENT_BUFFER_SIZE", "-8"},
+		{"negative subscriber limit", "EVENT_MAX_SUBSCRIBERS", "-1"},
+		{"negative stream lifetime", "EVENT_STREAM_MAX_LIFETIME", "-1m"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(tc.key, tc.value)
+			if _, err := Load(); err == nil {
+				t.Fatalf("expected error for %s=%q", tc.key, tc.value)
+			}
+		})
+	}
+}
+
+func TestLoadParsesCurrencySettings(t *testing.T) {
+	t.Setenv("BASE_CURRENCY", "sgd")
+	t.Setenv("FX_RATES", "USD=1.35, eur=1.45")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.BaseCurrency != "SGD" {
+		t.Fatalf("expected base currency SGD, got %q", cfg.BaseCurrency)
+	}
+	if cfg.FXRates["USD"] != 1.35 || cfg.FXRates["EUR"] != 1.45 {
+		t.Fatalf("unexpected rates %#v", cfg.FXRates)
+	}
+
+	t.Setenv("FX_RATES", "USD")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for malformed FX_RATES")
+	}
+}
+
+func TestLoadParsesCategoryLists(t *testing.T) {
+	t.Setenv("ASSET_CATEGORIES", " cash, brokerage,,cash ")
+	t.Setenv("EXPENSE_CATEGORIES", "housing")
+	t.Setenv("CATEGORY_STRICT", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if want := []string{"cash", "brokerage"}; !slices.Equal(cfg.AssetCategories, want) {
+		t.Fatalf("expected asset categories %v, got %v", want, cfg.AssetCategories)
+	}
+	if cfg.LiabilityCategories != nil {
+		t.Fatalf("expected no liability categories, got %v", cfg.LiabilityCategories)
+	}
+	if !cfg.CategoryStrict || !slices.Equal(cfg.ExpenseCategories, []string{"housing"}) {
+		t.Fatalf("expected strict expense categories [housing], got strict=%v %v", cfg.CategoryStrict, cfg.ExpenseCategories)
+	}
+
+	t.Setenv("CATEGORY_STRICT", "sometimes")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for malformed CATEGORY_STRICT")
+	}
+}
+
+func TestLoadParsesDatabaseConnectSettings(t *testing.T) {
+	t.Setenv("DB_CONNECT_RETRIES", "10")
+	t.Setenv("DB_CONNECT_BACKOFF", "2s")
+	t.Setenv("DB_STATEMENT_TIMEOUT", "750ms")
+	t.Setenv("DB_SLOW_QUERY_THRESHOLD", "200ms")
+	t.Setenv("SLOW_REQUEST_THRESHOLD", "2s")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.DBConnectRetries != 10 || cfg.DBConnectBackoff != 2*time.Second {
+		t.Fatalf("unexpected connect settings: retries=%d backoff=%s", cfg.DBConnectRetries, cfg.DBConnectBackoff)
+	}
+	if cfg.DBStatementTimeout != 750*time.Millisecond {
+		t.Fatalf("expected statement timeout 750ms, got %s", cfg.DBStatementTimeout)
+	}
+	if cfg.DBSlowQueryThreshold != 200*time.Millisecond {
+		t.Fatalf("expected slow query threshold 200ms, got %s", cfg.DBSlowQueryThreshold)
+	}
+	if cfg.SlowRequestThreshold != 2*time.Second {
+		t.Fatalf("expected slow request threshold 2s, got %s", cfg.SlowRequestThreshold)
+	}
+
+	t.Setenv("DB_CONNECT_RETRIES", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for negative DB_CONNECT_RETRIES")
+	}
+}
+
+func TestLoadListenNetwork(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "assetra.sock")
+	t.Setenv("LISTEN_NETWORK", "unix")
+	t.Setenv("LISTEN_ADDR", socket)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.ListenNetwork != "unix" || cfg.Addr() != socket {
+		t.Fatalf("expected unix listener on %s, got %s %s", socket, cfg.ListenNetwork, cfg.Addr())
+	}
+
+	t.Setenv("LISTEN_ADDR", filepath.Join(t.TempDir(), "missing", "assetra.sock"))
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a socket in a missing directory")
+	}
+
+	t.Setenv("LISTEN_NETWORK", "udp")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an unsupported network")
+	}
+}
+
+func TestLoadDefaultFrequency(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.DefaultFrequency != "monthly" {
+		t.Fatalf("expected monthly default, got %q", cfg.DefaultFrequency)
+	}
+
+	t.Setenv("DEFAULT_FREQUENCY", "Yearly")
+	if cfg, err = Load(); err != nil || cfg.DefaultFrequency != "yearly" {
+		t.Fatalf("expected yearly, got %q (%v)", cfg.DefaultFrequency, err)
+	}
+
+	t.Setenv("DEFAULT_FREQUENCY", "daily")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an unsupported frequency")
+	}
+}
+
+func TestLoadTimeZone(t *testing.T) {
+	cfg, err := Load()
+	if err != nil || cfg.Location != time.UTC {
+		t.Fatalf("expected UTC default, got %v (%v)", cfg.Location, err)
+	}
+
+	t.Setenv("APP_TIMEZONE", "Asia/Singapore")
+	if cfg, err = Load(); err != nil || cfg.Location.String() != "Asia/Singapore" {
+		t.Fatalf("expected Asia/Singapore, got %v (%v)", cfg.Location, err)
+	}
+
+	t.Setenv("APP_TIMEZONE", "Mars/Olympus_Mons")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "APP_TIMEZONE") {
+		t.Fatalf("expected APP_TIMEZONE error, got %v", err)
+	}
+}
+
+func TestLoadAlertThresholds(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.AlertMinSavingsRate != 0.1 || cfg.AlertMinRunwayMonths != 3 || cfg.AlertEmergencyCategory != "cash" {
+		t.Fatalf("unexpected alert defaults: %+v", cfg)
+	}
+
+	t.Setenv("ALERT_MIN_SAVINGS_RATE", "0.25")
+	t.Setenv("ALERT_MIN_RUNWAY_MONTHS", "6")
+	t.Setenv("ALERT_EMERGENCY_CATEGORY", "savings")
+	if cfg, err = Load(); err != nil || cfg.AlertMinSavingsRate != 0.25 || cfg.AlertMinRunwayMonths != 6 || cfg.AlertEmergencyCategory != "savings" {
+		t.Fatalf("unexpected alert settings %+v (%v)", cfg, err)
+	}
+
+	t.Setenv("ALERT_MIN_SAVINGS_RATE", "25")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a savings rate above 1")
+	}
+}
+
+func TestLoadFieldLengthLimits(t *testing.T) {
+	cfg, err := Load()
+	if err != nil || cfg.MaxNameLength != 200 || cfg.MaxNotesLength != 2000 {
+		t.Fatalf("unexpected length defaults %d/%d (%v)", cfg.MaxNameLength, cfg.MaxNotesLength, err)
+	}
+
+	t.Setenv("MAX_NAME_LENGTH", "80")
+	t.Setenv("MAX_NOTES_LENGTH", "500")
+	if cfg, err = Load(); err != nil || cfg.MaxNameLength != 80 || cfg.MaxNotesLength != 500 {
+		t.Fatalf("unexpected length limits %d/%d (%v)", cfg.MaxNameLength, cfg.MaxNotesLength, err)
+	}
+
+	t.Setenv("MAX_NAME_LENGTH", "500")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a name limit above the column limit")
+	}
+}
+
+func TestLoadNotesSanitize(t *testing.T) {
+	cfg, err := Load()
+	if err != nil || cfg.NotesSanitize != "strip" {
+		t.Fatalf("expected notes to be stripped by default, got %q (%v)", cfg.NotesSanitize, err)
+	}
+
+	t.Setenv("NOTES_SANITIZE", "Escape")
+	if cfg, err = Load(); err != nil || cfg.NotesSanitize != "escape" {
+		t.Fatalf("expected escape mode, got %q (%v)", cfg.NotesSanitize, err)
+	}
+
+	t.Setenv("NOTES_SANITIZE", "allow")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an unknown mode")
+	}
+}
+
+func TestLoadMaintenanceMode(t *testing.T) {
+	cfg, err := Load()
+	if err != nil || cfg.MaintenanceMode != "" {
+		t.Fatalf("expected no maintenance mode by default, got %q (%v)", cfg.MaintenanceMode, err)
+	}
+
+	t.Setenv("MAINTENANCE_MODE", "ReadOnly")
+	if cfg, err = Load(); err != nil || cfg.MaintenanceMode != "readonly" {
+		t.Fatalf("expected readonly, got %q (%v)", cfg.MaintenanceMode, err)
+	}
+
+	t.Setenv("MAINTENANCE_MODE", "offline")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an unknown mode")
+	}
+}
+
+func TestLoadHealthCheckTimeout(t *testing.T) {
+	cfg, err := Load()
+	if err != nil || cfg.HealthCheckTimeout != 2*time.Second {
+		t.Fatalf("expected a 2s default, got %v (%v)", cfg.HealthCheckTimeout, err)
+	}
+
+	t.Setenv("HEALTH_CHECK_TIMEOUT", "500ms")
+	if cfg, err = Load(); err != nil || cfg.HealthCheckTimeout != 500*time.Millisecond {
+		t.Fatalf("expected 500ms, got %v (%v)", cfg.HealthCheckTimeout, err)
+	}
+
+	t.Setenv("HEALTH_CHECK_TIMEOUT", "0s")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a zero timeout")
+	}
+}
+
+func TestLoadEventsEnabled(t *testing.T) {
+	cfg, err := Load()
+	if err != nil || !cfg.EventsEnabled {
+		t.Fatalf("expected events on by default, got %v (%v)", cfg.EventsEnabled, err)
+	}
+
+	t.Setenv("EVENTS_ENABLED", "false")
+	if cfg, err = Load(); err != nil || cfg.EventsEnabled {
+		t.Fatalf("expected events off, got %v (%v)", cfg.EventsEnabled, err)
+	}
+
+	t.Setenv("EVENTS_ENABLED", "quietly")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a non-boolean value")
+	}
+}
+
+func TestLoadScenarioCompression(t *testing.T) {
+	cfg, err := Load()
+	if err != nil || cfg.ScenarioCompression {
+		t.Fatalf("expected compression off by default, got %v (%v)", cfg.ScenarioCompression, err)
+	}
+
+	t.Setenv("SCENARIO_COMPRESSION", "true")
+	if cfg, err = Load(); err != nil || !cfg.ScenarioCompression {
+		t.Fatalf("expected compression on, got %v (%v)", cfg.ScenarioCompression, err)
+	}
+
+	t.Setenv("SCENARIO_COMPRESSION", "sometimes")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a non-boolean value")
+	}
+}
+
+func TestLoadScenarioRecompute(t *testing.T) {
+	cfg, err := Load()
+	if err != nil || cfg.ScenarioRecomputeInterval != 0 || cfg.ScenarioRecomputeTolerance != 1 {
+		t.Fatalf("unexpected recompute defaults %+v (%v)", cfg, err)
+	}
+
+	t.Setenv("SCENARIO_RECOMPUTE_INTERVAL", "6h")
+	t.Setenv("SCENARIO_RECOMPUTE_TOLERANCE", "0.5")
+	if cfg, err = Load(); err != nil || cfg.ScenarioRecomputeInterval != 6*time.Hour || cfg.ScenarioRecomputeTolerance != 0.5 {
+		t.Fatalf("unexpected recompute settings %+v (%v)", cfg, err)
+	}
+
+	t.Setenv("SCENARIO_RECOMPUTE_TOLERANCE", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for a negative tolerance")
+	}
+}
+
+func TestLoadTrustedProxies(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(cfg.TrustedProxies) != 2 || cfg.TrustedProxies[1].String() != "192.168.1.10/32" {
+		t.Fatalf("unexpected trusted proxies %v", cfg.TrustedProxies)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "not-a-network")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an invalid CIDR")
+	}
+}
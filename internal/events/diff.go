@@ -0,0 +1,61 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Diff compares the JSON forms of before and after, which should be the same type, and
+// returns the top-level fields whose values differ, keyed by JSON name with their new
+// value. A field present before but omitted after is reported as nil.
+func Diff(before, after any) (map[string]any, error) {
+	old, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]any)
+	for name, value := range current {
+		if prev, ok := old[name]; !ok || !reflect.DeepEqual(prev, value) {
+			changes[name] = value
+		}
+	}
+	for name := range old {
+		if _, ok := current[name]; !ok {
+			changes[name] = nil
+		}
+	}
+	return changes, nil
+}
+
+func jsonFields(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// mergeChanges combines the diffs of two coalesced updates so the surviving event still
+// lists every changed field. If either update was not diffed, neither is the result.
+func mergeChanges(earlier, later map[string]any) map[string]any {
+	if earlier == nil || later == nil {
+		return nil
+	}
+	merged := make(map[string]any, len(earlier)+len(later))
+	for name, value := range earlier {
+		merged[name] = value
+	}
+	for name, value := range later {
+		merged[name] = value
+	}
+	return merged
+}
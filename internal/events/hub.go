@@ -2,11 +2,41 @@ package events
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 )
 
+var (
+	// ErrTooManySubscribers is returned by Subscribe when the hub is at its subscriber limit.
+	ErrTooManySubscribers = errors.New("events: too many subscribers")
+	// ErrClosed is returned by Subscribe once the hub has been closed.
+	ErrClosed = errors.New("events: hub closed")
+	// ErrInvalidCursor is returned for a cursor that is not an event's cursor value.
+	ErrInvalidCursor = errors.New("events: invalid cursor")
+)
+
+// CursorAll asks Subscribe for the entire retained history, as does "0".
+const CursorAll = "all"
+
+// ParseCursor reads a cursor as sent by a client: the decimal Cursor of an event, or
+// empty, "0" or CursorAll, which all parse as zero (before every event). Anything else
+// is ErrInvalidCursor, so a client bug is reported instead of silently replaying
+// everything.
+func ParseCursor(cursor string) (uint64, error) {
+	if cursor == "" || cursor == CursorAll {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: must be an event's cursor", ErrInvalidCursor, cursor)
+	}
+	return id, nil
+}
+
 // StreamEvent represents a change that should be broadcast to subscribers.
 type StreamEvent struct {
 	ID         uint64         `json:"id"`
@@ -18,11 +48,16 @@ type StreamEvent struct {
 	Data       interface{}    `json:"data,omitempty"`
 	Timestamp  time.Time      `json:"timestamp"`
 	Metadata   map[string]any `json:"metadata,omitempty"`
+	// Changes holds only the fields an update changed, keyed by their JSON names (see
+	// Diff). Streams opened with ?diff=true send it in place of Data. It is nil when the
+	// publisher did not diff, and is never marshalled itself.
+	Changes map[string]any `json:"-"`
 }
 
 // Hub coordinates publishing events to connected subscribers.
 type Hub struct {
 	mu             sync.Mutex
+	flushMu        sync.Mutex
 	clients        map[int]chan StreamEvent
 	nextClientID   int
 	history        []StreamEvent
@@ -32,7 +67,18 @@ type Hub struct {
 	debounceWindow time.Duration
 	pending        []StreamEvent
 	pendingKeys    map[string]int
+	pendingWindow  time.Duration
 	debounceTimer  *time.Timer
+	backlogTimeout time.Duration
+	immediate      map[string]bool
+	maxSubscribers int
+	// types and actions are the accepted taxonomy; see taxonomy.go.
+	types   map[string]bool
+	actions map[string]bool
+	// closed is set by Close under mu; done wakes subscriber goroutines so each closes
+	// its own channel.
+	closed bool
+	done   chan struct{}
 }
 
 // Option configures hub behavior.
@@ -65,6 +111,35 @@ func WithBufferSize(size int) Option {
 	}
 }
 
+// WithBacklogTimeout bounds how long replay delivery waits on a subscriber that is not reading.
+func WithBacklogTimeout(timeout time.Duration) Option {
+	return func(h *Hub) {
+		if timeout > 0 {
+			h.backlogTimeout = timeout
+		}
+	}
+}
+
+// WithMaxSubscribers caps concurrent subscribers; further Subscribe calls fail with
+// ErrTooManySubscribers until one disconnects. Zero means no limit.
+func WithMaxSubscribers(max int) Option {
+	return func(h *Hub) {
+		if max >= 0 {
+			h.maxSubscribers = max
+		}
+	}
+}
+
+// WithImmediateActions marks actions that skip the debounce window. Any pending batch is
+// flushed first so the immediate event is never delivered ahead of earlier changes.
+func WithImmediateActions(actions ...string) Option {
+	return func(h *Hub) {
+		for _, action := range actions {
+			h.immediate[action] = true
+		}
+	}
+}
+
 // NewHub constructs a publisher with sane defaults.
 func NewHub(opts ...Option) *Hub {
 	h := &Hub{
@@ -73,6 +148,11 @@ func NewHub(opts ...Option) *Hub {
 		bufferSize:     32,
 		debounceWindow: 100 * time.Millisecond,
 		pendingKeys:    make(map[string]int),
+		backlogTimeout: 5 * time.Second,
+		immediate:      make(map[string]bool),
+		types:          toSet(DefaultTypes),
+		actions:        toSet(DefaultActions),
+		done:           make(chan struct{}),
 	}
 	for _, opt := range opts {
 		opt(h)
@@ -80,73 +160,236 @@ func NewHub(opts ...Option) *Hub {
 	return h
 }
 
-// Publish queues an event for broadcast, applying lightweight debouncing.
-func (h *Hub) Publish(evt StreamEvent) {
+// Publish queues an event for broadcast, applying lightweight debouncing. An empty
+// Type defaults to TypeChange; an event whose type or action is outside the hub's
+// taxonomy, or that has no entity, is dropped with an error wrapping ErrInvalidEvent.
+func (h *Hub) Publish(evt StreamEvent) error {
+	return h.publish(evt, h.debounceWindow)
+}
+
+// PublishWith queues an event with its own debounce window, e.g. a longer one for
+// high-frequency updates such as a slider being dragged. A negative window uses the
+// hub default. Each publish restarts the flush timer with the shortest window of any
+// pending event, so a hinted batch waits longer on its own but is flushed promptly,
+// in order, once an event with a shorter window joins it. Events are validated as in
+// Publish.
+func (h *Hub) PublishWith(evt StreamEvent, window time.Duration) error {
+	if window < 0 {
+		window = h.debounceWindow
+	}
+	return h.publish(evt, window)
+}
+
+func (h *Hub) publish(evt StreamEvent, window time.Duration) error {
+	if evt.Type == "" {
+		evt.Type = TypeChange
+	}
+	if err := h.validate(evt); err != nil {
+		return err
+	}
 	key := evtKey(evt)
 
 	h.mu.Lock()
-	if idx, ok := h.pendingKeys[key]; ok {
+	if h.closed {
+		// Handlers still in flight during shutdown may publish after Close.
+		h.mu.Unlock()
+		return nil
+	}
+	if len(h.pending) == 0 || window < h.pendingWindow {
+		h.pendingWindow = window
+	}
+	immediate := h.immediate[evt.Action]
+	if immediate {
+		h.pending = append(h.pending, evt)
+	} else if idx, ok := h.pendingKeys[key]; ok {
+		evt.Changes = mergeChanges(h.pending[idx].Changes, evt.Changes)
 		h.pending[idx] = evt
 	} else {
 		h.pendingKeys[key] = len(h.pending)
 		h.pending = append(h.pending, evt)
 	}
 
-	if h.debounceWindow <= 0 {
-		pending := h.pending
-		h.pending = nil
-		h.pendingKeys = make(map[string]int)
+	if h.pendingWindow <= 0 || immediate {
+		if h.debounceTimer != nil {
+			h.debounceTimer.Stop()
+		}
 		h.mu.Unlock()
-		h.flush(pending)
-		return
+		h.drainPending()
+		return nil
 	}
 
 	if h.debounceTimer == nil {
-		h.debounceTimer = time.AfterFunc(h.debounceWindow, h.drainPending)
-	} else {
-		h.debounceTimer.Reset(h.debounceWindow)
+		h.debounceTimer = time.AfterFunc(h.pendingWindow, h.drainPending)
+	} else if h.debounceTimer.Stop() {
+		h.debounceTimer.Reset(h.pendingWindow)
 	}
+	// A timer that already fired has a drain waiting on the lock; it will pick this event up.
 	h.mu.Unlock()
+	return nil
 }
 
-// Subscribe registers a subscriber and replays history newer than the cursor.
+// Subscribe registers a subscriber. An empty cursor starts live, with no backlog;
+// "0" or CursorAll replays the entire retained history first, and an event's cursor
+// replays the history after it. A malformed cursor is ErrInvalidCursor.
 func (h *Hub) Subscribe(ctx context.Context, cursor string) (<-chan StreamEvent, error) {
-	ch := make(chan StreamEvent, h.bufferSize)
+	lastID, err := ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if cursor == "" {
+		return h.subscribe(ctx, func() []StreamEvent { return nil })
+	}
+	return h.subscribe(ctx, func() []StreamEvent { return h.backlogLocked(lastID) })
+}
+
+// SubscribeFromStart registers a subscriber and replays the entire retained history,
+// oldest first, regardless of what the client has already seen.
+func (h *Hub) SubscribeFromStart(ctx context.Context) (<-chan StreamEvent, error) {
+	return h.subscribe(ctx, func() []StreamEvent {
+		out := make([]StreamEvent, len(h.history))
+		copy(out, h.history)
+		return out
+	})
+}
 
+// subscribe registers a client; backlogLocked runs under h.mu so no event published
+// concurrently is both replayed and delivered live.
+func (h *Hub) subscribe(ctx context.Context, backlogLocked func() []StreamEvent) (<-chan StreamEvent, error) {
 	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if h.maxSubscribers > 0 && len(h.clients) >= h.maxSubscribers {
+		h.mu.Unlock()
+		return nil, ErrTooManySubscribers
+	}
+	ch := make(chan StreamEvent, h.bufferSize)
 	id := h.nextClientID
 	h.nextClientID++
 	h.clients[id] = ch
-	backlog := h.backlogLocked(cursor)
+	backlog := backlogLocked()
 	h.mu.Unlock()
 
 	go func() {
 		defer h.removeClient(id)
-		for _, evt := range backlog {
-			select {
-			case ch <- evt:
-			case <-ctx.Done():
-				return
-			}
+		if !h.deliverBacklog(ctx, ch, backlog) {
+			return
 		}
 
-		<-ctx.Done()
+		select {
+		case <-ctx.Done():
+		case <-h.done:
+		}
 	}()
 
 	return ch, nil
 }
 
-func (h *Hub) backlogLocked(cursor string) []StreamEvent {
-	if len(h.history) == 0 {
+// Close stops the hub: pending events are dropped, later publishes are ignored and
+// every subscriber channel is closed so streams end. It is safe to call more than once
+// and concurrently with Publish.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	if h.debounceTimer != nil {
+		h.debounceTimer.Stop()
+		h.debounceTimer = nil
+	}
+	h.pending = nil
+	h.pendingKeys = make(map[string]int)
+	close(h.done)
+}
+
+// History returns buffered events newer than the cursor, capped at limit when positive.
+// Check the cursor with ParseCursor first; a malformed one is treated as none.
+func (h *Hub) History(cursor string, limit int) []StreamEvent {
+	lastID, _ := ParseCursor(cursor)
+	h.mu.Lock()
+	backlog := h.backlogLocked(lastID)
+	h.mu.Unlock()
+
+	if limit > 0 && len(backlog) > limit {
+		backlog = backlog[:limit]
+	}
+	return backlog
+}
+
+// HistoryBefore pages backward through buffered events: it returns the newest events
+// older than before, up to limit when positive, oldest first. A zero before starts from
+// the newest event. Pass the first returned event's ID as the next before; an empty or
+// short page means the oldest retained event has been reached.
+func (h *Hub) HistoryBefore(before uint64, limit int) []StreamEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// IDs are assigned in order and history is appended to, so it is sorted by ID.
+	end := len(h.history)
+	if before > 0 {
+		end = sort.Search(len(h.history), func(i int) bool { return h.history[i].ID >= before })
+	}
+	start := 0
+	if limit > 0 && end > limit {
+		start = end - limit
+	}
+	if start == end {
 		return nil
 	}
 
-	var lastID uint64
-	if cursor != "" {
-		if parsed, err := strconv.ParseUint(cursor, 10, 64); err == nil {
-			lastID = parsed
+	out := make([]StreamEvent, end-start)
+	copy(out, h.history[start:end])
+	return out
+}
+
+// SubscriberCount reports how many subscribers are currently connected.
+func (h *Hub) SubscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// PublishedCount reports how many events have been broadcast since the hub started.
+// Coalesced duplicates count once.
+func (h *Hub) PublishedCount() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.seq
+}
+
+// deliverBacklog replays history to a subscriber, giving up once the backlog timeout
+// elapses so a stalled consumer cannot pin the goroutine. Abandoned subscribers are
+// disconnected and expected to reconnect from their last cursor.
+func (h *Hub) deliverBacklog(ctx context.Context, ch chan StreamEvent, backlog []StreamEvent) bool {
+	if len(backlog) == 0 {
+		return true
+	}
+
+	deadline := time.NewTimer(h.backlogTimeout)
+	defer deadline.Stop()
+
+	for _, evt := range backlog {
+		select {
+		case ch <- evt:
+		case <-ctx.Done():
+			return false
+		case <-h.done:
+			return false
+		case <-deadline.C:
+			return false
 		}
 	}
+	return true
+}
+
+// backlogLocked returns the retained events after lastID; zero returns them all.
+func (h *Hub) backlogLocked(lastID uint64) []StreamEvent {
+	if len(h.history) == 0 {
+		return nil
+	}
 
 	startIdx := 0
 	if lastID > 0 {
@@ -170,8 +413,17 @@ func (h *Hub) backlogLocked(cursor string) []StreamEvent {
 	return out
 }
 
+// drainPending swaps out the pending batch and broadcasts it. flushMu serialises
+// drains so batches are sequenced and delivered in the order they were taken.
 func (h *Hub) drainPending() {
+	h.flushMu.Lock()
+	defer h.flushMu.Unlock()
+
 	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
 	pending := h.pending
 	h.pending = nil
 	h.pendingKeys = make(map[string]int)
@@ -192,7 +444,14 @@ func (h *Hub) broadcast(evt StreamEvent) {
 		evt.Timestamp = time.Now().UTC()
 	}
 
+	// Sends happen under mu: removeClient deletes a channel from clients under the same
+	// lock before closing it, so a broadcast never sends on a closed channel. The sends
+	// never block, so holding the lock is cheap.
 	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
 	h.seq++
 	evt.ID = h.seq
 	evt.Cursor = strconv.FormatUint(evt.ID, 10)
@@ -202,13 +461,7 @@ func (h *Hub) broadcast(evt StreamEvent) {
 		h.history = h.history[len(h.history)-h.maxHistory:]
 	}
 
-	clients := make([]chan StreamEvent, 0, len(h.clients))
 	for _, ch := range h.clients {
-		clients = append(clients, ch)
-	}
-	h.mu.Unlock()
-
-	for _, ch := range clients {
 		select {
 		case ch <- evt:
 		default:
@@ -217,6 +470,9 @@ func (h *Hub) broadcast(evt StreamEvent) {
 	}
 }
 
+// removeClient unregisters and closes a subscriber channel. Only the subscriber's own
+// goroutine calls it, after it has stopped replaying the backlog, so it is the last
+// sender besides broadcast.
 func (h *Hub) removeClient(id int) {
 	h.mu.Lock()
 	ch, ok := h.clients[id]
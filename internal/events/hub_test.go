@@ -2,6 +2,10 @@ package events
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 )
@@ -37,6 +41,99 @@ func TestHubPublishesEventsToSubscribers(t *testing.T) {
 	}
 }
 
+func TestHubSubscribeCursorSelectsBacklog(t *testing.T) {
+	cases := map[string][]string{
+		"":        nil,
+		"0":       {"asset-1", "asset-2", "asset-3"},
+		CursorAll: {"asset-1", "asset-2", "asset-3"},
+		"2":       {"asset-3"},
+	}
+	for cursor, want := range cases {
+		hub := NewHub(WithDebounceWindow(0))
+		for _, id := range []string{"asset-1", "asset-2", "asset-3"} {
+			hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: id})
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := hub.Subscribe(ctx, cursor)
+		if err != nil {
+			t.Fatalf("cursor %q: subscribe returned error: %v", cursor, err)
+		}
+		for _, id := range want {
+			select {
+			case evt := <-stream:
+				if evt.ResourceID != id {
+					t.Fatalf("cursor %q: expected %s, got %s", cursor, id, evt.ResourceID)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("cursor %q: timed out waiting for %s", cursor, id)
+			}
+		}
+
+		// Anything further must be live: the next event is the one published now.
+		hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: "live"})
+		select {
+		case evt := <-stream:
+			if evt.ResourceID != "live" {
+				t.Fatalf("cursor %q: expected the live event after the backlog, got %s", cursor, evt.ResourceID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("cursor %q: timed out waiting for the live event", cursor)
+		}
+		cancel()
+		hub.Close()
+	}
+}
+
+func TestHubRejectsMalformedCursor(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(0))
+	defer hub.Close()
+
+	if _, err := hub.Subscribe(context.Background(), "not-a-cursor"); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+	if count := hub.SubscriberCount(); count != 0 {
+		t.Fatalf("expected the rejected subscriber not to register, got %d", count)
+	}
+	if id, err := ParseCursor("42"); err != nil || id != 42 {
+		t.Fatalf("expected cursor 42, got %d, %v", id, err)
+	}
+}
+
+func TestHubHistoryBeforePagesBackward(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(0), WithMaxHistory(10))
+	defer hub.Close()
+	for i := 1; i <= 12; i++ {
+		hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: fmt.Sprintf("asset-%d", i)})
+	}
+
+	// History keeps events 3-12; pages of four walk back from the newest.
+	var pages [][]uint64
+	for before := uint64(0); ; {
+		page := hub.HistoryBefore(before, 4)
+		if len(page) == 0 {
+			break
+		}
+		ids := make([]uint64, len(page))
+		for i, evt := range page {
+			ids[i] = evt.ID
+		}
+		pages = append(pages, ids)
+		before = page[0].ID
+	}
+	want := [][]uint64{{9, 10, 11, 12}, {5, 6, 7, 8}, {3, 4}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Fatalf("expected pages %v, got %v", want, pages)
+	}
+
+	if page := hub.HistoryBefore(100, 2); len(page) != 2 || page[1].ID != 12 {
+		t.Fatalf("expected a before past the newest event to end at it, got %v", page)
+	}
+	if page := hub.HistoryBefore(2, 4); page != nil {
+		t.Fatalf("expected nothing before the oldest retained event, got %v", page)
+	}
+}
+
 func TestHubReplaysHistoryFromCursor(t *testing.T) {
 	hub := NewHub(WithDebounceWindow(0))
 
@@ -123,3 +220,395 @@ func TestHubDebouncesDuplicateKeys(t *testing.T) {
 		t.Fatal("timeout waiting for debounced event")
 	}
 }
+
+func TestHubSubscribeReleasesStalledBacklogOnCancel(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(0), WithBufferSize(1))
+	for _, id := range []string{"asset-5", "asset-6", "asset-7"} {
+		hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: id})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := hub.Subscribe(ctx, ""); err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+	cancel()
+
+	waitForClients(t, hub, 0)
+}
+
+func TestHubSubscribeAbandonsStalledBacklogAfterTimeout(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(0), WithBufferSize(1), WithBacklogTimeout(20*time.Millisecond))
+	for _, id := range []string{"asset-8", "asset-9", "asset-10"} {
+		hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: id})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := hub.Subscribe(ctx, CursorAll)
+	if err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+
+	waitForClients(t, hub, 0)
+
+	// The buffered event is still readable before the channel reports closed.
+	for range stream {
+	}
+}
+
+func TestHubDebounceKeepsInterleavedResourcesIntact(t *testing.T) {
+	const publishers = 4
+	const perPublisher = 100
+
+	hub := NewHub(WithDebounceWindow(time.Millisecond), WithBufferSize(publishers*perPublisher))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := hub.Subscribe(ctx, "")
+	if err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for p := 0; p < publishers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perPublisher; i++ {
+				hub.Publish(StreamEvent{
+					Entity:     "asset",
+					Action:     "update",
+					ResourceID: fmt.Sprintf("asset-%d-%d", p, i),
+					Data:       map[string]int{"publisher": p, "seq": i},
+				})
+				if i%10 == 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	next := make([]int, publishers)
+	var lastID uint64
+	for len(seen) < publishers*perPublisher {
+		select {
+		case evt := <-stream:
+			if seen[evt.ResourceID] {
+				t.Fatalf("duplicate event for %s", evt.ResourceID)
+			}
+			seen[evt.ResourceID] = true
+			if evt.ID <= lastID {
+				t.Fatalf("expected increasing ids, got %d after %d", evt.ID, lastID)
+			}
+			lastID = evt.ID
+
+			payload := evt.Data.(map[string]int)
+			p := payload["publisher"]
+			if payload["seq"] != next[p] {
+				t.Fatalf("publisher %d: expected seq %d, got %d", p, next[p], payload["seq"])
+			}
+			next[p]++
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %d of %d events", len(seen), publishers*perPublisher)
+		}
+	}
+}
+
+func TestHubImmediateActionsBypassDebounce(t *testing.T) {
+	window := 500 * time.Millisecond
+	hub := NewHub(WithDebounceWindow(window), WithImmediateActions("delete"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := hub.Subscribe(ctx, "")
+	if err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+
+	hub.Publish(StreamEvent{Entity: "asset", Action: "create", ResourceID: "asset-11"})
+	hub.Publish(StreamEvent{Entity: "asset", Action: "delete", ResourceID: "asset-11"})
+
+	var actions []string
+	timeout := time.After(window / 2)
+	for len(actions) < 2 {
+		select {
+		case evt := <-stream:
+			actions = append(actions, evt.Action)
+		case <-timeout:
+			t.Fatalf("expected create and delete before the debounce window, got %v", actions)
+		}
+	}
+	if actions[0] != "create" || actions[1] != "delete" {
+		t.Fatalf("expected create then delete, got %v", actions)
+	}
+}
+
+func TestHubTracksSubscriberAndPublishedCounts(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(0))
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	if _, err := hub.Subscribe(ctx1, ""); err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+	if _, err := hub.Subscribe(ctx2, ""); err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+	if got := hub.SubscriberCount(); got != 2 {
+		t.Fatalf("expected 2 subscribers, got %d", got)
+	}
+
+	hub.Publish(StreamEvent{Entity: "asset", Action: "create", ResourceID: "a"})
+	hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: "a"})
+	if got := hub.PublishedCount(); got != 2 {
+		t.Fatalf("expected 2 published events, got %d", got)
+	}
+
+	cancel1()
+	cancel2()
+	waitForClients(t, hub, 0)
+}
+
+func waitForClients(t *testing.T, hub *Hub, want int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if hub.SubscriberCount() == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d subscribers", want)
+}
+
+func TestHubPublishWithHoldsHintedEventsLonger(t *testing.T) {
+	window := 20 * time.Millisecond
+	hub := NewHub(WithDebounceWindow(window))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := hub.Subscribe(ctx, "")
+	if err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+
+	hinted := 20 * window
+	for i := 1; i <= 3; i++ {
+		hub.PublishWith(StreamEvent{Entity: "propertyScenario", Action: "update", ResourceID: "s1", Data: i}, hinted)
+	}
+
+	select {
+	case evt := <-stream:
+		t.Fatalf("expected hinted event to be held past the default window, got %+v", evt)
+	case <-time.After(5 * window):
+	}
+
+	select {
+	case evt := <-stream:
+		if evt.Data != 3 {
+			t.Fatalf("expected coalesced latest payload 3, got %v", evt.Data)
+		}
+	case <-time.After(2 * hinted):
+		t.Fatal("timeout waiting for hinted event")
+	}
+}
+
+func TestHubShortWindowFlushesHintedBatchInOrder(t *testing.T) {
+	window := 20 * time.Millisecond
+	hub := NewHub(WithDebounceWindow(window))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := hub.Subscribe(ctx, "")
+	if err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+
+	hub.PublishWith(StreamEvent{Entity: "propertyScenario", Action: "update", ResourceID: "s1"}, time.Minute)
+	hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: "a1"})
+
+	for _, want := range []string{"propertyScenario", "asset"} {
+		select {
+		case evt := <-stream:
+			if evt.Entity != want {
+				t.Fatalf("expected %s next, got %s", want, evt.Entity)
+			}
+		case <-time.After(50 * window):
+			t.Fatalf("timeout waiting for %s", want)
+		}
+	}
+}
+
+func TestHubRejectsSubscribersBeyondLimit(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(0), WithMaxSubscribers(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first, cancelFirst := context.WithCancel(ctx)
+	if _, err := hub.Subscribe(first, ""); err != nil {
+		t.Fatalf("first subscribe: %v", err)
+	}
+	if _, err := hub.Subscribe(ctx, ""); err != nil {
+		t.Fatalf("second subscribe: %v", err)
+	}
+	if _, err := hub.Subscribe(ctx, ""); !errors.Is(err, ErrTooManySubscribers) {
+		t.Fatalf("expected ErrTooManySubscribers, got %v", err)
+	}
+
+	cancelFirst()
+	deadline := time.Now().Add(time.Second)
+	for hub.SubscriberCount() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected disconnect to free a slot, still %d subscribers", hub.SubscriberCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := hub.Subscribe(ctx, ""); err != nil {
+		t.Fatalf("expected a freed slot to accept a subscriber, got %v", err)
+	}
+}
+
+func TestHubCloseWhilePublishingDoesNotPanic(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		hub := NewHub(WithDebounceWindow(0), WithBufferSize(1))
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			stream, err := hub.Subscribe(ctx, "")
+			if err != nil {
+				t.Fatalf("subscribe returned error: %v", err)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range stream {
+				}
+			}()
+		}
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: fmt.Sprintf("asset-%d-%d", i, j)})
+				}
+			}(i)
+		}
+		// Cancelling subscribers races their channel close against live broadcasts.
+		if round%2 == 0 {
+			cancel()
+		}
+		hub.Close()
+		wg.Wait()
+		cancel()
+	}
+}
+
+func TestHubIgnoresPublishesAfterClose(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := hub.Subscribe(ctx, "")
+	if err != nil {
+		t.Fatalf("subscribe returned error: %v", err)
+	}
+	hub.Publish(StreamEvent{Entity: "asset", Action: "update", ResourceID: "pending"})
+	hub.Close()
+	hub.Close()
+	hub.Publish(StreamEvent{Entity: "asset", Action: "delete", ResourceID: "late"})
+
+	select {
+	case evt, ok := <-stream:
+		if ok {
+			t.Fatalf("expected the stream to close without events, got %#v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the stream to close")
+	}
+	if hub.PublishedCount() != 0 {
+		t.Fatalf("expected nothing published after close, got %d", hub.PublishedCount())
+	}
+	if _, err := hub.Subscribe(ctx, ""); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed subscribing to a closed hub, got %v", err)
+	}
+}
+
+func TestHubRejectsEventsOutsideTheTaxonomy(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(0))
+
+	if err := hub.Publish(StreamEvent{Entity: "asset", Action: "udpate", ResourceID: "a1"}); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent for a misspelled action, got %v", err)
+	}
+	if err := hub.Publish(StreamEvent{Type: "finance.chnage", Entity: "asset", Action: ActionUpdate}); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent for an unknown type, got %v", err)
+	}
+	if err := hub.Publish(StreamEvent{Action: ActionUpdate}); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent without an entity, got %v", err)
+	}
+	if hub.PublishedCount() != 0 {
+		t.Fatalf("expected rejected events to be dropped, got %d published", hub.PublishedCount())
+	}
+
+	if err := hub.Publish(StreamEvent{Entity: "asset", Action: ActionUpdate, ResourceID: "a1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if history := hub.History("", 0); len(history) != 1 || history[0].Type != TypeChange {
+		t.Fatalf("expected an untyped event to default to %q, got %#v", TypeChange, history)
+	}
+}
+
+func TestHubAcceptsConfiguredTaxonomy(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(0), WithTypes("finance.forecast"), WithActions("recompute"))
+
+	if err := hub.Publish(StreamEvent{Type: "finance.forecast", Entity: "cashflow", Action: "recompute"}); err != nil {
+		t.Fatalf("expected configured type and action to be accepted, got %v", err)
+	}
+	if hub.PublishedCount() != 1 {
+		t.Fatalf("expected 1 published event, got %d", hub.PublishedCount())
+	}
+}
+
+func TestDiffReportsChangedAndRemovedFields(t *testing.T) {
+	type entity struct {
+		Name  string  `json:"name"`
+		Value float64 `json:"value"`
+		Notes string  `json:"notes,omitempty"`
+	}
+	changes, err := Diff(entity{Name: "Savings", Value: 1, Notes: "x"}, entity{Name: "Savings", Value: 2})
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if len(changes) != 2 || changes["value"] != 2.0 {
+		t.Fatalf("expected value and notes to change, got %v", changes)
+	}
+	if notes, ok := changes["notes"]; !ok || notes != nil {
+		t.Fatalf("expected the omitted notes to be reported as nil, got %v", changes)
+	}
+}
+
+func TestHubMergesChangesOfCoalescedUpdates(t *testing.T) {
+	hub := NewHub(WithDebounceWindow(time.Hour))
+
+	hub.Publish(StreamEvent{Entity: "asset", Action: ActionUpdate, ResourceID: "a1", Changes: map[string]any{"name": "A"}})
+	hub.Publish(StreamEvent{Entity: "asset", Action: ActionUpdate, ResourceID: "a1", Changes: map[string]any{"value": 2}})
+	hub.drainPending()
+
+	history := hub.History("", 0)
+	if len(history) != 1 {
+		t.Fatalf("expected the updates to coalesce, got %d events", len(history))
+	}
+	if got := history[0].Changes; len(got) != 2 || got["name"] != "A" || got["value"] != 2 {
+		t.Fatalf("expected both changed fields after coalescing, got %v", got)
+	}
+}
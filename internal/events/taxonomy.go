@@ -0,0 +1,72 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidEvent is returned by Publish for an event outside the hub's taxonomy.
+var ErrInvalidEvent = errors.New("events: invalid event")
+
+// Event types carried in StreamEvent.Type.
+const (
+	// TypeChange marks a create, update or delete of a finance entity. Publish fills
+	// it in when an event has no type.
+	TypeChange = "finance.change"
+	// TypeSnapshot marks the full-state event sent to a stream before live changes.
+	TypeSnapshot = "stream.snapshot"
+)
+
+// Actions carried in StreamEvent.Action.
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionImport   = "import"
+	ActionSnapshot = "snapshot"
+)
+
+// DefaultTypes and DefaultActions make up the taxonomy every hub accepts.
+var (
+	DefaultTypes   = []string{TypeChange, TypeSnapshot}
+	DefaultActions = []string{ActionCreate, ActionUpdate, ActionDelete, ActionImport, ActionSnapshot}
+)
+
+// WithTypes adds event types to those the hub accepts.
+func WithTypes(types ...string) Option {
+	return func(h *Hub) {
+		for _, typ := range types {
+			h.types[typ] = true
+		}
+	}
+}
+
+// WithActions adds actions to those the hub accepts.
+func WithActions(actions ...string) Option {
+	return func(h *Hub) {
+		for _, action := range actions {
+			h.actions[action] = true
+		}
+	}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// validate checks evt against the hub's taxonomy. Entities are open-ended but must be set.
+func (h *Hub) validate(evt StreamEvent) error {
+	switch {
+	case !h.types[evt.Type]:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, evt.Type)
+	case !h.actions[evt.Action]:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidEvent, evt.Action)
+	case evt.Entity == "":
+		return fmt.Errorf("%w: missing entity", ErrInvalidEvent)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package finance
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// EvaluateAlerts turns a monthly cash-flow summary and the current assets into alerts,
+// most severe first. It flags a negative net cash flow, a savings rate below
+// thresholds.MinSavingsRate, and an emergency fund covering fewer than
+// thresholds.MinRunwayMonths of expenses; a runway under one month is critical. The
+// result is never nil.
+func EvaluateAlerts(summary CashFlowSummary, assets []Asset, thresholds AlertThresholds) []Alert {
+	alerts := []Alert{}
+
+	if summary.NetMonthly < 0 {
+		alerts = append(alerts, Alert{
+			Code:     AlertNegativeCashFlow,
+			Severity: SeverityCritical,
+			Message:  fmt.Sprintf("expenses exceed income by %.2f a month", -summary.NetMonthly),
+			Value:    summary.NetMonthly,
+		})
+	}
+
+	// A negative net flow already covers the no-income and overspending cases.
+	if thresholds.MinSavingsRate > 0 && summary.MonthlyIncome > 0 && summary.NetMonthly >= 0 {
+		rate := roundTo(summary.NetMonthly/summary.MonthlyIncome, 4)
+		if rate < thresholds.MinSavingsRate {
+			alerts = append(alerts, Alert{
+				Code:      AlertLowSavingsRate,
+				Severity:  SeverityWarning,
+				Message:   fmt.Sprintf("saving %.1f%% of income, below the %.1f%% target", rate*100, thresholds.MinSavingsRate*100),
+				Value:     rate,
+				Threshold: thresholds.MinSavingsRate,
+			})
+		}
+	}
+
+	if thresholds.MinRunwayMonths > 0 && summary.MonthlyExpenses > 0 {
+		var fund float64
+		for _, asset := range assets {
+			if asset.Category == thresholds.EmergencyFundCategory {
+				fund += asset.CurrentValue
+			}
+		}
+		runway := roundTo(math.Max(fund, 0)/summary.MonthlyExpenses, 2)
+		if runway < thresholds.MinRunwayMonths {
+			severity := SeverityWarning
+			if runway < 1 {
+				severity = SeverityCritical
+			}
+			alerts = append(alerts, Alert{
+				Code:      AlertShortRunway,
+				Severity:  severity,
+				Message:   fmt.Sprintf("%s assets cover %.1f months of expenses, below the %.1f month target", thresholds.EmergencyFundCategory, runway, thresholds.MinRunwayMonths),
+				Value:     runway,
+				Threshold: thresholds.MinRunwayMonths,
+			})
+		}
+	}
+
+	slices.SortStableFunc(alerts, func(a, b Alert) int {
+		return severityRank(b.Severity) - severityRank(a.Severity)
+	})
+	return alerts
+}
+
+func severityRank(s AlertSeverity) int {
+	if s == SeverityCritical {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package finance
+
+import "testing"
+
+func TestEvaluateAlerts(t *testing.T) {
+	thresholds := AlertThresholds{MinSavingsRate: 0.1, MinRunwayMonths: 3, EmergencyFundCategory: "cash"}
+	cushion := []Asset{{Category: "cash", CurrentValue: 30000}, {Category: "brokerage", CurrentValue: 100000}}
+
+	cases := []struct {
+		name    string
+		summary CashFlowSummary
+		assets  []Asset
+		want    []string
+	}{
+		{
+			name:    "healthy",
+			summary: CashFlowSummary{MonthlyIncome: 10000, MonthlyExpenses: 7000, NetMonthly: 3000},
+			assets:  cushion,
+		},
+		{
+			name:    "negative cash flow",
+			summary: CashFlowSummary{MonthlyIncome: 5000, MonthlyExpenses: 6000, NetMonthly: -1000},
+			assets:  cushion,
+			want:    []string{AlertNegativeCashFlow},
+		},
+		{
+			name:    "low savings rate",
+			summary: CashFlowSummary{MonthlyIncome: 10000, MonthlyExpenses: 9500, NetMonthly: 500},
+			assets:  cushion,
+			want:    []string{AlertLowSavingsRate},
+		},
+		{
+			name:    "savings rate exactly at target",
+			summary: CashFlowSummary{MonthlyIncome: 10000, MonthlyExpenses: 9000, NetMonthly: 1000},
+			assets:  cushion,
+		},
+		{
+			name:    "short runway counts only the emergency category",
+			summary: CashFlowSummary{MonthlyIncome: 10000, MonthlyExpenses: 5000, NetMonthly: 5000},
+			assets:  []Asset{{Category: "cash", CurrentValue: 10000}, {Category: "brokerage", CurrentValue: 500000}},
+			want:    []string{AlertShortRunway},
+		},
+		{
+			name:    "critical alerts sort first",
+			summary: CashFlowSummary{MonthlyIncome: 0, MonthlyExpenses: 2000, NetMonthly: -2000},
+			want:    []string{AlertNegativeCashFlow, AlertShortRunway},
+		},
+		{
+			name:    "no expenses means no runway alert",
+			summary: CashFlowSummary{MonthlyIncome: 1000, NetMonthly: 1000},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			alerts := EvaluateAlerts(tc.summary, tc.assets, thresholds)
+			if alerts == nil || len(alerts) != len(tc.want) {
+				t.Fatalf("expected %v, got %+v", tc.want, alerts)
+			}
+			for i, code := range tc.want {
+				if alerts[i].Code != code {
+					t.Fatalf("expected %v, got %+v", tc.want, alerts)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateAlertsRunwaySeverity(t *testing.T) {
+	thresholds := AlertThresholds{MinRunwayMonths: 6, EmergencyFundCategory: "cash"}
+	summary := CashFlowSummary{MonthlyIncome: 5000, MonthlyExpenses: 4000, NetMonthly: 1000}
+
+	alerts := EvaluateAlerts(summary, []Asset{{Category: "cash", CurrentValue: 8000}}, thresholds)
+	if len(alerts) != 1 || alerts[0].Severity != SeverityWarning || alerts[0].Value != 2 || alerts[0].Threshold != 6 {
+		t.Fatalf("expected a two-month warning, got %+v", alerts)
+	}
+
+	alerts = EvaluateAlerts(summary, []Asset{{Category: "cash", CurrentValue: 2000}}, thresholds)
+	if len(alerts) != 1 || alerts[0].Severity != SeverityCritical {
+		t.Fatalf("expected a critical alert under one month, got %+v", alerts)
+	}
+
+	if alerts := EvaluateAlerts(summary, nil, AlertThresholds{}); len(alerts) != 0 {
+		t.Fatalf("expected zero thresholds to disable alerts, got %+v", alerts)
+	}
+}
@@ -1,38 +1,104 @@
 package finance
 
 import (
+	"cmp"
+	"maps"
 	"math"
+	"slices"
+)
+
+const (
+	PeriodMonthly = "monthly"
+	PeriodAnnual  = "annual"
 )
 
 // MonthlyAmount converts an income entry to a monthly value.
 func (i Income) MonthlyAmount() float64 {
-	return i.Amount * i.Frequency.monthlyFactor()
+	return i.Amount * cadence{i.Frequency, i.Interval}.monthlyFactor()
 }
 
 // MonthlyAmount converts an expense entry to a monthly value.
 func (e Expense) MonthlyAmount() float64 {
-	return e.Amount * e.Frequency.monthlyFactor()
+	return e.Amount * cadence{e.Frequency, e.Interval}.monthlyFactor()
 }
 
 // MonthlyCashFlow computes aggregate income/expense totals keyed to monthly cadence.
+// Amounts are summed in cents per frequency and each total is converted to a monthly
+// value once, so the result does not drift with the number or order of entries.
 func MonthlyCashFlow(incomes []Income, expenses []Expense) CashFlowSummary {
-	var incomeTotal, expenseTotal float64
-
+	incomeTotals := make(frequencyTotals)
 	for _, income := range incomes {
-		incomeTotal += income.MonthlyAmount()
+		incomeTotals.add(cadence{income.Frequency, income.Interval}, income.Amount)
 	}
 
+	expenseTotals := make(frequencyTotals)
 	for _, expense := range expenses {
-		expenseTotal += expense.MonthlyAmount()
+		expenseTotals.add(cadence{expense.Frequency, expense.Interval}, expense.Amount)
+	}
+
+	incomeTotal := incomeTotals.monthly()
+	expenseTotal := expenseTotals.monthly()
+
+	return CashFlowSummary{
+		Period:          PeriodMonthly,
+		MonthlyIncome:   incomeTotal.Float64(),
+		MonthlyExpenses: expenseTotal.Float64(),
+		NetMonthly:      incomeTotal.Sub(expenseTotal).Float64(),
+	}
+}
+
+// cadence is how often an entry recurs: its interval when it has one, else its frequency.
+type cadence struct {
+	frequency Frequency
+	interval  string
+}
+
+// monthlyFactor uses the interval when it parses, so an entry stored with a bad one
+// still counts at its frequency.
+func (c cadence) monthlyFactor() float64 {
+	if c.interval != "" {
+		if in, err := ParseInterval(c.interval); err == nil {
+			return in.MonthlyFactor()
+		}
+	}
+	return c.frequency.monthlyFactor()
+}
+
+// frequencyTotals sums entry amounts by the cadence they recur at.
+type frequencyTotals map[cadence]Money
+
+func (t frequencyTotals) add(c cadence, amount float64) {
+	if c.interval != "" {
+		// Entries with an interval are bucketed by it alone, whatever their frequency.
+		c.frequency = ""
 	}
+	t[c] = t[c].Add(MoneyFromFloat(amount))
+}
+
+// monthly converts every bucket to its monthly equivalent and rounds the sum once.
+// Buckets are visited in a fixed order so the float sum is reproducible.
+func (t frequencyTotals) monthly() Money {
+	keys := slices.SortedFunc(maps.Keys(t), func(a, b cadence) int {
+		return cmp.Or(cmp.Compare(a.frequency, b.frequency), cmp.Compare(a.interval, b.interval))
+	})
+	var cents float64
+	for _, c := range keys {
+		cents += float64(t[c]) * c.monthlyFactor()
+	}
+	return Money(math.Round(cents))
+}
 
-	incomeTotal = roundToCents(incomeTotal)
-	expenseTotal = roundToCents(expenseTotal)
+// AnnualCashFlow scales the monthly totals to a twelve-month view.
+func AnnualCashFlow(incomes []Income, expenses []Expense) CashFlowSummary {
+	monthly := MonthlyCashFlow(incomes, expenses)
+	incomeTotal := MoneyFromFloat(monthly.MonthlyIncome).Mul(12)
+	expenseTotal := MoneyFromFloat(monthly.MonthlyExpenses).Mul(12)
 
 	return CashFlowSummary{
-		MonthlyIncome:   incomeTotal,
-		MonthlyExpenses: expenseTotal,
-		NetMonthly:      roundToCents(incomeTotal - expenseTotal),
+		Period:          PeriodAnnual,
+		MonthlyIncome:   incomeTotal.Float64(),
+		MonthlyExpenses: expenseTotal.Float64(),
+		NetMonthly:      incomeTotal.Sub(expenseTotal).Float64(),
 	}
 }
 
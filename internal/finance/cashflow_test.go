@@ -32,6 +32,23 @@ func TestMonthlyCashFlow(t *testing.T) {
 	}
 }
 
+func TestAnnualCashFlowScalesSeedData(t *testing.T) {
+	seed := DefaultSeedData(time.Now().UTC())
+
+	monthly := MonthlyCashFlow(seed.Incomes, seed.Expenses)
+	annual := AnnualCashFlow(seed.Incomes, seed.Expenses)
+
+	if annual.Period != PeriodAnnual {
+		t.Fatalf("expected annual period label, got %q", annual.Period)
+	}
+	if annual.MonthlyIncome != roundToCents(monthly.MonthlyIncome*12) {
+		t.Fatalf("expected annual income %.2f, got %.2f", monthly.MonthlyIncome*12, annual.MonthlyIncome)
+	}
+	if annual.MonthlyExpenses != roundToCents(monthly.MonthlyExpenses*12) {
+		t.Fatalf("expected annual expenses %.2f, got %.2f", monthly.MonthlyExpenses*12, annual.MonthlyExpenses)
+	}
+}
+
 func TestMonthlyAmountFrequencyConversion(t *testing.T) {
 	cases := []struct {
 		name      string
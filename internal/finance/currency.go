@@ -0,0 +1,186 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultBaseCurrency is used when no base currency is configured.
+const DefaultBaseCurrency = "USD"
+
+// defaultMinorUnits is the number of decimal places for currencies not listed in
+// minorUnits, which covers most ISO 4217 codes.
+const defaultMinorUnits = 2
+
+// minorUnits lists ISO 4217 currencies whose minor unit is not a hundredth.
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits returns how many decimal places amounts in currency are quoted to. Unknown
+// and empty codes use two places.
+func MinorUnits(currency string) int {
+	if units, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return units
+	}
+	return defaultMinorUnits
+}
+
+// CurrencyFormat tells clients how to display amounts in a currency. Grouping lists
+// digit group sizes from the right, the last repeating: [3] gives 1,234,567 and [3, 2]
+// gives 12,34,567. Separators are left to the client's locale.
+type CurrencyFormat struct {
+	Code          string `json:"code"`
+	Symbol        string `json:"symbol"`
+	DecimalPlaces int    `json:"decimalPlaces"`
+	Grouping      []int  `json:"grouping"`
+}
+
+// currencySymbols covers the codes the app is commonly configured with. Symbols are
+// the unambiguous forms, so dollars other than USD carry a prefix.
+var currencySymbols = map[string]string{
+	"AUD": "A$", "CAD": "CA$", "CHF": "CHF", "CNY": "CN¥", "EUR": "€", "GBP": "£",
+	"HKD": "HK$", "IDR": "Rp", "INR": "₹", "JPY": "¥", "KRW": "₩", "KWD": "KD",
+	"MYR": "RM", "NZD": "NZ$", "PHP": "₱", "SEK": "kr", "SGD": "S$", "THB": "฿",
+	"USD": "$", "VND": "₫",
+}
+
+// FormatFor returns display metadata for currency. Codes without a known symbol use the
+// code itself as the symbol.
+func FormatFor(currency string) CurrencyFormat {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	format := CurrencyFormat{
+		Code:          code,
+		Symbol:        code,
+		DecimalPlaces: MinorUnits(code),
+		Grouping:      []int{3},
+	}
+	if symbol, ok := currencySymbols[code]; ok {
+		format.Symbol = symbol
+	}
+	if code == "INR" {
+		format.Grouping = []int{3, 2}
+	}
+	return format
+}
+
+// ErrMixedCurrencies is returned when entries in different currencies would be summed
+// without an exchange-rate provider to convert them.
+var ErrMixedCurrencies = errors.New("finance: mixed currencies cannot be aggregated without exchange rates")
+
+// RateProvider returns how many units of the base currency one unit of currency is worth.
+type RateProvider interface {
+	Rate(currency, base string) (float64, error)
+}
+
+// StaticRates is a RateProvider backed by fixed rates keyed by currency code, each
+// expressed in units of the configured base currency.
+type StaticRates map[string]float64
+
+// Rate implements RateProvider.
+func (r StaticRates) Rate(currency, base string) (float64, error) {
+	if strings.EqualFold(currency, base) {
+		return 1, nil
+	}
+	rate, ok := r[strings.ToUpper(currency)]
+	if !ok {
+		return 0, fmt.Errorf("%w: no rate for %s to %s", ErrMixedCurrencies, currency, base)
+	}
+	return rate, nil
+}
+
+// CurrencyConverter normalises entity amounts into a base currency before aggregation.
+// Entries without a currency are assumed to already be in the base currency.
+type CurrencyConverter struct {
+	Base  string
+	Rates RateProvider
+}
+
+// Assets returns copies of the assets with values expressed in the base currency.
+func (c CurrencyConverter) Assets(items []Asset) ([]Asset, error) {
+	out := make([]Asset, len(items))
+	for i, item := range items {
+		rate, err := c.rate(item.Currency)
+		if err != nil {
+			return nil, err
+		}
+		item.CurrentValue *= rate
+		item.Currency = c.Base
+		out[i] = item
+	}
+	return out, nil
+}
+
+// Liabilities returns copies of the liabilities with balances in the base currency.
+func (c CurrencyConverter) Liabilities(items []Liability) ([]Liability, error) {
+	out := make([]Liability, len(items))
+	for i, item := range items {
+		rate, err := c.rate(item.Currency)
+		if err != nil {
+			return nil, err
+		}
+		item.CurrentBalance *= rate
+		item.MinimumPayment *= rate
+		item.Currency = c.Base
+		out[i] = item
+	}
+	return out, nil
+}
+
+// Incomes returns copies of the incomes with amounts in the base currency.
+func (c CurrencyConverter) Incomes(items []Income) ([]Income, error) {
+	out := make([]Income, len(items))
+	for i, item := range items {
+		rate, err := c.rate(item.Currency)
+		if err != nil {
+			return nil, err
+		}
+		item.Amount *= rate
+		item.Currency = c.Base
+		out[i] = item
+	}
+	return out, nil
+}
+
+// Expenses returns copies of the expenses with amounts in the base currency.
+func (c CurrencyConverter) Expenses(items []Expense) ([]Expense, error) {
+	out := make([]Expense, len(items))
+	for i, item := range items {
+		rate, err := c.rate(item.Currency)
+		if err != nil {
+			return nil, err
+		}
+		item.Amount *= rate
+		item.Currency = c.Base
+		out[i] = item
+	}
+	return out, nil
+}
+
+// Transactions returns copies of the transactions with amounts in the base currency.
+func (c CurrencyConverter) Transactions(items []Transaction) ([]Transaction, error) {
+	out := make([]Transaction, len(items))
+	for i, item := range items {
+		rate, err := c.rate(item.Currency)
+		if err != nil {
+			return nil, err
+		}
+		item.Amount *= rate
+		item.Currency = c.Base
+		out[i] = item
+	}
+	return out, nil
+}
+
+func (c CurrencyConverter) rate(currency string) (float64, error) {
+	if currency == "" || strings.EqualFold(currency, c.Base) {
+		return 1, nil
+	}
+	if c.Rates == nil {
+		return 0, fmt.Errorf("%w: found %s alongside base currency %s", ErrMixedCurrencies, strings.ToUpper(currency), c.Base)
+	}
+	return c.Rates.Rate(currency, c.Base)
+}
@@ -0,0 +1,63 @@
+package finance
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCurrencyConverterRejectsMixedCurrenciesWithoutRates(t *testing.T) {
+	converter := CurrencyConverter{Base: "USD"}
+	incomes := []Income{
+		{ID: "i1", Amount: 1000},
+		{ID: "i2", Amount: 500, Currency: "EUR"},
+	}
+
+	if _, err := converter.Incomes(incomes); !errors.Is(err, ErrMixedCurrencies) {
+		t.Fatalf("expected ErrMixedCurrencies, got %v", err)
+	}
+}
+
+func TestCurrencyConverterConvertsWithRates(t *testing.T) {
+	converter := CurrencyConverter{Base: "USD", Rates: StaticRates{"EUR": 1.1}}
+	assets := []Asset{
+		{ID: "a1", CurrentValue: 1000, Currency: "USD"},
+		{ID: "a2", CurrentValue: 500, Currency: "eur"},
+	}
+
+	converted, err := converter.Assets(assets)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if converted[1].CurrentValue != 550 || converted[1].Currency != "USD" {
+		t.Fatalf("expected EUR asset converted to 550 USD, got %#v", converted[1])
+	}
+	if assets[1].CurrentValue != 500 {
+		t.Fatal("expected input slice to be left untouched")
+	}
+
+	if _, err := converter.Assets([]Asset{{ID: "a3", CurrentValue: 1, Currency: "JPY"}}); !errors.Is(err, ErrMixedCurrencies) {
+		t.Fatalf("expected ErrMixedCurrencies for missing rate, got %v", err)
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	cases := []struct {
+		code     string
+		symbol   string
+		places   int
+		grouping []int
+	}{
+		{"usd", "$", 2, []int{3}},
+		{"JPY", "¥", 0, []int{3}},
+		{"KWD", "KD", 3, []int{3}},
+		{"INR", "₹", 2, []int{3, 2}},
+		{"XYZ", "XYZ", 2, []int{3}},
+	}
+	for _, tc := range cases {
+		got := FormatFor(tc.code)
+		if got.Symbol != tc.symbol || got.DecimalPlaces != tc.places || !slices.Equal(got.Grouping, tc.grouping) {
+			t.Fatalf("%s: unexpected format %+v", tc.code, got)
+		}
+	}
+}
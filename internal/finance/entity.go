@@ -0,0 +1,46 @@
+package finance
+
+import "time"
+
+// The accessors below let generic stores manage IDs and timestamps without knowing
+// each entity's field layout.
+
+func (a *Asset) GetID() string          { return a.ID }
+func (a *Asset) SetID(id string)        { a.ID = id }
+func (a *Asset) Touch(at time.Time)     { a.UpdatedAt = at }
+func (a *Asset) LastUpdated() time.Time { return a.UpdatedAt }
+
+func (l *Liability) GetID() string          { return l.ID }
+func (l *Liability) SetID(id string)        { l.ID = id }
+func (l *Liability) Touch(at time.Time)     { l.UpdatedAt = at }
+func (l *Liability) LastUpdated() time.Time { return l.UpdatedAt }
+
+func (a *Account) GetID() string          { return a.ID }
+func (a *Account) SetID(id string)        { a.ID = id }
+func (a *Account) Touch(at time.Time)     { a.UpdatedAt = at }
+func (a *Account) LastUpdated() time.Time { return a.UpdatedAt }
+
+func (t *Transaction) GetID() string          { return t.ID }
+func (t *Transaction) SetID(id string)        { t.ID = id }
+func (t *Transaction) Touch(at time.Time)     { t.UpdatedAt = at }
+func (t *Transaction) LastUpdated() time.Time { return t.UpdatedAt }
+
+func (i *Income) GetID() string          { return i.ID }
+func (i *Income) SetID(id string)        { i.ID = id }
+func (i *Income) Touch(at time.Time)     { i.UpdatedAt = at }
+func (i *Income) LastUpdated() time.Time { return i.UpdatedAt }
+
+func (e *Expense) GetID() string          { return e.ID }
+func (e *Expense) SetID(id string)        { e.ID = id }
+func (e *Expense) Touch(at time.Time)     { e.UpdatedAt = at }
+func (e *Expense) LastUpdated() time.Time { return e.UpdatedAt }
+
+func (s *PropertyPlannerScenario) GetID() string          { return s.ID }
+func (s *PropertyPlannerScenario) SetID(id string)        { s.ID = id }
+func (s *PropertyPlannerScenario) Touch(at time.Time)     { s.UpdatedAt = at }
+func (s *PropertyPlannerScenario) LastUpdated() time.Time { return s.UpdatedAt }
+
+func (g *Goal) GetID() string          { return g.ID }
+func (g *Goal) SetID(id string)        { g.ID = id }
+func (g *Goal) Touch(at time.Time)     { g.UpdatedAt = at }
+func (g *Goal) LastUpdated() time.Time { return g.UpdatedAt }
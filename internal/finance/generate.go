@@ -0,0 +1,190 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Distribution shapes one kind of generated entity. Categories maps each category to
+// a relative weight; amounts are drawn log-uniformly between MinAmount and MaxAmount,
+// so small balances are common and large ones rare.
+type Distribution struct {
+	Categories map[string]int `json:"categories"`
+	MinAmount  float64        `json:"minAmount"`
+	MaxAmount  float64        `json:"maxAmount"`
+}
+
+// GeneratorProfile configures GenerateSeedData.
+type GeneratorProfile struct {
+	Assets      Distribution `json:"assets"`
+	Liabilities Distribution `json:"liabilities"`
+	Incomes     Distribution `json:"incomes"`
+	Expenses    Distribution `json:"expenses"`
+}
+
+// DefaultGeneratorProfile returns a household-like mix of categories and amounts.
+func DefaultGeneratorProfile() GeneratorProfile {
+	return GeneratorProfile{
+		Assets: Distribution{
+			Categories: map[string]int{"cash": 4, "brokerage": 3, "retirement": 2, "property": 1},
+			MinAmount:  500,
+			MaxAmount:  1_000_000,
+		},
+		Liabilities: Distribution{
+			Categories: map[string]int{"credit_card": 4, "auto_loan": 2, "student_loan": 2, "mortgage": 1},
+			MinAmount:  200,
+			MaxAmount:  750_000,
+		},
+		Incomes: Distribution{
+			Categories: map[string]int{"salary": 5, "bonus": 1, "dividends": 2, "rental": 1},
+			MinAmount:  50,
+			MaxAmount:  20_000,
+		},
+		Expenses: Distribution{
+			Categories: map[string]int{"housing": 2, "groceries": 4, "transport": 3, "utilities": 3, "entertainment": 2},
+			MinAmount:  5,
+			MaxAmount:  5_000,
+		},
+	}
+}
+
+// Validate reports the first distribution that cannot produce entities.
+func (p GeneratorProfile) Validate() error {
+	for _, kind := range []struct {
+		name string
+		dist Distribution
+	}{
+		{"assets", p.Assets},
+		{"liabilities", p.Liabilities},
+		{"incomes", p.Incomes},
+		{"expenses", p.Expenses},
+	} {
+		if err := kind.dist.validate(); err != nil {
+			return fmt.Errorf("%s: %w", kind.name, err)
+		}
+	}
+	return nil
+}
+
+func (d Distribution) validate() error {
+	if len(d.Categories) == 0 {
+		return errors.New("at least one category is required")
+	}
+	for category, weight := range d.Categories {
+		if strings.TrimSpace(category) == "" || weight <= 0 {
+			return fmt.Errorf("category %q needs a name and a positive weight", category)
+		}
+	}
+	if d.MinAmount <= 0 || d.MaxAmount < d.MinAmount {
+		return errors.New("amounts need 0 < minAmount <= maxAmount")
+	}
+	return nil
+}
+
+// GenerateSeedData builds count random but plausible assets, liabilities, incomes and
+// expenses for load testing. IDs are left empty for the repository to assign. The same
+// rng seed and profile always produce the same data.
+func GenerateSeedData(rng *rand.Rand, count int, profile GeneratorProfile, now time.Time) SeedData {
+	assetCategories := newWeightedPicker(profile.Assets.Categories)
+	liabilityCategories := newWeightedPicker(profile.Liabilities.Categories)
+	incomeCategories := newWeightedPicker(profile.Incomes.Categories)
+	expenseCategories := newWeightedPicker(profile.Expenses.Categories)
+	frequencies := []Frequency{FrequencyWeekly, FrequencyBiWeekly, FrequencyMonthly, FrequencyMonthly, FrequencyQuarterly, FrequencyYearly}
+
+	data := SeedData{
+		Assets:      make([]Asset, 0, count),
+		Liabilities: make([]Liability, 0, count),
+		Incomes:     make([]Income, 0, count),
+		Expenses:    make([]Expense, 0, count),
+	}
+	for i := 1; i <= count; i++ {
+		category := assetCategories.pick(rng)
+		data.Assets = append(data.Assets, Asset{
+			Name:             fmt.Sprintf("%s #%d", categoryLabel(category), i),
+			Category:         category,
+			CurrentValue:     profile.Assets.amount(rng),
+			AnnualGrowthRate: roundTo(-0.02+rng.Float64()*0.12, 4),
+			UpdatedAt:        now,
+		})
+
+		category = liabilityCategories.pick(rng)
+		balance := profile.Liabilities.amount(rng)
+		data.Liabilities = append(data.Liabilities, Liability{
+			Name:            fmt.Sprintf("%s #%d", categoryLabel(category), i),
+			Category:        category,
+			CurrentBalance:  balance,
+			InterestRateAPR: roundTo(0.02+rng.Float64()*0.23, 4),
+			MinimumPayment:  roundToCents(math.Max(25, balance*0.02)),
+			UpdatedAt:       now,
+		})
+
+		category = incomeCategories.pick(rng)
+		data.Incomes = append(data.Incomes, Income{
+			Source:    fmt.Sprintf("%s #%d", categoryLabel(category), i),
+			Category:  category,
+			Amount:    profile.Incomes.amount(rng),
+			Frequency: frequencies[rng.IntN(len(frequencies))],
+			StartDate: now.AddDate(0, 0, -rng.IntN(730)),
+			UpdatedAt: now,
+		})
+
+		category = expenseCategories.pick(rng)
+		data.Expenses = append(data.Expenses, Expense{
+			Payee:     fmt.Sprintf("%s #%d", categoryLabel(category), i),
+			Category:  category,
+			Amount:    profile.Expenses.amount(rng),
+			Frequency: frequencies[rng.IntN(len(frequencies))],
+			UpdatedAt: now,
+		})
+	}
+	return data
+}
+
+func (d Distribution) amount(rng *rand.Rand) float64 {
+	lo, hi := math.Log(d.MinAmount), math.Log(d.MaxAmount)
+	return roundToCents(math.Exp(lo + rng.Float64()*(hi-lo)))
+}
+
+// weightedPicker draws categories in proportion to their weights. Names are sorted so
+// a seeded rng is reproducible despite map iteration order.
+type weightedPicker struct {
+	names      []string
+	cumulative []int
+}
+
+func newWeightedPicker(weights map[string]int) weightedPicker {
+	var p weightedPicker
+	for name := range weights {
+		p.names = append(p.names, name)
+	}
+	sort.Strings(p.names)
+	total := 0
+	for _, name := range p.names {
+		total += weights[name]
+		p.cumulative = append(p.cumulative, total)
+	}
+	return p
+}
+
+func (p weightedPicker) pick(rng *rand.Rand) string {
+	n := rng.IntN(p.cumulative[len(p.cumulative)-1])
+	return p.names[sort.SearchInts(p.cumulative, n+1)]
+}
+
+func categoryLabel(category string) string {
+	words := strings.Fields(strings.ReplaceAll(category, "_", " "))
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
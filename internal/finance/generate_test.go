@@ -0,0 +1,62 @@
+package finance
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateSeedDataProducesValidEntities(t *testing.T) {
+	profile := DefaultGeneratorProfile()
+	if err := profile.Validate(); err != nil {
+		t.Fatalf("default profile invalid: %v", err)
+	}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	data := GenerateSeedData(rand.New(rand.NewPCG(1, 2)), 500, profile, now)
+	if len(data.Assets) != 500 || len(data.Liabilities) != 500 || len(data.Incomes) != 500 || len(data.Expenses) != 500 {
+		t.Fatalf("expected 500 of each kind, got %d/%d/%d/%d", len(data.Assets), len(data.Liabilities), len(data.Incomes), len(data.Expenses))
+	}
+
+	inRange := func(d Distribution, v float64) bool { return v >= d.MinAmount && v <= d.MaxAmount }
+	for _, a := range data.Assets {
+		if a.Name == "" || profile.Assets.Categories[a.Category] == 0 || !inRange(profile.Assets, a.CurrentValue) {
+			t.Fatalf("invalid asset %+v", a)
+		}
+	}
+	for _, l := range data.Liabilities {
+		if l.Name == "" || profile.Liabilities.Categories[l.Category] == 0 || !inRange(profile.Liabilities, l.CurrentBalance) || l.InterestRateAPR <= 0 {
+			t.Fatalf("invalid liability %+v", l)
+		}
+	}
+	for _, i := range data.Incomes {
+		if i.Source == "" || i.Frequency == "" || profile.Incomes.Categories[i.Category] == 0 || !inRange(profile.Incomes, i.Amount) || i.StartDate.After(now) {
+			t.Fatalf("invalid income %+v", i)
+		}
+	}
+	for _, e := range data.Expenses {
+		if e.Payee == "" || profile.Expenses.Categories[e.Category] == 0 || !inRange(profile.Expenses, e.Amount) {
+			t.Fatalf("invalid expense %+v", e)
+		}
+	}
+
+	again := GenerateSeedData(rand.New(rand.NewPCG(1, 2)), 500, profile, now)
+	if !reflect.DeepEqual(data, again) {
+		t.Fatal("expected the same seed to reproduce the same data")
+	}
+}
+
+func TestGeneratorProfileValidate(t *testing.T) {
+	profile := DefaultGeneratorProfile()
+	profile.Expenses.Categories = map[string]int{"groceries": 0}
+	if err := profile.Validate(); err == nil {
+		t.Fatal("expected error for a zero weight")
+	}
+
+	profile = DefaultGeneratorProfile()
+	profile.Assets.MaxAmount = profile.Assets.MinAmount / 2
+	if err := profile.Validate(); err == nil {
+		t.Fatal("expected error for an inverted amount range")
+	}
+}
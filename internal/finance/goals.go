@@ -0,0 +1,42 @@
+package finance
+
+import (
+	"math"
+	"time"
+)
+
+// EvaluateGoal sums the assets linked to the goal's category and projects when the
+// target will be met if monthlySavings keeps flowing into them.
+func EvaluateGoal(goal Goal, assets []Asset, monthlySavings float64, now time.Time) GoalProgress {
+	var current float64
+	for _, asset := range assets {
+		if asset.Category == goal.AssetCategory {
+			current += asset.CurrentValue
+		}
+	}
+	current = roundToCents(current)
+
+	progress := GoalProgress{
+		CurrentAmount: current,
+		Remaining:     roundToCents(math.Max(goal.TargetAmount-current, 0)),
+	}
+	if goal.TargetAmount > 0 {
+		// Clamped to [0, 100]: a net-negative category reads as no progress, not below zero.
+		progress.Percent = roundToCents(math.Max(math.Min(current/goal.TargetAmount, 1), 0) * 100)
+	}
+
+	var projected time.Time
+	switch {
+	case progress.Remaining == 0:
+		projected = now
+	case monthlySavings > 0:
+		months := int(math.Ceil(progress.Remaining / monthlySavings))
+		projected = now.AddDate(0, months, 0)
+	default:
+		return progress
+	}
+
+	progress.ProjectedDate = &projected
+	progress.OnTrack = goal.TargetDate == nil || !projected.After(*goal.TargetDate)
+	return progress
+}
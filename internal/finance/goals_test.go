@@ -0,0 +1,54 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvaluateGoalSumsLinkedAssetsAndProjects(t *testing.T) {
+	now := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	targetDate := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	goal := Goal{Name: "Emergency fund", TargetAmount: 20000, TargetDate: &targetDate, AssetCategory: "cash"}
+	assets := []Asset{
+		{Category: "cash", CurrentValue: 8000},
+		{Category: "cash", CurrentValue: 2000},
+		{Category: "investment", CurrentValue: 50000},
+	}
+
+	progress := EvaluateGoal(goal, assets, 1500, now)
+
+	if progress.CurrentAmount != 10000 || progress.Remaining != 10000 {
+		t.Fatalf("expected 10000 saved and 10000 remaining, got %+v", progress)
+	}
+	if progress.Percent != 50 {
+		t.Fatalf("expected 50%%, got %.2f", progress.Percent)
+	}
+	// 10000 / 1500 rounds up to 7 months.
+	want := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	if progress.ProjectedDate == nil || !progress.ProjectedDate.Equal(want) {
+		t.Fatalf("expected projected date %s, got %v", want, progress.ProjectedDate)
+	}
+	if !progress.OnTrack {
+		t.Fatal("expected goal to be on track")
+	}
+}
+
+func TestEvaluateGoalWithoutSavingsHasNoProjection(t *testing.T) {
+	now := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	goal := Goal{TargetAmount: 5000, AssetCategory: "cash"}
+
+	progress := EvaluateGoal(goal, nil, -200, now)
+	if progress.ProjectedDate != nil || progress.OnTrack {
+		t.Fatalf("expected no projection when savings are negative, got %+v", progress)
+	}
+
+	overdrawn := EvaluateGoal(goal, []Asset{{Category: "cash", CurrentValue: -300}}, 0, now)
+	if overdrawn.Percent != 0 || overdrawn.Remaining != 5300 {
+		t.Fatalf("expected a negative balance to show no progress, got %+v", overdrawn)
+	}
+
+	met := EvaluateGoal(goal, []Asset{{Category: "cash", CurrentValue: 6000}}, 0, now)
+	if met.Percent != 100 || met.Remaining != 0 || met.ProjectedDate == nil || !met.OnTrack {
+		t.Fatalf("expected a met goal to be complete and on track, got %+v", met)
+	}
+}
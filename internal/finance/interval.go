@@ -0,0 +1,100 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Interval is a recurrence period read from an ISO 8601 duration such as "P2W" or
+// "P1Y6M". Only date components are supported; a recurrence shorter than a day has no
+// meaning for cash flow. ParseInterval normalises it, carrying 12 months into a year
+// and 7 days into a week, so equal periods compare and print alike.
+type Interval struct {
+	Years  int
+	Months int
+	Weeks  int
+	Days   int
+}
+
+// ParseInterval parses an ISO 8601 duration made of whole, non-negative year, month,
+// week and day components in that order, e.g. "P3M", "P2W" or "P1Y2M10D".
+func ParseInterval(s string) (Interval, error) {
+	rest, ok := strings.CutPrefix(s, "P")
+	if !ok || rest == "" {
+		return Interval{}, fmt.Errorf("interval %q is not an ISO 8601 duration", s)
+	}
+	if strings.Contains(rest, "T") {
+		return Interval{}, fmt.Errorf("interval %q has time components; use days or longer", s)
+	}
+
+	var in Interval
+	units := "YMWD"
+	for rest != "" {
+		end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if end <= 0 {
+			return Interval{}, fmt.Errorf("interval %q is not an ISO 8601 duration", s)
+		}
+		n, err := strconv.Atoi(rest[:end])
+		if err != nil || n > 10000 {
+			return Interval{}, fmt.Errorf("interval %q is out of range", s)
+		}
+		idx := strings.IndexByte(units, rest[end])
+		if idx < 0 {
+			return Interval{}, fmt.Errorf("interval %q is not an ISO 8601 duration", s)
+		}
+		switch units[idx] {
+		case 'Y':
+			in.Years = n
+		case 'M':
+			in.Months = n
+		case 'W':
+			in.Weeks = n
+		case 'D':
+			in.Days = n
+		}
+		// Components must appear once each, largest first.
+		units = units[idx+1:]
+		rest = rest[end+1:]
+	}
+	if in == (Interval{}) {
+		return Interval{}, errors.New("interval must be longer than zero")
+	}
+	return in.normalize(), nil
+}
+
+func (in Interval) normalize() Interval {
+	in.Years += in.Months / 12
+	in.Months %= 12
+	in.Weeks += in.Days / 7
+	in.Days %= 7
+	return in
+}
+
+// String formats in as an ISO 8601 duration, e.g. "P1Y2M".
+func (in Interval) String() string {
+	var b strings.Builder
+	b.WriteByte('P')
+	for _, part := range []struct {
+		n    int
+		unit byte
+	}{{in.Years, 'Y'}, {in.Months, 'M'}, {in.Weeks, 'W'}, {in.Days, 'D'}} {
+		if part.n > 0 {
+			b.WriteString(strconv.Itoa(part.n))
+			b.WriteByte(part.unit)
+		}
+	}
+	return b.String()
+}
+
+// InMonths reports the length of one period in months. Weeks are 12/52 of a month and
+// days 12/365, so "P1W" and "P2W" agree with the weekly and biweekly frequencies.
+func (in Interval) InMonths() float64 {
+	return float64(in.Years*12+in.Months) + float64(in.Weeks)*12/52 + float64(in.Days)*12/365
+}
+
+// MonthlyFactor converts an amount paid once per interval to its monthly equivalent.
+func (in Interval) MonthlyFactor() float64 {
+	return 1 / in.InMonths()
+}
@@ -0,0 +1,70 @@
+package finance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseIntervalNormalizes(t *testing.T) {
+	cases := map[string]string{
+		"P2W":      "P2W",
+		"P3M":      "P3M",
+		"P1Y":      "P1Y",
+		"P14D":     "P2W",
+		"P18M":     "P1Y6M",
+		"P1Y2M10D": "P1Y2M1W3D",
+	}
+	for input, want := range cases {
+		in, err := ParseInterval(input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", input, err)
+		}
+		if got := in.String(); got != want {
+			t.Fatalf("%s: expected %s, got %s", input, want, got)
+		}
+	}
+}
+
+func TestParseIntervalRejectsInvalidDurations(t *testing.T) {
+	for _, input := range []string{"", "P", "2W", "P0D", "PT12H", "P1.5M", "P2M1Y", "P1M1M", "P-1W", "monthly", "P1X"} {
+		if _, err := ParseInterval(input); err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestIntervalMonthlyFactor(t *testing.T) {
+	cases := map[string]float64{
+		"P1M": 1,
+		"P3M": FrequencyQuarterly.monthlyFactor(),
+		"P1Y": FrequencyYearly.monthlyFactor(),
+		"P1W": FrequencyWeekly.monthlyFactor(),
+		"P2W": FrequencyBiWeekly.monthlyFactor(),
+		"P6M": 1.0 / 6,
+	}
+	for input, want := range cases {
+		in, err := ParseInterval(input)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if got := in.MonthlyFactor(); math.Abs(got-want) > 1e-12 {
+			t.Fatalf("%s: expected factor %v, got %v", input, want, got)
+		}
+	}
+}
+
+func TestIntervalOverridesFrequencyInCashFlow(t *testing.T) {
+	incomes := []Income{{Source: "Dividends", Amount: 600, Frequency: FrequencyMonthly, Interval: "P6M"}}
+	expenses := []Expense{
+		{Payee: "Cleaner", Amount: 120, Frequency: FrequencyMonthly, Interval: "P2W"},
+		{Payee: "Rent", Amount: 2000, Frequency: FrequencyMonthly},
+	}
+
+	if got := incomes[0].MonthlyAmount(); got != 100 {
+		t.Fatalf("expected 600 every six months to be 100 a month, got %v", got)
+	}
+	summary := MonthlyCashFlow(incomes, expenses)
+	if summary.MonthlyIncome != 100 || summary.MonthlyExpenses != 2260 {
+		t.Fatalf("expected income 100 and expenses 2260, got %+v", summary)
+	}
+}
@@ -15,15 +15,30 @@ const (
 	FrequencyYearly    Frequency = "yearly"
 )
 
+// Valid reports whether f is one of the supported frequencies. The empty string is not.
+func (f Frequency) Valid() bool {
+	switch f {
+	case FrequencyWeekly, FrequencyBiWeekly, FrequencyMonthly, FrequencyQuarterly, FrequencyYearly:
+		return true
+	default:
+		return false
+	}
+}
+
 // Asset models a net-worth positive account (brokerage, cash, property, etc).
 type Asset struct {
-	ID               string    `json:"id"`
-	Name             string    `json:"name"`
-	Category         string    `json:"category"`
-	CurrentValue     float64   `json:"currentValue"`
-	AnnualGrowthRate float64   `json:"annualGrowthRate"`
-	Notes            string    `json:"notes,omitempty"`
-	UpdatedAt        time.Time `json:"updatedAt"`
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Category         string  `json:"category"`
+	CurrentValue     float64 `json:"currentValue"`
+	AnnualGrowthRate float64 `json:"annualGrowthRate"`
+	Currency         string  `json:"currency,omitempty"`
+	AccountID        string  `json:"accountId,omitempty"`
+	// LinkedLiabilityID names the liability secured against this asset, e.g. the
+	// mortgage on a property.
+	LinkedLiabilityID string    `json:"linkedLiabilityId,omitempty"`
+	Notes             string    `json:"notes,omitempty"`
+	UpdatedAt         time.Time `json:"updatedAt"`
 }
 
 // Liability represents a debt obligation such as mortgages or credit cards.
@@ -34,40 +49,191 @@ type Liability struct {
 	CurrentBalance  float64   `json:"currentBalance"`
 	InterestRateAPR float64   `json:"interestRateApr"`
 	MinimumPayment  float64   `json:"minimumPayment"`
+	Currency        string    `json:"currency,omitempty"`
+	AccountID       string    `json:"accountId,omitempty"`
 	Notes           string    `json:"notes,omitempty"`
 	UpdatedAt       time.Time `json:"updatedAt"`
 }
 
-// Income captures recurring cash inflows.
+// Account groups the assets and liabilities held at one institution.
+type Account struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Institution string    `json:"institution,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Income captures recurring cash inflows. DayOfMonth (1-31) anchors monthly, quarterly
+// and yearly entries to a calendar day; DayOfWeek (0=Sunday) anchors weekly cadences.
+// Interval, an optional normalised ISO 8601 duration (see ParseInterval), overrides
+// Frequency in cash-flow totals.
 type Income struct {
-	ID        string    `json:"id"`
-	Source    string    `json:"source"`
-	Amount    float64   `json:"amount"`
-	Frequency Frequency `json:"frequency"`
-	StartDate time.Time `json:"startDate"`
-	Category  string    `json:"category"`
-	Notes     string    `json:"notes,omitempty"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID         string    `json:"id"`
+	Source     string    `json:"source"`
+	Amount     float64   `json:"amount"`
+	Currency   string    `json:"currency,omitempty"`
+	Frequency  Frequency `json:"frequency"`
+	Interval   string    `json:"interval,omitempty"`
+	StartDate  time.Time `json:"startDate"`
+	Category   string    `json:"category"`
+	Notes      string    `json:"notes,omitempty"`
+	DayOfMonth *int      `json:"dayOfMonth,omitempty"`
+	DayOfWeek  *int      `json:"dayOfWeek,omitempty"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
-// Expense captures recurring cash outflows.
+// Expense captures recurring cash outflows, anchored and optionally given an Interval
+// the same way as Income.
 type Expense struct {
-	ID        string    `json:"id"`
-	Payee     string    `json:"payee"`
-	Amount    float64   `json:"amount"`
-	Frequency Frequency `json:"frequency"`
-	Category  string    `json:"category"`
-	Notes     string    `json:"notes,omitempty"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID         string    `json:"id"`
+	Payee      string    `json:"payee"`
+	Amount     float64   `json:"amount"`
+	Currency   string    `json:"currency,omitempty"`
+	Frequency  Frequency `json:"frequency"`
+	Interval   string    `json:"interval,omitempty"`
+	Category   string    `json:"category"`
+	Notes      string    `json:"notes,omitempty"`
+	DayOfMonth *int      `json:"dayOfMonth,omitempty"`
+	DayOfWeek  *int      `json:"dayOfWeek,omitempty"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
-// CashFlowSummary aggregates incomes and expenses into monthly totals.
+// CashFlowSummary aggregates incomes and expenses into totals for Period. The field
+// names predate annual summaries; when Period is annual the totals cover a full year.
 type CashFlowSummary struct {
+	Period          string  `json:"period"`
 	MonthlyIncome   float64 `json:"monthlyIncome"`
 	MonthlyExpenses float64 `json:"monthlyExpenses"`
 	NetMonthly      float64 `json:"netMonthly"`
 }
 
+// CashEvent is a single dated occurrence of a recurring income or expense.
+type CashEvent struct {
+	Date     time.Time `json:"date"`
+	Kind     string    `json:"kind"`
+	SourceID string    `json:"sourceId"`
+	Name     string    `json:"name"`
+	Category string    `json:"category"`
+	Amount   float64   `json:"amount"`
+}
+
+// ForecastMonth groups the dated cash events expected in a calendar month.
+type ForecastMonth struct {
+	Month    string      `json:"month"`
+	Income   float64     `json:"income"`
+	Expenses float64     `json:"expenses"`
+	Net      float64     `json:"net"`
+	Events   []CashEvent `json:"events"`
+}
+
+// Transaction is a single dated ledger entry. Amount is always positive; Direction
+// carries the sign.
+type Transaction struct {
+	ID        string               `json:"id"`
+	Date      time.Time            `json:"date"`
+	Amount    float64              `json:"amount"`
+	Currency  string               `json:"currency,omitempty"`
+	Direction TransactionDirection `json:"direction"`
+	Category  string               `json:"category"`
+	AccountID string               `json:"accountId,omitempty"`
+	Memo      string               `json:"memo,omitempty"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+// CategoryReconciliation compares budgeted and logged amounts for one category.
+// Variance is Actual minus Budgeted.
+type CategoryReconciliation struct {
+	Kind     string  `json:"kind"`
+	Category string  `json:"category"`
+	Budgeted float64 `json:"budgeted"`
+	Actual   float64 `json:"actual"`
+	Variance float64 `json:"variance"`
+}
+
+// MonthlyReconciliation rolls a month of logged transactions up against the recurring budget.
+type MonthlyReconciliation struct {
+	Month            string                   `json:"month"`
+	BudgetedIncome   float64                  `json:"budgetedIncome"`
+	ActualIncome     float64                  `json:"actualIncome"`
+	BudgetedExpenses float64                  `json:"budgetedExpenses"`
+	ActualExpenses   float64                  `json:"actualExpenses"`
+	Categories       []CategoryReconciliation `json:"categories"`
+}
+
+// NetWorthSnapshot records total assets, liabilities and net worth at a point in time.
+type NetWorthSnapshot struct {
+	ID               string    `json:"id"`
+	TotalAssets      float64   `json:"totalAssets"`
+	TotalLiabilities float64   `json:"totalLiabilities"`
+	NetWorth         float64   `json:"netWorth"`
+	RecordedAt       time.Time `json:"recordedAt"`
+}
+
+// AssetEquity is an asset's value net of its linked liability, such as a property less
+// its outstanding mortgage.
+type AssetEquity struct {
+	AssetID          string  `json:"assetId"`
+	LiabilityID      string  `json:"liabilityId"`
+	AssetValue       float64 `json:"assetValue"`
+	LiabilityBalance float64 `json:"liabilityBalance"`
+	Equity           float64 `json:"equity"`
+}
+
+// Goal is a savings target funded by the assets in AssetCategory. TargetDate is optional.
+type Goal struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	TargetAmount  float64    `json:"targetAmount"`
+	TargetDate    *time.Time `json:"targetDate,omitempty"`
+	AssetCategory string     `json:"assetCategory"`
+	Notes         string     `json:"notes,omitempty"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// GoalProgress reports how close a goal's linked assets are to its target. ProjectedDate
+// is nil when current savings would never close the gap.
+type GoalProgress struct {
+	CurrentAmount float64    `json:"currentAmount"`
+	Remaining     float64    `json:"remaining"`
+	Percent       float64    `json:"percent"`
+	ProjectedDate *time.Time `json:"projectedDate,omitempty"`
+	OnTrack       bool       `json:"onTrack"`
+}
+
+// AlertSeverity ranks how urgently an alert needs attention.
+type AlertSeverity string
+
+const (
+	SeverityWarning  AlertSeverity = "warning"
+	SeverityCritical AlertSeverity = "critical"
+)
+
+// Alert codes identify the condition an Alert reports.
+const (
+	AlertNegativeCashFlow = "negative-cash-flow"
+	AlertLowSavingsRate   = "low-savings-rate"
+	AlertShortRunway      = "short-runway"
+)
+
+// Alert flags a cash-flow condition worth acting on. Value is the measured figure and
+// Threshold the limit it crossed, in the same unit.
+type Alert struct {
+	Code      string        `json:"code"`
+	Severity  AlertSeverity `json:"severity"`
+	Message   string        `json:"message"`
+	Value     float64       `json:"value"`
+	Threshold float64       `json:"threshold"`
+}
+
+// AlertThresholds configures EvaluateAlerts. MinSavingsRate is a fraction of monthly
+// income; MinRunwayMonths is how many months of expenses the assets in
+// EmergencyFundCategory should cover. A zero threshold disables that alert.
+type AlertThresholds struct {
+	MinSavingsRate        float64
+	MinRunwayMonths       float64
+	EmergencyFundCategory string
+}
+
 // PropertyPlannerScenario captures the state of the mortgage planner UI.
 type PropertyPlannerScenario struct {
 	ID            string                     `json:"id"`
@@ -157,11 +323,15 @@ type PropertyPlannerInsight struct {
 	Tone   string `json:"tone"`
 }
 
-// SeedData is a convenience structure for populating demo repositories.
+// SeedData is a convenience structure for populating demo repositories. It doubles
+// as the document format for full-dataset export and import.
 type SeedData struct {
-	Assets            []Asset
-	Liabilities       []Liability
-	Incomes           []Income
-	Expenses          []Expense
-	PropertyScenarios []PropertyPlannerScenario
+	Assets            []Asset                   `json:"assets"`
+	Liabilities       []Liability               `json:"liabilities"`
+	Incomes           []Income                  `json:"incomes"`
+	Expenses          []Expense                 `json:"expenses"`
+	PropertyScenarios []PropertyPlannerScenario `json:"propertyScenarios"`
+	Goals             []Goal                    `json:"goals"`
+	Accounts          []Account                 `json:"accounts"`
+	Transactions      []Transaction             `json:"transactions"`
 }
@@ -0,0 +1,132 @@
+package finance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in integer cents. Aggregations sum Money rather than float64 so
+// totals do not depend on summation order and never pick up sub-cent drift; the models
+// keep float64 fields, and Money marshals as a plain JSON number so the wire format is
+// unchanged.
+type Money int64
+
+// centsPerUnit is the scale of Money. Aggregates are reported in the base currency,
+// which is quoted to cents.
+const centsPerUnit = 100
+
+// MoneyFromFloat rounds v to the nearest cent, halves away from zero.
+func MoneyFromFloat(v float64) Money {
+	return Money(math.Round(v * centsPerUnit))
+}
+
+// ParseMoney parses a decimal string such as "1234.5" or "-0.07" without going through
+// float64. More than two decimal places is an error rather than a silent rounding.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	raw := s
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" && frac == "" || hasPoint && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount %q has more than two decimal places", raw)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	for _, part := range []string{whole, frac} {
+		if strings.Trim(part, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid amount %q", raw)
+		}
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/centsPerUnit-1 {
+		return 0, fmt.Errorf("amount %q is out of range", raw)
+	}
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+	m := Money(units*centsPerUnit + cents)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// Add returns m + other.
+func (m Money) Add(other Money) Money { return m + other }
+
+// Sub returns m - other.
+func (m Money) Sub(other Money) Money { return m - other }
+
+// Mul scales m by factor, rounding the result to the nearest cent.
+func (m Money) Mul(factor float64) Money {
+	return Money(math.Round(float64(m) * factor))
+}
+
+// Float64 returns m in whole units, e.g. 1234.56.
+func (m Money) Float64() float64 {
+	return float64(m) / centsPerUnit
+}
+
+// String formats m with exactly two decimal places.
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/centsPerUnit, cents%centsPerUnit)
+}
+
+// MarshalJSON implements json.Marshaler. Amounts are written as numbers, with trailing
+// zero decimals dropped as encoding/json does for float64.
+func (m Money) MarshalJSON() ([]byte, error) {
+	s := strings.TrimSuffix(strings.TrimRight(m.String(), "0"), ".")
+	if s == "" || s == "-" {
+		s = "0"
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts a JSON number or a decimal
+// string and reads either exactly.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	} else if strings.ContainsAny(text, "eE") {
+		// Exponent notation is valid JSON; read it through float64 and round.
+		var v float64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*m = MoneyFromFloat(v)
+		return nil
+	}
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,124 @@
+package finance
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Money
+	}{
+		{"1234.56", 123456},
+		{"1234.5", 123450},
+		{"-0.07", -7},
+		{"+3", 300},
+		{".5", 50},
+		{"0", 0},
+	}
+	for _, tc := range cases {
+		got, err := ParseMoney(tc.in)
+		if err != nil || got != tc.want {
+			t.Fatalf("ParseMoney(%q) = %d, %v; want %d", tc.in, got, err, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"", "-", "1.", "1.234", "12a", "1.-5", "1e3", "99999999999999999999"} {
+		if _, err := ParseMoney(bad); err == nil {
+			t.Fatalf("expected ParseMoney(%q) to fail", bad)
+		}
+	}
+}
+
+func TestMoneyJSONRoundTrip(t *testing.T) {
+	var decoded struct {
+		Number Money `json:"number"`
+		String Money `json:"string"`
+		Exp    Money `json:"exp"`
+	}
+	if err := json.Unmarshal([]byte(`{"number":3466.67,"string":"-12.30","exp":1.5e2}`), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Number != 346667 || decoded.String != -1230 || decoded.Exp != 15000 {
+		t.Fatalf("unexpected decoded amounts %+v", decoded)
+	}
+
+	raw, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(raw) != `{"number":3466.67,"string":-12.3,"exp":150}` {
+		t.Fatalf("expected float64-compatible numbers, got %s", raw)
+	}
+
+	if err := json.Unmarshal([]byte(`{"number":1.005}`), &decoded); err == nil {
+		t.Fatal("expected sub-cent amounts to be rejected")
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	var total Money
+	for range 10 {
+		total = total.Add(MoneyFromFloat(0.1))
+	}
+	if total != MoneyFromFloat(1) || total.String() != "1.00" {
+		t.Fatalf("expected ten dimes to make exactly 1.00, got %s", total)
+	}
+	if got := MoneyFromFloat(1300).Mul(12.0 / 52.0); got != 30000 {
+		t.Fatalf("expected 1300 * 12/52 = 300.00, got %s", got)
+	}
+	if got := Money(-5).Sub(Money(10)).String(); got != "-0.15" {
+		t.Fatalf("expected -0.15, got %s", got)
+	}
+}
+
+// TestSeedSumsDoNotDrift sums the seed dataset in every rotation and in reverse. Float
+// totals can differ in the last bits by order; Money totals must be identical and equal
+// the exact cent sums.
+func TestSeedSumsDoNotDrift(t *testing.T) {
+	seed := DefaultSeedData(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var wantAssets, wantLiabilities Money
+	for _, asset := range seed.Assets {
+		wantAssets += MoneyFromFloat(asset.CurrentValue)
+	}
+	for _, liability := range seed.Liabilities {
+		wantLiabilities += MoneyFromFloat(liability.CurrentBalance)
+	}
+	baseline := MonthlyCashFlow(seed.Incomes, seed.Expenses)
+
+	for shift := range len(seed.Expenses) {
+		assets := rotate(seed.Assets, shift)
+		liabilities := rotate(seed.Liabilities, shift)
+		incomes := rotate(seed.Incomes, shift)
+		expenses := rotate(seed.Expenses, shift)
+		if shift%2 == 1 {
+			slices.Reverse(assets)
+			slices.Reverse(expenses)
+		}
+
+		snapshot := NetWorth(assets, liabilities, time.Time{})
+		if MoneyFromFloat(snapshot.TotalAssets) != wantAssets || MoneyFromFloat(snapshot.TotalLiabilities) != wantLiabilities {
+			t.Fatalf("order %d: net worth totals drifted: %+v", shift, snapshot)
+		}
+		if snapshot.NetWorth != wantAssets.Sub(wantLiabilities).Float64() {
+			t.Fatalf("order %d: expected net worth %s, got %v", shift, wantAssets.Sub(wantLiabilities), snapshot.NetWorth)
+		}
+
+		summary := MonthlyCashFlow(incomes, expenses)
+		if summary != baseline {
+			t.Fatalf("order %d: cash flow drifted from %+v to %+v", shift, baseline, summary)
+		}
+		if MoneyFromFloat(summary.NetMonthly) != MoneyFromFloat(summary.MonthlyIncome).Sub(MoneyFromFloat(summary.MonthlyExpenses)) {
+			t.Fatalf("order %d: net monthly does not equal income minus expenses: %+v", shift, summary)
+		}
+	}
+}
+
+func rotate[T any](items []T, n int) []T {
+	n %= len(items)
+	return append(slices.Clone(items[n:]), items[:n]...)
+}
@@ -0,0 +1,100 @@
+package finance
+
+import (
+	"math"
+	"time"
+)
+
+// NetWorth totals assets and liabilities into a point-in-time snapshot. Stored assets
+// are never negative (overdrawn balances are modelled as liabilities), but a negative
+// value still simply reduces the asset total rather than being dropped. Totals are
+// summed in cents.
+func NetWorth(assets []Asset, liabilities []Liability, at time.Time) NetWorthSnapshot {
+	var assetTotal, liabilityTotal Money
+
+	for _, asset := range assets {
+		assetTotal = assetTotal.Add(MoneyFromFloat(asset.CurrentValue))
+	}
+
+	for _, liability := range liabilities {
+		liabilityTotal = liabilityTotal.Add(MoneyFromFloat(liability.CurrentBalance))
+	}
+
+	return NetWorthSnapshot{
+		TotalAssets:      assetTotal.Float64(),
+		TotalLiabilities: liabilityTotal.Float64(),
+		NetWorth:         assetTotal.Sub(liabilityTotal).Float64(),
+		RecordedAt:       at,
+	}
+}
+
+// AccountNetWorth is NetWorth restricted to the holdings linked to accountID.
+func AccountNetWorth(accountID string, assets []Asset, liabilities []Liability, at time.Time) NetWorthSnapshot {
+	var held []Asset
+	for _, asset := range assets {
+		if asset.AccountID == accountID {
+			held = append(held, asset)
+		}
+	}
+	var owed []Liability
+	for _, liability := range liabilities {
+		if liability.AccountID == accountID {
+			owed = append(owed, liability)
+		}
+	}
+	return NetWorth(held, owed, at)
+}
+
+// Equity nets the liability's balance off the asset's value. The caller is responsible
+// for pairing an asset with the liability it links to.
+func Equity(asset Asset, liability Liability) AssetEquity {
+	return AssetEquity{
+		AssetID:          asset.ID,
+		LiabilityID:      liability.ID,
+		AssetValue:       roundToCents(asset.CurrentValue),
+		LiabilityBalance: roundToCents(liability.CurrentBalance),
+		Equity:           roundToCents(asset.CurrentValue - liability.CurrentBalance),
+	}
+}
+
+// LinkedEquity computes equity for every asset whose LinkedLiabilityID resolves to one
+// of liabilities, in asset order. Unlinked assets and dangling links are skipped.
+func LinkedEquity(assets []Asset, liabilities []Liability) []AssetEquity {
+	byID := make(map[string]Liability, len(liabilities))
+	for _, liability := range liabilities {
+		byID[liability.ID] = liability
+	}
+	out := []AssetEquity{}
+	for _, asset := range assets {
+		if liability, ok := byID[asset.LinkedLiabilityID]; ok && asset.LinkedLiabilityID != "" {
+			out = append(out, Equity(asset, liability))
+		}
+	}
+	return out
+}
+
+// ProjectedValue compounds the asset's annual growth rate over years (fractional years
+// compound continuously between anniversaries). A rate at or below -100% projects zero.
+func ProjectedValue(asset Asset, years float64) float64 {
+	growth := 1 + asset.AnnualGrowthRate
+	if growth <= 0 {
+		return 0
+	}
+	return roundToCents(asset.CurrentValue * math.Pow(growth, years))
+}
+
+// PortfolioGrowthRate is the value-weighted average AnnualGrowthRate across assets, so a
+// large holding moves the blend more than a small one and negative rates pull it down.
+// It returns 0 when the assets are worth nothing in total. Values should already be in
+// one currency.
+func PortfolioGrowthRate(assets []Asset) float64 {
+	var total, weighted float64
+	for _, asset := range assets {
+		total += asset.CurrentValue
+		weighted += asset.CurrentValue * asset.AnnualGrowthRate
+	}
+	if total <= 0 {
+		return 0
+	}
+	return weighted / total
+}
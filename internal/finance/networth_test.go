@@ -0,0 +1,131 @@
+package finance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNetWorth(t *testing.T) {
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	assets := []Asset{
+		{ID: "a1", CurrentValue: 1000.105},
+		{ID: "a2", CurrentValue: 2500},
+	}
+	liabilities := []Liability{
+		{ID: "l1", CurrentBalance: 1200.5},
+	}
+
+	snapshot := NetWorth(assets, liabilities, at)
+
+	if snapshot.TotalAssets != 3500.11 {
+		t.Fatalf("expected total assets 3500.11, got %.2f", snapshot.TotalAssets)
+	}
+	if snapshot.TotalLiabilities != 1200.5 {
+		t.Fatalf("expected total liabilities 1200.50, got %.2f", snapshot.TotalLiabilities)
+	}
+	if snapshot.NetWorth != 2299.61 {
+		t.Fatalf("expected net worth 2299.61, got %.2f", snapshot.NetWorth)
+	}
+	if !snapshot.RecordedAt.Equal(at) {
+		t.Fatalf("expected recordedAt %s, got %s", at, snapshot.RecordedAt)
+	}
+}
+
+func TestNetWorthSubtractsNegativeAssets(t *testing.T) {
+	assets := []Asset{
+		{ID: "a1", CurrentValue: 1000},
+		{ID: "a2", CurrentValue: -250.5},
+	}
+
+	snapshot := NetWorth(assets, []Liability{{ID: "l1", CurrentBalance: 100}}, time.Time{})
+
+	if snapshot.TotalAssets != 749.5 || snapshot.NetWorth != 649.5 {
+		t.Fatalf("expected a negative asset to reduce totals, got %+v", snapshot)
+	}
+}
+
+func TestAccountNetWorthOnlyCountsLinkedHoldings(t *testing.T) {
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	assets := []Asset{
+		{ID: "a1", AccountID: "acct-1", CurrentValue: 5000},
+		{ID: "a2", AccountID: "acct-2", CurrentValue: 9000},
+		{ID: "a3", CurrentValue: 100},
+	}
+	liabilities := []Liability{
+		{ID: "l1", AccountID: "acct-1", CurrentBalance: 1500},
+		{ID: "l2", CurrentBalance: 700},
+	}
+
+	snapshot := AccountNetWorth("acct-1", assets, liabilities, at)
+
+	if snapshot.TotalAssets != 5000 || snapshot.TotalLiabilities != 1500 || snapshot.NetWorth != 3500 {
+		t.Fatalf("unexpected account snapshot %+v", snapshot)
+	}
+}
+
+func TestLinkedEquityPairsAssetsWithTheirLiabilities(t *testing.T) {
+	assets := []Asset{
+		{ID: "house", CurrentValue: 850000, LinkedLiabilityID: "mortgage"},
+		{ID: "cash", CurrentValue: 20000},
+		{ID: "condo", CurrentValue: 400000, LinkedLiabilityID: "gone"},
+	}
+	liabilities := []Liability{
+		{ID: "mortgage", CurrentBalance: 512345.675},
+		{ID: "card", CurrentBalance: 900},
+	}
+
+	equity := LinkedEquity(assets, liabilities)
+
+	if len(equity) != 1 {
+		t.Fatalf("expected only the resolvable link, got %+v", equity)
+	}
+	want := AssetEquity{AssetID: "house", LiabilityID: "mortgage", AssetValue: 850000, LiabilityBalance: 512345.68, Equity: 337654.33}
+	if equity[0] != want {
+		t.Fatalf("expected %+v, got %+v", want, equity[0])
+	}
+	if got := LinkedEquity(nil, nil); got == nil || len(got) != 0 {
+		t.Fatalf("expected an empty non-nil slice, got %#v", got)
+	}
+}
+
+func TestProjectedValue(t *testing.T) {
+	cases := []struct {
+		name  string
+		asset Asset
+		years float64
+		want  float64
+	}{
+		{"positive growth", Asset{CurrentValue: 1000, AnnualGrowthRate: 0.05}, 1, 1050},
+		{"compounds over years", Asset{CurrentValue: 1000, AnnualGrowthRate: 0.1}, 2, 1210},
+		{"zero growth", Asset{CurrentValue: 1000}, 1, 1000},
+		{"negative growth", Asset{CurrentValue: 1000, AnnualGrowthRate: -0.2}, 1, 800},
+		{"total loss", Asset{CurrentValue: 1000, AnnualGrowthRate: -1}, 1, 0},
+		{"zero years", Asset{CurrentValue: 1000, AnnualGrowthRate: 0.05}, 0, 1000},
+	}
+	for _, tc := range cases {
+		if got := ProjectedValue(tc.asset, tc.years); got != tc.want {
+			t.Fatalf("%s: expected %.2f, got %.2f", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestPortfolioGrowthRate(t *testing.T) {
+	cases := []struct {
+		name   string
+		assets []Asset
+		want   float64
+	}{
+		{"weights by value", []Asset{{CurrentValue: 90000, AnnualGrowthRate: 0.07}, {CurrentValue: 10000, AnnualGrowthRate: 0.01}}, 0.064},
+		{"negative rates drag", []Asset{{CurrentValue: 50000, AnnualGrowthRate: 0.06}, {CurrentValue: 50000, AnnualGrowthRate: -0.1}}, -0.02},
+		{"zero-value assets carry no weight", []Asset{{CurrentValue: 1000, AnnualGrowthRate: 0.05}, {CurrentValue: 0, AnnualGrowthRate: 0.9}}, 0.05},
+		{"zero total", []Asset{{CurrentValue: 0, AnnualGrowthRate: 0.05}}, 0},
+		{"no assets", nil, 0},
+	}
+	for _, tc := range cases {
+		got := PortfolioGrowthRate(tc.assets)
+		if math.IsNaN(got) || math.Abs(got-tc.want) > 1e-12 {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Mortgage terms accepted by the property planner, in years.
+const (
+	MinLoanTermYears = 1
+	MaxLoanTermYears = 40
+)
+
+// Validate reports the first mortgage input outside the range the planner can
+// amortize.
+func (in MortgageInputs) Validate() error {
+	if in.LoanTermYears < MinLoanTermYears || in.LoanTermYears > MaxLoanTermYears {
+		return fmt.Errorf("loanTermYears must be between %d and %d", MinLoanTermYears, MaxLoanTermYears)
+	}
+	if in.FixedYears < 0 || in.FixedYears > in.LoanTermYears {
+		return errors.New("fixedYears must be between 0 and loanTermYears")
+	}
+	for _, rate := range []struct {
+		name  string
+		value float64
+	}{
+		{"fixedRate", in.FixedRate},
+		{"floatingRate", in.FloatingRate},
+	} {
+		if rate.value < 0 || math.IsNaN(rate.value) || math.IsInf(rate.value, 0) {
+			return fmt.Errorf("%s must be a non-negative number", rate.name)
+		}
+	}
+	return nil
+}
+
+// CheckTimeline reports a timeline whose years go backwards, which no amortization
+// produces and which would misplace points on a chart.
+func (s PropertyPlannerScenario) CheckTimeline() error {
+	for i := 1; i < len(s.Timeline); i++ {
+		if s.Timeline[i].Year < s.Timeline[i-1].Year {
+			return fmt.Errorf("timeline year %d follows %d", s.Timeline[i].Year, s.Timeline[i-1].Year)
+		}
+	}
+	return nil
+}
+
+// SingleRate reports whether one rate applies for the whole term: the fixed period
+// covers it, no floating rate is set, or it equals the fixed rate. Only such mortgages
+// are modelled by ComputeAmortization.
+func (in MortgageInputs) SingleRate() bool {
+	return in.FixedYears >= in.LoanTermYears || in.FloatingRate == 0 || in.FloatingRate == in.FixedRate
+}
+
+// ComputeAmortization derives the yearly amortization and headline snapshot for a
+// mortgage, matching the property planner's client-side calculation: a level monthly
+// payment at the fixed rate over the whole term. It ignores any floating period, so
+// check SingleRate before trusting it over a stored result. Without a start month the
+// schedule starts in now's year.
+func ComputeAmortization(in MortgageInputs, now time.Time) (MortgageAmortization, MortgageSnapshot) {
+	totalMonths := in.LoanTermYears * 12
+	monthlyRate := in.FixedRate / 100 / 12
+
+	var monthlyPayment float64
+	switch {
+	case totalMonths <= 0:
+	case monthlyRate == 0:
+		monthlyPayment = in.LoanAmount / float64(totalMonths)
+	default:
+		monthlyPayment = in.LoanAmount * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(totalMonths)))
+	}
+
+	snapshot := MortgageSnapshot{
+		MonthlyPayment: monthlyPayment,
+		TotalInterest:  monthlyPayment*float64(totalMonths) - in.LoanAmount,
+		LoanEndDate:    loanEndDate(in.LoanStartMonth, in.LoanTermYears),
+	}
+	if in.HouseholdIncome != 0 {
+		snapshot.MSRRatio = math.Max(0, math.Min(monthlyPayment/in.HouseholdIncome, 1.5))
+	}
+	return amortize(in.LoanAmount, monthlyPayment, monthlyRate, totalMonths, loanStartYear(in.LoanStartMonth, now)), snapshot
+}
+
+func amortize(loanAmount, monthlyPayment, monthlyRate float64, totalMonths, startYear int) MortgageAmortization {
+	var out MortgageAmortization
+	if monthlyPayment <= 0 || math.IsNaN(monthlyPayment) || math.IsInf(monthlyPayment, 0) {
+		return MortgageAmortization{BalancePoints: []MortgageBalancePoint{}, Composition: []MortgageCompositionPoint{}}
+	}
+
+	remaining := loanAmount
+	var interestAcc, principalAcc float64
+	for month := 1; month <= totalMonths; month++ {
+		interest := remaining * monthlyRate
+		principal := math.Min(math.Max(monthlyPayment-interest, 0), remaining)
+		remaining = math.Max(remaining-principal, 0)
+		interestAcc += interest
+		principalAcc += principal
+
+		if month%12 == 0 || month == totalMonths {
+			yearIndex := (month + 11) / 12
+			label := fmt.Sprintf("Year %d", yearIndex)
+			year := startYear + yearIndex - 1
+			out.BalancePoints = append(out.BalancePoints, MortgageBalancePoint{
+				Label: label, Balance: math.Round(remaining), Year: year, YearIndex: yearIndex,
+			})
+			out.Composition = append(out.Composition, MortgageCompositionPoint{
+				Label: label, Interest: math.Round(interestAcc), Principal: math.Round(principalAcc), Year: year, YearIndex: yearIndex,
+			})
+			interestAcc, principalAcc = 0, 0
+		}
+		if remaining <= 0 {
+			break
+		}
+	}
+	return out
+}
+
+// loanStartYear reads the year from a YYYY-MM start month, defaulting to now's year.
+func loanStartYear(startMonth string, now time.Time) int {
+	if start, err := time.Parse("2006-01", startMonth); err == nil {
+		return start.Year()
+	}
+	return now.Year()
+}
+
+// loanEndDate formats the month the term ends as e.g. "Mar 2049", or "—" without a
+// start month.
+func loanEndDate(startMonth string, termYears int) string {
+	start, err := time.Parse("2006-01", startMonth)
+	if err != nil {
+		return "—"
+	}
+	return start.AddDate(termYears, 0, 0).Format("Jan 2006")
+}
@@ -0,0 +1,88 @@
+package finance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMortgageInputsValidate(t *testing.T) {
+	valid := MortgageInputs{LoanAmount: 500000, LoanTermYears: 25, FixedYears: 3, FixedRate: 2.6, FloatingRate: 3.9}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid inputs, got %v", err)
+	}
+
+	for name, mutate := range map[string]func(*MortgageInputs){
+		"zero term":        func(in *MortgageInputs) { in.LoanTermYears = 0 },
+		"term too long":    func(in *MortgageInputs) { in.LoanTermYears = 41 },
+		"negative fixed":   func(in *MortgageInputs) { in.FixedRate = -0.1 },
+		"negative float":   func(in *MortgageInputs) { in.FloatingRate = -1 },
+		"fixed past term":  func(in *MortgageInputs) { in.FixedYears = 30 },
+		"negative fixedYr": func(in *MortgageInputs) { in.FixedYears = -1 },
+	} {
+		in := valid
+		mutate(&in)
+		if err := in.Validate(); err == nil {
+			t.Fatalf("%s: expected an error for %+v", name, in)
+		}
+	}
+}
+
+func TestCheckTimelineRejectsYearsGoingBackwards(t *testing.T) {
+	scenario := PropertyPlannerScenario{Timeline: []PropertyPlannerTimeline{{Year: 2024}, {Year: 2025}, {Year: 2025}, {Year: 2030}}}
+	if err := scenario.CheckTimeline(); err != nil {
+		t.Fatalf("expected a non-decreasing timeline to pass, got %v", err)
+	}
+	scenario.Timeline = append(scenario.Timeline, PropertyPlannerTimeline{Year: 2029})
+	if err := scenario.CheckTimeline(); err == nil {
+		t.Fatal("expected an error when the timeline goes backwards")
+	}
+}
+
+func TestComputeAmortization(t *testing.T) {
+	inputs := MortgageInputs{LoanAmount: 500000, LoanTermYears: 25, LoanStartMonth: "2024-03", FixedRate: 3, HouseholdIncome: 10000}
+	amortization, snapshot := ComputeAmortization(inputs, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if math.Abs(snapshot.MonthlyPayment-2371.06) > 0.01 {
+		t.Fatalf("expected a monthly payment of about 2371.06, got %.4f", snapshot.MonthlyPayment)
+	}
+	if math.Abs(snapshot.TotalInterest-(snapshot.MonthlyPayment*300-500000)) > 1e-6 {
+		t.Fatalf("unexpected total interest %.2f", snapshot.TotalInterest)
+	}
+	if snapshot.LoanEndDate != "Mar 2049" || math.Abs(snapshot.MSRRatio-0.2371) > 0.0001 {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+
+	points := amortization.BalancePoints
+	if len(points) != 25 || points[0].Year != 2024 || points[24].Year != 2048 || points[24].Balance != 0 {
+		t.Fatalf("unexpected balance points %+v", points)
+	}
+	if first := amortization.Composition[0]; first.Label != "Year 1" || first.Interest <= first.Principal {
+		t.Fatalf("expected early payments to be mostly interest, got %+v", first)
+	}
+}
+
+func TestComputeAmortizationStartsInNowsYearWithoutStartMonth(t *testing.T) {
+	inputs := MortgageInputs{LoanAmount: 100000, LoanTermYears: 2, FixedRate: 2}
+	amortization, _ := ComputeAmortization(inputs, time.Date(2031, 12, 31, 0, 0, 0, 0, time.UTC))
+	if points := amortization.BalancePoints; len(points) != 2 || points[0].Year != 2031 || points[1].Year != 2032 {
+		t.Fatalf("expected the schedule to start in 2031, got %+v", points)
+	}
+}
+
+func TestMortgageInputsSingleRate(t *testing.T) {
+	for _, tc := range []struct {
+		in   MortgageInputs
+		want bool
+	}{
+		{MortgageInputs{LoanTermYears: 25, FixedYears: 25, FixedRate: 3, FloatingRate: 4}, true},
+		{MortgageInputs{LoanTermYears: 25, FixedYears: 3, FixedRate: 3, FloatingRate: 3}, true},
+		{MortgageInputs{LoanTermYears: 25, FixedYears: 3, FixedRate: 3, FloatingRate: 4}, false},
+		{MortgageInputs{LoanTermYears: 25, FixedRate: 3}, true},
+		{MortgageInputs{LoanTermYears: 25, FixedRate: 3, FloatingRate: 4}, false},
+	} {
+		if got := tc.in.SingleRate(); got != tc.want {
+			t.Fatalf("SingleRate(%+v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
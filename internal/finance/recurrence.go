@@ -0,0 +1,172 @@
+package finance
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	CashEventIncome  = "income"
+	CashEventExpense = "expense"
+)
+
+// Forecast places recurring incomes and expenses on concrete dates for each calendar
+// month starting with the month containing from.
+func Forecast(incomes []Income, expenses []Expense, from time.Time, months int) []ForecastMonth {
+	out := make([]ForecastMonth, 0, months)
+	start := monthStart(from)
+
+	for i := 0; i < months; i++ {
+		monthFrom := start.AddDate(0, i, 0)
+		events := expandBetween(incomes, expenses, monthFrom, monthFrom.AddDate(0, 1, 0))
+
+		var incomeTotal, expenseTotal float64
+		for _, evt := range events {
+			if evt.Kind == CashEventIncome {
+				incomeTotal += evt.Amount
+			} else {
+				expenseTotal += evt.Amount
+			}
+		}
+		incomeTotal = roundToCents(incomeTotal)
+		expenseTotal = roundToCents(expenseTotal)
+
+		out = append(out, ForecastMonth{
+			Month:    monthFrom.Format("2006-01"),
+			Income:   incomeTotal,
+			Expenses: expenseTotal,
+			Net:      roundToCents(incomeTotal - expenseTotal),
+			Events:   events,
+		})
+	}
+	return out
+}
+
+// ExpandOccurrences lists the concrete income and expense occurrences falling within
+// the next days calendar days starting at from, ordered chronologically.
+func ExpandOccurrences(incomes []Income, expenses []Expense, from time.Time, days int) []CashEvent {
+	start := dateOf(from, from.Location())
+	return expandBetween(incomes, expenses, start, start.AddDate(0, 0, days))
+}
+
+// expandBetween lists every occurrence in [from, to), ordered by date.
+func expandBetween(incomes []Income, expenses []Expense, from, to time.Time) []CashEvent {
+	events := []CashEvent{}
+
+	for _, income := range incomes {
+		for _, date := range recurrenceDates(income.Frequency, income.DayOfMonth, income.DayOfWeek, income.StartDate, from, to) {
+			events = append(events, CashEvent{
+				Date:     date,
+				Kind:     CashEventIncome,
+				SourceID: income.ID,
+				Name:     income.Source,
+				Category: income.Category,
+				Amount:   income.Amount,
+			})
+		}
+	}
+
+	for _, expense := range expenses {
+		for _, date := range recurrenceDates(expense.Frequency, expense.DayOfMonth, expense.DayOfWeek, time.Time{}, from, to) {
+			events = append(events, CashEvent{
+				Date:     date,
+				Kind:     CashEventExpense,
+				SourceID: expense.ID,
+				Name:     expense.Payee,
+				Category: expense.Category,
+				Amount:   expense.Amount,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+	return events
+}
+
+// recurrenceDates returns the dates in [from, to) on which an entry lands. Entries without
+// an anchor fall back to the first of the month (or the start date's weekday for weekly
+// cadences). Day-of-month anchors past the end of a short month clamp to its last day.
+func recurrenceDates(freq Frequency, dayOfMonth, dayOfWeek *int, start, from, to time.Time) []time.Time {
+	loc := from.Location()
+	from = dateOf(from, loc)
+	if !start.IsZero() {
+		start = dateOf(start, loc)
+		if start.After(from) {
+			from = start
+		}
+	}
+
+	var dates []time.Time
+	switch freq {
+	case FrequencyWeekly, FrequencyBiWeekly:
+		step := 7
+		if freq == FrequencyBiWeekly {
+			step = 14
+		}
+
+		anchor := start
+		if anchor.IsZero() {
+			anchor = monthStart(from)
+		}
+		if dayOfWeek != nil {
+			anchor = anchor.AddDate(0, 0, (*dayOfWeek-int(anchor.Weekday())+7)%7)
+		}
+		if anchor.Before(from) {
+			gap := daysBetween(anchor, from)
+			anchor = anchor.AddDate(0, 0, (gap+step-1)/step*step)
+		}
+
+		for d := anchor; d.Before(to); d = d.AddDate(0, 0, step) {
+			dates = append(dates, d)
+		}
+	default:
+		interval := 1
+		switch freq {
+		case FrequencyQuarterly:
+			interval = 3
+		case FrequencyYearly:
+			interval = 12
+		}
+
+		phase := time.January
+		if !start.IsZero() {
+			phase = start.Month()
+		}
+
+		day := 1
+		if dayOfMonth != nil {
+			day = *dayOfMonth
+		}
+
+		for m := monthStart(from); m.Before(to); m = m.AddDate(0, 1, 0) {
+			if (int(m.Month())-int(phase)+12)%interval != 0 {
+				continue
+			}
+			d := time.Date(m.Year(), m.Month(), min(day, daysIn(m)), 0, 0, 0, 0, loc)
+			if d.Before(from) || !d.Before(to) {
+				continue
+			}
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+func dateOf(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func daysIn(month time.Time) int {
+	return monthStart(month).AddDate(0, 1, -1).Day()
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours()/24 + 0.5)
+}
@@ -0,0 +1,158 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestForecastPlacesSalaryOnAnchoredDay(t *testing.T) {
+	from := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	incomes := []Income{
+		{ID: "i1", Source: "Salary", Amount: 8000, Frequency: FrequencyMonthly, DayOfMonth: intPtr(25), StartDate: from.AddDate(-1, 0, 0)},
+	}
+
+	forecast := Forecast(incomes, nil, from, 3)
+
+	if len(forecast) != 3 {
+		t.Fatalf("expected 3 months, got %d", len(forecast))
+	}
+	for _, month := range forecast {
+		if len(month.Events) != 1 {
+			t.Fatalf("%s: expected 1 event, got %d", month.Month, len(month.Events))
+		}
+		if day := month.Events[0].Date.Day(); day != 25 {
+			t.Fatalf("%s: expected salary on the 25th, got day %d", month.Month, day)
+		}
+		if month.Income != 8000 || month.Net != 8000 {
+			t.Fatalf("%s: unexpected totals %#v", month.Month, month)
+		}
+	}
+	if forecast[0].Month != "2024-01" {
+		t.Fatalf("expected forecast to start in 2024-01, got %s", forecast[0].Month)
+	}
+}
+
+func TestForecastFallsBackToStartOfMonth(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	expenses := []Expense{
+		{ID: "e1", Payee: "Rent", Amount: 2500, Frequency: FrequencyMonthly},
+	}
+
+	forecast := Forecast(nil, expenses, from, 1)
+
+	events := forecast[0].Events
+	if len(events) != 1 || events[0].Date.Day() != 1 {
+		t.Fatalf("expected rent on the 1st, got %#v", events)
+	}
+	if forecast[0].Net != -2500 {
+		t.Fatalf("expected net -2500, got %.2f", forecast[0].Net)
+	}
+}
+
+func TestForecastBucketsMonthsInFromLocation(t *testing.T) {
+	singapore, err := time.LoadLocation("Asia/Singapore")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	// 20:00 UTC on Jan 31 is already 04:00 on Feb 1 in Singapore.
+	instant := time.Date(2024, 1, 31, 20, 0, 0, 0, time.UTC)
+	expenses := []Expense{
+		{ID: "e1", Payee: "Rent", Amount: 2500, Frequency: FrequencyMonthly, DayOfMonth: intPtr(1)},
+	}
+
+	utc := Forecast(nil, expenses, instant, 1)
+	local := Forecast(nil, expenses, instant.In(singapore), 1)
+
+	if utc[0].Month != "2024-01" {
+		t.Fatalf("expected the UTC forecast to start in January, got %s", utc[0].Month)
+	}
+	if local[0].Month != "2024-02" || len(local[0].Events) != 1 {
+		t.Fatalf("expected February with rent due in Singapore, got %s with %d events", local[0].Month, len(local[0].Events))
+	}
+	if got := local[0].Events[0].Date; got.Location() != singapore || got.Day() != 1 {
+		t.Fatalf("expected rent on Feb 1 Singapore time, got %s", got)
+	}
+}
+
+func TestForecastAnchorsWeeklyToDayOfWeek(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	expenses := []Expense{
+		{ID: "e1", Payee: "Groceries", Amount: 150, Frequency: FrequencyWeekly, DayOfWeek: intPtr(int(time.Friday))},
+	}
+
+	forecast := Forecast(nil, expenses, from, 1)
+
+	events := forecast[0].Events
+	if len(events) != 4 {
+		t.Fatalf("expected 4 Fridays in April 2024, got %d", len(events))
+	}
+	for _, evt := range events {
+		if evt.Date.Weekday() != time.Friday {
+			t.Fatalf("expected Friday, got %s", evt.Date.Weekday())
+		}
+	}
+}
+
+func TestForecastSkipsOccurrencesBeforeStartDate(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	incomes := []Income{
+		{ID: "i1", Source: "New job", Amount: 5000, Frequency: FrequencyMonthly, DayOfMonth: intPtr(15), StartDate: time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)},
+	}
+
+	forecast := Forecast(incomes, nil, from, 3)
+
+	if len(forecast[0].Events) != 0 || len(forecast[1].Events) != 0 {
+		t.Fatalf("expected no income before the start date, got %#v", forecast[:2])
+	}
+	if len(forecast[2].Events) != 1 {
+		t.Fatalf("expected income in March, got %#v", forecast[2])
+	}
+}
+
+func TestExpandOccurrencesClampsToMonthEnd(t *testing.T) {
+	from := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	expenses := []Expense{
+		{ID: "e1", Payee: "Card", Amount: 300, Frequency: FrequencyMonthly, DayOfMonth: intPtr(31)},
+	}
+
+	events := ExpandOccurrences(nil, expenses, from, 60)
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 occurrences, got %#v", events)
+	}
+	if got := events[0].Date; got.Month() != time.January || got.Day() != 31 {
+		t.Fatalf("expected January 31, got %s", got)
+	}
+	if got := events[1].Date; got.Month() != time.February || got.Day() != 29 {
+		t.Fatalf("expected clamp to February 29, got %s", got)
+	}
+}
+
+func TestExpandOccurrencesSortsChronologically(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	incomes := []Income{
+		{ID: "i1", Source: "Salary", Amount: 5000, Frequency: FrequencyMonthly, DayOfMonth: intPtr(20)},
+	}
+	expenses := []Expense{
+		{ID: "e1", Payee: "Rent", Amount: 2000, Frequency: FrequencyMonthly, DayOfMonth: intPtr(5)},
+		{ID: "e2", Payee: "Gym", Amount: 20, Frequency: FrequencyBiWeekly, DayOfWeek: intPtr(int(time.Monday))},
+	}
+
+	events := ExpandOccurrences(incomes, expenses, from, 7)
+
+	if len(events) != 2 {
+		t.Fatalf("expected rent and one gym payment in the first week, got %#v", events)
+	}
+	if events[0].SourceID != "e2" || events[1].SourceID != "e1" {
+		t.Fatalf("expected gym (Jun 3) before rent (Jun 5), got %#v", events)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Date.Before(events[i-1].Date) {
+			t.Fatalf("events out of order: %#v", events)
+		}
+	}
+}
@@ -1,9 +1,104 @@
 package finance
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// SeedOptions varies the demo dataset. The zero value reproduces DefaultSeedData.
+type SeedOptions struct {
+	// ValueScale multiplies asset values, liability balances and payments, and income
+	// and expense amounts. Zero means 1.
+	ValueScale float64
+	// AssetGrowthRate, when set, replaces every asset's annual growth rate.
+	AssetGrowthRate *float64
+	// InterestRate, when set, replaces every liability's APR.
+	InterestRate *float64
+	// Assets, Liabilities, Incomes and Expenses set how many of each entity to return.
+	// Zero keeps the default count; fewer takes the first n, and more repeats the
+	// defaults with numbered IDs and names.
+	Assets      int
+	Liabilities int
+	Incomes     int
+	Expenses    int
+}
 
 // DefaultSeedData returns demo-friendly finance entities for local dev/test.
 func DefaultSeedData(now time.Time) SeedData {
+	return NewSeedData(now, SeedOptions{})
+}
+
+// NewSeedData returns the demo dataset adjusted by opts.
+func NewSeedData(now time.Time, opts SeedOptions) SeedData {
+	data := seedTemplates(now)
+	scale := opts.ValueScale
+	if scale == 0 {
+		scale = 1
+	}
+
+	data.Assets = resize(data.Assets, opts.Assets, func(asset *Asset, n int) {
+		asset.ID, asset.Name = numberedID(asset.ID, n), numbered(asset.Name, n)
+	})
+	for i := range data.Assets {
+		data.Assets[i].CurrentValue = roundToCents(data.Assets[i].CurrentValue * scale)
+		if opts.AssetGrowthRate != nil {
+			data.Assets[i].AnnualGrowthRate = *opts.AssetGrowthRate
+		}
+	}
+
+	data.Liabilities = resize(data.Liabilities, opts.Liabilities, func(liability *Liability, n int) {
+		liability.ID, liability.Name = numberedID(liability.ID, n), numbered(liability.Name, n)
+	})
+	for i := range data.Liabilities {
+		data.Liabilities[i].CurrentBalance = roundToCents(data.Liabilities[i].CurrentBalance * scale)
+		data.Liabilities[i].MinimumPayment = roundToCents(data.Liabilities[i].MinimumPayment * scale)
+		if opts.InterestRate != nil {
+			data.Liabilities[i].InterestRateAPR = *opts.InterestRate
+		}
+	}
+
+	data.Incomes = resize(data.Incomes, opts.Incomes, func(income *Income, n int) {
+		income.ID, income.Source = numberedID(income.ID, n), numbered(income.Source, n)
+	})
+	for i := range data.Incomes {
+		data.Incomes[i].Amount = roundToCents(data.Incomes[i].Amount * scale)
+	}
+
+	data.Expenses = resize(data.Expenses, opts.Expenses, func(expense *Expense, n int) {
+		expense.ID, expense.Payee = numberedID(expense.ID, n), numbered(expense.Payee, n)
+	})
+	for i := range data.Expenses {
+		data.Expenses[i].Amount = roundToCents(data.Expenses[i].Amount * scale)
+	}
+	return data
+}
+
+// resize returns count items cycling through templates; rename labels the nth repeat
+// (2, 3, ...) so IDs stay unique. A count of zero returns templates unchanged.
+func resize[T any](templates []T, count int, rename func(item *T, n int)) []T {
+	if count <= 0 {
+		return templates
+	}
+	out := make([]T, count)
+	for i := range out {
+		out[i] = templates[i%len(templates)]
+		if round := i / len(templates); round > 0 {
+			rename(&out[i], round+1)
+		}
+	}
+	return out
+}
+
+func numbered(label string, n int) string {
+	return fmt.Sprintf("%s %d", label, n)
+}
+
+func numberedID(id string, n int) string {
+	return fmt.Sprintf("%s-%d", id, n)
+}
+
+// seedTemplates is the default demo dataset.
+func seedTemplates(now time.Time) SeedData {
 	return SeedData{
 		Assets: []Asset{
 			{
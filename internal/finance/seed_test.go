@@ -0,0 +1,55 @@
+package finance
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewSeedDataZeroOptionsMatchesDefault(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !reflect.DeepEqual(NewSeedData(now, SeedOptions{}), DefaultSeedData(now)) {
+		t.Fatal("expected zero options to reproduce the default seed data")
+	}
+}
+
+func TestNewSeedDataAppliesOverrides(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	growth, apr := 0.0, 0.05
+	data := NewSeedData(now, SeedOptions{
+		ValueScale:      2,
+		AssetGrowthRate: &growth,
+		InterestRate:    &apr,
+		Assets:          7,
+		Liabilities:     1,
+		Expenses:        2,
+	})
+	defaults := DefaultSeedData(now)
+
+	if len(data.Assets) != 7 || len(data.Liabilities) != 1 || len(data.Incomes) != len(defaults.Incomes) || len(data.Expenses) != 2 {
+		t.Fatalf("unexpected counts: %d assets, %d liabilities, %d incomes, %d expenses",
+			len(data.Assets), len(data.Liabilities), len(data.Incomes), len(data.Expenses))
+	}
+	ids := make(map[string]bool)
+	for _, asset := range data.Assets {
+		if ids[asset.ID] {
+			t.Fatalf("duplicate asset id %q", asset.ID)
+		}
+		ids[asset.ID] = true
+		if asset.AnnualGrowthRate != 0 {
+			t.Fatalf("expected the growth override on %s, got %v", asset.ID, asset.AnnualGrowthRate)
+		}
+	}
+	if repeat := data.Assets[3]; repeat.ID != "asset-brokerage-2" || repeat.Name != "Total Market Index 2" {
+		t.Fatalf("expected repeats to be numbered, got %+v", repeat)
+	}
+	if data.Assets[0].CurrentValue != 2*defaults.Assets[0].CurrentValue {
+		t.Fatalf("expected values to scale, got %v", data.Assets[0].CurrentValue)
+	}
+	if liability := data.Liabilities[0]; liability.InterestRateAPR != 0.05 || liability.MinimumPayment != 2*defaults.Liabilities[0].MinimumPayment {
+		t.Fatalf("expected rate override and scaled payment, got %+v", liability)
+	}
+	if data.Incomes[0].Amount != 2*defaults.Incomes[0].Amount {
+		t.Fatalf("expected income amounts to scale, got %v", data.Incomes[0].Amount)
+	}
+}
@@ -0,0 +1,74 @@
+package finance
+
+import (
+	"sort"
+	"time"
+)
+
+// TransactionDirection says whether a ledger entry brought money in or sent it out.
+type TransactionDirection string
+
+const (
+	TransactionInflow  TransactionDirection = "inflow"
+	TransactionOutflow TransactionDirection = "outflow"
+)
+
+// ReconcileMonth compares the transactions logged in the calendar month containing
+// month against the recurring incomes and expenses expected to land in it. Inflows are
+// matched to income categories and outflows to expense categories.
+func ReconcileMonth(month time.Time, incomes []Income, expenses []Expense, transactions []Transaction) MonthlyReconciliation {
+	start := monthStart(month)
+	end := start.AddDate(0, 1, 0)
+
+	type key struct{ kind, category string }
+	rows := make(map[key]*CategoryReconciliation)
+	row := func(kind, category string) *CategoryReconciliation {
+		k := key{kind, category}
+		if rows[k] == nil {
+			rows[k] = &CategoryReconciliation{Kind: kind, Category: category}
+		}
+		return rows[k]
+	}
+
+	out := MonthlyReconciliation{Month: start.Format("2006-01")}
+	for _, evt := range expandBetween(incomes, expenses, start, end) {
+		row(evt.Kind, evt.Category).Budgeted += evt.Amount
+		if evt.Kind == CashEventIncome {
+			out.BudgetedIncome += evt.Amount
+		} else {
+			out.BudgetedExpenses += evt.Amount
+		}
+	}
+	for _, txn := range transactions {
+		if txn.Date.Before(start) || !txn.Date.Before(end) {
+			continue
+		}
+		if txn.Direction == TransactionInflow {
+			row(CashEventIncome, txn.Category).Actual += txn.Amount
+			out.ActualIncome += txn.Amount
+		} else {
+			row(CashEventExpense, txn.Category).Actual += txn.Amount
+			out.ActualExpenses += txn.Amount
+		}
+	}
+
+	out.BudgetedIncome = roundToCents(out.BudgetedIncome)
+	out.BudgetedExpenses = roundToCents(out.BudgetedExpenses)
+	out.ActualIncome = roundToCents(out.ActualIncome)
+	out.ActualExpenses = roundToCents(out.ActualExpenses)
+
+	out.Categories = make([]CategoryReconciliation, 0, len(rows))
+	for _, r := range rows {
+		r.Budgeted = roundToCents(r.Budgeted)
+		r.Actual = roundToCents(r.Actual)
+		r.Variance = roundToCents(r.Actual - r.Budgeted)
+		out.Categories = append(out.Categories, *r)
+	}
+	sort.Slice(out.Categories, func(i, j int) bool {
+		if out.Categories[i].Kind != out.Categories[j].Kind {
+			return out.Categories[i].Kind == CashEventIncome
+		}
+		return out.Categories[i].Category < out.Categories[j].Category
+	})
+	return out
+}
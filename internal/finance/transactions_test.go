@@ -0,0 +1,50 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconcileMonthComparesLoggedAgainstBudget(t *testing.T) {
+	month := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	day := 1
+	incomes := []Income{
+		{ID: "i1", Source: "Salary", Amount: 5000, Frequency: FrequencyMonthly, Category: "salary", DayOfMonth: &day},
+	}
+	expenses := []Expense{
+		{ID: "e1", Payee: "Rent", Amount: 2000, Frequency: FrequencyMonthly, Category: "housing"},
+		{ID: "e2", Payee: "Groceries", Amount: 600, Frequency: FrequencyMonthly, Category: "food"},
+	}
+	transactions := []Transaction{
+		{ID: "t1", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Amount: 5000, Direction: TransactionInflow, Category: "salary"},
+		{ID: "t2", Date: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Amount: 2000, Direction: TransactionOutflow, Category: "housing"},
+		{ID: "t3", Date: time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), Amount: 450.25, Direction: TransactionOutflow, Category: "food"},
+		{ID: "t4", Date: time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), Amount: 300, Direction: TransactionOutflow, Category: "food"},
+		{ID: "t5", Date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Amount: 999, Direction: TransactionOutflow, Category: "food"},
+	}
+
+	got := ReconcileMonth(month, incomes, expenses, transactions)
+
+	if got.Month != "2024-03" {
+		t.Fatalf("expected month 2024-03, got %s", got.Month)
+	}
+	if got.BudgetedIncome != 5000 || got.ActualIncome != 5000 {
+		t.Fatalf("unexpected income totals %+v", got)
+	}
+	if got.BudgetedExpenses != 2600 || got.ActualExpenses != 2750.25 {
+		t.Fatalf("unexpected expense totals %+v", got)
+	}
+
+	var food *CategoryReconciliation
+	for i := range got.Categories {
+		if got.Categories[i].Category == "food" {
+			food = &got.Categories[i]
+		}
+	}
+	if food == nil || food.Variance != 150.25 {
+		t.Fatalf("expected food variance 150.25, got %+v", food)
+	}
+	if got.Categories[0].Kind != CashEventIncome {
+		t.Fatalf("expected income categories first, got %+v", got.Categories)
+	}
+}
@@ -0,0 +1,117 @@
+// Package healthcheck runs the named dependency checks behind the readiness probe.
+package healthcheck
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Check reports whether a dependency is usable. It must return once ctx is done.
+type Check func(ctx context.Context) error
+
+// Status is the outcome of one check.
+type Status struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Status values.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Registry holds the checks that must pass for the service to be ready. It is safe for
+// concurrent use, so components can register checks after the server starts.
+type Registry struct {
+	mu      sync.RWMutex
+	checks  map[string]Check
+	timeout time.Duration
+}
+
+// NewRegistry returns an empty registry that gives each run timeout to finish; zero
+// or negative leaves runs bounded only by the caller's context.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{checks: make(map[string]Check), timeout: timeout}
+}
+
+// Register adds check under name, replacing any check already registered with it.
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// Names lists the registered checks in alphabetical order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run executes every check concurrently and reports each one's status, keyed by name.
+// ready is true when all passed. A check still running when the timeout expires is
+// reported as failed with the context error; Run does not wait for it to return.
+func (r *Registry) Run(ctx context.Context) (statuses map[string]Status, ready bool) {
+	r.mu.RLock()
+	checks := make(map[string]Check, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	r.mu.RUnlock()
+
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		name   string
+		status Status
+	}
+	results := make(chan result, len(checks))
+	for name, check := range checks {
+		go func() {
+			start := time.Now()
+			err := check(ctx)
+			status := Status{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				status.Status = StatusFail
+				status.Error = err.Error()
+			}
+			results <- result{name: name, status: status}
+		}()
+	}
+
+	start := time.Now()
+	statuses = make(map[string]Status, len(checks))
+	for len(statuses) < len(checks) {
+		select {
+		case res := <-results:
+			statuses[res.name] = res.status
+		case <-ctx.Done():
+			for name := range checks {
+				if _, ok := statuses[name]; !ok {
+					statuses[name] = Status{Status: StatusFail, Error: ctx.Err().Error(), DurationMs: time.Since(start).Milliseconds()}
+				}
+			}
+		}
+	}
+
+	ready = true
+	for _, status := range statuses {
+		if status.Status != StatusOK {
+			ready = false
+		}
+	}
+	return statuses, ready
+}
@@ -0,0 +1,57 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunReportsEachCheck(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register("database", func(context.Context) error { return nil })
+	registry.Register("fx", func(context.Context) error { return errors.New("rates unavailable") })
+
+	statuses, ready := registry.Run(context.Background())
+	if ready {
+		t.Fatal("expected not ready with a failing check")
+	}
+	if statuses["database"].Status != StatusOK {
+		t.Fatalf("expected database ok, got %+v", statuses["database"])
+	}
+	if fx := statuses["fx"]; fx.Status != StatusFail || fx.Error != "rates unavailable" {
+		t.Fatalf("expected fx to fail with its error, got %+v", fx)
+	}
+
+	registry.Register("fx", func(context.Context) error { return nil })
+	if _, ready := registry.Run(context.Background()); !ready {
+		t.Fatal("expected ready once every check passes")
+	}
+}
+
+func TestRunTimesOutHungChecks(t *testing.T) {
+	registry := NewRegistry(20 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	registry.Register("hung", func(context.Context) error {
+		<-release
+		return nil
+	})
+	registry.Register("fast", func(context.Context) error { return nil })
+
+	start := time.Now()
+	statuses, ready := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the run to give up at the timeout, took %s", elapsed)
+	}
+	if ready || statuses["hung"].Status != StatusFail || statuses["fast"].Status != StatusOK {
+		t.Fatalf("expected only the hung check to fail, got %+v", statuses)
+	}
+}
+
+func TestRunWithNoChecksIsReady(t *testing.T) {
+	statuses, ready := NewRegistry(0).Run(context.Background())
+	if !ready || len(statuses) != 0 {
+		t.Fatalf("expected an empty registry to be ready, got %v %+v", ready, statuses)
+	}
+}
@@ -15,23 +15,86 @@ var migrationFiles embed.FS
 
 // Run applies all pending migrations using the provided sql.DB connection.
 func Run(db *sql.DB) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	m, err := newMigrator(db)
 	if err != nil {
-		return fmt.Errorf("configure postgres driver: %w", err)
+		return err
 	}
 
-	d, err := iofs.New(migrationFiles, "sql")
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("run migrations: %w", err)
+	}
+	return nil
+}
+
+// Down rolls back the given number of migrations. A steps value of zero rolls back
+// every migration, dropping all managed tables.
+func Down(db *sql.DB, steps int) error {
+	if steps < 0 {
+		return fmt.Errorf("steps must not be negative, got %d", steps)
+	}
+
+	m, err := newMigrator(db)
 	if err != nil {
-		return fmt.Errorf("load embedded migrations: %w", err)
+		return err
 	}
 
-	m, err := migrate.NewWithInstance("iofs", d, "postgres", driver)
+	if steps == 0 {
+		err = m.Down()
+	} else {
+		err = m.Steps(-steps)
+	}
+	if err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("roll back migrations: %w", err)
+	}
+	return nil
+}
+
+// Version reports the applied schema version and whether the last migration left it
+// dirty. A database with no migrations applied reports version 0.
+func Version(db *sql.DB) (version uint, dirty bool, err error) {
+	m, err := newMigrator(db)
 	if err != nil {
-		return fmt.Errorf("create migrator: %w", err)
+		return 0, false, err
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("run migrations: %w", err)
+	version, dirty, err = m.Version()
+	if err == migrate.ErrNilVersion {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read migration version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Force records version as the current schema version and clears the dirty flag
+// without running any migration. Use it after manually repairing a failed migration.
+func Force(db *sql.DB, version int) error {
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("force migration version: %w", err)
 	}
 	return nil
 }
+
+func newMigrator(db *sql.DB) (*migrate.Migrate, error) {
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("configure postgres driver: %w", err)
+	}
+
+	d, err := iofs.New(migrationFiles, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("load embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", d, "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("create migrator: %w", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,128 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// openTestDB connects to TEST_DATABASE_URL, skipping when no disposable database
+// is configured. Tests migrate it freely, so never point it at real data.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestDownRestoresPriorSchema(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := Run(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	if err := Verify(db); err != nil {
+		t.Fatalf("expected migrated schema to verify: %v", err)
+	}
+
+	before, _, err := Version(db)
+	if err != nil {
+		t.Fatalf("read version: %v", err)
+	}
+	if err := Down(db, 1); err != nil {
+		t.Fatalf("roll back migration: %v", err)
+	}
+	after, _, err := Version(db)
+	if err != nil {
+		t.Fatalf("read version: %v", err)
+	}
+	if after != before-1 {
+		t.Fatalf("expected version %d after rolling back one step, got %d", before-1, after)
+	}
+	if err := Verify(db); err == nil {
+		t.Fatal("expected verification to fail after rolling back")
+	}
+
+	if err := Run(db); err != nil {
+		t.Fatalf("re-run migrations: %v", err)
+	}
+	if err := Verify(db); err != nil {
+		t.Fatalf("expected re-migrated schema to verify: %v", err)
+	}
+}
+
+func TestDownRejectsNegativeSteps(t *testing.T) {
+	if err := Down(nil, -1); err == nil {
+		t.Fatal("expected negative steps to be rejected")
+	}
+}
+
+func TestVersionAndForce(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := Run(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	version, dirty, err := Version(db)
+	if err != nil {
+		t.Fatalf("read version: %v", err)
+	}
+	if version == 0 || dirty {
+		t.Fatalf("expected a clean non-zero version, got %d (dirty=%t)", version, dirty)
+	}
+
+	// Simulate a failed deploy, then clear it.
+	if _, err := db.Exec(`UPDATE schema_migrations SET dirty = true`); err != nil {
+		t.Fatalf("mark dirty: %v", err)
+	}
+	if _, dirty, _ := Version(db); !dirty {
+		t.Fatal("expected version to report dirty")
+	}
+	if err := Force(db, int(version)); err != nil {
+		t.Fatalf("force version: %v", err)
+	}
+	forced, dirty, err := Version(db)
+	if err != nil {
+		t.Fatalf("read version: %v", err)
+	}
+	if forced != version || dirty {
+		t.Fatalf("expected clean version %d after force, got %d (dirty=%t)", version, forced, dirty)
+	}
+}
+
+func TestCheckConstraintsRejectBadRows(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := Run(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	statements := map[string]string{
+		"unknown frequency": `INSERT INTO finance_incomes (id, source, amount, frequency, start_date, category)
+			VALUES (gen_random_uuid(), 'Salary', 100, 'daily', now(), 'salary')`,
+		"zero amount": `INSERT INTO finance_expenses (id, payee, amount, frequency, category)
+			VALUES (gen_random_uuid(), 'Rent', 0, 'monthly', 'housing')`,
+		"negative value": `INSERT INTO finance_assets (id, name, category, current_value)
+			VALUES (gen_random_uuid(), 'Savings', 'cash', -1)`,
+		"negative apr": `INSERT INTO finance_liabilities (id, name, category, current_balance, interest_rate_apr)
+			VALUES (gen_random_uuid(), 'Card', 'credit_card', 100, -0.1)`,
+	}
+	for name, stmt := range statements {
+		var pgErr *pgconn.PgError
+		if _, err := db.Exec(stmt); !errors.As(err, &pgErr) || pgErr.Code != "23514" {
+			t.Fatalf("expected %s to violate a check constraint, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS net_worth_snapshots_recorded_at_idx;
+DROP TABLE IF EXISTS net_worth_snapshots;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS net_worth_snapshots (
+    id uuid PRIMARY KEY,
+    total_assets double precision NOT NULL,
+    total_liabilities double precision NOT NULL,
+    net_worth double precision NOT NULL,
+    recorded_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS net_worth_snapshots_recorded_at_idx
+ON net_worth_snapshots(recorded_at);
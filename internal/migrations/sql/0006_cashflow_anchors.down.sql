@@ -0,0 +1,7 @@
+ALTER TABLE finance_expenses
+    DROP COLUMN IF EXISTS day_of_week,
+    DROP COLUMN IF EXISTS day_of_month;
+
+ALTER TABLE finance_incomes
+    DROP COLUMN IF EXISTS day_of_week,
+    DROP COLUMN IF EXISTS day_of_month;
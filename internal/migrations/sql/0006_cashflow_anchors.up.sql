@@ -0,0 +1,7 @@
+ALTER TABLE finance_incomes
+    ADD COLUMN IF NOT EXISTS day_of_month smallint CHECK (day_of_month BETWEEN 1 AND 31),
+    ADD COLUMN IF NOT EXISTS day_of_week smallint CHECK (day_of_week BETWEEN 0 AND 6);
+
+ALTER TABLE finance_expenses
+    ADD COLUMN IF NOT EXISTS day_of_month smallint CHECK (day_of_month BETWEEN 1 AND 31),
+    ADD COLUMN IF NOT EXISTS day_of_week smallint CHECK (day_of_week BETWEEN 0 AND 6);
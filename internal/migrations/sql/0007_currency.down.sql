@@ -0,0 +1,4 @@
+ALTER TABLE finance_expenses DROP COLUMN IF EXISTS currency;
+ALTER TABLE finance_incomes DROP COLUMN IF EXISTS currency;
+ALTER TABLE finance_liabilities DROP COLUMN IF EXISTS currency;
+ALTER TABLE finance_assets DROP COLUMN IF EXISTS currency;
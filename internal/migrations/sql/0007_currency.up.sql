@@ -0,0 +1,4 @@
+ALTER TABLE finance_assets ADD COLUMN IF NOT EXISTS currency text;
+ALTER TABLE finance_liabilities ADD COLUMN IF NOT EXISTS currency text;
+ALTER TABLE finance_incomes ADD COLUMN IF NOT EXISTS currency text;
+ALTER TABLE finance_expenses ADD COLUMN IF NOT EXISTS currency text;
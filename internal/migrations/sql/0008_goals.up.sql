@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS finance_goals (
+    id uuid PRIMARY KEY,
+    name text NOT NULL,
+    target_amount double precision NOT NULL CHECK (target_amount > 0),
+    target_date timestamptz,
+    asset_category text NOT NULL,
+    notes text,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE finance_liabilities DROP COLUMN IF EXISTS account_id;
+ALTER TABLE finance_assets DROP COLUMN IF EXISTS account_id;
+DROP TABLE IF EXISTS finance_accounts;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS finance_accounts (
+    id uuid PRIMARY KEY,
+    name text NOT NULL,
+    institution text,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE finance_assets
+    ADD COLUMN IF NOT EXISTS account_id uuid REFERENCES finance_accounts(id) ON DELETE RESTRICT;
+ALTER TABLE finance_liabilities
+    ADD COLUMN IF NOT EXISTS account_id uuid REFERENCES finance_accounts(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS finance_assets_account_id_idx ON finance_assets (account_id);
+CREATE INDEX IF NOT EXISTS finance_liabilities_account_id_idx ON finance_liabilities (account_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS finance_transactions;
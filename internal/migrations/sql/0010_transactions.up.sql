@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS finance_transactions (
+    id uuid PRIMARY KEY,
+    occurred_at timestamptz NOT NULL,
+    amount double precision NOT NULL CHECK (amount > 0),
+    currency text,
+    direction text NOT NULL CHECK (direction IN ('inflow', 'outflow')),
+    category text NOT NULL,
+    account_id uuid REFERENCES finance_accounts(id) ON DELETE RESTRICT,
+    memo text,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS finance_transactions_occurred_at_idx ON finance_transactions (occurred_at DESC);
+CREATE INDEX IF NOT EXISTS finance_transactions_category_idx ON finance_transactions (category);
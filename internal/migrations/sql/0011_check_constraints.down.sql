@@ -0,0 +1,8 @@
+ALTER TABLE finance_liabilities DROP CONSTRAINT IF EXISTS finance_liabilities_interest_rate_apr_check;
+ALTER TABLE finance_assets DROP CONSTRAINT IF EXISTS finance_assets_current_value_check;
+ALTER TABLE finance_expenses
+    DROP CONSTRAINT IF EXISTS finance_expenses_amount_check,
+    DROP CONSTRAINT IF EXISTS finance_expenses_frequency_check;
+ALTER TABLE finance_incomes
+    DROP CONSTRAINT IF EXISTS finance_incomes_amount_check,
+    DROP CONSTRAINT IF EXISTS finance_incomes_frequency_check;
@@ -0,0 +1,22 @@
+-- Unknown frequencies were always computed as monthly, so store them that way before
+-- the constraint makes them impossible.
+UPDATE finance_incomes SET frequency = 'monthly'
+WHERE frequency NOT IN ('weekly', 'biweekly', 'monthly', 'quarterly', 'yearly');
+UPDATE finance_expenses SET frequency = 'monthly'
+WHERE frequency NOT IN ('weekly', 'biweekly', 'monthly', 'quarterly', 'yearly');
+
+ALTER TABLE finance_incomes
+    ADD CONSTRAINT finance_incomes_frequency_check
+        CHECK (frequency IN ('weekly', 'biweekly', 'monthly', 'quarterly', 'yearly')),
+    ADD CONSTRAINT finance_incomes_amount_check CHECK (amount > 0);
+
+ALTER TABLE finance_expenses
+    ADD CONSTRAINT finance_expenses_frequency_check
+        CHECK (frequency IN ('weekly', 'biweekly', 'monthly', 'quarterly', 'yearly')),
+    ADD CONSTRAINT finance_expenses_amount_check CHECK (amount > 0);
+
+ALTER TABLE finance_assets
+    ADD CONSTRAINT finance_assets_current_value_check CHECK (current_value >= 0);
+
+ALTER TABLE finance_liabilities
+    ADD CONSTRAINT finance_liabilities_interest_rate_apr_check CHECK (interest_rate_apr >= 0);
@@ -0,0 +1 @@
+ALTER TABLE finance_assets DROP COLUMN IF EXISTS linked_liability_id;
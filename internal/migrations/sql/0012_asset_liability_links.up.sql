@@ -0,0 +1,6 @@
+-- Links an asset to the liability secured against it (a property and its mortgage).
+-- Deleting a linked liability is refused until the asset is unlinked.
+ALTER TABLE finance_assets
+    ADD COLUMN IF NOT EXISTS linked_liability_id uuid REFERENCES finance_liabilities(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS finance_assets_linked_liability_id_idx ON finance_assets (linked_liability_id);
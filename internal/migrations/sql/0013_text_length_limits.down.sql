@@ -0,0 +1,26 @@
+ALTER TABLE finance_transactions
+    DROP CONSTRAINT IF EXISTS finance_transactions_category_length_check,
+    DROP CONSTRAINT IF EXISTS finance_transactions_memo_length_check;
+ALTER TABLE finance_accounts
+    DROP CONSTRAINT IF EXISTS finance_accounts_name_length_check,
+    DROP CONSTRAINT IF EXISTS finance_accounts_institution_length_check;
+ALTER TABLE finance_goals
+    DROP CONSTRAINT IF EXISTS finance_goals_name_length_check,
+    DROP CONSTRAINT IF EXISTS finance_goals_asset_category_length_check,
+    DROP CONSTRAINT IF EXISTS finance_goals_notes_length_check;
+ALTER TABLE finance_expenses
+    DROP CONSTRAINT IF EXISTS finance_expenses_payee_length_check,
+    DROP CONSTRAINT IF EXISTS finance_expenses_category_length_check,
+    DROP CONSTRAINT IF EXISTS finance_expenses_notes_length_check;
+ALTER TABLE finance_incomes
+    DROP CONSTRAINT IF EXISTS finance_incomes_source_length_check,
+    DROP CONSTRAINT IF EXISTS finance_incomes_category_length_check,
+    DROP CONSTRAINT IF EXISTS finance_incomes_notes_length_check;
+ALTER TABLE finance_liabilities
+    DROP CONSTRAINT IF EXISTS finance_liabilities_name_length_check,
+    DROP CONSTRAINT IF EXISTS finance_liabilities_category_length_check,
+    DROP CONSTRAINT IF EXISTS finance_liabilities_notes_length_check;
+ALTER TABLE finance_assets
+    DROP CONSTRAINT IF EXISTS finance_assets_name_length_check,
+    DROP CONSTRAINT IF EXISTS finance_assets_category_length_check,
+    DROP CONSTRAINT IF EXISTS finance_assets_notes_length_check;
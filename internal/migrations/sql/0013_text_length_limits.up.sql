@@ -0,0 +1,70 @@
+-- Caps free-text columns. Labels (names, categories, payees, sources, institutions)
+-- match the API's 200-character limit. Notes and memos allow 10000 characters: the API
+-- limits them to 2000 as submitted, and NOTES_SANITIZE=escape can grow that text up
+-- to five times over. Existing rows over a limit are not shortened here: the migration
+-- fails naming them, so they can be edited by hand before it is rerun.
+
+DO $$
+DECLARE
+    offending text;
+BEGIN
+    SELECT string_agg(row_ref, ', ') INTO offending FROM (
+        SELECT 'finance_assets ' || id AS row_ref FROM finance_assets
+        WHERE char_length(name) > 200 OR char_length(category) > 200 OR char_length(notes) > 10000
+        UNION ALL
+        SELECT 'finance_liabilities ' || id FROM finance_liabilities
+        WHERE char_length(name) > 200 OR char_length(category) > 200 OR char_length(notes) > 10000
+        UNION ALL
+        SELECT 'finance_incomes ' || id FROM finance_incomes
+        WHERE char_length(source) > 200 OR char_length(category) > 200 OR char_length(notes) > 10000
+        UNION ALL
+        SELECT 'finance_expenses ' || id FROM finance_expenses
+        WHERE char_length(payee) > 200 OR char_length(category) > 200 OR char_length(notes) > 10000
+        UNION ALL
+        SELECT 'finance_goals ' || id FROM finance_goals
+        WHERE char_length(name) > 200 OR char_length(asset_category) > 200 OR char_length(notes) > 10000
+        UNION ALL
+        SELECT 'finance_accounts ' || id FROM finance_accounts
+        WHERE char_length(name) > 200 OR char_length(institution) > 200
+        UNION ALL
+        SELECT 'finance_transactions ' || id FROM finance_transactions
+        WHERE char_length(category) > 200 OR char_length(memo) > 10000
+    ) AS over_limit;
+
+    IF offending IS NOT NULL THEN
+        RAISE EXCEPTION 'rows exceed the new text length limits (200 for labels, 10000 for notes and memos); shorten them and rerun: %', offending;
+    END IF;
+END $$;
+
+ALTER TABLE finance_assets
+    ADD CONSTRAINT finance_assets_name_length_check CHECK (char_length(name) <= 200),
+    ADD CONSTRAINT finance_assets_category_length_check CHECK (char_length(category) <= 200),
+    ADD CONSTRAINT finance_assets_notes_length_check CHECK (char_length(notes) <= 10000);
+
+ALTER TABLE finance_liabilities
+    ADD CONSTRAINT finance_liabilities_name_length_check CHECK (char_length(name) <= 200),
+    ADD CONSTRAINT finance_liabilities_category_length_check CHECK (char_length(category) <= 200),
+    ADD CONSTRAINT finance_liabilities_notes_length_check CHECK (char_length(notes) <= 10000);
+
+ALTER TABLE finance_incomes
+    ADD CONSTRAINT finance_incomes_source_length_check CHECK (char_length(source) <= 200),
+    ADD CONSTRAINT finance_incomes_category_length_check CHECK (char_length(category) <= 200),
+    ADD CONSTRAINT finance_incomes_notes_length_check CHECK (char_length(notes) <= 10000);
+
+ALTER TABLE finance_expenses
+    ADD CONSTRAINT finance_expenses_payee_length_check CHECK (char_length(payee) <= 200),
+    ADD CONSTRAINT finance_expenses_category_length_check CHECK (char_length(category) <= 200),
+    ADD CONSTRAINT finance_expenses_notes_length_check CHECK (char_length(notes) <= 10000);
+
+ALTER TABLE finance_goals
+    ADD CONSTRAINT finance_goals_name_length_check CHECK (char_length(name) <= 200),
+    ADD CONSTRAINT finance_goals_asset_category_length_check CHECK (char_length(asset_category) <= 200),
+    ADD CONSTRAINT finance_goals_notes_length_check CHECK (char_length(notes) <= 10000);
+
+ALTER TABLE finance_accounts
+    ADD CONSTRAINT finance_accounts_name_length_check CHECK (char_length(name) <= 200),
+    ADD CONSTRAINT finance_accounts_institution_length_check CHECK (char_length(institution) <= 200);
+
+ALTER TABLE finance_transactions
+    ADD CONSTRAINT finance_transactions_category_length_check CHECK (char_length(category) <= 200),
+    ADD CONSTRAINT finance_transactions_memo_length_check CHECK (char_length(memo) <= 10000);
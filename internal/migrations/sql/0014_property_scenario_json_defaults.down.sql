@@ -0,0 +1,8 @@
+ALTER TABLE property_planner_scenarios
+    ALTER COLUMN loan_inputs DROP DEFAULT,
+    ALTER COLUMN amortization DROP DEFAULT,
+    ALTER COLUMN snapshot DROP DEFAULT,
+    ALTER COLUMN summary DROP DEFAULT,
+    ALTER COLUMN timeline DROP DEFAULT,
+    ALTER COLUMN milestones DROP DEFAULT,
+    ALTER COLUMN insights DROP DEFAULT;
@@ -0,0 +1,30 @@
+-- Scenario documents default to an empty object or array, so an insert that omits
+-- them (or a legacy row written before they were required) still decodes. Any NULLs
+-- left behind are backfilled before the columns are pinned NOT NULL.
+
+UPDATE property_planner_scenarios
+SET loan_inputs = COALESCE(loan_inputs, '{}'),
+    amortization = COALESCE(amortization, '{}'),
+    snapshot = COALESCE(snapshot, '{}'),
+    summary = COALESCE(summary, '[]'),
+    timeline = COALESCE(timeline, '[]'),
+    milestones = COALESCE(milestones, '[]'),
+    insights = COALESCE(insights, '[]')
+WHERE loan_inputs IS NULL OR amortization IS NULL OR snapshot IS NULL OR summary IS NULL
+   OR timeline IS NULL OR milestones IS NULL OR insights IS NULL;
+
+ALTER TABLE property_planner_scenarios
+    ALTER COLUMN loan_inputs SET DEFAULT '{}',
+    ALTER COLUMN loan_inputs SET NOT NULL,
+    ALTER COLUMN amortization SET DEFAULT '{}',
+    ALTER COLUMN amortization SET NOT NULL,
+    ALTER COLUMN snapshot SET DEFAULT '{}',
+    ALTER COLUMN snapshot SET NOT NULL,
+    ALTER COLUMN summary SET DEFAULT '[]',
+    ALTER COLUMN summary SET NOT NULL,
+    ALTER COLUMN timeline SET DEFAULT '[]',
+    ALTER COLUMN timeline SET NOT NULL,
+    ALTER COLUMN milestones SET DEFAULT '[]',
+    ALTER COLUMN milestones SET NOT NULL,
+    ALTER COLUMN insights SET DEFAULT '[]',
+    ALTER COLUMN insights SET NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE finance_expenses
+    DROP COLUMN IF EXISTS recurrence_interval;
+
+ALTER TABLE finance_incomes
+    DROP COLUMN IF EXISTS recurrence_interval;
@@ -0,0 +1,5 @@
+ALTER TABLE finance_incomes
+    ADD COLUMN IF NOT EXISTS recurrence_interval text CHECK (recurrence_interval ~ '^P[0-9YMWD]+$');
+
+ALTER TABLE finance_expenses
+    ADD COLUMN IF NOT EXISTS recurrence_interval text CHECK (recurrence_interval ~ '^P[0-9YMWD]+$');
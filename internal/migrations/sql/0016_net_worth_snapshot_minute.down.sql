@@ -0,0 +1 @@
+DROP INDEX IF EXISTS net_worth_snapshots_minute_idx;
@@ -0,0 +1,8 @@
+-- Keep the earliest snapshot of any minute recorded more than once before the index existed.
+DELETE FROM net_worth_snapshots a
+USING net_worth_snapshots b
+WHERE date_trunc('minute', a.recorded_at AT TIME ZONE 'UTC') = date_trunc('minute', b.recorded_at AT TIME ZONE 'UTC')
+  AND (a.recorded_at, a.id) > (b.recorded_at, b.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS net_worth_snapshots_minute_idx
+    ON net_worth_snapshots ((date_trunc('minute', recorded_at AT TIME ZONE 'UTC')));
@@ -0,0 +1,131 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// expectedSchema lists the tables and columns the repository reads and writes.
+// Keep it in step with the migrations under sql/.
+var expectedSchema = map[string][]string{
+	"finance_assets": {
+		"id", "name", "category", "current_value", "annual_growth_rate", "notes", "updated_at", "currency",
+		"account_id", "linked_liability_id",
+	},
+	"finance_liabilities": {
+		"id", "name", "category", "current_balance", "interest_rate_apr", "minimum_payment", "notes", "updated_at", "currency",
+		"account_id",
+	},
+	"finance_incomes": {
+		"id", "source", "amount", "frequency", "start_date", "category", "notes",
+		"day_of_month", "day_of_week", "updated_at", "currency", "recurrence_interval",
+	},
+	"finance_expenses": {
+		"id", "payee", "amount", "frequency", "category", "notes",
+		"day_of_month", "day_of_week", "updated_at", "currency", "recurrence_interval",
+	},
+	"property_planner_scenarios": {
+		"id", "property_type", "headline", "subheadline", "last_refreshed", "loan_inputs", "amortization",
+		"snapshot", "summary", "timeline", "milestones", "insights", "updated_at",
+	},
+	"finance_goals": {
+		"id", "name", "target_amount", "target_date", "asset_category", "notes", "updated_at",
+	},
+	"finance_accounts": {
+		"id", "name", "institution", "updated_at",
+	},
+	"finance_transactions": {
+		"id", "occurred_at", "amount", "currency", "direction", "category", "account_id", "memo", "updated_at",
+	},
+	"net_worth_snapshots": {
+		"id", "total_assets", "total_liabilities", "net_worth", "recorded_at",
+	},
+}
+
+// expectedIndexes lists the indexes the repository's ON CONFLICT clauses infer.
+var expectedIndexes = []string{
+	"net_worth_snapshots_minute_idx",
+}
+
+// Verify checks that every table, column and index the service depends on exists in
+// the current schema. It is meant to run after Run to catch a service pointed at the
+// wrong database.
+func Verify(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()`)
+	if err != nil {
+		return fmt.Errorf("read schema: %w", err)
+	}
+	defer rows.Close()
+
+	actual := make(map[string]map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return fmt.Errorf("read schema: %w", err)
+		}
+		if actual[table] == nil {
+			actual[table] = make(map[string]bool)
+		}
+		actual[table][column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read schema: %w", err)
+	}
+
+	indexes, err := db.Query(`
+		SELECT indexname
+		FROM pg_indexes
+		WHERE schemaname = current_schema()`)
+	if err != nil {
+		return fmt.Errorf("read indexes: %w", err)
+	}
+	defer indexes.Close()
+
+	present := make(map[string]bool)
+	for indexes.Next() {
+		var name string
+		if err := indexes.Scan(&name); err != nil {
+			return fmt.Errorf("read indexes: %w", err)
+		}
+		present[name] = true
+	}
+	if err := indexes.Err(); err != nil {
+		return fmt.Errorf("read indexes: %w", err)
+	}
+
+	missing := missingSchema(expectedSchema, actual)
+	for _, name := range expectedIndexes {
+		if !present[name] {
+			missing = append(missing, "index "+name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("schema verification failed, missing: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// missingSchema reports expected tables and table.column pairs absent from actual,
+// sorted for stable error messages.
+func missingSchema(expected map[string][]string, actual map[string]map[string]bool) []string {
+	var missing []string
+	for table, columns := range expected {
+		present, ok := actual[table]
+		if !ok {
+			missing = append(missing, "table "+table)
+			continue
+		}
+		for _, column := range columns {
+			if !present[column] {
+				missing = append(missing, table+"."+column)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
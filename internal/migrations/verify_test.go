@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingSchemaPassesForCompleteSchema(t *testing.T) {
+	actual := make(map[string]map[string]bool)
+	for table, columns := range expectedSchema {
+		actual[table] = make(map[string]bool)
+		for _, column := range columns {
+			actual[table][column] = true
+		}
+	}
+	actual["unrelated"] = map[string]bool{"id": true}
+
+	if missing := missingSchema(expectedSchema, actual); len(missing) != 0 {
+		t.Fatalf("expected no missing schema, got %v", missing)
+	}
+}
+
+func TestMissingSchemaReportsTablesAndColumns(t *testing.T) {
+	expected := map[string][]string{
+		"finance_assets":      {"id", "currency"},
+		"net_worth_snapshots": {"id"},
+	}
+	actual := map[string]map[string]bool{
+		"finance_assets": {"id": true},
+	}
+
+	got := missingSchema(expected, actual)
+	want := []string{"finance_assets.currency", "table net_worth_snapshots"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "time"
+
+// Clock supplies the timestamps stores stamp on writes (UpdatedAt, RecordedAt and
+// defaulted start dates). Tests install a fixed clock to assert exact values.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock in UTC. It is the default for every repository.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now().UTC() }
+
+// ClockFunc adapts a plain function to Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
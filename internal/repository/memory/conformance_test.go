@@ -0,0 +1,15 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+	"github.com/jcleow/assetra2/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T, clock repository.Clock) repository.Repository {
+		return NewRepository(finance.SeedData{}, WithClock(clock))
+	})
+}
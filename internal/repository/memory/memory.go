@@ -4,7 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
-	"strings"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,23 +12,62 @@ import (
 	"github.com/jcleow/assetra2/internal/repository"
 )
 
+// Option customises optional repository behaviour.
+type Option func(*inMemoryRepository)
+
+// WithClock replaces the wall clock used to stamp UpdatedAt and RecordedAt.
+func WithClock(clock repository.Clock) Option {
+	return func(r *inMemoryRepository) {
+		if clock != nil {
+			r.clock = clock
+		}
+	}
+}
+
 // NewRepository wires an in-memory repository populated with optional seed data.
-func NewRepository(seed finance.SeedData) repository.Repository {
-	return &inMemoryRepository{
-		assets:            newAssetStore(seed.Assets),
-		liabilities:       newLiabilityStore(seed.Liabilities),
+func NewRepository(seed finance.SeedData, opts ...Option) repository.Repository {
+	assets := newAssetStore(seed.Assets)
+	liabilities := newLiabilityStore(seed.Liabilities, assets)
+	transactions := newTransactionStore(seed.Transactions)
+	r := &inMemoryRepository{
+		clock:             repository.SystemClock{},
+		assets:            assets,
+		liabilities:       liabilities,
 		incomes:           newIncomeStore(seed.Incomes),
 		expenses:          newExpenseStore(seed.Expenses),
 		propertyScenarios: newPropertyScenarioStore(seed.PropertyScenarios),
+		goals:             newGoalStore(seed.Goals),
+		accounts:          newAccountStore(seed.Accounts, assets, liabilities, transactions),
+		transactions:      transactions,
+		netWorthSnapshots: &netWorthSnapshotStore{},
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	r.assets.clock = r.clock
+	r.liabilities.clock = r.clock
+	r.incomes.clock = r.clock
+	r.expenses.clock = r.clock
+	r.propertyScenarios.clock = r.clock
+	r.goals.clock = r.clock
+	r.accounts.clock = r.clock
+	r.transactions.clock = r.clock
+	r.netWorthSnapshots.clock = r.clock
+	return r
 }
 
 type inMemoryRepository struct {
+	clock             repository.Clock
 	assets            *assetStore
 	liabilities       *liabilityStore
 	incomes           *incomeStore
 	expenses          *expenseStore
 	propertyScenarios *propertyScenarioStore
+	goals             *goalStore
+	accounts          *accountStore
+	transactions      *transactionStore
+	netWorthSnapshots *netWorthSnapshotStore
 }
 
 func (r *inMemoryRepository) Assets() repository.AssetStore {
@@ -51,423 +90,438 @@ func (r *inMemoryRepository) PropertyPlanner() repository.PropertyPlannerStore {
 	return r.propertyScenarios
 }
 
-// --- asset store ---
-
-type assetStore struct {
-	mu    sync.RWMutex
-	items map[string]finance.Asset
+func (r *inMemoryRepository) Goals() repository.GoalStore {
+	return r.goals
 }
 
-func newAssetStore(seed []finance.Asset) *assetStore {
-	store := &assetStore{
-		items: make(map[string]finance.Asset),
-	}
-	for _, asset := range seed {
-		store.items[asset.ID] = asset
-	}
-	return store
+func (r *inMemoryRepository) Accounts() repository.AccountStore {
+	return r.accounts
 }
 
-func (s *assetStore) List(_ context.Context) ([]finance.Asset, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	out := make([]finance.Asset, 0, len(s.items))
-	for _, asset := range s.items {
-		out = append(out, asset)
-	}
-	return out, nil
+func (r *inMemoryRepository) Transactions() repository.TransactionStore {
+	return r.transactions
 }
 
-func (s *assetStore) Get(_ context.Context, id string) (finance.Asset, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	asset, ok := s.items[id]
-	if !ok {
-		return finance.Asset{}, repository.ErrNotFound
-	}
-	return asset, nil
+func (r *inMemoryRepository) NetWorthSnapshots() repository.NetWorthSnapshotStore {
+	return r.netWorthSnapshots
 }
 
-func (s *assetStore) Create(_ context.Context, asset finance.Asset) (finance.Asset, error) {
-	if asset.Name == "" {
-		return finance.Asset{}, repository.ErrInvalidInput
+func (r *inMemoryRepository) RenameCashFlowCategory(_ context.Context, from, to string) (int, int, error) {
+	if from == "" || to == "" {
+		return 0, 0, repository.ErrInvalidInput
 	}
+	// Hold both locks so readers never see one side renamed without the other.
+	r.incomes.mu.Lock()
+	defer r.incomes.mu.Unlock()
+	r.expenses.mu.Lock()
+	defer r.expenses.mu.Unlock()
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	asset.ID = ensureID(asset.ID)
-	asset.UpdatedAt = time.Now().UTC()
-	s.items[asset.ID] = asset
-	return asset, nil
-}
-
-func (s *assetStore) Update(_ context.Context, asset finance.Asset) (finance.Asset, error) {
-	if asset.ID == "" {
-		return finance.Asset{}, repository.ErrInvalidInput
+	incomes := r.incomes.updateWhereLocked(func(income *finance.Income) bool {
+		if income.Category != from {
+			return false
+		}
+		income.Category = to
+		return true
+	})
+	expenses := r.expenses.updateWhereLocked(func(expense *finance.Expense) bool {
+		if expense.Category != from {
+			return false
+		}
+		expense.Category = to
+		return true
+	})
+	return incomes, expenses, nil
+}
+
+func (r *inMemoryRepository) ImportDataset(_ context.Context, data finance.SeedData, mode repository.ImportMode) error {
+	// Hold every store lock so readers never observe a partially imported dataset.
+	r.assets.mu.Lock()
+	defer r.assets.mu.Unlock()
+	r.liabilities.mu.Lock()
+	defer r.liabilities.mu.Unlock()
+	r.incomes.mu.Lock()
+	defer r.incomes.mu.Unlock()
+	r.expenses.mu.Lock()
+	defer r.expenses.mu.Unlock()
+	r.propertyScenarios.mu.Lock()
+	defer r.propertyScenarios.mu.Unlock()
+	r.goals.mu.Lock()
+	defer r.goals.mu.Unlock()
+	r.accounts.mu.Lock()
+	defer r.accounts.mu.Unlock()
+	r.transactions.mu.Lock()
+	defer r.transactions.mu.Unlock()
+
+	if mode == repository.ImportReplace {
+		r.assets.items = make(map[string]finance.Asset)
+		r.liabilities.items = make(map[string]finance.Liability)
+		r.incomes.items = make(map[string]finance.Income)
+		r.expenses.items = make(map[string]finance.Expense)
+		r.propertyScenarios.items = make(map[string]finance.PropertyPlannerScenario)
+		r.goals.items = make(map[string]finance.Goal)
+		r.accounts.items = make(map[string]finance.Account)
+		r.transactions.items = make(map[string]finance.Transaction)
+	}
+
+	now := r.clock.Now().UTC()
+	for _, asset := range data.Assets {
+		asset.ID = ensureID(asset.ID)
+		if asset.UpdatedAt.IsZero() {
+			asset.UpdatedAt = now
+		}
+		r.assets.items[asset.ID] = asset
 	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, ok := s.items[asset.ID]; !ok {
-		return finance.Asset{}, repository.ErrNotFound
+	for _, liability := range data.Liabilities {
+		liability.ID = ensureID(liability.ID)
+		if liability.UpdatedAt.IsZero() {
+			liability.UpdatedAt = now
+		}
+		r.liabilities.items[liability.ID] = liability
 	}
-	asset.UpdatedAt = time.Now().UTC()
-	s.items[asset.ID] = asset
-	return asset, nil
-}
-
-func (s *assetStore) Delete(_ context.Context, id string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, ok := s.items[id]; !ok {
-		return repository.ErrNotFound
+	for _, income := range data.Incomes {
+		income.ID = ensureID(income.ID)
+		if income.UpdatedAt.IsZero() {
+			income.UpdatedAt = now
+		}
+		r.incomes.items[income.ID] = income
+	}
+	for _, expense := range data.Expenses {
+		expense.ID = ensureID(expense.ID)
+		if expense.UpdatedAt.IsZero() {
+			expense.UpdatedAt = now
+		}
+		r.expenses.items[expense.ID] = expense
+	}
+	for _, scenario := range data.PropertyScenarios {
+		scenario.ID = ensureID(scenario.ID)
+		if scenario.UpdatedAt.IsZero() {
+			scenario.UpdatedAt = now
+		}
+		r.propertyScenarios.items[scenario.ID] = scenario
+	}
+	for _, goal := range data.Goals {
+		goal.ID = ensureID(goal.ID)
+		if goal.UpdatedAt.IsZero() {
+			goal.UpdatedAt = now
+		}
+		r.goals.items[goal.ID] = goal
+	}
+	for _, account := range data.Accounts {
+		account.ID = ensureID(account.ID)
+		if account.UpdatedAt.IsZero() {
+			account.UpdatedAt = now
+		}
+		r.accounts.items[account.ID] = account
+	}
+	for _, txn := range data.Transactions {
+		txn.ID = ensureID(txn.ID)
+		if txn.UpdatedAt.IsZero() {
+			txn.UpdatedAt = now
+		}
+		r.transactions.items[txn.ID] = txn
 	}
-	delete(s.items, id)
 	return nil
 }
 
-// --- liability store ---
+// --- typed stores ---
 
-type liabilityStore struct {
-	mu    sync.RWMutex
-	items map[string]finance.Liability
-}
+type goalStore = Store[finance.Goal, *finance.Goal]
 
-func newLiabilityStore(seed []finance.Liability) *liabilityStore {
-	store := &liabilityStore{
-		items: make(map[string]finance.Liability),
-	}
-	for _, liability := range seed {
-		store.items[liability.ID] = liability
-	}
-	return store
+type assetStore struct {
+	*Store[finance.Asset, *finance.Asset]
 }
 
-func (s *liabilityStore) List(_ context.Context) ([]finance.Liability, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	out := make([]finance.Liability, 0, len(s.items))
-	for _, liability := range s.items {
-		out = append(out, liability)
-	}
-	return out, nil
+func newAssetStore(seed []finance.Asset) *assetStore {
+	return &assetStore{NewStore(seed, func(asset finance.Asset) bool {
+		return asset.Name != "" && asset.CurrentValue >= 0
+	})}
 }
 
-func (s *liabilityStore) Get(_ context.Context, id string) (finance.Liability, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	item, ok := s.items[id]
-	if !ok {
-		return finance.Liability{}, repository.ErrNotFound
-	}
-	return item, nil
+func (s *assetStore) Categories(_ context.Context) ([]string, error) {
+	return s.distinct(func(asset finance.Asset) string { return asset.Category }), nil
 }
 
-func (s *liabilityStore) Create(_ context.Context, liability finance.Liability) (finance.Liability, error) {
-	if liability.Name == "" {
-		return finance.Liability{}, repository.ErrInvalidInput
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	liability.ID = ensureID(liability.ID)
-	liability.UpdatedAt = time.Now().UTC()
-	s.items[liability.ID] = liability
-	return liability, nil
+type liabilityStore struct {
+	*Store[finance.Liability, *finance.Liability]
+	assets *assetStore
 }
 
-func (s *liabilityStore) Update(_ context.Context, liability finance.Liability) (finance.Liability, error) {
-	if liability.ID == "" {
-		return finance.Liability{}, repository.ErrInvalidInput
+func newLiabilityStore(seed []finance.Liability, assets *assetStore) *liabilityStore {
+	return &liabilityStore{
+		Store: NewStore(seed, func(liability finance.Liability) bool {
+			return liability.Name != "" && liability.InterestRateAPR >= 0
+		}),
+		assets: assets,
 	}
+}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, ok := s.items[liability.ID]; !ok {
-		return finance.Liability{}, repository.ErrNotFound
-	}
-	liability.UpdatedAt = time.Now().UTC()
-	s.items[liability.ID] = liability
-	return liability, nil
+func (s *liabilityStore) Categories(_ context.Context) ([]string, error) {
+	return s.distinct(func(liability finance.Liability) string { return liability.Category }), nil
 }
 
+// Delete refuses to remove a liability that an asset still links to, naming the assets.
+// The asset lock is held across the check and the delete, so no asset can link to the
+// liability in between.
 func (s *liabilityStore) Delete(_ context.Context, id string) error {
+	s.assets.mu.RLock()
+	defer s.assets.mu.RUnlock()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.items[id]; !ok {
-		return repository.ErrNotFound
+	if ids := s.assets.matchingIDsLocked(func(asset finance.Asset) bool { return asset.LinkedLiabilityID == id }); len(ids) > 0 {
+		return &repository.ReferencedError{Entity: "asset", IDs: ids}
 	}
-	delete(s.items, id)
-	return nil
+	return s.deleteLocked(id)
 }
 
-// --- income store ---
+func newGoalStore(seed []finance.Goal) *goalStore {
+	return NewStore(seed, func(goal finance.Goal) bool {
+		return goal.Name != "" && goal.TargetAmount > 0
+	})
+}
 
 type incomeStore struct {
-	mu    sync.RWMutex
-	items map[string]finance.Income
+	*Store[finance.Income, *finance.Income]
 }
 
 func newIncomeStore(seed []finance.Income) *incomeStore {
-	store := &incomeStore{
-		items: make(map[string]finance.Income),
-	}
-	for _, income := range seed {
-		store.items[income.ID] = income
-	}
-	return store
+	return &incomeStore{NewStore(seed, func(income finance.Income) bool {
+		return income.Source != "" && income.Amount > 0 && income.Frequency.Valid()
+	})}
 }
 
-func (s *incomeStore) List(_ context.Context) ([]finance.Income, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	out := make([]finance.Income, 0, len(s.items))
-	for _, income := range s.items {
-		out = append(out, income)
-	}
-	return out, nil
+type expenseStore struct {
+	*Store[finance.Expense, *finance.Expense]
 }
 
-func (s *incomeStore) Get(_ context.Context, id string) (finance.Income, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	item, ok := s.items[id]
-	if !ok {
-		return finance.Income{}, repository.ErrNotFound
-	}
-	return item, nil
+func newExpenseStore(seed []finance.Expense) *expenseStore {
+	return &expenseStore{NewStore(seed, func(expense finance.Expense) bool {
+		return expense.Payee != "" && expense.Amount > 0 && expense.Frequency.Valid()
+	})}
 }
 
-func (s *incomeStore) Create(_ context.Context, income finance.Income) (finance.Income, error) {
-	if income.Source == "" || income.Amount <= 0 {
-		return finance.Income{}, repository.ErrInvalidInput
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	income.ID = ensureID(income.ID)
-	income.UpdatedAt = time.Now().UTC()
-	s.items[income.ID] = income
-	return income, nil
+func (s *expenseStore) UpdateMany(_ context.Context, ids []string, change func(*finance.Expense) error) ([]finance.Expense, []string, error) {
+	return s.updateMany(ids, change)
 }
 
-func (s *incomeStore) Update(_ context.Context, income finance.Income) (finance.Income, error) {
-	if income.ID == "" {
-		return finance.Income{}, repository.ErrInvalidInput
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
+type accountStore struct {
+	*Store[finance.Account, *finance.Account]
+	assets       *assetStore
+	liabilities  *liabilityStore
+	transactions *transactionStore
+}
 
-	if _, ok := s.items[income.ID]; !ok {
-		return finance.Income{}, repository.ErrNotFound
+func newAccountStore(seed []finance.Account, assets *assetStore, liabilities *liabilityStore, transactions *transactionStore) *accountStore {
+	return &accountStore{
+		Store: NewStore(seed, func(account finance.Account) bool {
+			return account.Name != ""
+		}),
+		assets:       assets,
+		liabilities:  liabilities,
+		transactions: transactions,
 	}
-	income.UpdatedAt = time.Now().UTC()
-	s.items[income.ID] = income
-	return income, nil
 }
 
-func (s *incomeStore) Delete(_ context.Context, id string) error {
+// Delete refuses to remove an account that still has holdings linked to it. The locks
+// of the referring stores are held across the check and the delete, taken in the same
+// order as ImportDataset.
+func (s *accountStore) Delete(_ context.Context, id string) error {
+	s.assets.mu.RLock()
+	defer s.assets.mu.RUnlock()
+	s.liabilities.mu.RLock()
+	defer s.liabilities.mu.RUnlock()
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.transactions.mu.RLock()
+	defer s.transactions.mu.RUnlock()
 
-	if _, ok := s.items[id]; !ok {
-		return repository.ErrNotFound
+	if _, ok := s.assets.findLocked(func(asset finance.Asset) bool { return asset.AccountID == id }); ok {
+		return repository.ErrConflict
 	}
-	delete(s.items, id)
-	return nil
+	if _, ok := s.liabilities.findLocked(func(liability finance.Liability) bool { return liability.AccountID == id }); ok {
+		return repository.ErrConflict
+	}
+	if _, ok := s.transactions.findLocked(func(txn finance.Transaction) bool { return txn.AccountID == id }); ok {
+		return repository.ErrConflict
+	}
+	return s.deleteLocked(id)
 }
 
-// --- expense store ---
+type transactionStore struct {
+	*Store[finance.Transaction, *finance.Transaction]
+}
 
-type expenseStore struct {
-	mu    sync.RWMutex
-	items map[string]finance.Expense
+func newTransactionStore(seed []finance.Transaction) *transactionStore {
+	return &transactionStore{NewStore(seed, func(txn finance.Transaction) bool {
+		return !txn.Date.IsZero() && txn.Amount > 0 && txn.Category != "" &&
+			(txn.Direction == finance.TransactionInflow || txn.Direction == finance.TransactionOutflow)
+	})}
 }
 
-func newExpenseStore(seed []finance.Expense) *expenseStore {
-	store := &expenseStore{
-		items: make(map[string]finance.Expense),
+func (s *transactionStore) List(ctx context.Context, filter repository.TransactionFilter) ([]finance.Transaction, error) {
+	all, err := s.Store.List(ctx)
+	if err != nil {
+		return nil, err
 	}
-	for _, expense := range seed {
-		store.items[expense.ID] = expense
+	out := make([]finance.Transaction, 0, len(all))
+	for _, txn := range all {
+		if filter.Matches(txn) {
+			out = append(out, txn)
+		}
 	}
-	return store
-}
-
-func (s *expenseStore) List(_ context.Context) ([]finance.Expense, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	out := make([]finance.Expense, 0, len(s.items))
-	for _, expense := range s.items {
-		out = append(out, expense)
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Date.Equal(out[j].Date) {
+			return out[i].Date.After(out[j].Date)
+		}
+		return out[i].ID > out[j].ID
+	})
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
 	}
 	return out, nil
 }
 
-func (s *expenseStore) Get(_ context.Context, id string) (finance.Expense, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// ListPage counts the bounded ledger directly before applying the cursor and limit.
+func (s *transactionStore) ListPage(ctx context.Context, filter repository.TransactionFilter) (repository.TransactionPage, error) {
+	bounds := filter
+	bounds.After, bounds.Limit = nil, 0
+	all, err := s.List(ctx, bounds)
+	if err != nil {
+		return repository.TransactionPage{}, err
+	}
 
-	item, ok := s.items[id]
-	if !ok {
-		return finance.Expense{}, repository.ErrNotFound
+	page := repository.TransactionPage{Items: []finance.Transaction{}, Total: len(all)}
+	for _, txn := range all {
+		if filter.After != nil && !repository.TransactionBefore(txn, *filter.After) {
+			continue
+		}
+		if filter.Limit > 0 && len(page.Items) == filter.Limit {
+			break
+		}
+		page.Items = append(page.Items, txn)
 	}
-	return item, nil
+	return page, nil
 }
 
-func (s *expenseStore) Create(_ context.Context, expense finance.Expense) (finance.Expense, error) {
-	if expense.Payee == "" || expense.Amount <= 0 {
-		return finance.Expense{}, repository.ErrInvalidInput
-	}
+type propertyScenarioStore struct {
+	*Store[finance.PropertyPlannerScenario, *finance.PropertyPlannerScenario]
+}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func newPropertyScenarioStore(seed []finance.PropertyPlannerScenario) *propertyScenarioStore {
+	return &propertyScenarioStore{NewStore(seed, func(scenario finance.PropertyPlannerScenario) bool {
+		return scenario.Type != "" && scenario.Headline != "" && scenario.Inputs.Validate() == nil
+	})}
+}
 
-	expense.ID = ensureID(expense.ID)
-	expense.UpdatedAt = time.Now().UTC()
-	s.items[expense.ID] = expense
-	return expense, nil
+func (s *propertyScenarioStore) UpdateMany(_ context.Context, ids []string, change func(*finance.PropertyPlannerScenario) error) ([]finance.PropertyPlannerScenario, []string, error) {
+	return s.updateMany(ids, change)
 }
 
-func (s *expenseStore) Update(_ context.Context, expense finance.Expense) (finance.Expense, error) {
-	if expense.ID == "" {
-		return finance.Expense{}, repository.ErrInvalidInput
+// GetByType matches types exactly, like UpsertByType.
+func (s *propertyScenarioStore) GetByType(_ context.Context, scenarioType string) (finance.PropertyPlannerScenario, error) {
+	scenario, ok := s.find(func(scenario finance.PropertyPlannerScenario) bool {
+		return scenario.Type == scenarioType
+	})
+	if !ok {
+		return finance.PropertyPlannerScenario{}, repository.ErrNotFound
 	}
+	return scenario, nil
+}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, ok := s.items[expense.ID]; !ok {
-		return finance.Expense{}, repository.ErrNotFound
+// UpsertByType matches types exactly, like the unique index in postgres.
+func (s *propertyScenarioStore) UpsertByType(_ context.Context, scenario finance.PropertyPlannerScenario) (finance.PropertyPlannerScenario, bool, error) {
+	if !s.valid(scenario) {
+		return finance.PropertyPlannerScenario{}, false, repository.ErrInvalidInput
 	}
-	expense.UpdatedAt = time.Now().UTC()
-	s.items[expense.ID] = expense
-	return expense, nil
-}
 
-func (s *expenseStore) Delete(_ context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.items[id]; !ok {
-		return repository.ErrNotFound
+	for id, existing := range s.items {
+		if existing.Type == scenario.Type {
+			scenario.ID = id
+			return s.putLocked(scenario, s.now()), false, nil
+		}
 	}
-	delete(s.items, id)
-	return nil
+	if _, ok := s.items[scenario.ID]; ok && scenario.ID != "" {
+		return finance.PropertyPlannerScenario{}, false, repository.ErrDuplicateID
+	}
+	return s.putLocked(scenario, s.now()), true, nil
 }
 
-// --- property planner store ---
+// --- net worth snapshot store ---
 
-type propertyScenarioStore struct {
+type netWorthSnapshotStore struct {
 	mu    sync.RWMutex
-	items map[string]finance.PropertyPlannerScenario
+	items []finance.NetWorthSnapshot
+	clock repository.Clock
 }
 
-func newPropertyScenarioStore(seed []finance.PropertyPlannerScenario) *propertyScenarioStore {
-	store := &propertyScenarioStore{
-		items: make(map[string]finance.PropertyPlannerScenario),
-	}
-	for _, scenario := range seed {
-		if scenario.ID == "" {
-			scenario.ID = ensureID("")
-		}
-		store.items[scenario.ID] = scenario
-	}
-	return store
-}
-
-func (s *propertyScenarioStore) List(_ context.Context) ([]finance.PropertyPlannerScenario, error) {
+func (s *netWorthSnapshotStore) List(_ context.Context) ([]finance.NetWorthSnapshot, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	out := make([]finance.PropertyPlannerScenario, 0, len(s.items))
-	for _, scenario := range s.items {
-		out = append(out, scenario)
-	}
+	out := make([]finance.NetWorthSnapshot, len(s.items))
+	copy(out, s.items)
 	return out, nil
 }
 
-func (s *propertyScenarioStore) Get(_ context.Context, id string) (finance.PropertyPlannerScenario, error) {
+func (s *netWorthSnapshotStore) Latest(_ context.Context) (finance.NetWorthSnapshot, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	scenario, ok := s.items[id]
-	if !ok {
-		return finance.PropertyPlannerScenario{}, repository.ErrNotFound
+	if len(s.items) == 0 {
+		return finance.NetWorthSnapshot{}, repository.ErrNotFound
 	}
-	return scenario, nil
+	return s.items[len(s.items)-1], nil
 }
 
-func (s *propertyScenarioStore) GetByType(_ context.Context, scenarioType string) (finance.PropertyPlannerScenario, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	for _, scenario := range s.items {
-		if strings.EqualFold(scenario.Type, scenarioType) {
-			return scenario, nil
-		}
-	}
-	return finance.PropertyPlannerScenario{}, repository.ErrNotFound
-}
-
-func (s *propertyScenarioStore) Create(_ context.Context, scenario finance.PropertyPlannerScenario) (finance.PropertyPlannerScenario, error) {
-	if scenario.Type == "" || scenario.Headline == "" {
-		return finance.PropertyPlannerScenario{}, repository.ErrInvalidInput
-	}
-
+func (s *netWorthSnapshotStore) Create(_ context.Context, snapshot finance.NetWorthSnapshot) (finance.NetWorthSnapshot, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	scenario.ID = ensureID(scenario.ID)
-	if scenario.UpdatedAt.IsZero() {
-		scenario.UpdatedAt = time.Now().UTC()
+	for _, existing := range s.items {
+		if snapshot.ID != "" && existing.ID == snapshot.ID {
+			return finance.NetWorthSnapshot{}, repository.ErrDuplicateID
+		}
 	}
-	s.items[scenario.ID] = scenario
-	return scenario, nil
+	return s.insertLocked(snapshot), nil
 }
 
-func (s *propertyScenarioStore) Update(_ context.Context, scenario finance.PropertyPlannerScenario) (finance.PropertyPlannerScenario, error) {
-	if scenario.ID == "" {
-		return finance.PropertyPlannerScenario{}, repository.ErrInvalidInput
-	}
-
+func (s *netWorthSnapshotStore) CreateOncePerMinute(_ context.Context, snapshot finance.NetWorthSnapshot) (finance.NetWorthSnapshot, bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.items[scenario.ID]; !ok {
-		return finance.PropertyPlannerScenario{}, repository.ErrNotFound
+	if snapshot.RecordedAt.IsZero() {
+		snapshot.RecordedAt = s.clock.Now().UTC()
 	}
-	if scenario.UpdatedAt.IsZero() {
-		scenario.UpdatedAt = time.Now().UTC()
+	minute := snapshot.RecordedAt.UTC().Truncate(time.Minute)
+	for _, existing := range s.items {
+		if snapshot.ID != "" && existing.ID == snapshot.ID {
+			return finance.NetWorthSnapshot{}, false, repository.ErrDuplicateID
+		}
+		if existing.RecordedAt.UTC().Truncate(time.Minute).Equal(minute) {
+			return existing, false, nil
+		}
 	}
-	s.items[scenario.ID] = scenario
-	return scenario, nil
+	return s.insertLocked(snapshot), true, nil
 }
 
-func (s *propertyScenarioStore) Delete(_ context.Context, id string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, ok := s.items[id]; !ok {
-		return repository.ErrNotFound
+// insertLocked stores snapshot in order; the caller holds s.mu.
+func (s *netWorthSnapshotStore) insertLocked(snapshot finance.NetWorthSnapshot) finance.NetWorthSnapshot {
+	snapshot.ID = ensureID(snapshot.ID)
+	if snapshot.RecordedAt.IsZero() {
+		snapshot.RecordedAt = s.clock.Now().UTC()
 	}
-	delete(s.items, id)
-	return nil
+
+	// Keep snapshots ordered oldest first so history reads chronologically.
+	idx := sort.Search(len(s.items), func(i int) bool {
+		return s.items[i].RecordedAt.After(snapshot.RecordedAt)
+	})
+	s.items = append(s.items, finance.NetWorthSnapshot{})
+	copy(s.items[idx+1:], s.items[idx:])
+	s.items[idx] = snapshot
+	return snapshot
 }
 
 func ensureID(id string) string {
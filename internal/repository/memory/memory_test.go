@@ -2,6 +2,9 @@ package memory
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -90,6 +93,29 @@ func TestIncomeValidations(t *testing.T) {
 	}
 }
 
+func TestCashFlowStoresRejectUnknownFrequency(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(finance.SeedData{
+		Incomes:  []finance.Income{{ID: "i1", Source: "Salary", Amount: 100, Frequency: finance.FrequencyMonthly}},
+		Expenses: []finance.Expense{{ID: "e1", Payee: "Rent", Amount: 100, Frequency: finance.FrequencyMonthly}},
+	})
+
+	for _, freq := range []finance.Frequency{"", "fortnightly"} {
+		if _, err := repo.Incomes().Create(ctx, finance.Income{Source: "Bonus", Amount: 100, Frequency: freq}); err != repository.ErrInvalidInput {
+			t.Fatalf("expected invalid input creating income with %q, got %v", freq, err)
+		}
+		if _, err := repo.Incomes().Update(ctx, finance.Income{ID: "i1", Source: "Salary", Amount: 100, Frequency: freq}); err != repository.ErrInvalidInput {
+			t.Fatalf("expected invalid input updating income with %q, got %v", freq, err)
+		}
+		if _, err := repo.Expenses().Create(ctx, finance.Expense{Payee: "Gym", Amount: 100, Frequency: freq}); err != repository.ErrInvalidInput {
+			t.Fatalf("expected invalid input creating expense with %q, got %v", freq, err)
+		}
+		if _, err := repo.Expenses().Update(ctx, finance.Expense{ID: "e1", Payee: "Rent", Amount: 100, Frequency: freq}); err != repository.ErrInvalidInput {
+			t.Fatalf("expected invalid input updating expense with %q, got %v", freq, err)
+		}
+	}
+}
+
 func TestExpenseValidations(t *testing.T) {
 	ctx := context.Background()
 	repo := NewRepository(finance.SeedData{})
@@ -103,6 +129,41 @@ func TestExpenseValidations(t *testing.T) {
 	}
 }
 
+func TestExpenseUpdateManyIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(finance.SeedData{Expenses: []finance.Expense{
+		{ID: "e1", Payee: "Rent", Amount: 2000, Frequency: finance.FrequencyMonthly, Category: "housing"},
+		{ID: "e2", Payee: "Power", Amount: 120, Frequency: finance.FrequencyMonthly, Category: "utilities"},
+	}})
+	store := repo.Expenses()
+
+	updated, missing, err := store.UpdateMany(ctx, []string{"e1", "nope", "e2"}, func(expense *finance.Expense) error {
+		expense.Category = "household"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update many: %v", err)
+	}
+	if len(updated) != 2 || len(missing) != 1 || missing[0] != "nope" {
+		t.Fatalf("expected 2 updated and nope missing, got %d updated, missing %v", len(updated), missing)
+	}
+
+	_, _, err = store.UpdateMany(ctx, []string{"e1", "e2"}, func(expense *finance.Expense) error {
+		if expense.ID == "e2" {
+			expense.Amount = 0
+		}
+		expense.Category = "bills"
+		return nil
+	})
+	if err != repository.ErrInvalidInput {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+	got, _ := store.Get(ctx, "e1")
+	if got.Category != "household" {
+		t.Fatalf("expected failed batch to leave e1 untouched, got %q", got.Category)
+	}
+}
+
 func TestLiabilityUpdateRequiresExistingRecord(t *testing.T) {
 	ctx := context.Background()
 	repo := NewRepository(finance.SeedData{})
@@ -127,3 +188,193 @@ func TestLiabilityUpdateRequiresExistingRecord(t *testing.T) {
 		t.Fatalf("update existing: %v", err)
 	}
 }
+
+func TestAccountDeleteBlockedWhileReferenced(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(finance.SeedData{
+		Accounts: []finance.Account{{ID: "acct-1", Name: "Brokerage"}},
+		Assets: []finance.Asset{
+			{ID: "asset-1", Name: "Index fund", Category: "investments", AccountID: "acct-1"},
+		},
+	})
+
+	if err := repo.Accounts().Delete(ctx, "acct-1"); err != repository.ErrConflict {
+		t.Fatalf("expected ErrConflict deleting referenced account, got %v", err)
+	}
+
+	if err := repo.Assets().Delete(ctx, "asset-1"); err != nil {
+		t.Fatalf("delete asset: %v", err)
+	}
+	if err := repo.Accounts().Delete(ctx, "acct-1"); err != nil {
+		t.Fatalf("expected delete to succeed once unreferenced, got %v", err)
+	}
+}
+
+func TestLiabilityDeleteBlockedWhileLinked(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(finance.SeedData{
+		Liabilities: []finance.Liability{{ID: "mortgage", Name: "Mortgage", Category: "mortgage"}},
+		Assets: []finance.Asset{
+			{ID: "house", Name: "House", Category: "property", LinkedLiabilityID: "mortgage"},
+		},
+	})
+
+	err := repo.Liabilities().Delete(ctx, "mortgage")
+	var referenced *repository.ReferencedError
+	if !errors.Is(err, repository.ErrConflict) || !errors.As(err, &referenced) || referenced.IDs[0] != "house" {
+		t.Fatalf("expected a conflict naming the house deleting linked liability, got %v", err)
+	}
+
+	house, _ := repo.Assets().Get(ctx, "house")
+	house.LinkedLiabilityID = ""
+	if _, err := repo.Assets().Update(ctx, house); err != nil {
+		t.Fatalf("unlink asset: %v", err)
+	}
+	if err := repo.Liabilities().Delete(ctx, "mortgage"); err != nil {
+		t.Fatalf("expected delete to succeed once unlinked, got %v", err)
+	}
+}
+
+func TestTransactionListFiltersAndOrdersNewestFirst(t *testing.T) {
+	ctx := context.Background()
+	march := func(day int) time.Time { return time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC) }
+	repo := NewRepository(finance.SeedData{
+		Transactions: []finance.Transaction{
+			{ID: "t1", Date: march(1), Amount: 10, Direction: finance.TransactionOutflow, Category: "food"},
+			{ID: "t2", Date: march(10), Amount: 20, Direction: finance.TransactionOutflow, Category: "food"},
+			{ID: "t3", Date: march(5), Amount: 30, Direction: finance.TransactionOutflow, Category: "travel"},
+			{ID: "t4", Date: march(20), Amount: 40, Direction: finance.TransactionOutflow, Category: "food"},
+		},
+	})
+
+	items, err := repo.Transactions().List(ctx, repository.TransactionFilter{From: march(1), To: march(20), Category: "food"})
+	if err != nil {
+		t.Fatalf("list transactions: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != "t2" || items[1].ID != "t1" {
+		t.Fatalf("expected t2 then t1, got %+v", items)
+	}
+}
+
+func TestListOrdersByUpdatedAtThenID(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewRepository(finance.SeedData{
+		Assets: []finance.Asset{
+			{ID: "a", Name: "Oldest", Category: "cash", UpdatedAt: base},
+			{ID: "b", Name: "Tied", Category: "cash", UpdatedAt: base.Add(time.Hour)},
+			{ID: "c", Name: "Tied", Category: "cash", UpdatedAt: base.Add(time.Hour)},
+			{ID: "d", Name: "Newest", Category: "cash", UpdatedAt: base.Add(2 * time.Hour)},
+		},
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		assets, err := repo.Assets().List(ctx)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		var ids []string
+		for _, asset := range assets {
+			ids = append(ids, asset.ID)
+		}
+		if got := strings.Join(ids, ","); got != "d,c,b,a" {
+			t.Fatalf("expected d,c,b,a, got %s", got)
+		}
+	}
+
+	created, err := repo.Incomes().Create(ctx, finance.Income{Source: "Bonus", Amount: 100, Frequency: finance.FrequencyMonthly, StartDate: base})
+	if err != nil {
+		t.Fatalf("create income: %v", err)
+	}
+	if _, err := repo.Incomes().Create(ctx, finance.Income{Source: "Salary", Amount: 100, Frequency: finance.FrequencyMonthly, StartDate: base}); err != nil {
+		t.Fatalf("create income: %v", err)
+	}
+	if _, err := repo.Incomes().Update(ctx, created); err != nil {
+		t.Fatalf("update income: %v", err)
+	}
+	incomes, err := repo.Incomes().List(ctx)
+	if err != nil {
+		t.Fatalf("list incomes: %v", err)
+	}
+	if len(incomes) != 2 || incomes[0].ID != created.ID {
+		t.Fatalf("expected the most recently updated income first, got %+v", incomes)
+	}
+}
+
+func TestFixedClockStampsWrites(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	repo := NewRepository(finance.SeedData{}, WithClock(repository.ClockFunc(func() time.Time { return now })))
+
+	created, err := repo.Assets().Create(ctx, finance.Asset{Name: "Cash", Category: "cash", CurrentValue: 100})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !created.UpdatedAt.Equal(now) {
+		t.Fatalf("expected updatedAt %v, got %v", now, created.UpdatedAt)
+	}
+
+	now = now.Add(time.Hour)
+	created.CurrentValue = 150
+	updated, err := repo.Assets().Update(ctx, created)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !updated.UpdatedAt.Equal(now) {
+		t.Fatalf("expected updatedAt %v, got %v", now, updated.UpdatedAt)
+	}
+
+	snapshot, err := repo.NetWorthSnapshots().Create(ctx, finance.NetWorthSnapshot{NetWorth: 150})
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if !snapshot.RecordedAt.Equal(now) {
+		t.Fatalf("expected recordedAt %v, got %v", now, snapshot.RecordedAt)
+	}
+}
+
+func TestReferenceCheckedDeletesHoldTheAssetLock(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(finance.SeedData{
+		Liabilities: []finance.Liability{{ID: "mortgage", Name: "Mortgage", Category: "mortgage"}},
+		Accounts:    []finance.Account{{ID: "acct-1", Name: "Brokerage"}},
+	}).(*inMemoryRepository)
+
+	for _, tc := range []struct {
+		name   string
+		lock   *sync.RWMutex
+		delete func() error
+		asset  finance.Asset
+	}{
+		{"liability", &repo.liabilities.mu, func() error { return repo.Liabilities().Delete(ctx, "mortgage") },
+			finance.Asset{Name: "House", Category: "property", LinkedLiabilityID: "mortgage"}},
+		{"account", &repo.accounts.mu, func() error { return repo.Accounts().Delete(ctx, "acct-1") },
+			finance.Asset{Name: "Index fund", Category: "investments", AccountID: "acct-1"}},
+	} {
+		// Stall the delete after it has checked for referring assets.
+		tc.lock.Lock()
+		deleted := make(chan error, 1)
+		go func() { deleted <- tc.delete() }()
+		time.Sleep(20 * time.Millisecond)
+
+		linked := make(chan error, 1)
+		go func() {
+			_, err := repo.Assets().Create(ctx, tc.asset)
+			linked <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+		select {
+		case <-linked:
+			t.Fatalf("%s: an asset was linked while the delete was still in progress", tc.name)
+		default:
+		}
+
+		tc.lock.Unlock()
+		if err := <-deleted; err != nil {
+			t.Fatalf("%s: delete: %v", tc.name, err)
+		}
+		if err := <-linked; err != nil {
+			t.Fatalf("%s: create asset: %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+// Entity is implemented by pointers to the finance types kept in a Store.
+type Entity[T any] interface {
+	*T
+	GetID() string
+	SetID(id string)
+	Touch(at time.Time)
+	LastUpdated() time.Time
+}
+
+// Store is a concurrency-safe in-memory collection keyed by entity ID. It provides
+// the CRUD methods shared by every typed store interface in the repository package.
+type Store[T any, P Entity[T]] struct {
+	mu    sync.RWMutex
+	items map[string]T
+	// valid reports whether an entity may be created or updated; nil accepts everything.
+	valid func(T) bool
+	clock repository.Clock
+}
+
+// NewStore builds a Store seeded with items, assigning IDs where missing.
+func NewStore[T any, P Entity[T]](seed []T, valid func(T) bool) *Store[T, P] {
+	s := &Store[T, P]{
+		items: make(map[string]T),
+		valid: valid,
+		clock: repository.SystemClock{},
+	}
+	for _, item := range seed {
+		s.putLocked(item, time.Time{})
+	}
+	return s
+}
+
+func (s *Store[T, P]) List(_ context.Context) ([]T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]T, 0, len(s.items))
+	for _, item := range s.items {
+		out = append(out, item)
+	}
+	// Match the postgres ORDER BY updated_at DESC, id DESC so both backends list alike.
+	sort.Slice(out, func(i, j int) bool {
+		a, b := P(&out[i]), P(&out[j])
+		if !a.LastUpdated().Equal(b.LastUpdated()) {
+			return a.LastUpdated().After(b.LastUpdated())
+		}
+		return a.GetID() > b.GetID()
+	})
+	return out, nil
+}
+
+func (s *Store[T, P]) Get(_ context.Context, id string) (T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		var zero T
+		return zero, repository.ErrNotFound
+	}
+	return item, nil
+}
+
+// GetMany returns the items with the given ids keyed by id. Unknown ids are skipped.
+func (s *Store[T, P]) GetMany(_ context.Context, ids []string) (map[string]T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found := make(map[string]T, len(ids))
+	for _, id := range ids {
+		if item, ok := s.items[id]; ok {
+			found[id] = item
+		}
+	}
+	return found, nil
+}
+
+func (s *Store[T, P]) Create(_ context.Context, item T) (T, error) {
+	if s.valid != nil && !s.valid(item) {
+		var zero T
+		return zero, repository.ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id := P(&item).GetID(); id != "" {
+		if _, ok := s.items[id]; ok {
+			var zero T
+			return zero, repository.ErrDuplicateID
+		}
+	}
+	return s.putLocked(item, s.now()), nil
+}
+
+func (s *Store[T, P]) Update(_ context.Context, item T) (T, error) {
+	var zero T
+	id := P(&item).GetID()
+	if id == "" || (s.valid != nil && !s.valid(item)) {
+		return zero, repository.ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[id]; !ok {
+		return zero, repository.ErrNotFound
+	}
+	return s.putLocked(item, s.now()), nil
+}
+
+func (s *Store[T, P]) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deleteLocked(id)
+}
+
+// deleteLocked removes the item with id. The caller holds s.mu.
+func (s *Store[T, P]) deleteLocked(id string) error {
+	if _, ok := s.items[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(s.items, id)
+	return nil
+}
+
+// Meta counts the items and reports the newest LastUpdated.
+func (s *Store[T, P]) Meta(_ context.Context) (repository.CollectionMeta, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	meta := repository.CollectionMeta{Count: len(s.items)}
+	for _, item := range s.items {
+		updated := P(&item).LastUpdated()
+		if meta.LastUpdatedAt == nil || updated.After(*meta.LastUpdatedAt) {
+			meta.LastUpdatedAt = &updated
+		}
+	}
+	return meta, nil
+}
+
+// find returns the first item matching fn.
+func (s *Store[T, P]) find(fn func(T) bool) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.findLocked(fn)
+}
+
+// findLocked is find for a caller that holds s.mu.
+func (s *Store[T, P]) findLocked(fn func(T) bool) (T, bool) {
+	for _, item := range s.items {
+		if fn(item) {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// distinct returns the sorted, distinct non-empty values of field across the items.
+func (s *Store[T, P]) distinct(field func(T) string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	values := []string{}
+	for _, item := range s.items {
+		if v := field(item); v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// matchingIDs returns the sorted IDs of every item matching fn.
+func (s *Store[T, P]) matchingIDs(fn func(T) bool) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.matchingIDsLocked(fn)
+}
+
+// matchingIDsLocked is matchingIDs for a caller that holds s.mu.
+func (s *Store[T, P]) matchingIDsLocked(fn func(T) bool) []string {
+	var ids []string
+	for id, item := range s.items {
+		if fn(item) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// updateWhereLocked applies fn to every item, touching and counting those it reports
+// changed. The caller holds s.mu.
+func (s *Store[T, P]) updateWhereLocked(fn func(P) bool) int {
+	now := s.now()
+	count := 0
+	for id, item := range s.items {
+		if !fn(&item) {
+			continue
+		}
+		P(&item).Touch(now)
+		s.items[id] = item
+		count++
+	}
+	return count
+}
+
+// updateMany applies change to copies of the items in ids and stores them only if every
+// change succeeds and passes validation. Unknown IDs are skipped and reported in missing.
+func (s *Store[T, P]) updateMany(ids []string, change func(P) error) ([]T, []string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var updated []T
+	var missing []string
+	for _, id := range ids {
+		item, ok := s.items[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		if err := change(&item); err != nil {
+			return nil, nil, err
+		}
+		P(&item).SetID(id)
+		if s.valid != nil && !s.valid(item) {
+			return nil, nil, repository.ErrInvalidInput
+		}
+		updated = append(updated, item)
+	}
+
+	now := s.now()
+	for i := range updated {
+		updated[i] = s.putLocked(updated[i], now)
+	}
+	return updated, missing, nil
+}
+
+func (s *Store[T, P]) now() time.Time {
+	return s.clock.Now().UTC()
+}
+
+// putLocked stores item, assigning an ID if needed. A non-zero at stamps the item;
+// a zero at keeps whatever timestamp it already carries. Callers must hold s.mu.
+func (s *Store[T, P]) putLocked(item T, at time.Time) T {
+	p := P(&item)
+	p.SetID(ensureID(p.GetID()))
+	if !at.IsZero() {
+		p.Touch(at)
+	}
+	s.items[p.GetID()] = item
+	return item
+}
@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+type note struct {
+	ID        string
+	Text      string
+	UpdatedAt time.Time
+}
+
+func (n *note) GetID() string          { return n.ID }
+func (n *note) SetID(id string)        { n.ID = id }
+func (n *note) Touch(at time.Time)     { n.UpdatedAt = at }
+func (n *note) LastUpdated() time.Time { return n.UpdatedAt }
+
+func TestStoreCRUDForNewEntityType(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore[note](nil, func(n note) bool { return n.Text != "" })
+
+	if _, err := store.Create(ctx, note{}); err != repository.ErrInvalidInput {
+		t.Fatalf("expected invalid input for empty note, got %v", err)
+	}
+
+	created, err := store.Create(ctx, note{Text: "rebalance portfolio"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID == "" || created.UpdatedAt.IsZero() {
+		t.Fatalf("expected id and timestamp to be assigned, got %+v", created)
+	}
+
+	created.Text = "rebalance in Q3"
+	updated, err := store.Update(ctx, created)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := store.Get(ctx, created.ID)
+	if err != nil || got.Text != updated.Text {
+		t.Fatalf("expected stored note to be updated, got %+v (%v)", got, err)
+	}
+
+	if _, err := store.Update(ctx, note{ID: "missing", Text: "x"}); err != repository.ErrNotFound {
+		t.Fatalf("expected ErrNotFound updating missing note, got %v", err)
+	}
+	if _, err := store.Update(ctx, note{Text: "x"}); err != repository.ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput updating without id, got %v", err)
+	}
+
+	if err := store.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, created.ID); err != repository.ErrNotFound {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestStoreSeedKeepsTimestampsAndAssignsIDs(t *testing.T) {
+	ctx := context.Background()
+	seededAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore[note]([]note{
+		{ID: "note-1", Text: "seeded", UpdatedAt: seededAt},
+		{Text: "no id"},
+	}, nil)
+
+	items, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 seeded notes, got %d", len(items))
+	}
+	for _, item := range items {
+		if item.ID == "" {
+			t.Fatal("expected seeded note without id to be assigned one")
+		}
+	}
+
+	seeded, _ := store.Get(ctx, "note-1")
+	if !seeded.UpdatedAt.Equal(seededAt) {
+		t.Fatalf("expected seed timestamp to be kept, got %s", seeded.UpdatedAt)
+	}
+}
+
+func TestStoreUpdateWhereLockedTouchesMatches(t *testing.T) {
+	store := NewStore[note]([]note{
+		{ID: "a", Text: "draft"},
+		{ID: "b", Text: "draft"},
+		{ID: "c", Text: "final"},
+	}, nil)
+
+	store.mu.Lock()
+	count := store.updateWhereLocked(func(n *note) bool {
+		if n.Text != "draft" {
+			return false
+		}
+		n.Text = "review"
+		return true
+	})
+	store.mu.Unlock()
+	if count != 2 {
+		t.Fatalf("expected 2 notes changed, got %d", count)
+	}
+
+	final, _ := store.Get(context.Background(), "c")
+	if final.Text != "final" || !final.UpdatedAt.IsZero() {
+		t.Fatalf("expected unmatched note untouched, got %+v", final)
+	}
+	review, _ := store.Get(context.Background(), "a")
+	if review.Text != "review" || review.UpdatedAt.IsZero() {
+		t.Fatalf("expected matched note updated and touched, got %+v", review)
+	}
+}
+
+func TestStoreCreateRejectsDuplicateID(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore[note]([]note{{ID: "n1", Text: "original"}}, nil)
+
+	if _, err := store.Create(ctx, note{ID: "n1", Text: "replacement"}); !errors.Is(err, repository.ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID, got %v", err)
+	}
+	got, err := store.Get(ctx, "n1")
+	if err != nil || got.Text != "original" {
+		t.Fatalf("expected the existing note to be kept, got %+v (%v)", got, err)
+	}
+}
+
+func TestStoreMetaReportsCountAndNewestUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore[note](nil, nil)
+
+	meta, err := store.Meta(ctx)
+	if err != nil {
+		t.Fatalf("meta: %v", err)
+	}
+	if meta.Count != 0 || meta.LastUpdatedAt != nil {
+		t.Fatalf("expected empty meta, got %+v", meta)
+	}
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(48 * time.Hour)
+	store = NewStore[note]([]note{
+		{ID: "a", Text: "old", UpdatedAt: older},
+		{ID: "b", Text: "new", UpdatedAt: newer},
+		{ID: "c", Text: "mid", UpdatedAt: older.Add(time.Hour)},
+	}, nil)
+
+	meta, err = store.Meta(ctx)
+	if err != nil {
+		t.Fatalf("meta: %v", err)
+	}
+	if meta.Count != 3 {
+		t.Fatalf("expected count 3, got %d", meta.Count)
+	}
+	if meta.LastUpdatedAt == nil || !meta.LastUpdatedAt.Equal(newer) {
+		t.Fatalf("expected lastUpdatedAt %s, got %v", newer, meta.LastUpdatedAt)
+	}
+}
+
+func TestStoreGetManyReturnsPartialMatches(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore[note]([]note{{ID: "a", Text: "one"}, {ID: "b", Text: "two"}, {ID: "c", Text: "three"}}, nil)
+
+	found, err := store.GetMany(ctx, []string{"c", "missing", "a"})
+	if err != nil {
+		t.Fatalf("get many: %v", err)
+	}
+	if len(found) != 2 || found["a"].Text != "one" || found["c"].Text != "three" {
+		t.Fatalf("expected a and c only, got %+v", found)
+	}
+
+	reversed, _ := store.GetMany(ctx, []string{"a", "missing", "c"})
+	if len(reversed) != len(found) || reversed["a"] != found["a"] || reversed["c"] != found["c"] {
+		t.Fatalf("expected the same result regardless of id order, got %+v", reversed)
+	}
+
+	if empty, err := store.GetMany(ctx, nil); err != nil || empty == nil || len(empty) != 0 {
+		t.Fatalf("expected an empty map for no ids, got %v (%v)", empty, err)
+	}
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Scenario JSON columns stay jsonb, so a compressed document is stored as a one-key
+// object {"$gzip": "<base64 gzip of the document>"}. Rows written before compression
+// was enabled, and documents too small to benefit, hold the plain document; readers
+// tell the two apart by the key.
+const compressedKey = "$gzip"
+
+// minCompressBytes skips documents where gzip and base64 overhead would outweigh
+// the saving.
+const minCompressBytes = 1024
+
+// compressJSON wraps doc in the compressed envelope when that makes it smaller.
+func compressJSON(doc []byte) ([]byte, error) {
+	if len(doc) < minCompressBytes {
+		return doc, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(doc); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	envelope, err := json.Marshal(map[string]string{compressedKey: base64.StdEncoding.EncodeToString(buf.Bytes())})
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope) >= len(doc) {
+		return doc, nil
+	}
+	return envelope, nil
+}
+
+// decompressJSON returns the document held in value, unwrapping the compressed
+// envelope if present. Plain documents are returned unchanged.
+func decompressJSON(value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(value), []byte(`{"`+compressedKey+`"`)) {
+		return value, nil
+	}
+	var envelope map[string]string
+	if err := json.Unmarshal(value, &envelope); err != nil || len(envelope) != 1 {
+		return value, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(envelope[compressedKey])
+	if err != nil {
+		return nil, fmt.Errorf("decode compressed scenario: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("decompress scenario: %w", err)
+	}
+	defer zr.Close()
+	doc, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("decompress scenario: %w", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,46 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/migrations"
+	"github.com/jcleow/assetra2/internal/repository"
+	"github.com/jcleow/assetra2/internal/repository/repotest"
+)
+
+// TestConformance runs the shared repository suite against a real database. Run it
+// with -tags integration and TEST_DATABASE_URL pointing at a disposable database;
+// every subtest wipes the finance tables and net-worth history.
+func TestConformance(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := migrations.Run(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T, clock repository.Clock) repository.Repository {
+		repo := New(db, WithClock(clock))
+		if err := repo.ImportDataset(context.Background(), finance.SeedData{}, repository.ImportReplace); err != nil {
+			t.Fatalf("reset finance tables: %v", err)
+		}
+		if _, err := db.Exec("DELETE FROM net_worth_snapshots"); err != nil {
+			t.Fatalf("reset net-worth history: %v", err)
+		}
+		return repo
+	})
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeDB is a database/sql driver that records statements instead of running them.
+// Queries return no rows and execs report one affected row, which is enough to
+// exercise routing and instrumentation without a live Postgres. Tables listed in
+// populated answer EXISTS probes with true; every other table reads as empty, and
+// plain COUNT(*) queries report zero.
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []string
+	// args holds the arguments of each query, in order.
+	args      [][]any
+	delay     time.Duration
+	populated map[string]bool
+	// queryErr, when set, fails matching queries with the returned error.
+	queryErr func(query string) error
+	// column, when set, answers matching queries with one row per returned value.
+	column func(query string) []driver.Value
+	// commits and rollbacks count finished transactions.
+	commits, rollbacks int
+}
+
+func newFakeDB() (*fakeDB, *sql.DB) {
+	f := &fakeDB{}
+	return f, sql.OpenDB(f)
+}
+
+func (f *fakeDB) record(query string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = append(f.statements, query)
+}
+
+func (f *fakeDB) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.statements)
+}
+
+// wait simulates a slow statement, returning early if ctx is cancelled.
+func (f *fakeDB) wait(ctx context.Context) error {
+	if f.delay <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(f.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fakeDriver: use sql.OpenDB")
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakeConn: prepare not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+
+// CheckNamedValue accepts any argument, as the pgx driver does for slices.
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                { return fakeTx{db: c.db}, nil }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query)
+	args := make([]any, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+	c.db.mu.Lock()
+	c.db.args = append(c.db.args, args)
+	c.db.mu.Unlock()
+	if err := c.db.wait(ctx); err != nil {
+		return nil, err
+	}
+	if c.db.queryErr != nil {
+		if err := c.db.queryErr(query); err != nil {
+			return nil, err
+		}
+	}
+	if table, ok := existsProbe(query); ok {
+		c.db.mu.Lock()
+		populated := c.db.populated[table]
+		c.db.mu.Unlock()
+		return &fakeValueRows{column: "exists", value: populated}, nil
+	}
+	if c.db.column != nil {
+		if values := c.db.column(query); values != nil {
+			return &fakeColumnRows{values: values}, nil
+		}
+	}
+	if strings.HasPrefix(query, "SELECT COUNT(*) FROM ") {
+		return &fakeValueRows{column: "count", value: int64(0)}, nil
+	}
+	return fakeRows{}, nil
+}
+
+// existsProbe extracts the table from a "SELECT EXISTS (SELECT 1 FROM t)" query.
+func existsProbe(query string) (string, bool) {
+	rest, ok := strings.CutPrefix(query, "SELECT EXISTS (SELECT 1 FROM ")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSuffix(rest, ")"), true
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query)
+	if err := c.db.wait(ctx); err != nil {
+		return nil, err
+	}
+	if c.db.queryErr != nil {
+		if err := c.db.queryErr(query); err != nil {
+			return nil, err
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return nil }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+// fakeValueRows yields a single row holding one value.
+type fakeValueRows struct {
+	column string
+	value  driver.Value
+	done   bool
+}
+
+func (r *fakeValueRows) Columns() []string { return []string{r.column} }
+func (r *fakeValueRows) Close() error      { return nil }
+func (r *fakeValueRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+// fakeColumnRows yields one single-column row per value.
+type fakeColumnRows struct {
+	values []driver.Value
+}
+
+func (r *fakeColumnRows) Columns() []string { return []string{"value"} }
+func (r *fakeColumnRows) Close() error      { return nil }
+func (r *fakeColumnRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
@@ -7,32 +7,256 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
+
 	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/logging"
 	"github.com/jcleow/assetra2/internal/repository"
 )
 
 // Repository implements the finance Repository interface backed by Postgres.
 type Repository struct {
 	db            *sql.DB
+	clock         repository.Clock
+	opts          *options
 	assetStore    *assetStore
 	liabStore     *liabilityStore
 	incomeStore   *incomeStore
 	expenseStore  *expenseStore
 	propertyStore *propertyScenarioStore
+	goalStore     *goalStore
+	accountStore  *accountStore
+	txnStore      *transactionStore
+	netWorthStore *netWorthSnapshotStore
+}
+
+// Option customises optional repository behaviour.
+type Option func(*options)
+
+type options struct {
+	statementTimeout   time.Duration
+	logger             *slog.Logger
+	slowQueryThreshold time.Duration
+	clock              repository.Clock
+	compressScenarios  bool
+}
+
+// WithStatementTimeout bounds each store call so a pathological query cannot hold a
+// connection indefinitely. Zero leaves calls bounded only by the caller's context.
+func WithStatementTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout >= 0 {
+			o.statementTimeout = timeout
+		}
+	}
+}
+
+// WithLogger sets the logger used for slow-query warnings.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithSlowQueryThreshold logs a warning for store calls that take longer than
+// threshold. Zero disables slow-query logging.
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(o *options) {
+		if threshold >= 0 {
+			o.slowQueryThreshold = threshold
+		}
+	}
+}
+
+// WithClock replaces the wall clock used to stamp UpdatedAt and RecordedAt.
+func WithClock(clock repository.Clock) Option {
+	return func(o *options) {
+		if clock != nil {
+			o.clock = clock
+		}
+	}
+}
+
+// WithScenarioCompression gzips large property scenario JSON documents before storing
+// them. Reads handle compressed and plain rows alike, so it can be switched either way
+// without rewriting existing data.
+func WithScenarioCompression(enabled bool) Option {
+	return func(o *options) {
+		o.compressScenarios = enabled
+	}
 }
 
 // New creates a repository backed by the provided database connection.
-func New(db *sql.DB) *Repository {
+func New(db *sql.DB, opts ...Option) *Repository {
+	return NewWithReplica(db, db, opts...)
+}
+
+// NewWithReplica creates a repository that sends List/Get reads to replica and
+// every write, transaction, and seed check to primary. Reads may lag behind writes
+// by the replica's replication delay.
+func NewWithReplica(primary, replica *sql.DB, opts ...Option) *Repository {
+	cfg := &options{clock: repository.SystemClock{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	base := storeBase{db: primary, reader: replica, opts: cfg}
+
 	return &Repository{
-		db:            db,
-		assetStore:    &assetStore{db: db},
-		liabStore:     &liabilityStore{db: db},
-		incomeStore:   &incomeStore{db: db},
-		expenseStore:  &expenseStore{db: db},
-		propertyStore: &propertyScenarioStore{db: db},
+		db:            primary,
+		clock:         cfg.clock,
+		opts:          cfg,
+		assetStore:    &assetStore{base},
+		liabStore:     &liabilityStore{base},
+		incomeStore:   &incomeStore{base},
+		expenseStore:  &expenseStore{base},
+		propertyStore: &propertyScenarioStore{base},
+		goalStore:     &goalStore{base},
+		accountStore:  &accountStore{base},
+		txnStore:      &transactionStore{base},
+		netWorthStore: &netWorthSnapshotStore{base},
+	}
+}
+
+// Ping checks that the primary, and the replica when one is configured, accept
+// connections. It backs the readiness probe.
+func (r *Repository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("primary: %w", err)
+	}
+	if reader := r.assetStore.reader; reader != r.db {
+		if err := reader.PingContext(ctx); err != nil {
+			return fmt.Errorf("replica: %w", err)
+		}
+	}
+	return nil
+}
+
+// storeBase carries the connections and settings shared by every store.
+type storeBase struct {
+	db     *sql.DB
+	reader *sql.DB
+	opts   *options
+}
+
+// begin derives the per-call context for a store method. The returned func must be
+// deferred: it releases the timeout and logs the call if it hit its deadline or ran
+// slower than the threshold. Logs carry the request ID from ctx, when there is one,
+// and the call's time is added to ctx's repository.QueryTimer.
+func (b storeBase) begin(ctx context.Context, op string) (context.Context, func()) {
+	cancel := context.CancelFunc(func() {})
+	if b.opts.statementTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.opts.statementTimeout)
+	}
+
+	start := time.Now()
+	stopTimer := repository.TimeQuery(ctx)
+	return ctx, func() {
+		stopTimer()
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+		if b.opts.logger == nil {
+			return
+		}
+		elapsed := time.Since(start)
+		attrs := []any{"op", op, "elapsed", elapsed}
+		if requestID := logging.RequestID(ctx); requestID != "" {
+			attrs = append(attrs, "request_id", requestID)
+		}
+		switch {
+		case timedOut:
+			b.opts.logger.Error("query deadline exceeded", append(attrs, "timeout", b.opts.statementTimeout)...)
+		case b.opts.slowQueryThreshold > 0 && elapsed > b.opts.slowQueryThreshold:
+			b.opts.logger.Warn("slow query", append(attrs, "threshold", b.opts.slowQueryThreshold)...)
+		}
+	}
+}
+
+// now reads the configured clock.
+func (b storeBase) now() time.Time {
+	return b.opts.clock.Now().UTC()
+}
+
+// meta reports the row count and newest updated_at of table from the read connection.
+func (b storeBase) meta(ctx context.Context, table string) (repository.CollectionMeta, error) {
+	var meta repository.CollectionMeta
+	var last sql.NullTime
+	if err := b.reader.QueryRowContext(ctx, `SELECT COUNT(*), MAX(updated_at) FROM `+table).Scan(&meta.Count, &last); err != nil {
+		return repository.CollectionMeta{}, err
+	}
+	if last.Valid {
+		updated := last.Time.UTC()
+		meta.LastUpdatedAt = &updated
+	}
+	return meta, nil
+}
+
+// categories lists the distinct non-empty categories of table from the read
+// connection. COLLATE "C" sorts by byte, matching the memory store.
+func (b storeBase) categories(ctx context.Context, table string) ([]string, error) {
+	rows, err := b.reader.QueryContext(ctx, `SELECT DISTINCT category FROM `+table+` WHERE category <> '' ORDER BY category COLLATE "C"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []string{}
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// getMany runs query with the usable ids as $1 and indexes the scanned rows by id.
+// Unknown ids are simply absent from the result. Ids that cannot be UUIDs are dropped
+// before querying, since one malformed element would otherwise fail the uuid[] cast
+// for the whole batch.
+func getMany[T any](ctx context.Context, db *sql.DB, query string, ids []string, scan func(scanner) (T, error), id func(T) string) (map[string]T, error) {
+	wanted := make([]string, 0, len(ids))
+	for _, candidate := range ids {
+		if looksLikeUUID(candidate) {
+			wanted = append(wanted, candidate)
+		}
+	}
+	found := make(map[string]T, len(wanted))
+	if len(wanted) == 0 {
+		return found, nil
+	}
+
+	rows, err := db.QueryContext(ctx, query, wanted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		found[id(item)] = item
+	}
+	return found, rows.Err()
+}
+
+// looksLikeUUID reports whether postgres would accept id as a uuid: 32 hex digits,
+// optionally hyphenated or wrapped in braces.
+func looksLikeUUID(id string) bool {
+	id = strings.TrimSuffix(strings.TrimPrefix(id, "{"), "}")
+	id = strings.ReplaceAll(id, "-", "")
+	if len(id) != 32 {
+		return false
 	}
+	_, err := hex.DecodeString(id)
+	return err == nil
 }
 
 func (r *Repository) Assets() repository.AssetStore { return r.assetStore }
@@ -44,16 +268,29 @@ func (r *Repository) Expenses() repository.ExpenseStore { return r.expenseStore
 func (r *Repository) PropertyPlanner() repository.PropertyPlannerStore {
 	return r.propertyStore
 }
+func (r *Repository) Goals() repository.GoalStore { return r.goalStore }
+func (r *Repository) Accounts() repository.AccountStore {
+	return r.accountStore
+}
+func (r *Repository) Transactions() repository.TransactionStore {
+	return r.txnStore
+}
+func (r *Repository) NetWorthSnapshots() repository.NetWorthSnapshotStore {
+	return r.netWorthStore
+}
 
 type assetStore struct {
-	db *sql.DB
+	storeBase
 }
 
 func (s *assetStore) List(ctx context.Context) ([]finance.Asset, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, category, current_value, annual_growth_rate, notes, updated_at
+	ctx, done := s.begin(ctx, "assets.list")
+	defer done()
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, name, category, current_value, annual_growth_rate, notes, updated_at, currency, account_id, linked_liability_id
 		FROM finance_assets
-		ORDER BY updated_at DESC`)
+		ORDER BY updated_at DESC, id DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -74,8 +311,11 @@ func (s *assetStore) List(ctx context.Context) ([]finance.Asset, error) {
 }
 
 func (s *assetStore) Get(ctx context.Context, id string) (finance.Asset, error) {
-	row := s.db.QueryRowContext(ctx, `
-		SELECT id, name, category, current_value, annual_growth_rate, notes, updated_at
+	ctx, done := s.begin(ctx, "assets.get")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
+		SELECT id, name, category, current_value, annual_growth_rate, notes, updated_at, currency, account_id, linked_liability_id
 		FROM finance_assets
 		WHERE id = $1`, id)
 	asset, err := scanAsset(row)
@@ -85,26 +325,42 @@ func (s *assetStore) Get(ctx context.Context, id string) (finance.Asset, error)
 	return asset, err
 }
 
+func (s *assetStore) GetMany(ctx context.Context, ids []string) (map[string]finance.Asset, error) {
+	ctx, done := s.begin(ctx, "assets.get_many")
+	defer done()
+
+	return getMany(ctx, s.reader, `
+		SELECT id, name, category, current_value, annual_growth_rate, notes, updated_at, currency, account_id, linked_liability_id
+		FROM finance_assets
+		WHERE id = ANY($1::uuid[])`, ids, scanAsset, func(item finance.Asset) string { return item.ID })
+}
+
 func (s *assetStore) Create(ctx context.Context, asset finance.Asset) (finance.Asset, error) {
-	if asset.Name == "" || asset.Category == "" {
+	ctx, done := s.begin(ctx, "assets.create")
+	defer done()
+
+	if asset.Name == "" || asset.Category == "" || asset.CurrentValue < 0 {
 		return finance.Asset{}, repository.ErrInvalidInput
 	}
 	asset.ID = ensureID(asset.ID)
-	asset.UpdatedAt = time.Now().UTC()
+	asset.UpdatedAt = s.now()
 
 	row := s.db.QueryRowContext(ctx, `
-		INSERT INTO finance_assets (id, name, category, current_value, annual_growth_rate, notes, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
-		RETURNING id, name, category, current_value, annual_growth_rate, COALESCE(notes, ''), updated_at`,
-		asset.ID, asset.Name, asset.Category, asset.CurrentValue, asset.AnnualGrowthRate, asset.Notes, asset.UpdatedAt)
-	return scanAsset(row)
+		INSERT INTO finance_assets (id, name, category, current_value, annual_growth_rate, notes, updated_at, currency, account_id, linked_liability_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, '')::uuid, NULLIF($10, '')::uuid)
+		RETURNING id, name, category, current_value, annual_growth_rate, COALESCE(notes, ''), updated_at, currency, account_id, linked_liability_id`,
+		asset.ID, asset.Name, asset.Category, asset.CurrentValue, asset.AnnualGrowthRate, asset.Notes, asset.UpdatedAt, asset.Currency, asset.AccountID, asset.LinkedLiabilityID)
+	return writeResult(scanAsset(row))
 }
 
 func (s *assetStore) Update(ctx context.Context, asset finance.Asset) (finance.Asset, error) {
-	if asset.ID == "" {
+	ctx, done := s.begin(ctx, "assets.update")
+	defer done()
+
+	if asset.ID == "" || asset.CurrentValue < 0 {
 		return finance.Asset{}, repository.ErrInvalidInput
 	}
-	asset.UpdatedAt = time.Now().UTC()
+	asset.UpdatedAt = s.now()
 
 	row := s.db.QueryRowContext(ctx, `
 		UPDATE finance_assets
@@ -113,18 +369,24 @@ func (s *assetStore) Update(ctx context.Context, asset finance.Asset) (finance.A
 		    current_value=$4,
 		    annual_growth_rate=$5,
 		    notes=NULLIF($6, ''),
-		    updated_at=$7
+		    updated_at=$7,
+		    currency=NULLIF($8, ''),
+		    account_id=NULLIF($9, '')::uuid,
+		    linked_liability_id=NULLIF($10, '')::uuid
 		WHERE id=$1
-		RETURNING id, name, category, current_value, annual_growth_rate, COALESCE(notes, ''), updated_at`,
-		asset.ID, asset.Name, asset.Category, asset.CurrentValue, asset.AnnualGrowthRate, asset.Notes, asset.UpdatedAt)
+		RETURNING id, name, category, current_value, annual_growth_rate, COALESCE(notes, ''), updated_at, currency, account_id, linked_liability_id`,
+		asset.ID, asset.Name, asset.Category, asset.CurrentValue, asset.AnnualGrowthRate, asset.Notes, asset.UpdatedAt, asset.Currency, asset.AccountID, asset.LinkedLiabilityID)
 	updated, err := scanAsset(row)
 	if errors.Is(err, sql.ErrNoRows) {
 		return finance.Asset{}, repository.ErrNotFound
 	}
-	return updated, err
+	return writeResult(updated, err)
 }
 
 func (s *assetStore) Delete(ctx context.Context, id string) error {
+	ctx, done := s.begin(ctx, "assets.delete")
+	defer done()
+
 	result, err := s.db.ExecContext(ctx, `DELETE FROM finance_assets WHERE id=$1`, id)
 	if err != nil {
 		return err
@@ -136,15 +398,32 @@ func (s *assetStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (s *assetStore) Meta(ctx context.Context) (repository.CollectionMeta, error) {
+	ctx, done := s.begin(ctx, "assets.meta")
+	defer done()
+
+	return s.meta(ctx, "finance_assets")
+}
+
+func (s *assetStore) Categories(ctx context.Context) ([]string, error) {
+	ctx, done := s.begin(ctx, "assets.categories")
+	defer done()
+
+	return s.categories(ctx, "finance_assets")
+}
+
 type liabilityStore struct {
-	db *sql.DB
+	storeBase
 }
 
 func (s *liabilityStore) List(ctx context.Context) ([]finance.Liability, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at
+	ctx, done := s.begin(ctx, "liabilities.list")
+	defer done()
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at, currency, account_id
 		FROM finance_liabilities
-		ORDER BY updated_at DESC`)
+		ORDER BY updated_at DESC, id DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -165,8 +444,11 @@ func (s *liabilityStore) List(ctx context.Context) ([]finance.Liability, error)
 }
 
 func (s *liabilityStore) Get(ctx context.Context, id string) (finance.Liability, error) {
-	row := s.db.QueryRowContext(ctx, `
-		SELECT id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at
+	ctx, done := s.begin(ctx, "liabilities.get")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
+		SELECT id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at, currency, account_id
 		FROM finance_liabilities
 		WHERE id = $1`, id)
 	item, err := scanLiability(row)
@@ -176,26 +458,42 @@ func (s *liabilityStore) Get(ctx context.Context, id string) (finance.Liability,
 	return item, err
 }
 
+func (s *liabilityStore) GetMany(ctx context.Context, ids []string) (map[string]finance.Liability, error) {
+	ctx, done := s.begin(ctx, "liabilities.get_many")
+	defer done()
+
+	return getMany(ctx, s.reader, `
+		SELECT id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at, currency, account_id
+		FROM finance_liabilities
+		WHERE id = ANY($1::uuid[])`, ids, scanLiability, func(item finance.Liability) string { return item.ID })
+}
+
 func (s *liabilityStore) Create(ctx context.Context, liability finance.Liability) (finance.Liability, error) {
+	ctx, done := s.begin(ctx, "liabilities.create")
+	defer done()
+
 	if liability.Name == "" || liability.Category == "" {
 		return finance.Liability{}, repository.ErrInvalidInput
 	}
 	liability.ID = ensureID(liability.ID)
-	liability.UpdatedAt = time.Now().UTC()
+	liability.UpdatedAt = s.now()
 
 	row := s.db.QueryRowContext(ctx, `
-		INSERT INTO finance_liabilities (id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
-		RETURNING id, name, category, current_balance, interest_rate_apr, minimum_payment, COALESCE(notes, ''), updated_at`,
-		liability.ID, liability.Name, liability.Category, liability.CurrentBalance, liability.InterestRateAPR, liability.MinimumPayment, liability.Notes, liability.UpdatedAt)
-	return scanLiability(row)
+		INSERT INTO finance_liabilities (id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at, currency, account_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, NULLIF($9, ''), NULLIF($10, '')::uuid)
+		RETURNING id, name, category, current_balance, interest_rate_apr, minimum_payment, COALESCE(notes, ''), updated_at, currency, account_id`,
+		liability.ID, liability.Name, liability.Category, liability.CurrentBalance, liability.InterestRateAPR, liability.MinimumPayment, liability.Notes, liability.UpdatedAt, liability.Currency, liability.AccountID)
+	return writeResult(scanLiability(row))
 }
 
 func (s *liabilityStore) Update(ctx context.Context, liability finance.Liability) (finance.Liability, error) {
+	ctx, done := s.begin(ctx, "liabilities.update")
+	defer done()
+
 	if liability.ID == "" {
 		return finance.Liability{}, repository.ErrInvalidInput
 	}
-	liability.UpdatedAt = time.Now().UTC()
+	liability.UpdatedAt = s.now()
 
 	row := s.db.QueryRowContext(ctx, `
 		UPDATE finance_liabilities
@@ -205,19 +503,30 @@ func (s *liabilityStore) Update(ctx context.Context, liability finance.Liability
 		    interest_rate_apr=$5,
 		    minimum_payment=$6,
 		    notes=NULLIF($7, ''),
-		    updated_at=$8
+		    updated_at=$8,
+		    currency=NULLIF($9, ''),
+		    account_id=NULLIF($10, '')::uuid
 		WHERE id=$1
-		RETURNING id, name, category, current_balance, interest_rate_apr, minimum_payment, COALESCE(notes, ''), updated_at`,
-		liability.ID, liability.Name, liability.Category, liability.CurrentBalance, liability.InterestRateAPR, liability.MinimumPayment, liability.Notes, liability.UpdatedAt)
+		RETURNING id, name, category, current_balance, interest_rate_apr, minimum_payment, COALESCE(notes, ''), updated_at, currency, account_id`,
+		liability.ID, liability.Name, liability.Category, liability.CurrentBalance, liability.InterestRateAPR, liability.MinimumPayment, liability.Notes, liability.UpdatedAt, liability.Currency, liability.AccountID)
 	updated, err := scanLiability(row)
 	if errors.Is(err, sql.ErrNoRows) {
 		return finance.Liability{}, repository.ErrNotFound
 	}
-	return updated, err
+	return writeResult(updated, err)
 }
 
+// Delete reports a *repository.ReferencedError naming the assets while any asset still
+// links to the liability. The foreign key rejects the delete; the blocking assets are
+// looked up only then, so the common path stays a single statement.
 func (s *liabilityStore) Delete(ctx context.Context, id string) error {
+	ctx, done := s.begin(ctx, "liabilities.delete")
+	defer done()
+
 	result, err := s.db.ExecContext(ctx, `DELETE FROM finance_liabilities WHERE id=$1`, id)
+	if isForeignKeyViolation(err) {
+		return s.linkedAssets(ctx, id)
+	}
 	if err != nil {
 		return err
 	}
@@ -228,15 +537,55 @@ func (s *liabilityStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// linkedAssets builds the conflict for a liability that assets still link to. If the
+// lookup fails the plain ErrConflict is still reported.
+func (s *liabilityStore) linkedAssets(ctx context.Context, id string) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM finance_assets WHERE linked_liability_id=$1 ORDER BY id`, id)
+	if err != nil {
+		return repository.ErrConflict
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var assetID string
+		if err := rows.Scan(&assetID); err != nil {
+			return repository.ErrConflict
+		}
+		ids = append(ids, assetID)
+	}
+	if rows.Err() != nil || len(ids) == 0 {
+		return repository.ErrConflict
+	}
+	return &repository.ReferencedError{Entity: "asset", IDs: ids}
+}
+
+func (s *liabilityStore) Meta(ctx context.Context) (repository.CollectionMeta, error) {
+	ctx, done := s.begin(ctx, "liabilities.meta")
+	defer done()
+
+	return s.meta(ctx, "finance_liabilities")
+}
+
+func (s *liabilityStore) Categories(ctx context.Context) ([]string, error) {
+	ctx, done := s.begin(ctx, "liabilities.categories")
+	defer done()
+
+	return s.categories(ctx, "finance_liabilities")
+}
+
 type incomeStore struct {
-	db *sql.DB
+	storeBase
 }
 
 func (s *incomeStore) List(ctx context.Context) ([]finance.Income, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, source, amount, frequency, start_date, category, notes, updated_at
+	ctx, done := s.begin(ctx, "incomes.list")
+	defer done()
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, source, amount, frequency, start_date, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval
 		FROM finance_incomes
-		ORDER BY updated_at DESC`)
+		ORDER BY updated_at DESC, id DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -257,8 +606,11 @@ func (s *incomeStore) List(ctx context.Context) ([]finance.Income, error) {
 }
 
 func (s *incomeStore) Get(ctx context.Context, id string) (finance.Income, error) {
-	row := s.db.QueryRowContext(ctx, `
-		SELECT id, source, amount, frequency, start_date, category, notes, updated_at
+	ctx, done := s.begin(ctx, "incomes.get")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
+		SELECT id, source, amount, frequency, start_date, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval
 		FROM finance_incomes
 		WHERE id = $1`, id)
 	item, err := scanIncome(row)
@@ -268,29 +620,45 @@ func (s *incomeStore) Get(ctx context.Context, id string) (finance.Income, error
 	return item, err
 }
 
+func (s *incomeStore) GetMany(ctx context.Context, ids []string) (map[string]finance.Income, error) {
+	ctx, done := s.begin(ctx, "incomes.get_many")
+	defer done()
+
+	return getMany(ctx, s.reader, `
+		SELECT id, source, amount, frequency, start_date, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval
+		FROM finance_incomes
+		WHERE id = ANY($1::uuid[])`, ids, scanIncome, func(item finance.Income) string { return item.ID })
+}
+
 func (s *incomeStore) Create(ctx context.Context, income finance.Income) (finance.Income, error) {
-	if income.Source == "" || income.Amount <= 0 {
+	ctx, done := s.begin(ctx, "incomes.create")
+	defer done()
+
+	if income.Source == "" || income.Amount <= 0 || !income.Frequency.Valid() {
 		return finance.Income{}, repository.ErrInvalidInput
 	}
 	income.ID = ensureID(income.ID)
 	if income.StartDate.IsZero() {
-		income.StartDate = time.Now().UTC()
+		income.StartDate = s.now()
 	}
-	income.UpdatedAt = time.Now().UTC()
+	income.UpdatedAt = s.now()
 
 	row := s.db.QueryRowContext(ctx, `
-		INSERT INTO finance_incomes (id, source, amount, frequency, start_date, category, notes, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
-		RETURNING id, source, amount, frequency, start_date, category, COALESCE(notes, ''), updated_at`,
-		income.ID, income.Source, income.Amount, income.Frequency, income.StartDate, income.Category, income.Notes, income.UpdatedAt)
-	return scanIncome(row)
+		INSERT INTO finance_incomes (id, source, amount, frequency, start_date, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, NULLIF($11, ''), NULLIF($12, ''))
+		RETURNING id, source, amount, frequency, start_date, category, COALESCE(notes, ''), day_of_month, day_of_week, updated_at, currency, recurrence_interval`,
+		income.ID, income.Source, income.Amount, income.Frequency, income.StartDate, income.Category, income.Notes, income.DayOfMonth, income.DayOfWeek, income.UpdatedAt, income.Currency, income.Interval)
+	return writeResult(scanIncome(row))
 }
 
 func (s *incomeStore) Update(ctx context.Context, income finance.Income) (finance.Income, error) {
-	if income.ID == "" {
+	ctx, done := s.begin(ctx, "incomes.update")
+	defer done()
+
+	if income.ID == "" || !income.Frequency.Valid() {
 		return finance.Income{}, repository.ErrInvalidInput
 	}
-	income.UpdatedAt = time.Now().UTC()
+	income.UpdatedAt = s.now()
 
 	row := s.db.QueryRowContext(ctx, `
 		UPDATE finance_incomes
@@ -300,18 +668,25 @@ func (s *incomeStore) Update(ctx context.Context, income finance.Income) (financ
 		    start_date=$5,
 		    category=$6,
 		    notes=NULLIF($7, ''),
-		    updated_at=$8
+		    day_of_month=$8,
+		    day_of_week=$9,
+		    updated_at=$10,
+		    currency=NULLIF($11, ''),
+		    recurrence_interval=NULLIF($12, '')
 		WHERE id=$1
-		RETURNING id, source, amount, frequency, start_date, category, COALESCE(notes, ''), updated_at`,
-		income.ID, income.Source, income.Amount, income.Frequency, income.StartDate, income.Category, income.Notes, income.UpdatedAt)
+		RETURNING id, source, amount, frequency, start_date, category, COALESCE(notes, ''), day_of_month, day_of_week, updated_at, currency, recurrence_interval`,
+		income.ID, income.Source, income.Amount, income.Frequency, income.StartDate, income.Category, income.Notes, income.DayOfMonth, income.DayOfWeek, income.UpdatedAt, income.Currency, income.Interval)
 	updated, err := scanIncome(row)
 	if errors.Is(err, sql.ErrNoRows) {
 		return finance.Income{}, repository.ErrNotFound
 	}
-	return updated, err
+	return writeResult(updated, err)
 }
 
 func (s *incomeStore) Delete(ctx context.Context, id string) error {
+	ctx, done := s.begin(ctx, "incomes.delete")
+	defer done()
+
 	result, err := s.db.ExecContext(ctx, `DELETE FROM finance_incomes WHERE id=$1`, id)
 	if err != nil {
 		return err
@@ -323,15 +698,25 @@ func (s *incomeStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (s *incomeStore) Meta(ctx context.Context) (repository.CollectionMeta, error) {
+	ctx, done := s.begin(ctx, "incomes.meta")
+	defer done()
+
+	return s.meta(ctx, "finance_incomes")
+}
+
 type expenseStore struct {
-	db *sql.DB
+	storeBase
 }
 
 func (s *expenseStore) List(ctx context.Context) ([]finance.Expense, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, payee, amount, frequency, category, notes, updated_at
+	ctx, done := s.begin(ctx, "expenses.list")
+	defer done()
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, payee, amount, frequency, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval
 		FROM finance_expenses
-		ORDER BY updated_at DESC`)
+		ORDER BY updated_at DESC, id DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -352,8 +737,11 @@ func (s *expenseStore) List(ctx context.Context) ([]finance.Expense, error) {
 }
 
 func (s *expenseStore) Get(ctx context.Context, id string) (finance.Expense, error) {
-	row := s.db.QueryRowContext(ctx, `
-		SELECT id, payee, amount, frequency, category, notes, updated_at
+	ctx, done := s.begin(ctx, "expenses.get")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
+		SELECT id, payee, amount, frequency, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval
 		FROM finance_expenses
 		WHERE id = $1`, id)
 	item, err := scanExpense(row)
@@ -363,46 +751,56 @@ func (s *expenseStore) Get(ctx context.Context, id string) (finance.Expense, err
 	return item, err
 }
 
+func (s *expenseStore) GetMany(ctx context.Context, ids []string) (map[string]finance.Expense, error) {
+	ctx, done := s.begin(ctx, "expenses.get_many")
+	defer done()
+
+	return getMany(ctx, s.reader, `
+		SELECT id, payee, amount, frequency, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval
+		FROM finance_expenses
+		WHERE id = ANY($1::uuid[])`, ids, scanExpense, func(item finance.Expense) string { return item.ID })
+}
+
 func (s *expenseStore) Create(ctx context.Context, expense finance.Expense) (finance.Expense, error) {
-	if expense.Payee == "" || expense.Amount <= 0 {
+	ctx, done := s.begin(ctx, "expenses.create")
+	defer done()
+
+	if expense.Payee == "" || expense.Amount <= 0 || !expense.Frequency.Valid() {
 		return finance.Expense{}, repository.ErrInvalidInput
 	}
 	expense.ID = ensureID(expense.ID)
-	expense.UpdatedAt = time.Now().UTC()
+	expense.UpdatedAt = s.now()
 
 	row := s.db.QueryRowContext(ctx, `
-		INSERT INTO finance_expenses (id, payee, amount, frequency, category, notes, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
-		RETURNING id, payee, amount, frequency, category, COALESCE(notes, ''), updated_at`,
-		expense.ID, expense.Payee, expense.Amount, expense.Frequency, expense.Category, expense.Notes, expense.UpdatedAt)
-	return scanExpense(row)
+		INSERT INTO finance_expenses (id, payee, amount, frequency, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''))
+		RETURNING id, payee, amount, frequency, category, COALESCE(notes, ''), day_of_month, day_of_week, updated_at, currency, recurrence_interval`,
+		expense.ID, expense.Payee, expense.Amount, expense.Frequency, expense.Category, expense.Notes, expense.DayOfMonth, expense.DayOfWeek, expense.UpdatedAt, expense.Currency, expense.Interval)
+	return writeResult(scanExpense(row))
 }
 
 func (s *expenseStore) Update(ctx context.Context, expense finance.Expense) (finance.Expense, error) {
-	if expense.ID == "" {
+	ctx, done := s.begin(ctx, "expenses.update")
+	defer done()
+
+	if expense.ID == "" || !expense.Frequency.Valid() {
 		return finance.Expense{}, repository.ErrInvalidInput
 	}
-	expense.UpdatedAt = time.Now().UTC()
+	expense.UpdatedAt = s.now()
 
-	row := s.db.QueryRowContext(ctx, `
-		UPDATE finance_expenses
-		SET payee=$2,
-		    amount=$3,
-		    frequency=$4,
-		    category=$5,
-		    notes=NULLIF($6, ''),
-		    updated_at=$7
-		WHERE id=$1
-		RETURNING id, payee, amount, frequency, category, COALESCE(notes, ''), updated_at`,
-		expense.ID, expense.Payee, expense.Amount, expense.Frequency, expense.Category, expense.Notes, expense.UpdatedAt)
+	row := s.db.QueryRowContext(ctx, updateExpenseSQL,
+		expense.ID, expense.Payee, expense.Amount, expense.Frequency, expense.Category, expense.Notes, expense.DayOfMonth, expense.DayOfWeek, expense.UpdatedAt, expense.Currency, expense.Interval)
 	updated, err := scanExpense(row)
 	if errors.Is(err, sql.ErrNoRows) {
 		return finance.Expense{}, repository.ErrNotFound
 	}
-	return updated, err
+	return writeResult(updated, err)
 }
 
 func (s *expenseStore) Delete(ctx context.Context, id string) error {
+	ctx, done := s.begin(ctx, "expenses.delete")
+	defer done()
+
 	result, err := s.db.ExecContext(ctx, `DELETE FROM finance_expenses WHERE id=$1`, id)
 	if err != nil {
 		return err
@@ -414,16 +812,114 @@ func (s *expenseStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (s *expenseStore) Meta(ctx context.Context) (repository.CollectionMeta, error) {
+	ctx, done := s.begin(ctx, "expenses.meta")
+	defer done()
+
+	return s.meta(ctx, "finance_expenses")
+}
+
+const updateExpenseSQL = `
+		UPDATE finance_expenses
+		SET payee=$2,
+		    amount=$3,
+		    frequency=$4,
+		    category=$5,
+		    notes=NULLIF($6, ''),
+		    day_of_month=$7,
+		    day_of_week=$8,
+		    updated_at=$9,
+		    currency=NULLIF($10, ''),
+		    recurrence_interval=NULLIF($11, '')
+		WHERE id=$1
+		RETURNING id, payee, amount, frequency, category, COALESCE(notes, ''), day_of_month, day_of_week, updated_at, currency, recurrence_interval`
+
+// UpdateMany locks each expense in turn, applies change and writes it back in one
+// transaction, so a failure part-way leaves every expense untouched. A transaction
+// that hits a serialization failure or deadlock is retried, so change may run more
+// than once per expense.
+func (s *expenseStore) UpdateMany(ctx context.Context, ids []string, change func(*finance.Expense) error) ([]finance.Expense, []string, error) {
+	ctx, done := s.begin(ctx, "expenses.updateMany")
+	defer done()
+
+	var updated []finance.Expense
+	var missing []string
+	err := withRetry(ctx, s.opts, "expenses.updateMany", func() error {
+		var err error
+		updated, missing, err = s.updateMany(ctx, ids, change)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, missing, nil
+}
+
+// updateMany makes a single attempt at UpdateMany in its own transaction.
+func (s *expenseStore) updateMany(ctx context.Context, ids []string, change func(*finance.Expense) error) ([]finance.Expense, []string, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	now := s.now()
+	var updated []finance.Expense
+	var missing []string
+	for _, id := range ids {
+		// A malformed id would fail the uuid cast and abort the transaction.
+		if !looksLikeUUID(id) {
+			missing = append(missing, id)
+			continue
+		}
+		expense, err := scanExpense(tx.QueryRowContext(ctx, `
+			SELECT id, payee, amount, frequency, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval
+			FROM finance_expenses
+			WHERE id = $1
+			FOR UPDATE`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			missing = append(missing, id)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := change(&expense); err != nil {
+			return nil, nil, err
+		}
+		expense.ID = id
+		if expense.Payee == "" || expense.Amount <= 0 || !expense.Frequency.Valid() {
+			return nil, nil, repository.ErrInvalidInput
+		}
+		expense.UpdatedAt = now
+
+		row := tx.QueryRowContext(ctx, updateExpenseSQL,
+			expense.ID, expense.Payee, expense.Amount, expense.Frequency, expense.Category, expense.Notes, expense.DayOfMonth, expense.DayOfWeek, expense.UpdatedAt, expense.Currency, expense.Interval)
+		expense, err = writeResult(scanExpense(row))
+		if err != nil {
+			return nil, nil, err
+		}
+		updated = append(updated, expense)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return updated, missing, nil
+}
+
 type propertyScenarioStore struct {
-	db *sql.DB
+	storeBase
 }
 
 func (s *propertyScenarioStore) List(ctx context.Context) ([]finance.PropertyPlannerScenario, error) {
-	rows, err := s.db.QueryContext(ctx, `
+	ctx, done := s.begin(ctx, "propertyScenarios.list")
+	defer done()
+
+	rows, err := s.reader.QueryContext(ctx, `
 		SELECT id, property_type, headline, subheadline, last_refreshed,
 		       loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at
 		FROM property_planner_scenarios
-		ORDER BY updated_at DESC`)
+		ORDER BY updated_at DESC, id DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -444,7 +940,10 @@ func (s *propertyScenarioStore) List(ctx context.Context) ([]finance.PropertyPla
 }
 
 func (s *propertyScenarioStore) Get(ctx context.Context, id string) (finance.PropertyPlannerScenario, error) {
-	row := s.db.QueryRowContext(ctx, `
+	ctx, done := s.begin(ctx, "propertyScenarios.get")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
 		SELECT id, property_type, headline, subheadline, last_refreshed,
 		       loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at
 		FROM property_planner_scenarios
@@ -457,7 +956,10 @@ func (s *propertyScenarioStore) Get(ctx context.Context, id string) (finance.Pro
 }
 
 func (s *propertyScenarioStore) GetByType(ctx context.Context, scenarioType string) (finance.PropertyPlannerScenario, error) {
-	row := s.db.QueryRowContext(ctx, `
+	ctx, done := s.begin(ctx, "propertyScenarios.getByType")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
 		SELECT id, property_type, headline, subheadline, last_refreshed,
 		       loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at
 		FROM property_planner_scenarios
@@ -470,12 +972,18 @@ func (s *propertyScenarioStore) GetByType(ctx context.Context, scenarioType stri
 }
 
 func (s *propertyScenarioStore) Create(ctx context.Context, scenario finance.PropertyPlannerScenario) (finance.PropertyPlannerScenario, error) {
+	ctx, done := s.begin(ctx, "propertyScenarios.create")
+	defer done()
+
 	if scenario.Type == "" || scenario.Headline == "" {
 		return finance.PropertyPlannerScenario{}, repository.ErrInvalidInput
 	}
+	if err := scenario.Inputs.Validate(); err != nil {
+		return finance.PropertyPlannerScenario{}, fmt.Errorf("%w: %v", repository.ErrInvalidInput, err)
+	}
 	scenario.ID = ensureID(scenario.ID)
-	scenario.UpdatedAt = time.Now().UTC()
-	payload, err := buildScenarioPayload(scenario)
+	scenario.UpdatedAt = s.now()
+	payload, err := buildScenarioPayload(scenario, s.opts.compressScenarios)
 	if err != nil {
 		return finance.PropertyPlannerScenario{}, err
 	}
@@ -488,21 +996,9 @@ func (s *propertyScenarioStore) Create(ctx context.Context, scenario finance.Pro
 		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
 		RETURNING id, property_type, headline, subheadline, last_refreshed,
 		          loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at`,
-		payload.ID,
-		payload.Type,
-		payload.Headline,
-		payload.Subheadline,
-		payload.LastRefreshed,
-		payload.LoanInputsJSON,
-		payload.AmortizationJSON,
-		payload.SnapshotJSON,
-		payload.SummaryJSON,
-		payload.TimelineJSON,
-		payload.MilestonesJSON,
-		payload.InsightsJSON,
-		scenario.UpdatedAt,
+		payload.args(scenario.UpdatedAt)...,
 	)
-	created, err := scanPropertyScenario(row)
+	created, err := writeResult(scanPropertyScenario(row))
 	if err != nil {
 		return finance.PropertyPlannerScenario{}, err
 	}
@@ -510,16 +1006,81 @@ func (s *propertyScenarioStore) Create(ctx context.Context, scenario finance.Pro
 }
 
 func (s *propertyScenarioStore) Update(ctx context.Context, scenario finance.PropertyPlannerScenario) (finance.PropertyPlannerScenario, error) {
+	ctx, done := s.begin(ctx, "propertyScenarios.update")
+	defer done()
+
 	if scenario.ID == "" {
 		return finance.PropertyPlannerScenario{}, repository.ErrInvalidInput
 	}
-	scenario.UpdatedAt = time.Now().UTC()
-	payload, err := buildScenarioPayload(scenario)
+	if err := scenario.Inputs.Validate(); err != nil {
+		return finance.PropertyPlannerScenario{}, fmt.Errorf("%w: %v", repository.ErrInvalidInput, err)
+	}
+	scenario.UpdatedAt = s.now()
+	payload, err := buildScenarioPayload(scenario, s.opts.compressScenarios)
 	if err != nil {
 		return finance.PropertyPlannerScenario{}, err
 	}
 
+	row := s.db.QueryRowContext(ctx, updatePropertyScenarioSQL, payload.args(scenario.UpdatedAt)...)
+	updated, err := scanPropertyScenario(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finance.PropertyPlannerScenario{}, repository.ErrNotFound
+	}
+	return updated, err
+}
+
+// UpsertByType writes the scenario with INSERT ... ON CONFLICT (property_type), so a
+// concurrent upsert of the same type updates rather than fails. The existing row keeps
+// its ID; xmax is zero only for a freshly inserted row.
+func (s *propertyScenarioStore) UpsertByType(ctx context.Context, scenario finance.PropertyPlannerScenario) (finance.PropertyPlannerScenario, bool, error) {
+	ctx, done := s.begin(ctx, "propertyScenarios.upsertByType")
+	defer done()
+
+	if scenario.Type == "" || scenario.Headline == "" {
+		return finance.PropertyPlannerScenario{}, false, repository.ErrInvalidInput
+	}
+	if err := scenario.Inputs.Validate(); err != nil {
+		return finance.PropertyPlannerScenario{}, false, fmt.Errorf("%w: %v", repository.ErrInvalidInput, err)
+	}
+	scenario.ID = ensureID(scenario.ID)
+	scenario.UpdatedAt = s.now()
+	payload, err := buildScenarioPayload(scenario, s.opts.compressScenarios)
+	if err != nil {
+		return finance.PropertyPlannerScenario{}, false, err
+	}
+
+	var created bool
 	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO property_planner_scenarios (
+			id, property_type, headline, subheadline, last_refreshed,
+			loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at
+		)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+		ON CONFLICT (property_type) DO UPDATE
+		SET headline=EXCLUDED.headline,
+		    subheadline=EXCLUDED.subheadline,
+		    last_refreshed=EXCLUDED.last_refreshed,
+		    loan_inputs=EXCLUDED.loan_inputs,
+		    amortization=EXCLUDED.amortization,
+		    snapshot=EXCLUDED.snapshot,
+		    summary=EXCLUDED.summary,
+		    timeline=EXCLUDED.timeline,
+		    milestones=EXCLUDED.milestones,
+		    insights=EXCLUDED.insights,
+		    updated_at=EXCLUDED.updated_at
+		RETURNING id, property_type, headline, subheadline, last_refreshed,
+		          loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at,
+		          xmax = 0`,
+		payload.args(scenario.UpdatedAt)...,
+	)
+	saved, err := writeResult(scanPropertyScenario(extraScanner{row, []any{&created}}))
+	if err != nil {
+		return finance.PropertyPlannerScenario{}, false, err
+	}
+	return saved, created, nil
+}
+
+const updatePropertyScenarioSQL = `
 		UPDATE property_planner_scenarios
 		SET property_type=$2,
 		    headline=$3,
@@ -535,29 +1096,90 @@ func (s *propertyScenarioStore) Update(ctx context.Context, scenario finance.Pro
 		    updated_at=$13
 		WHERE id=$1
 		RETURNING id, property_type, headline, subheadline, last_refreshed,
-		          loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at`,
-		payload.ID,
-		payload.Type,
-		payload.Headline,
-		payload.Subheadline,
-		payload.LastRefreshed,
-		payload.LoanInputsJSON,
-		payload.AmortizationJSON,
-		payload.SnapshotJSON,
-		payload.SummaryJSON,
-		payload.TimelineJSON,
-		payload.MilestonesJSON,
-		payload.InsightsJSON,
-		scenario.UpdatedAt,
-	)
-	updated, err := scanPropertyScenario(row)
-	if errors.Is(err, sql.ErrNoRows) {
-		return finance.PropertyPlannerScenario{}, repository.ErrNotFound
+		          loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at`
+
+// UpdateMany applies change to each scenario in ids inside one transaction, locking the
+// rows first so a concurrent edit cannot be overwritten with a stale copy. Transient
+// failures are retried as in expenseStore.UpdateMany.
+func (s *propertyScenarioStore) UpdateMany(ctx context.Context, ids []string, change func(*finance.PropertyPlannerScenario) error) ([]finance.PropertyPlannerScenario, []string, error) {
+	ctx, done := s.begin(ctx, "propertyScenarios.updateMany")
+	defer done()
+
+	var updated []finance.PropertyPlannerScenario
+	var missing []string
+	err := withRetry(ctx, s.opts, "propertyScenarios.updateMany", func() error {
+		var err error
+		updated, missing, err = s.updateMany(ctx, ids, change)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
 	}
-	return updated, err
+	return updated, missing, nil
+}
+
+// updateMany makes a single attempt at UpdateMany in its own transaction.
+func (s *propertyScenarioStore) updateMany(ctx context.Context, ids []string, change func(*finance.PropertyPlannerScenario) error) ([]finance.PropertyPlannerScenario, []string, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	now := s.now()
+	var updated []finance.PropertyPlannerScenario
+	var missing []string
+	for _, id := range ids {
+		// A malformed id would fail the uuid cast and abort the transaction.
+		if !looksLikeUUID(id) {
+			missing = append(missing, id)
+			continue
+		}
+		scenario, err := scanPropertyScenario(tx.QueryRowContext(ctx, `
+			SELECT id, property_type, headline, subheadline, last_refreshed,
+			       loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at
+			FROM property_planner_scenarios
+			WHERE id = $1
+			FOR UPDATE`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			missing = append(missing, id)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := change(&scenario); err != nil {
+			return nil, nil, err
+		}
+		scenario.ID = id
+		if scenario.Type == "" || scenario.Headline == "" {
+			return nil, nil, repository.ErrInvalidInput
+		}
+		if err := scenario.Inputs.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", repository.ErrInvalidInput, err)
+		}
+		scenario.UpdatedAt = now
+		payload, err := buildScenarioPayload(scenario, s.opts.compressScenarios)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		scenario, err = writeResult(scanPropertyScenario(tx.QueryRowContext(ctx, updatePropertyScenarioSQL, payload.args(now)...)))
+		if err != nil {
+			return nil, nil, err
+		}
+		updated = append(updated, scenario)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return updated, missing, nil
 }
 
 func (s *propertyScenarioStore) Delete(ctx context.Context, id string) error {
+	ctx, done := s.begin(ctx, "propertyScenarios.delete")
+	defer done()
+
 	result, err := s.db.ExecContext(ctx, `DELETE FROM property_planner_scenarios WHERE id=$1`, id)
 	if err != nil {
 		return err
@@ -569,28 +1191,607 @@ func (s *propertyScenarioStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func scanAsset(row scanner) (finance.Asset, error) {
-	var asset finance.Asset
-	var notes sql.NullString
-	err := row.Scan(
-		&asset.ID,
-		&asset.Name,
+func (s *propertyScenarioStore) Meta(ctx context.Context) (repository.CollectionMeta, error) {
+	ctx, done := s.begin(ctx, "propertyScenarios.meta")
+	defer done()
+
+	return s.meta(ctx, "property_planner_scenarios")
+}
+
+type goalStore struct {
+	storeBase
+}
+
+func (s *goalStore) List(ctx context.Context) ([]finance.Goal, error) {
+	ctx, done := s.begin(ctx, "goals.list")
+	defer done()
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, name, target_amount, target_date, asset_category, notes, updated_at
+		FROM finance_goals
+		ORDER BY updated_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	goals := []finance.Goal{}
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+	return goals, rows.Err()
+}
+
+func (s *goalStore) Get(ctx context.Context, id string) (finance.Goal, error) {
+	ctx, done := s.begin(ctx, "goals.get")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
+		SELECT id, name, target_amount, target_date, asset_category, notes, updated_at
+		FROM finance_goals
+		WHERE id = $1`, id)
+	goal, err := scanGoal(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finance.Goal{}, repository.ErrNotFound
+	}
+	return goal, err
+}
+
+func (s *goalStore) GetMany(ctx context.Context, ids []string) (map[string]finance.Goal, error) {
+	ctx, done := s.begin(ctx, "goals.get_many")
+	defer done()
+
+	return getMany(ctx, s.reader, `
+		SELECT id, name, target_amount, target_date, asset_category, notes, updated_at
+		FROM finance_goals
+		WHERE id = ANY($1::uuid[])`, ids, scanGoal, func(item finance.Goal) string { return item.ID })
+}
+
+func (s *goalStore) Create(ctx context.Context, goal finance.Goal) (finance.Goal, error) {
+	ctx, done := s.begin(ctx, "goals.create")
+	defer done()
+
+	if goal.Name == "" || goal.TargetAmount <= 0 {
+		return finance.Goal{}, repository.ErrInvalidInput
+	}
+	goal.ID = ensureID(goal.ID)
+	goal.UpdatedAt = s.now()
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO finance_goals (id, name, target_amount, target_date, asset_category, notes, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+		RETURNING id, name, target_amount, target_date, asset_category, notes, updated_at`,
+		goal.ID, goal.Name, goal.TargetAmount, goal.TargetDate, goal.AssetCategory, goal.Notes, goal.UpdatedAt)
+	return writeResult(scanGoal(row))
+}
+
+func (s *goalStore) Update(ctx context.Context, goal finance.Goal) (finance.Goal, error) {
+	ctx, done := s.begin(ctx, "goals.update")
+	defer done()
+
+	if goal.ID == "" {
+		return finance.Goal{}, repository.ErrInvalidInput
+	}
+	goal.UpdatedAt = s.now()
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE finance_goals
+		SET name=$2,
+		    target_amount=$3,
+		    target_date=$4,
+		    asset_category=$5,
+		    notes=NULLIF($6, ''),
+		    updated_at=$7
+		WHERE id=$1
+		RETURNING id, name, target_amount, target_date, asset_category, notes, updated_at`,
+		goal.ID, goal.Name, goal.TargetAmount, goal.TargetDate, goal.AssetCategory, goal.Notes, goal.UpdatedAt)
+	updated, err := scanGoal(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finance.Goal{}, repository.ErrNotFound
+	}
+	return updated, err
+}
+
+func (s *goalStore) Delete(ctx context.Context, id string) error {
+	ctx, done := s.begin(ctx, "goals.delete")
+	defer done()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM finance_goals WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (s *goalStore) Meta(ctx context.Context) (repository.CollectionMeta, error) {
+	ctx, done := s.begin(ctx, "goals.meta")
+	defer done()
+
+	return s.meta(ctx, "finance_goals")
+}
+
+type accountStore struct {
+	storeBase
+}
+
+func (s *accountStore) List(ctx context.Context) ([]finance.Account, error) {
+	ctx, done := s.begin(ctx, "accounts.list")
+	defer done()
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, name, institution, updated_at
+		FROM finance_accounts
+		ORDER BY updated_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []finance.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+func (s *accountStore) Get(ctx context.Context, id string) (finance.Account, error) {
+	ctx, done := s.begin(ctx, "accounts.get")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
+		SELECT id, name, institution, updated_at
+		FROM finance_accounts
+		WHERE id = $1`, id)
+	account, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finance.Account{}, repository.ErrNotFound
+	}
+	return account, err
+}
+
+func (s *accountStore) GetMany(ctx context.Context, ids []string) (map[string]finance.Account, error) {
+	ctx, done := s.begin(ctx, "accounts.get_many")
+	defer done()
+
+	return getMany(ctx, s.reader, `
+		SELECT id, name, institution, updated_at
+		FROM finance_accounts
+		WHERE id = ANY($1::uuid[])`, ids, scanAccount, func(item finance.Account) string { return item.ID })
+}
+
+func (s *accountStore) Create(ctx context.Context, account finance.Account) (finance.Account, error) {
+	ctx, done := s.begin(ctx, "accounts.create")
+	defer done()
+
+	if account.Name == "" {
+		return finance.Account{}, repository.ErrInvalidInput
+	}
+	account.ID = ensureID(account.ID)
+	account.UpdatedAt = s.now()
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO finance_accounts (id, name, institution, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		RETURNING id, name, institution, updated_at`,
+		account.ID, account.Name, account.Institution, account.UpdatedAt)
+	return writeResult(scanAccount(row))
+}
+
+func (s *accountStore) Update(ctx context.Context, account finance.Account) (finance.Account, error) {
+	ctx, done := s.begin(ctx, "accounts.update")
+	defer done()
+
+	if account.ID == "" {
+		return finance.Account{}, repository.ErrInvalidInput
+	}
+	account.UpdatedAt = s.now()
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE finance_accounts
+		SET name=$2,
+		    institution=NULLIF($3, ''),
+		    updated_at=$4
+		WHERE id=$1
+		RETURNING id, name, institution, updated_at`,
+		account.ID, account.Name, account.Institution, account.UpdatedAt)
+	updated, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finance.Account{}, repository.ErrNotFound
+	}
+	return updated, err
+}
+
+// Delete relies on the account_id foreign keys: while holdings reference the account
+// Postgres rejects the delete and it is reported as ErrConflict.
+func (s *accountStore) Delete(ctx context.Context, id string) error {
+	ctx, done := s.begin(ctx, "accounts.delete")
+	defer done()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM finance_accounts WHERE id=$1`, id)
+	if isForeignKeyViolation(err) {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) Meta(ctx context.Context) (repository.CollectionMeta, error) {
+	ctx, done := s.begin(ctx, "accounts.meta")
+	defer done()
+
+	return s.meta(ctx, "finance_accounts")
+}
+
+type transactionStore struct {
+	storeBase
+}
+
+const transactionColumns = `id, occurred_at, amount, currency, direction, category, account_id, memo, updated_at`
+
+func (s *transactionStore) List(ctx context.Context, filter repository.TransactionFilter) ([]finance.Transaction, error) {
+	ctx, done := s.begin(ctx, "transactions.list")
+	defer done()
+
+	where, args := transactionBounds(filter)
+	query, args := transactionPageClause(`SELECT `+transactionColumns+` FROM finance_transactions WHERE `+where, args, filter)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []finance.Transaction{}
+	for rows.Next() {
+		item, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ListPage reads the total with COUNT(*) OVER() inside a CTE that applies only the
+// filter bounds, so the window counts the whole filtered ledger before the cursor and
+// limit trim it, in the same snapshot and round trip as the page.
+func (s *transactionStore) ListPage(ctx context.Context, filter repository.TransactionFilter) (repository.TransactionPage, error) {
+	ctx, done := s.begin(ctx, "transactions.listPage")
+	defer done()
+
+	where, boundArgs := transactionBounds(filter)
+	query, args := transactionPageClause(`
+		WITH filtered AS (
+			SELECT `+transactionColumns+`, COUNT(*) OVER() AS total
+			FROM finance_transactions
+			WHERE `+where+`
+		)
+		SELECT `+transactionColumns+`, total FROM filtered WHERE true`, boundArgs, filter)
+
+	rows, err := s.reader.QueryContext(ctx, query, args...)
+	if err != nil {
+		return repository.TransactionPage{}, err
+	}
+	page := repository.TransactionPage{Items: []finance.Transaction{}}
+	for rows.Next() {
+		item, err := scanTransaction(totalScanner{row: rows, total: &page.Total})
+		if err != nil {
+			rows.Close()
+			return repository.TransactionPage{}, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return repository.TransactionPage{}, err
+	}
+
+	// A cursor past the last row leaves no row to carry the window total.
+	if len(page.Items) == 0 && filter.After != nil {
+		row := s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM finance_transactions WHERE `+where, boundArgs...)
+		if err := row.Scan(&page.Total); err != nil {
+			return repository.TransactionPage{}, err
+		}
+	}
+	return page, nil
+}
+
+// transactionBounds renders the filter's date and category bounds as a WHERE clause.
+func transactionBounds(filter repository.TransactionFilter) (string, []any) {
+	where := "true"
+	var args []any
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		where += fmt.Sprintf(" AND occurred_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		where += fmt.Sprintf(" AND occurred_at < $%d", len(args))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		where += fmt.Sprintf(" AND category = $%d", len(args))
+	}
+	return where, args
+}
+
+// transactionPageClause appends the keyset cursor, newest-first ordering and limit.
+func transactionPageClause(query string, args []any, filter repository.TransactionFilter) (string, []any) {
+	if filter.After != nil {
+		args = append(args, filter.After.Date, filter.After.ID)
+		query += fmt.Sprintf(" AND (occurred_at, id) < ($%d, $%d::uuid)", len(args)-1, len(args))
+	}
+	query += " ORDER BY occurred_at DESC, id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	return query, args
+}
+
+// totalScanner appends a trailing COUNT(*) OVER() column to a row's scan targets.
+type totalScanner struct {
+	row   scanner
+	total *int
+}
+
+func (t totalScanner) Scan(dest ...any) error {
+	return t.row.Scan(append(dest, t.total)...)
+}
+
+func (s *transactionStore) Get(ctx context.Context, id string) (finance.Transaction, error) {
+	ctx, done := s.begin(ctx, "transactions.get")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `SELECT `+transactionColumns+` FROM finance_transactions WHERE id = $1`, id)
+	item, err := scanTransaction(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finance.Transaction{}, repository.ErrNotFound
+	}
+	return item, err
+}
+
+func (s *transactionStore) GetMany(ctx context.Context, ids []string) (map[string]finance.Transaction, error) {
+	ctx, done := s.begin(ctx, "transactions.get_many")
+	defer done()
+
+	return getMany(ctx, s.reader, `SELECT `+transactionColumns+` FROM finance_transactions WHERE id = ANY($1::uuid[])`,
+		ids, scanTransaction, func(item finance.Transaction) string { return item.ID })
+}
+
+func (s *transactionStore) Create(ctx context.Context, txn finance.Transaction) (finance.Transaction, error) {
+	ctx, done := s.begin(ctx, "transactions.create")
+	defer done()
+
+	if txn.Date.IsZero() || txn.Amount <= 0 || txn.Category == "" {
+		return finance.Transaction{}, repository.ErrInvalidInput
+	}
+	txn.ID = ensureID(txn.ID)
+	txn.UpdatedAt = s.now()
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO finance_transactions (id, occurred_at, amount, currency, direction, category, account_id, memo, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, '')::uuid, NULLIF($8, ''), $9)
+		RETURNING `+transactionColumns,
+		txn.ID, txn.Date, txn.Amount, txn.Currency, txn.Direction, txn.Category, txn.AccountID, txn.Memo, txn.UpdatedAt)
+	return writeResult(scanTransaction(row))
+}
+
+func (s *transactionStore) Update(ctx context.Context, txn finance.Transaction) (finance.Transaction, error) {
+	ctx, done := s.begin(ctx, "transactions.update")
+	defer done()
+
+	if txn.ID == "" {
+		return finance.Transaction{}, repository.ErrInvalidInput
+	}
+	txn.UpdatedAt = s.now()
+
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE finance_transactions
+		SET occurred_at=$2,
+		    amount=$3,
+		    currency=NULLIF($4, ''),
+		    direction=$5,
+		    category=$6,
+		    account_id=NULLIF($7, '')::uuid,
+		    memo=NULLIF($8, ''),
+		    updated_at=$9
+		WHERE id=$1
+		RETURNING `+transactionColumns,
+		txn.ID, txn.Date, txn.Amount, txn.Currency, txn.Direction, txn.Category, txn.AccountID, txn.Memo, txn.UpdatedAt)
+	updated, err := scanTransaction(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finance.Transaction{}, repository.ErrNotFound
+	}
+	return updated, err
+}
+
+func (s *transactionStore) Delete(ctx context.Context, id string) error {
+	ctx, done := s.begin(ctx, "transactions.delete")
+	defer done()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM finance_transactions WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (s *transactionStore) Meta(ctx context.Context) (repository.CollectionMeta, error) {
+	ctx, done := s.begin(ctx, "transactions.meta")
+	defer done()
+
+	return s.meta(ctx, "finance_transactions")
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// writeResult maps constraint violations from an INSERT or UPDATE to repository errors,
+// matching the memory store: a primary key clash is ErrDuplicateID and a failed CHECK
+// is ErrInvalidInput.
+func writeResult[T any](item T, err error) (T, error) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return item, err
+	}
+	var zero T
+	switch {
+	case pgErr.Code == "23505" && strings.HasSuffix(pgErr.ConstraintName, "_pkey"):
+		return zero, repository.ErrDuplicateID
+	case pgErr.Code == "23514":
+		return zero, repository.ErrInvalidInput
+	}
+	return item, err
+}
+
+type netWorthSnapshotStore struct {
+	storeBase
+}
+
+func (s *netWorthSnapshotStore) List(ctx context.Context) ([]finance.NetWorthSnapshot, error) {
+	ctx, done := s.begin(ctx, "netWorthSnapshots.list")
+	defer done()
+
+	rows, err := s.reader.QueryContext(ctx, `
+		SELECT id, total_assets, total_liabilities, net_worth, recorded_at
+		FROM net_worth_snapshots
+		ORDER BY recorded_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []finance.NetWorthSnapshot
+	for rows.Next() {
+		item, err := scanNetWorthSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if items == nil {
+		items = []finance.NetWorthSnapshot{}
+	}
+	return items, rows.Err()
+}
+
+func (s *netWorthSnapshotStore) Latest(ctx context.Context) (finance.NetWorthSnapshot, error) {
+	ctx, done := s.begin(ctx, "netWorthSnapshots.latest")
+	defer done()
+
+	row := s.reader.QueryRowContext(ctx, `
+		SELECT id, total_assets, total_liabilities, net_worth, recorded_at
+		FROM net_worth_snapshots
+		ORDER BY recorded_at DESC
+		LIMIT 1`)
+	item, err := scanNetWorthSnapshot(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return finance.NetWorthSnapshot{}, repository.ErrNotFound
+	}
+	return item, err
+}
+
+func (s *netWorthSnapshotStore) Create(ctx context.Context, snapshot finance.NetWorthSnapshot) (finance.NetWorthSnapshot, error) {
+	ctx, done := s.begin(ctx, "netWorthSnapshots.create")
+	defer done()
+
+	snapshot.ID = ensureID(snapshot.ID)
+	if snapshot.RecordedAt.IsZero() {
+		snapshot.RecordedAt = s.now()
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO net_worth_snapshots (id, total_assets, total_liabilities, net_worth, recorded_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, total_assets, total_liabilities, net_worth, recorded_at`,
+		snapshot.ID, snapshot.TotalAssets, snapshot.TotalLiabilities, snapshot.NetWorth, snapshot.RecordedAt)
+	return writeResult(scanNetWorthSnapshot(row))
+}
+
+// CreateOncePerMinute leans on net_worth_snapshots_minute_idx: a losing concurrent
+// insert does nothing, and the snapshot that won is read back from the primary.
+func (s *netWorthSnapshotStore) CreateOncePerMinute(ctx context.Context, snapshot finance.NetWorthSnapshot) (finance.NetWorthSnapshot, bool, error) {
+	ctx, done := s.begin(ctx, "netWorthSnapshots.createOncePerMinute")
+	defer done()
+
+	snapshot.ID = ensureID(snapshot.ID)
+	if snapshot.RecordedAt.IsZero() {
+		snapshot.RecordedAt = s.now()
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO net_worth_snapshots (id, total_assets, total_liabilities, net_worth, recorded_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT ((date_trunc('minute', recorded_at AT TIME ZONE 'UTC'))) DO NOTHING
+		RETURNING id, total_assets, total_liabilities, net_worth, recorded_at`,
+		snapshot.ID, snapshot.TotalAssets, snapshot.TotalLiabilities, snapshot.NetWorth, snapshot.RecordedAt)
+	created, err := writeResult(scanNetWorthSnapshot(row))
+	if !errors.Is(err, sql.ErrNoRows) {
+		return created, err == nil, err
+	}
+
+	row = s.db.QueryRowContext(ctx, `
+		SELECT id, total_assets, total_liabilities, net_worth, recorded_at
+		FROM net_worth_snapshots
+		WHERE date_trunc('minute', recorded_at AT TIME ZONE 'UTC') = date_trunc('minute', $1::timestamptz AT TIME ZONE 'UTC')`,
+		snapshot.RecordedAt)
+	existing, err := scanNetWorthSnapshot(row)
+	return existing, false, err
+}
+
+func scanAsset(row scanner) (finance.Asset, error) {
+	var asset finance.Asset
+	var notes, currency, accountID, linkedLiabilityID sql.NullString
+	err := row.Scan(
+		&asset.ID,
+		&asset.Name,
 		&asset.Category,
 		&asset.CurrentValue,
 		&asset.AnnualGrowthRate,
 		&notes,
 		&asset.UpdatedAt,
+		&currency,
+		&accountID,
+		&linkedLiabilityID,
 	)
 	if err != nil {
 		return finance.Asset{}, err
 	}
 	asset.Notes = notes.String
+	asset.Currency = currency.String
+	asset.AccountID = accountID.String
+	asset.LinkedLiabilityID = linkedLiabilityID.String
 	return asset, nil
 }
 
 func scanLiability(row scanner) (finance.Liability, error) {
 	var item finance.Liability
-	var notes sql.NullString
+	var notes, currency, accountID sql.NullString
 	err := row.Scan(
 		&item.ID,
 		&item.Name,
@@ -600,17 +1801,22 @@ func scanLiability(row scanner) (finance.Liability, error) {
 		&item.MinimumPayment,
 		&notes,
 		&item.UpdatedAt,
+		&currency,
+		&accountID,
 	)
 	if err != nil {
 		return finance.Liability{}, err
 	}
 	item.Notes = notes.String
+	item.Currency = currency.String
+	item.AccountID = accountID.String
 	return item, nil
 }
 
 func scanIncome(row scanner) (finance.Income, error) {
 	var item finance.Income
-	var notes sql.NullString
+	var notes, currency, interval sql.NullString
+	var dayOfMonth, dayOfWeek sql.NullInt16
 	err := row.Scan(
 		&item.ID,
 		&item.Source,
@@ -619,18 +1825,27 @@ func scanIncome(row scanner) (finance.Income, error) {
 		&item.StartDate,
 		&item.Category,
 		&notes,
+		&dayOfMonth,
+		&dayOfWeek,
 		&item.UpdatedAt,
+		&currency,
+		&interval,
 	)
 	if err != nil {
 		return finance.Income{}, err
 	}
 	item.Notes = notes.String
+	item.Currency = currency.String
+	item.Interval = interval.String
+	item.DayOfMonth = nullableInt(dayOfMonth)
+	item.DayOfWeek = nullableInt(dayOfWeek)
 	return item, nil
 }
 
 func scanExpense(row scanner) (finance.Expense, error) {
 	var item finance.Expense
-	var notes sql.NullString
+	var notes, currency, interval sql.NullString
+	var dayOfMonth, dayOfWeek sql.NullInt16
 	err := row.Scan(
 		&item.ID,
 		&item.Payee,
@@ -638,15 +1853,31 @@ func scanExpense(row scanner) (finance.Expense, error) {
 		&item.Frequency,
 		&item.Category,
 		&notes,
+		&dayOfMonth,
+		&dayOfWeek,
 		&item.UpdatedAt,
+		&currency,
+		&interval,
 	)
 	if err != nil {
 		return finance.Expense{}, err
 	}
 	item.Notes = notes.String
+	item.Currency = currency.String
+	item.Interval = interval.String
+	item.DayOfMonth = nullableInt(dayOfMonth)
+	item.DayOfWeek = nullableInt(dayOfWeek)
 	return item, nil
 }
 
+func nullableInt(v sql.NullInt16) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int16)
+	return &n
+}
+
 func scanPropertyScenario(row scanner) (finance.PropertyPlannerScenario, error) {
 	var item finance.PropertyPlannerScenario
 	var loanInputsData, amortizationData, snapshotData, summaryData, timelineData, milestonesData, insightsData []byte
@@ -669,27 +1900,111 @@ func scanPropertyScenario(row scanner) (finance.PropertyPlannerScenario, error)
 		return finance.PropertyPlannerScenario{}, err
 	}
 
-	if err := json.Unmarshal(loanInputsData, &item.Inputs); err != nil {
-		return finance.PropertyPlannerScenario{}, err
+	// A document of the wrong shape (an object where the summary array belongs, say)
+	// is corrupt data rather than a server fault, so report which column is bad.
+	columns := []struct {
+		name string
+		data []byte
+		dst  any
+	}{
+		{"loan_inputs", loanInputsData, &item.Inputs},
+		{"amortization", amortizationData, &item.Amortization},
+		{"snapshot", snapshotData, &item.Snapshot},
+		{"summary", summaryData, &item.Summary},
+		{"timeline", timelineData, &item.Timeline},
+		{"milestones", milestonesData, &item.Milestones},
+		{"insights", insightsData, &item.Insights},
+	}
+	for _, column := range columns {
+		// A NULL or empty column, say from a partial legacy insert, decodes as empty.
+		if len(column.data) == 0 {
+			continue
+		}
+		doc, err := decompressJSON(column.data)
+		if err != nil {
+			return finance.PropertyPlannerScenario{}, fmt.Errorf("%w: scenario %s %s: %v", repository.ErrInvalidData, item.ID, column.name, err)
+		}
+		if err := json.Unmarshal(doc, column.dst); err != nil {
+			return finance.PropertyPlannerScenario{}, fmt.Errorf("%w: scenario %s %s: %v", repository.ErrInvalidData, item.ID, column.name, err)
+		}
 	}
-	if err := json.Unmarshal(amortizationData, &item.Amortization); err != nil {
-		return finance.PropertyPlannerScenario{}, err
+	fillScenarioSlices(&item)
+	if err := item.CheckTimeline(); err != nil {
+		return finance.PropertyPlannerScenario{}, fmt.Errorf("%w: scenario %s: %v", repository.ErrInvalidData, item.ID, err)
 	}
-	if err := json.Unmarshal(snapshotData, &item.Snapshot); err != nil {
-		return finance.PropertyPlannerScenario{}, err
+	return item, nil
+}
+
+func scanNetWorthSnapshot(row scanner) (finance.NetWorthSnapshot, error) {
+	var item finance.NetWorthSnapshot
+	err := row.Scan(
+		&item.ID,
+		&item.TotalAssets,
+		&item.TotalLiabilities,
+		&item.NetWorth,
+		&item.RecordedAt,
+	)
+	if err != nil {
+		return finance.NetWorthSnapshot{}, err
 	}
-	if err := json.Unmarshal(summaryData, &item.Summary); err != nil {
-		return finance.PropertyPlannerScenario{}, err
+	return item, nil
+}
+
+func scanGoal(row scanner) (finance.Goal, error) {
+	var (
+		item       finance.Goal
+		targetDate sql.NullTime
+		notes      sql.NullString
+	)
+	err := row.Scan(
+		&item.ID,
+		&item.Name,
+		&item.TargetAmount,
+		&targetDate,
+		&item.AssetCategory,
+		&notes,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return finance.Goal{}, err
 	}
-	if err := json.Unmarshal(timelineData, &item.Timeline); err != nil {
-		return finance.PropertyPlannerScenario{}, err
+	if targetDate.Valid {
+		item.TargetDate = &targetDate.Time
 	}
-	if err := json.Unmarshal(milestonesData, &item.Milestones); err != nil {
-		return finance.PropertyPlannerScenario{}, err
+	item.Notes = notes.String
+	return item, nil
+}
+
+func scanAccount(row scanner) (finance.Account, error) {
+	var item finance.Account
+	var institution sql.NullString
+	if err := row.Scan(&item.ID, &item.Name, &institution, &item.UpdatedAt); err != nil {
+		return finance.Account{}, err
 	}
-	if err := json.Unmarshal(insightsData, &item.Insights); err != nil {
-		return finance.PropertyPlannerScenario{}, err
+	item.Institution = institution.String
+	return item, nil
+}
+
+func scanTransaction(row scanner) (finance.Transaction, error) {
+	var item finance.Transaction
+	var currency, accountID, memo sql.NullString
+	err := row.Scan(
+		&item.ID,
+		&item.Date,
+		&item.Amount,
+		&currency,
+		&item.Direction,
+		&item.Category,
+		&accountID,
+		&memo,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return finance.Transaction{}, err
 	}
+	item.Currency = currency.String
+	item.AccountID = accountID.String
+	item.Memo = memo.String
 	return item, nil
 }
 
@@ -697,6 +2012,17 @@ type scanner interface {
 	Scan(dest ...any) error
 }
 
+// extraScanner appends extra destinations for columns selected after the ones the
+// wrapped scan function reads.
+type extraScanner struct {
+	scanner
+	extra []any
+}
+
+func (e extraScanner) Scan(dest ...any) error {
+	return e.scanner.Scan(append(dest, e.extra...)...)
+}
+
 type propertyScenarioDBPayload struct {
 	ID               string
 	Type             string
@@ -712,20 +2038,23 @@ type propertyScenarioDBPayload struct {
 	InsightsJSON     []byte
 }
 
-func buildScenarioPayload(s finance.PropertyPlannerScenario) (propertyScenarioDBPayload, error) {
-	payload := propertyScenarioDBPayload{
-		ID:            s.ID,
-		Type:          s.Type,
-		Headline:      s.Headline,
-		Subheadline:   s.Subheadline,
-		LastRefreshed: s.LastRefreshed,
+// args lists the payload in the column order of the insert and update statements.
+func (p propertyScenarioDBPayload) args(updatedAt time.Time) []any {
+	return []any{
+		p.ID, p.Type, p.Headline, p.Subheadline, p.LastRefreshed,
+		p.LoanInputsJSON, p.AmortizationJSON, p.SnapshotJSON, p.SummaryJSON,
+		p.TimelineJSON, p.MilestonesJSON, p.InsightsJSON, updatedAt,
 	}
+}
 
-	if payload.Subheadline == "" {
-		payload.Subheadline = ""
+// fillScenarioSlices replaces nil slices with empty ones, so documents are written as
+// [] rather than null and scenarios read back from sparse rows encode the same way.
+func fillScenarioSlices(s *finance.PropertyPlannerScenario) {
+	if s.Amortization.BalancePoints == nil {
+		s.Amortization.BalancePoints = []finance.MortgageBalancePoint{}
 	}
-	if payload.LastRefreshed == "" {
-		payload.LastRefreshed = ""
+	if s.Amortization.Composition == nil {
+		s.Amortization.Composition = []finance.MortgageCompositionPoint{}
 	}
 	if s.Summary == nil {
 		s.Summary = []finance.PropertyPlannerSummary{}
@@ -739,6 +2068,26 @@ func buildScenarioPayload(s finance.PropertyPlannerScenario) (propertyScenarioDB
 	if s.Insights == nil {
 		s.Insights = []finance.PropertyPlannerInsight{}
 	}
+}
+
+// buildScenarioPayload marshals the scenario's documents for storage, compressing the
+// large ones when compress is set.
+func buildScenarioPayload(s finance.PropertyPlannerScenario, compress bool) (propertyScenarioDBPayload, error) {
+	payload := propertyScenarioDBPayload{
+		ID:            s.ID,
+		Type:          s.Type,
+		Headline:      s.Headline,
+		Subheadline:   s.Subheadline,
+		LastRefreshed: s.LastRefreshed,
+	}
+
+	if payload.Subheadline == "" {
+		payload.Subheadline = ""
+	}
+	if payload.LastRefreshed == "" {
+		payload.LastRefreshed = ""
+	}
+	fillScenarioSlices(&s)
 
 	var err error
 	if payload.LoanInputsJSON, err = json.Marshal(s.Inputs); err != nil {
@@ -763,6 +2112,16 @@ func buildScenarioPayload(s finance.PropertyPlannerScenario) (propertyScenarioDB
 		return propertyScenarioDBPayload{}, err
 	}
 
+	if compress {
+		for _, doc := range []*[]byte{
+			&payload.LoanInputsJSON, &payload.AmortizationJSON, &payload.SnapshotJSON, &payload.SummaryJSON,
+			&payload.TimelineJSON, &payload.MilestonesJSON, &payload.InsightsJSON,
+		} {
+			if *doc, err = compressJSON(*doc); err != nil {
+				return propertyScenarioDBPayload{}, err
+			}
+		}
+	}
 	return payload, nil
 }
 
@@ -0,0 +1,721 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/logging"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+func TestReplicaServesReadsAndPrimaryServesWrites(t *testing.T) {
+	primary, primaryDB := newFakeDB()
+	replica, replicaDB := newFakeDB()
+	repo := NewWithReplica(primaryDB, replicaDB)
+	ctx := context.Background()
+
+	if _, err := repo.Assets().List(ctx); err != nil {
+		t.Fatalf("list assets: %v", err)
+	}
+	if _, err := repo.Incomes().Get(ctx, "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected not found from replica, got %v", err)
+	}
+	if _, err := repo.NetWorthSnapshots().Latest(ctx); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected not found from replica, got %v", err)
+	}
+	if replica.count() != 3 || primary.count() != 0 {
+		t.Fatalf("expected 3 reads on the replica only, got replica=%d primary=%d", replica.count(), primary.count())
+	}
+
+	if err := repo.Expenses().Delete(ctx, "exp-1"); err != nil {
+		t.Fatalf("delete expense: %v", err)
+	}
+	if _, _, err := repo.RenameCashFlowCategory(ctx, "living", "household"); err != nil {
+		t.Fatalf("rename category: %v", err)
+	}
+	if err := repo.ImportDataset(ctx, finance.SeedData{}, repository.ImportReplace); err != nil {
+		t.Fatalf("import dataset: %v", err)
+	}
+	if replica.count() != 3 {
+		t.Fatalf("expected writes to skip the replica, got %d replica statements", replica.count())
+	}
+	if primary.count() == 0 {
+		t.Fatal("expected writes on the primary")
+	}
+}
+
+func TestNewRoutesEverythingToSingleDatabase(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+	ctx := context.Background()
+
+	if _, err := repo.Liabilities().List(ctx); err != nil {
+		t.Fatalf("list liabilities: %v", err)
+	}
+	if err := repo.Liabilities().Delete(ctx, "liab-1"); err != nil {
+		t.Fatalf("delete liability: %v", err)
+	}
+	if fake.count() != 2 {
+		t.Fatalf("expected both statements on the single database, got %d", fake.count())
+	}
+}
+
+func TestStatementTimeoutCancelsSlowQueries(t *testing.T) {
+	fake, db := newFakeDB()
+	fake.delay = time.Second
+	repo := New(db, WithStatementTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	_, err := repo.Assets().List(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the query to be cancelled promptly, took %s", elapsed)
+	}
+}
+
+func TestSlowQueriesAreLogged(t *testing.T) {
+	fake, db := newFakeDB()
+	fake.delay = 30 * time.Millisecond
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	repo := New(db, WithLogger(logger), WithSlowQueryThreshold(10*time.Millisecond))
+
+	if _, err := repo.Expenses().List(context.Background()); err != nil {
+		t.Fatalf("list expenses: %v", err)
+	}
+	if !strings.Contains(logs.String(), `"msg":"slow query"`) || !strings.Contains(logs.String(), `"op":"expenses.list"`) {
+		t.Fatalf("expected slow query warning for expenses.list, got %s", logs.String())
+	}
+
+	logs.Reset()
+	fake.delay = 0
+	if _, err := repo.Expenses().List(context.Background()); err != nil {
+		t.Fatalf("list expenses: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected fast query to be silent, got %s", logs.String())
+	}
+}
+
+func TestStoreLogsCarryTheRequestID(t *testing.T) {
+	fake, db := newFakeDB()
+	fake.delay = time.Second
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	repo := New(db, WithLogger(logger), WithStatementTimeout(20*time.Millisecond))
+	ctx := logging.WithRequestID(context.Background(), "req-123")
+
+	if _, err := repo.Goals().Get(ctx, "goal-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	out := logs.String()
+	if !strings.Contains(out, `"level":"ERROR"`) || !strings.Contains(out, `"op":"goals.get"`) || !strings.Contains(out, `"request_id":"req-123"`) {
+		t.Fatalf("expected an error log for goals.get tagged with the request ID, got %s", out)
+	}
+
+	logs.Reset()
+	fake.delay = 30 * time.Millisecond
+	repo = New(db, WithLogger(logger), WithSlowQueryThreshold(10*time.Millisecond))
+	if _, err := repo.Assets().List(ctx); err != nil {
+		t.Fatalf("list assets: %v", err)
+	}
+	if out := logs.String(); !strings.Contains(out, `"msg":"slow query"`) || !strings.Contains(out, `"request_id":"req-123"`) {
+		t.Fatalf("expected the slow query warning to carry the request ID, got %s", out)
+	}
+}
+
+func TestTransactionListAppliesFilterBounds(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+
+	filter := repository.TransactionFilter{
+		From:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Category: "food",
+	}
+	if _, err := repo.Transactions().List(context.Background(), filter); err != nil {
+		t.Fatalf("list transactions: %v", err)
+	}
+
+	query := fake.statements[len(fake.statements)-1]
+	if !strings.Contains(query, "occurred_at >= $1") || !strings.Contains(query, "category = $2") {
+		t.Fatalf("expected from and category bounds, got %q", query)
+	}
+	if strings.Contains(query, "occurred_at < ") {
+		t.Fatalf("expected no upper bound without To, got %q", query)
+	}
+}
+
+func TestTransactionListUsesKeysetCursor(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+
+	filter := repository.TransactionFilter{
+		After: &repository.TransactionCursor{Date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), ID: "t5"},
+		Limit: 3,
+	}
+	if _, err := repo.Transactions().List(context.Background(), filter); err != nil {
+		t.Fatalf("list transactions: %v", err)
+	}
+
+	query := fake.statements[len(fake.statements)-1]
+	if !strings.Contains(query, "(occurred_at, id) < ($1, $2::uuid)") || !strings.HasSuffix(query, "LIMIT $3") {
+		t.Fatalf("expected keyset predicate and limit, got %q", query)
+	}
+}
+
+func TestTransactionListPageCountsBeforeCursorAndLimit(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+
+	filter := repository.TransactionFilter{
+		Category: "food",
+		After:    &repository.TransactionCursor{Date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), ID: "t5"},
+		Limit:    3,
+	}
+	if _, err := repo.Transactions().ListPage(context.Background(), filter); err != nil {
+		t.Fatalf("list page: %v", err)
+	}
+
+	query := fake.statements[0]
+	window := strings.Index(query, "COUNT(*) OVER()")
+	keyset := strings.Index(query, "(occurred_at, id) < ($2, $3::uuid)")
+	if window < 0 || keyset < window || !strings.Contains(query[:keyset], "category = $1") {
+		t.Fatalf("expected the window total over the bounds only, before the cursor, got %q", query)
+	}
+	if !strings.HasSuffix(query, "LIMIT $4") {
+		t.Fatalf("expected the limit on the outer query, got %q", query)
+	}
+	// The empty page falls back to a plain count over the same bounds.
+	if len(fake.statements) != 2 || !strings.Contains(fake.statements[1], "SELECT COUNT(*) FROM finance_transactions WHERE true AND category = $1") {
+		t.Fatalf("expected a fallback count for an empty page, got %q", fake.statements)
+	}
+}
+
+func TestSeedDefaultsFillsOnlyEmptyTables(t *testing.T) {
+	fake, db := newFakeDB()
+	fake.populated = map[string]bool{"finance_assets": true}
+	repo := New(db)
+
+	seed := finance.SeedData{
+		Assets: []finance.Asset{{ID: "8c5e0c55-0d7c-4d7f-9a51-6f1f5b0f8d11", Name: "Savings", Category: "cash"}},
+		PropertyScenarios: []finance.PropertyPlannerScenario{
+			{ID: "0f0a5fd2-6f0e-4a43-a8b9-5b0c5e8f2d21", Type: "hdb"},
+		},
+	}
+	if err := repo.SeedDefaults(context.Background(), seed, nil); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+
+	var assetInserts, scenarioInserts int
+	for _, stmt := range fake.statements {
+		switch {
+		case strings.Contains(stmt, "INSERT INTO finance_assets"):
+			assetInserts++
+		case strings.Contains(stmt, "INSERT INTO property_planner_scenarios"):
+			scenarioInserts++
+		}
+	}
+	if assetInserts != 0 {
+		t.Fatalf("expected populated assets to be left alone, got %d inserts", assetInserts)
+	}
+	if scenarioInserts != 1 {
+		t.Fatalf("expected the empty scenarios table to be seeded, got %d inserts", scenarioInserts)
+	}
+}
+
+func TestCreateMapsPrimaryKeyViolationToDuplicateID(t *testing.T) {
+	fake, db := newFakeDB()
+	fake.queryErr = func(query string) error {
+		if strings.Contains(query, "INSERT INTO finance_assets") {
+			return &pgconn.PgError{Code: "23505", ConstraintName: "finance_assets_pkey"}
+		}
+		return nil
+	}
+	repo := New(db)
+
+	_, err := repo.Assets().Create(context.Background(), finance.Asset{ID: "asset-1", Name: "Savings", Category: "cash"})
+	if !errors.Is(err, repository.ErrDuplicateID) || !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected duplicate id conflict, got %v", err)
+	}
+}
+
+func TestLiabilityDeleteMapsLinkedAssetToConflict(t *testing.T) {
+	fake, db := newFakeDB()
+	fake.queryErr = func(query string) error {
+		if strings.Contains(query, "DELETE FROM finance_liabilities") {
+			return &pgconn.PgError{Code: "23503", ConstraintName: "finance_assets_linked_liability_id_fkey"}
+		}
+		return nil
+	}
+	fake.column = func(query string) []driver.Value {
+		if strings.Contains(query, "WHERE linked_liability_id=") {
+			return []driver.Value{"asset-1", "asset-2"}
+		}
+		return nil
+	}
+	repo := New(db)
+
+	err := repo.Liabilities().Delete(context.Background(), "liab-1")
+	var referenced *repository.ReferencedError
+	if !errors.Is(err, repository.ErrConflict) || !errors.As(err, &referenced) {
+		t.Fatalf("expected a referenced conflict while assets link to the liability, got %v", err)
+	}
+	if referenced.Entity != "asset" || !reflect.DeepEqual(referenced.IDs, []string{"asset-1", "asset-2"}) {
+		t.Fatalf("expected the linked assets to be named, got %+v", referenced)
+	}
+}
+
+func TestCashFlowStoresRejectUnknownFrequencyBeforeQuerying(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+	ctx := context.Background()
+
+	if _, err := repo.Incomes().Create(ctx, finance.Income{Source: "Salary", Amount: 100, Frequency: "fortnightly"}); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input creating income, got %v", err)
+	}
+	if _, err := repo.Incomes().Update(ctx, finance.Income{ID: "inc-1", Source: "Salary", Amount: 100}); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input updating income, got %v", err)
+	}
+	if _, err := repo.Expenses().Create(ctx, finance.Expense{Payee: "Rent", Amount: 100, Frequency: "daily"}); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input creating expense, got %v", err)
+	}
+	if _, err := repo.Expenses().Update(ctx, finance.Expense{ID: "exp-1", Payee: "Rent", Amount: 100, Frequency: "daily"}); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input updating expense, got %v", err)
+	}
+	if fake.count() != 0 {
+		t.Fatalf("expected no statements for invalid input, got %d", fake.count())
+	}
+}
+
+func TestExpenseUpdateManyLocksRowsAndReportsMissing(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+
+	called := false
+	updated, missing, err := repo.Expenses().UpdateMany(context.Background(), []string{"3c0d7a52-9e1b-4f6a-8d2c-5b4e3f2a1c0d", "8e5f1b7c-2d4a-4c9e-b6f3-0a1d2e3f4b5c"}, func(*finance.Expense) error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update many: %v", err)
+	}
+	if len(updated) != 0 || len(missing) != 2 || called {
+		t.Fatalf("expected both ids missing without applying changes, got updated=%v missing=%v", updated, missing)
+	}
+	for _, stmt := range fake.statements {
+		if strings.Contains(stmt, "FROM finance_expenses") && !strings.Contains(stmt, "FOR UPDATE") {
+			t.Fatalf("expected expense reads to lock rows, got %q", stmt)
+		}
+	}
+}
+
+func TestExpenseUpdateManyReportsMalformedIDsAsMissing(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+
+	valid := "3c0d7a52-9e1b-4f6a-8d2c-5b4e3f2a1c0d"
+	_, missing, err := repo.Expenses().UpdateMany(context.Background(), []string{"exp-1", valid}, func(*finance.Expense) error { return nil })
+	if err != nil {
+		t.Fatalf("expected a malformed id not to fail the batch, got %v", err)
+	}
+	if len(missing) != 2 || missing[0] != "exp-1" || missing[1] != valid {
+		t.Fatalf("expected both ids missing in request order, got %v", missing)
+	}
+	for _, args := range fake.args {
+		for _, arg := range args {
+			if arg == "exp-1" {
+				t.Fatalf("expected the malformed id never to reach postgres, got %#v", args)
+			}
+		}
+	}
+}
+
+func TestExpenseUpdateManyRetriesSerializationFailure(t *testing.T) {
+	fake, db := newFakeDB()
+	locks := 0
+	fake.queryErr = func(query string) error {
+		if !strings.Contains(query, "FOR UPDATE") {
+			return nil
+		}
+		locks++
+		if locks == 1 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	}
+	repo := New(db)
+
+	id := "3c0d7a52-9e1b-4f6a-8d2c-5b4e3f2a1c0d"
+	_, missing, err := repo.Expenses().UpdateMany(context.Background(), []string{id}, func(*finance.Expense) error { return nil })
+	if err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if locks != 2 {
+		t.Fatalf("expected exactly one retry, got %d attempts", locks)
+	}
+	if len(missing) != 1 || missing[0] != id {
+		t.Fatalf("expected the second attempt's result, got missing=%v", missing)
+	}
+}
+
+func TestRenameCashFlowCategoryRollsBackBothTablesOnFailure(t *testing.T) {
+	fake, db := newFakeDB()
+	fake.queryErr = func(query string) error {
+		if strings.HasPrefix(query, "UPDATE finance_expenses") {
+			return errors.New("expenses unavailable")
+		}
+		return nil
+	}
+	repo := New(db)
+
+	incomes, expenses, err := repo.RenameCashFlowCategory(context.Background(), "living", "household")
+	if err == nil || incomes != 0 || expenses != 0 {
+		t.Fatalf("expected the expenses failure to fail the rename, got %d, %d, %v", incomes, expenses, err)
+	}
+	if fake.count() != 2 || !strings.HasPrefix(fake.statements[0], "UPDATE finance_incomes") {
+		t.Fatalf("expected the incomes rename to run first in the same transaction, got %v", fake.statements)
+	}
+	if fake.commits != 0 || fake.rollbacks != 1 {
+		t.Fatalf("expected the incomes rename to be rolled back, got %d commits and %d rollbacks", fake.commits, fake.rollbacks)
+	}
+}
+
+func TestCreateOncePerMinuteRereadsTheWinnerFromThePrimary(t *testing.T) {
+	primary, primaryDB := newFakeDB()
+	replica, replicaDB := newFakeDB()
+	repo := NewWithReplica(primaryDB, replicaDB)
+
+	// The fake returns no rows, as Postgres does when ON CONFLICT skips the insert.
+	at := time.Date(2024, 5, 1, 9, 30, 5, 0, time.UTC)
+	if _, created, _ := repo.NetWorthSnapshots().CreateOncePerMinute(context.Background(), finance.NetWorthSnapshot{RecordedAt: at}); created {
+		t.Fatal("expected a skipped insert not to report a new snapshot")
+	}
+	if primary.count() != 2 || replica.count() != 0 {
+		t.Fatalf("expected the insert and the re-read on the primary, got primary=%d replica=%d", primary.count(), replica.count())
+	}
+	if !strings.Contains(primary.statements[0], "ON CONFLICT ((date_trunc('minute', recorded_at AT TIME ZONE 'UTC'))) DO NOTHING") {
+		t.Fatalf("expected the insert to defer to the per-minute index, got %s", primary.statements[0])
+	}
+	if !strings.Contains(primary.statements[1], "SELECT id") {
+		t.Fatalf("expected the winning snapshot to be read back, got %s", primary.statements[1])
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&pgconn.PgError{Code: "40001"}, true},
+		{fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{driver.ErrBadConn, true},
+		{&pgconn.PgError{Code: "23505"}, false},
+		{context.DeadlineExceeded, false},
+		{sql.ErrNoRows, false},
+	}
+	for _, tc := range cases {
+		if got := isRetryable(tc.err); got != tc.want {
+			t.Errorf("isRetryable(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestCheckViolationMapsToInvalidInput(t *testing.T) {
+	fake, db := newFakeDB()
+	fake.queryErr = func(query string) error {
+		if strings.Contains(query, "UPDATE finance_liabilities") {
+			return &pgconn.PgError{Code: "23514", ConstraintName: "finance_liabilities_interest_rate_apr_check"}
+		}
+		return nil
+	}
+	repo := New(db)
+
+	_, err := repo.Liabilities().Update(context.Background(), finance.Liability{ID: "liab-1", Name: "Card", Category: "credit", InterestRateAPR: -1})
+	if !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+}
+
+func TestAssetStoreRejectsNegativeValuesBeforeQuerying(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+	ctx := context.Background()
+
+	overdrawn := finance.Asset{ID: "asset-1", Name: "Checking", Category: "cash", CurrentValue: -50}
+	if _, err := repo.Assets().Create(ctx, overdrawn); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input creating asset, got %v", err)
+	}
+	if _, err := repo.Assets().Update(ctx, overdrawn); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input updating asset, got %v", err)
+	}
+	if fake.count() != 0 {
+		t.Fatalf("expected no statements for a negative asset, got %d", fake.count())
+	}
+}
+
+func TestGetManySkipsMalformedIDsAndQueriesOnce(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+	ctx := context.Background()
+
+	found, err := repo.Assets().GetMany(ctx, []string{"not-a-uuid", ""})
+	if err != nil || found == nil || len(found) != 0 {
+		t.Fatalf("expected an empty map, got %v (%v)", found, err)
+	}
+	if fake.count() != 0 {
+		t.Fatalf("expected no query when no id can match, got %d", fake.count())
+	}
+
+	ids := []string{"7f1c2a9e-4b4d-4f8e-9a55-1f0f4c3e2d10", "bogus", "{0f6b1c7e2d8a4e3f9b5c6d7e8f9a0b1c}"}
+	if _, err := repo.Transactions().GetMany(ctx, ids); err != nil {
+		t.Fatalf("get many: %v", err)
+	}
+	if fake.count() != 1 || !strings.Contains(fake.statements[0], "WHERE id = ANY($1::uuid[])") {
+		t.Fatalf("expected one ANY lookup, got %v", fake.statements)
+	}
+	got, ok := fake.args[0][0].([]string)
+	if !ok || len(got) != 2 || got[0] != ids[0] || got[1] != ids[2] {
+		t.Fatalf("expected only the uuid-shaped ids as $1, got %#v", fake.args[0])
+	}
+}
+
+// scenarioRow replays a stored payload through scanPropertyScenario.
+type scenarioRow struct {
+	payload   propertyScenarioDBPayload
+	updatedAt time.Time
+}
+
+func (r scenarioRow) Scan(dest ...any) error {
+	p := r.payload
+	values := []any{
+		p.ID, p.Type, p.Headline, p.Subheadline, p.LastRefreshed,
+		p.LoanInputsJSON, p.AmortizationJSON, p.SnapshotJSON, p.SummaryJSON,
+		p.TimelineJSON, p.MilestonesJSON, p.InsightsJSON, r.updatedAt,
+	}
+	for i, v := range values {
+		switch d := dest[i].(type) {
+		case *string:
+			*d = v.(string)
+		case *[]byte:
+			*d = v.([]byte)
+		case *time.Time:
+			*d = v.(time.Time)
+		default:
+			return fmt.Errorf("unexpected destination %T", d)
+		}
+	}
+	return nil
+}
+
+func largeScenario() finance.PropertyPlannerScenario {
+	scenario := finance.PropertyPlannerScenario{
+		ID:       "9b2f6c1e-4d3a-4e8b-9f21-6a7c5d4e3b2a",
+		Type:     "hdb",
+		Headline: "4-room resale",
+		Inputs:   finance.MortgageInputs{LoanAmount: 800000, LoanTermYears: 25, FixedRate: 2.6},
+	}
+	for year := range 40 {
+		scenario.Timeline = append(scenario.Timeline, finance.PropertyPlannerTimeline{
+			ID:          fmt.Sprintf("year-%d", year),
+			Year:        2024 + year,
+			Label:       fmt.Sprintf("Year %d", year+1),
+			CashOutlay:  12000,
+			LoanBalance: float64(800000 - year*20000),
+			Valuation:   float64(1000000 + year*15000),
+		})
+	}
+	return scenario
+}
+
+func TestScenarioPayloadRoundTripsThroughCompression(t *testing.T) {
+	scenario := largeScenario()
+	plain, err := buildScenarioPayload(scenario, false)
+	if err != nil {
+		t.Fatalf("build plain payload: %v", err)
+	}
+	compressed, err := buildScenarioPayload(scenario, true)
+	if err != nil {
+		t.Fatalf("build compressed payload: %v", err)
+	}
+
+	if !bytes.HasPrefix(compressed.TimelineJSON, []byte(`{"$gzip":`)) || len(compressed.TimelineJSON) >= len(plain.TimelineJSON) {
+		t.Fatalf("expected a smaller compressed timeline, got %d bytes from %d", len(compressed.TimelineJSON), len(plain.TimelineJSON))
+	}
+	if !bytes.Equal(compressed.LoanInputsJSON, plain.LoanInputsJSON) {
+		t.Fatalf("expected small documents to stay plain, got %s", compressed.LoanInputsJSON)
+	}
+
+	want, err := scanPropertyScenario(scenarioRow{payload: plain})
+	if err != nil {
+		t.Fatalf("scan plain payload: %v", err)
+	}
+	got, err := scanPropertyScenario(scenarioRow{payload: compressed})
+	if err != nil {
+		t.Fatalf("scan compressed payload: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) || len(got.Timeline) != 40 {
+		t.Fatalf("compressed scenario did not round-trip:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDecompressJSONPassesPlainDocumentsThrough(t *testing.T) {
+	for _, doc := range []string{`[]`, `{"loanAmount": 500000}`, `{"$gzip": "not base64", "other": 1}`} {
+		got, err := decompressJSON([]byte(doc))
+		if err != nil || string(got) != doc {
+			t.Fatalf("decompressJSON(%s) = %s, %v; want it unchanged", doc, got, err)
+		}
+	}
+	if _, err := decompressJSON([]byte(`{"$gzip": "!!!"}`)); err == nil {
+		t.Fatal("expected a corrupt envelope to fail")
+	}
+}
+
+func TestScanPropertyScenarioReportsCorruptData(t *testing.T) {
+	valid, err := buildScenarioPayload(largeScenario(), false)
+	if err != nil {
+		t.Fatalf("build payload: %v", err)
+	}
+
+	wrongShape := valid
+	wrongShape.SummaryJSON = []byte(`{"label":"not an array"}`)
+	if _, err := scanPropertyScenario(scenarioRow{payload: wrongShape}); !errors.Is(err, repository.ErrInvalidData) || !strings.Contains(err.Error(), "summary") {
+		t.Fatalf("expected invalid data naming the summary column, got %v", err)
+	}
+
+	backwards := valid
+	backwards.TimelineJSON = []byte(`[{"year":2030},{"year":2024}]`)
+	if _, err := scanPropertyScenario(scenarioRow{payload: backwards}); !errors.Is(err, repository.ErrInvalidData) {
+		t.Fatalf("expected invalid data for a timeline going backwards, got %v", err)
+	}
+}
+
+func TestScanPropertyScenarioDecodesNullColumnsAsEmpty(t *testing.T) {
+	sparse, err := buildScenarioPayload(largeScenario(), false)
+	if err != nil {
+		t.Fatalf("build payload: %v", err)
+	}
+	sparse.AmortizationJSON = nil
+	sparse.SnapshotJSON = nil
+	sparse.SummaryJSON = nil
+	sparse.TimelineJSON = []byte{}
+	sparse.MilestonesJSON = []byte(`null`)
+	sparse.InsightsJSON = nil
+
+	got, err := scanPropertyScenario(scenarioRow{payload: sparse})
+	if err != nil {
+		t.Fatalf("expected NULL columns to decode, got %v", err)
+	}
+	if got.Inputs.LoanAmount != 800000 || got.Snapshot != (finance.MortgageSnapshot{}) {
+		t.Fatalf("expected stored inputs and an empty snapshot, got %+v", got)
+	}
+	if got.Summary == nil || got.Timeline == nil || got.Milestones == nil || got.Insights == nil ||
+		got.Amortization.BalancePoints == nil || got.Amortization.Composition == nil {
+		t.Fatalf("expected empty rather than nil slices, got %+v", got)
+	}
+}
+
+func TestBuildScenarioPayloadNeverWritesNull(t *testing.T) {
+	payload, err := buildScenarioPayload(finance.PropertyPlannerScenario{Type: "hdb", Headline: "Empty"}, false)
+	if err != nil {
+		t.Fatalf("build payload: %v", err)
+	}
+	docs := map[string][]byte{
+		"loan_inputs":  payload.LoanInputsJSON,
+		"amortization": payload.AmortizationJSON,
+		"snapshot":     payload.SnapshotJSON,
+		"summary":      payload.SummaryJSON,
+		"timeline":     payload.TimelineJSON,
+		"milestones":   payload.MilestonesJSON,
+		"insights":     payload.InsightsJSON,
+	}
+	for column, doc := range docs {
+		if len(doc) == 0 || bytes.Contains(doc, []byte("null")) {
+			t.Fatalf("expected %s to be a non-null document, got %q", column, doc)
+		}
+	}
+}
+
+func TestPropertyScenarioStoreRejectsOutOfRangeInputsBeforeQuerying(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+	ctx := context.Background()
+
+	scenario := largeScenario()
+	scenario.Inputs.LoanTermYears = 50
+	if _, err := repo.PropertyPlanner().Create(ctx, scenario); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input creating scenario, got %v", err)
+	}
+	scenario.Inputs.LoanTermYears = 25
+	scenario.Inputs.FloatingRate = -1
+	if _, err := repo.PropertyPlanner().Update(ctx, scenario); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input updating scenario, got %v", err)
+	}
+	if _, _, err := repo.PropertyPlanner().UpsertByType(ctx, scenario); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected invalid input upserting scenario, got %v", err)
+	}
+	if fake.count() != 0 {
+		t.Fatalf("expected no statements for invalid inputs, got %d", fake.count())
+	}
+}
+
+func TestPropertyScenarioUpsertByTypeResolvesConflictsOnType(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+
+	scenario := largeScenario()
+	scenario.ID = ""
+	if _, _, err := repo.PropertyPlanner().UpsertByType(context.Background(), scenario); err == nil {
+		t.Fatal("expected the fake's empty result to surface as an error")
+	}
+	if fake.count() != 1 {
+		t.Fatalf("expected a single statement, got %d", fake.count())
+	}
+	stmt := fake.statements[0]
+	if !strings.Contains(stmt, "ON CONFLICT (property_type) DO UPDATE") || !strings.Contains(stmt, "xmax = 0") {
+		t.Fatalf("expected an upsert on property_type reporting inserts, got %q", stmt)
+	}
+	if strings.Contains(stmt, "SET id=") {
+		t.Fatalf("expected the existing row to keep its id, got %q", stmt)
+	}
+}
+
+func TestPropertyScenarioUpdateManyLocksRowsAndReportsMissing(t *testing.T) {
+	fake, db := newFakeDB()
+	repo := New(db)
+
+	// "s1" is not a uuid, so it must be reported missing without reaching postgres.
+	updated, missing, err := repo.PropertyPlanner().UpdateMany(context.Background(), []string{"s1", "5a4b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c1d"}, func(*finance.PropertyPlannerScenario) error {
+		t.Fatal("change applied to a missing scenario")
+		return nil
+	})
+	if err != nil || len(updated) != 0 || len(missing) != 2 || missing[0] != "s1" {
+		t.Fatalf("expected both ids missing, got updated=%v missing=%v err=%v", updated, missing, err)
+	}
+	for _, args := range fake.args {
+		for _, arg := range args {
+			if arg == "s1" {
+				t.Fatalf("expected the malformed id never to reach postgres, got %#v", args)
+			}
+		}
+	}
+	for _, stmt := range fake.statements {
+		if strings.Contains(stmt, "FROM property_planner_scenarios") && !strings.Contains(stmt, "FOR UPDATE") {
+			t.Fatalf("expected scenario reads to lock rows, got %q", stmt)
+		}
+	}
+}
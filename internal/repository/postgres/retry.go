@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/jcleow/assetra2/internal/logging"
+)
+
+const (
+	// maxWriteAttempts bounds how often a transactional write is tried in total.
+	maxWriteAttempts = 3
+	// retryBackoff is the pause before the first retry; it doubles for each one after.
+	retryBackoff = 10 * time.Millisecond
+)
+
+// isRetryable reports whether err is a transient failure that a fresh attempt of the
+// same transaction can be expected to clear: a serialization failure or deadlock
+// under concurrent updates, or a connection error raised before anything was sent.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 40001 serialization_failure, 40P01 deadlock_detected.
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	return errors.Is(err, driver.ErrBadConn) || pgconn.SafeToRetry(err)
+}
+
+// withRetry runs fn, running it again with a growing pause while it fails with a
+// retryable error, for at most maxWriteAttempts attempts. fn must open and finish its
+// own transaction so each attempt starts clean. Retries stop early once ctx is done.
+func withRetry(ctx context.Context, opts *options, op string, fn func() error) error {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if attempt == maxWriteAttempts || !isRetryable(err) {
+			return err
+		}
+		if opts.logger != nil {
+			attrs := []any{"op", op, "attempt", attempt, "err", err}
+			if requestID := logging.RequestID(ctx); requestID != "" {
+				attrs = append(attrs, "request_id", requestID)
+			}
+			opts.logger.Warn("retrying write", attrs...)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
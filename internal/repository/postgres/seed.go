@@ -7,153 +7,345 @@ import (
 	"time"
 
 	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
 )
 
-// SeedDefaults inserts the provided seed data if the finance tables are empty.
+// SeedDefaults seeds each finance table from the provided data when that table is
+// empty, so categories added to the seed later still reach existing deployments.
+// Tables that already hold rows are left untouched. All inserts share one transaction.
 func (r *Repository) SeedDefaults(ctx context.Context, seed finance.SeedData, logger *slog.Logger) error {
-	hasData, err := r.hasExistingData(ctx)
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return err
 	}
-	if hasData {
+	defer tx.Rollback()
+
+	now := r.clock.Now().UTC()
+	// Accounts go first so asset and liability account_id references resolve. When the
+	// accounts table is already populated the seed accounts are skipped, so links to
+	// them are dropped rather than failing the foreign key.
+	seedAccounts, err := tableIsEmpty(ctx, tx, "finance_accounts")
+	if err != nil {
+		return err
+	}
+	if !seedAccounts {
+		seed = unlinkAccounts(seed)
+	}
+	// Likewise liabilities precede assets, and asset links are dropped when the seed
+	// liabilities will not be inserted.
+	seedLiabilities, err := tableIsEmpty(ctx, tx, "finance_liabilities")
+	if err != nil {
+		return err
+	}
+	if !seedLiabilities {
+		seed = unlinkLiabilities(seed)
+	}
+
+	steps := []struct {
+		table  string
+		insert func() error
+	}{
+		{"finance_accounts", func() error { return insertAccounts(ctx, tx, seed.Accounts, now) }},
+		{"finance_liabilities", func() error { return insertLiabilities(ctx, tx, seed.Liabilities, now) }},
+		{"finance_assets", func() error { return insertAssets(ctx, tx, seed.Assets, now) }},
+		{"finance_incomes", func() error { return insertIncomes(ctx, tx, seed.Incomes, now) }},
+		{"finance_expenses", func() error { return insertExpenses(ctx, tx, seed.Expenses, now) }},
+		{"property_planner_scenarios", func() error {
+			return insertPropertyScenarios(ctx, tx, seed.PropertyScenarios, now, r.opts.compressScenarios)
+		}},
+		{"finance_goals", func() error { return insertGoals(ctx, tx, seed.Goals, now) }},
+		{"finance_transactions", func() error { return insertTransactions(ctx, tx, seed.Transactions, now) }},
+	}
+
+	var seeded []string
+	for _, step := range steps {
+		empty, err := tableIsEmpty(ctx, tx, step.table)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			continue
+		}
+		if err := step.insert(); err != nil {
+			return err
+		}
+		seeded = append(seeded, step.table)
+	}
+	if len(seeded) == 0 {
 		return nil
 	}
 
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if logger != nil {
+		logger.Info("seeded finance data into postgres", "tables", seeded)
+	}
+	return nil
+}
+
+// unlinkAccounts clears account references from seed holdings and transactions.
+func unlinkAccounts(seed finance.SeedData) finance.SeedData {
+	seed.Assets = append([]finance.Asset(nil), seed.Assets...)
+	for i := range seed.Assets {
+		seed.Assets[i].AccountID = ""
+	}
+	seed.Liabilities = append([]finance.Liability(nil), seed.Liabilities...)
+	for i := range seed.Liabilities {
+		seed.Liabilities[i].AccountID = ""
+	}
+	seed.Transactions = append([]finance.Transaction(nil), seed.Transactions...)
+	for i := range seed.Transactions {
+		seed.Transactions[i].AccountID = ""
+	}
+	return seed
+}
+
+// unlinkLiabilities clears liability references from seed assets.
+func unlinkLiabilities(seed finance.SeedData) finance.SeedData {
+	seed.Assets = append([]finance.Asset(nil), seed.Assets...)
+	for i := range seed.Assets {
+		seed.Assets[i].LinkedLiabilityID = ""
+	}
+	return seed
+}
+
+// ImportDataset writes the dataset in a single transaction. Replace mode clears the
+// finance tables first; merge mode upserts by ID. The transaction is retried if it
+// hits a serialization failure or deadlock.
+func (r *Repository) ImportDataset(ctx context.Context, data finance.SeedData, mode repository.ImportMode) error {
+	return withRetry(ctx, r.opts, "dataset.import", func() error {
+		return r.importDataset(ctx, data, mode)
+	})
+}
+
+// importDataset makes a single attempt at ImportDataset.
+func (r *Repository) importDataset(ctx context.Context, data finance.SeedData, mode repository.ImportMode) error {
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	if err := insertAssets(ctx, tx, seed.Assets); err != nil {
+	now := r.clock.Now().UTC()
+	if mode == repository.ImportReplace {
+		for _, tbl := range financeTables {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM "+tbl); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := insertAccounts(ctx, tx, data.Accounts, now); err != nil {
 		return err
 	}
-	if err := insertLiabilities(ctx, tx, seed.Liabilities); err != nil {
+	// Liabilities precede assets so linked_liability_id references resolve.
+	if err := insertLiabilities(ctx, tx, data.Liabilities, now); err != nil {
 		return err
 	}
-	if err := insertIncomes(ctx, tx, seed.Incomes); err != nil {
+	if err := insertAssets(ctx, tx, data.Assets, now); err != nil {
 		return err
 	}
-	if err := insertExpenses(ctx, tx, seed.Expenses); err != nil {
+	if err := insertIncomes(ctx, tx, data.Incomes, now); err != nil {
 		return err
 	}
-	if err := insertPropertyScenarios(ctx, tx, seed.PropertyScenarios); err != nil {
+	if err := insertExpenses(ctx, tx, data.Expenses, now); err != nil {
 		return err
 	}
-
-	if err := tx.Commit(); err != nil {
+	if err := insertPropertyScenarios(ctx, tx, data.PropertyScenarios, now, r.opts.compressScenarios); err != nil {
+		return err
+	}
+	if err := insertGoals(ctx, tx, data.Goals, now); err != nil {
+		return err
+	}
+	if err := insertTransactions(ctx, tx, data.Transactions, now); err != nil {
 		return err
 	}
+	return tx.Commit()
+}
 
-	if logger != nil {
-		logger.Info("seeded finance data into postgres")
+// RenameCashFlowCategory renames the category in incomes and expenses in one
+// transaction, so a failure part-way leaves both tables untouched. The transaction is
+// retried if it hits a serialization failure or deadlock.
+func (r *Repository) RenameCashFlowCategory(ctx context.Context, from, to string) (int, int, error) {
+	if from == "" || to == "" {
+		return 0, 0, repository.ErrInvalidInput
 	}
-	return nil
+	var incomes, expenses int
+	err := withRetry(ctx, r.opts, "cashflow.renameCategory", func() error {
+		var err error
+		incomes, expenses, err = r.renameCashFlowCategory(ctx, from, to)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return incomes, expenses, nil
 }
 
-func (r *Repository) hasExistingData(ctx context.Context) (bool, error) {
-	tables := []string{
-		"finance_assets",
-		"finance_liabilities",
-		"finance_incomes",
-		"finance_expenses",
-		"property_planner_scenarios",
+// renameCashFlowCategory makes a single attempt at RenameCashFlowCategory.
+func (r *Repository) renameCashFlowCategory(ctx context.Context, from, to string) (int, int, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, 0, err
 	}
-	for _, tbl := range tables {
-		var count int
-		if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tbl).Scan(&count); err != nil {
-			return false, err
+	defer tx.Rollback()
+
+	now := r.clock.Now().UTC()
+	counts := make([]int, 2)
+	for i, table := range []string{"finance_incomes", "finance_expenses"} {
+		result, err := tx.ExecContext(ctx, "UPDATE "+table+" SET category=$2, updated_at=$3 WHERE category=$1", from, to, now)
+		if err != nil {
+			return 0, 0, err
 		}
-		if count > 0 {
-			return true, nil
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, 0, err
 		}
+		counts[i] = int(rows)
 	}
-	return false, nil
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return counts[0], counts[1], nil
+}
+
+var financeTables = []string{
+	"finance_assets",
+	"finance_liabilities",
+	"finance_incomes",
+	"finance_expenses",
+	"property_planner_scenarios",
+	"finance_goals",
+	"finance_transactions",
+	// Accounts are deleted last; assets, liabilities and transactions reference them.
+	"finance_accounts",
+}
+
+func tableIsEmpty(ctx context.Context, tx *sql.Tx, table string) (bool, error) {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+table+")").Scan(&exists); err != nil {
+		return false, err
+	}
+	return !exists, nil
 }
 
-func insertAssets(ctx context.Context, tx *sql.Tx, assets []finance.Asset) error {
+func insertAssets(ctx context.Context, tx *sql.Tx, assets []finance.Asset, now time.Time) error {
 	for _, asset := range assets {
 		asset.ID = ensureID(asset.ID)
 		if asset.UpdatedAt.IsZero() {
-			asset.UpdatedAt = time.Now().UTC()
+			asset.UpdatedAt = now
 		}
 		if _, err := tx.ExecContext(ctx, `
-			INSERT INTO finance_assets (id, name, category, current_value, annual_growth_rate, notes, updated_at)
-			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
-		`, asset.ID, asset.Name, asset.Category, asset.CurrentValue, asset.AnnualGrowthRate, asset.Notes, asset.UpdatedAt); err != nil {
+			INSERT INTO finance_assets (id, name, category, current_value, annual_growth_rate, notes, updated_at, currency, account_id, linked_liability_id)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, '')::uuid, NULLIF($10, '')::uuid)
+			ON CONFLICT (id) DO UPDATE
+			SET name=EXCLUDED.name, category=EXCLUDED.category, current_value=EXCLUDED.current_value,
+			    annual_growth_rate=EXCLUDED.annual_growth_rate, notes=EXCLUDED.notes,
+			    updated_at=EXCLUDED.updated_at, currency=EXCLUDED.currency, account_id=EXCLUDED.account_id,
+			    linked_liability_id=EXCLUDED.linked_liability_id
+		`, asset.ID, asset.Name, asset.Category, asset.CurrentValue, asset.AnnualGrowthRate, asset.Notes, asset.UpdatedAt, asset.Currency, asset.AccountID, asset.LinkedLiabilityID); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func insertLiabilities(ctx context.Context, tx *sql.Tx, items []finance.Liability) error {
+func insertLiabilities(ctx context.Context, tx *sql.Tx, items []finance.Liability, now time.Time) error {
 	for _, liab := range items {
 		liab.ID = ensureID(liab.ID)
 		if liab.UpdatedAt.IsZero() {
-			liab.UpdatedAt = time.Now().UTC()
+			liab.UpdatedAt = now
 		}
 		if _, err := tx.ExecContext(ctx, `
-			INSERT INTO finance_liabilities (id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
-		`, liab.ID, liab.Name, liab.Category, liab.CurrentBalance, liab.InterestRateAPR, liab.MinimumPayment, liab.Notes, liab.UpdatedAt); err != nil {
+			INSERT INTO finance_liabilities (id, name, category, current_balance, interest_rate_apr, minimum_payment, notes, updated_at, currency, account_id)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, NULLIF($9, ''), NULLIF($10, '')::uuid)
+			ON CONFLICT (id) DO UPDATE
+			SET name=EXCLUDED.name, category=EXCLUDED.category, current_balance=EXCLUDED.current_balance,
+			    interest_rate_apr=EXCLUDED.interest_rate_apr, minimum_payment=EXCLUDED.minimum_payment,
+			    notes=EXCLUDED.notes, updated_at=EXCLUDED.updated_at, currency=EXCLUDED.currency,
+			    account_id=EXCLUDED.account_id
+		`, liab.ID, liab.Name, liab.Category, liab.CurrentBalance, liab.InterestRateAPR, liab.MinimumPayment, liab.Notes, liab.UpdatedAt, liab.Currency, liab.AccountID); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func insertIncomes(ctx context.Context, tx *sql.Tx, items []finance.Income) error {
+func insertIncomes(ctx context.Context, tx *sql.Tx, items []finance.Income, now time.Time) error {
 	for _, income := range items {
 		income.ID = ensureID(income.ID)
 		if income.StartDate.IsZero() {
-			income.StartDate = time.Now().UTC()
+			income.StartDate = now
 		}
 		if income.UpdatedAt.IsZero() {
-			income.UpdatedAt = time.Now().UTC()
+			income.UpdatedAt = now
 		}
 		if _, err := tx.ExecContext(ctx, `
-			INSERT INTO finance_incomes (id, source, amount, frequency, start_date, category, notes, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
-		`, income.ID, income.Source, income.Amount, income.Frequency, income.StartDate, income.Category, income.Notes, income.UpdatedAt); err != nil {
+			INSERT INTO finance_incomes (id, source, amount, frequency, start_date, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, NULLIF($11, ''), NULLIF($12, ''))
+			ON CONFLICT (id) DO UPDATE
+			SET source=EXCLUDED.source, amount=EXCLUDED.amount, frequency=EXCLUDED.frequency,
+			    start_date=EXCLUDED.start_date, category=EXCLUDED.category, notes=EXCLUDED.notes,
+			    day_of_month=EXCLUDED.day_of_month, day_of_week=EXCLUDED.day_of_week,
+			    updated_at=EXCLUDED.updated_at, currency=EXCLUDED.currency,
+			    recurrence_interval=EXCLUDED.recurrence_interval
+		`, income.ID, income.Source, income.Amount, income.Frequency, income.StartDate, income.Category, income.Notes, income.DayOfMonth, income.DayOfWeek, income.UpdatedAt, income.Currency, income.Interval); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func insertExpenses(ctx context.Context, tx *sql.Tx, items []finance.Expense) error {
+func insertExpenses(ctx context.Context, tx *sql.Tx, items []finance.Expense, now time.Time) error {
 	for _, expense := range items {
 		expense.ID = ensureID(expense.ID)
 		if expense.UpdatedAt.IsZero() {
-			expense.UpdatedAt = time.Now().UTC()
+			expense.UpdatedAt = now
 		}
 		if _, err := tx.ExecContext(ctx, `
-			INSERT INTO finance_expenses (id, payee, amount, frequency, category, notes, updated_at)
-			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
-		`, expense.ID, expense.Payee, expense.Amount, expense.Frequency, expense.Category, expense.Notes, expense.UpdatedAt); err != nil {
+			INSERT INTO finance_expenses (id, payee, amount, frequency, category, notes, day_of_month, day_of_week, updated_at, currency, recurrence_interval)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''))
+			ON CONFLICT (id) DO UPDATE
+			SET payee=EXCLUDED.payee, amount=EXCLUDED.amount, frequency=EXCLUDED.frequency,
+			    category=EXCLUDED.category, notes=EXCLUDED.notes,
+			    day_of_month=EXCLUDED.day_of_month, day_of_week=EXCLUDED.day_of_week,
+			    updated_at=EXCLUDED.updated_at, currency=EXCLUDED.currency,
+			    recurrence_interval=EXCLUDED.recurrence_interval
+		`, expense.ID, expense.Payee, expense.Amount, expense.Frequency, expense.Category, expense.Notes, expense.DayOfMonth, expense.DayOfWeek, expense.UpdatedAt, expense.Currency, expense.Interval); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func insertPropertyScenarios(ctx context.Context, tx *sql.Tx, items []finance.PropertyPlannerScenario) error {
+func insertPropertyScenarios(ctx context.Context, tx *sql.Tx, items []finance.PropertyPlannerScenario, now time.Time, compress bool) error {
 	for _, scenario := range items {
 		scenario.ID = ensureID(scenario.ID)
 		if scenario.UpdatedAt.IsZero() {
-			scenario.UpdatedAt = time.Now().UTC()
+			scenario.UpdatedAt = now
 		}
-		payload, err := buildScenarioPayload(scenario)
+		payload, err := buildScenarioPayload(scenario, compress)
 		if err != nil {
 			return err
 		}
+		// property_type is unique, so an imported scenario replaces any other row of its type.
+		if _, err := tx.ExecContext(ctx, `DELETE FROM property_planner_scenarios WHERE property_type=$1 AND id<>$2`, payload.Type, payload.ID); err != nil {
+			return err
+		}
 		if _, err := tx.ExecContext(ctx, `
 			INSERT INTO property_planner_scenarios (
 				id, property_type, headline, subheadline, last_refreshed,
 				loan_inputs, amortization, snapshot, summary, timeline, milestones, insights, updated_at
 			)
 			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+			ON CONFLICT (id) DO UPDATE
+			SET property_type=EXCLUDED.property_type, headline=EXCLUDED.headline,
+			    subheadline=EXCLUDED.subheadline, last_refreshed=EXCLUDED.last_refreshed,
+			    loan_inputs=EXCLUDED.loan_inputs, amortization=EXCLUDED.amortization,
+			    snapshot=EXCLUDED.snapshot, summary=EXCLUDED.summary, timeline=EXCLUDED.timeline,
+			    milestones=EXCLUDED.milestones, insights=EXCLUDED.insights, updated_at=EXCLUDED.updated_at
 		`,
 			payload.ID,
 			payload.Type,
@@ -174,3 +366,60 @@ func insertPropertyScenarios(ctx context.Context, tx *sql.Tx, items []finance.Pr
 	}
 	return nil
 }
+
+func insertGoals(ctx context.Context, tx *sql.Tx, items []finance.Goal, now time.Time) error {
+	for _, goal := range items {
+		goal.ID = ensureID(goal.ID)
+		if goal.UpdatedAt.IsZero() {
+			goal.UpdatedAt = now
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO finance_goals (id, name, target_amount, target_date, asset_category, notes, updated_at)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+			ON CONFLICT (id) DO UPDATE
+			SET name=EXCLUDED.name, target_amount=EXCLUDED.target_amount, target_date=EXCLUDED.target_date,
+			    asset_category=EXCLUDED.asset_category, notes=EXCLUDED.notes, updated_at=EXCLUDED.updated_at
+		`, goal.ID, goal.Name, goal.TargetAmount, goal.TargetDate, goal.AssetCategory, goal.Notes, goal.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertAccounts(ctx context.Context, tx *sql.Tx, items []finance.Account, now time.Time) error {
+	for _, account := range items {
+		account.ID = ensureID(account.ID)
+		if account.UpdatedAt.IsZero() {
+			account.UpdatedAt = now
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO finance_accounts (id, name, institution, updated_at)
+			VALUES ($1, $2, NULLIF($3, ''), $4)
+			ON CONFLICT (id) DO UPDATE
+			SET name=EXCLUDED.name, institution=EXCLUDED.institution, updated_at=EXCLUDED.updated_at
+		`, account.ID, account.Name, account.Institution, account.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertTransactions(ctx context.Context, tx *sql.Tx, items []finance.Transaction, now time.Time) error {
+	for _, txn := range items {
+		txn.ID = ensureID(txn.ID)
+		if txn.UpdatedAt.IsZero() {
+			txn.UpdatedAt = now
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO finance_transactions (id, occurred_at, amount, currency, direction, category, account_id, memo, updated_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, '')::uuid, NULLIF($8, ''), $9)
+			ON CONFLICT (id) DO UPDATE
+			SET occurred_at=EXCLUDED.occurred_at, amount=EXCLUDED.amount, currency=EXCLUDED.currency,
+			    direction=EXCLUDED.direction, category=EXCLUDED.category, account_id=EXCLUDED.account_id,
+			    memo=EXCLUDED.memo, updated_at=EXCLUDED.updated_at
+		`, txn.ID, txn.Date, txn.Amount, txn.Currency, txn.Direction, txn.Category, txn.AccountID, txn.Memo, txn.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jcleow/assetra2/internal/finance"
 )
@@ -12,42 +15,90 @@ var (
 	ErrNotFound = errors.New("repository: not found")
 	// ErrInvalidInput is returned when create/update payloads are malformed.
 	ErrInvalidInput = errors.New("repository: invalid input")
+	// ErrInvalidData is returned when a stored row cannot be read back as a valid entity.
+	ErrInvalidData = errors.New("repository: invalid stored data")
+	// ErrConflict is returned when a change would break a reference held by another entity.
+	ErrConflict = errors.New("repository: conflict")
+	// ErrDuplicateID is returned when a create supplies an ID that is already taken. It
+	// wraps ErrConflict.
+	ErrDuplicateID = fmt.Errorf("%w: id already exists", ErrConflict)
 )
 
+// ReferencedError is the ErrConflict returned when a delete is blocked by other
+// entities that still reference the target. Entity names their kind, e.g. "asset",
+// and IDs lists them in ascending order.
+type ReferencedError struct {
+	Entity string
+	IDs    []string
+}
+
+func (e *ReferencedError) Error() string {
+	return fmt.Sprintf("%v: referenced by %s %s", ErrConflict, e.Entity, strings.Join(e.IDs, ", "))
+}
+
+func (e *ReferencedError) Unwrap() error { return ErrConflict }
+
+// CollectionMeta summarises a collection so polling clients can tell whether anything
+// changed without fetching it. LastUpdatedAt is nil for an empty collection.
+type CollectionMeta struct {
+	Count         int        `json:"count"`
+	LastUpdatedAt *time.Time `json:"lastUpdatedAt"`
+}
+
 // AssetStore defines CRUD operations for assets.
 type AssetStore interface {
 	List(ctx context.Context) ([]finance.Asset, error)
 	Get(ctx context.Context, id string) (finance.Asset, error)
+	// GetMany looks up ids in one call, keyed by id; unknown ids are absent, not an error.
+	GetMany(ctx context.Context, ids []string) (map[string]finance.Asset, error)
 	Create(ctx context.Context, asset finance.Asset) (finance.Asset, error)
 	Update(ctx context.Context, asset finance.Asset) (finance.Asset, error)
 	Delete(ctx context.Context, id string) error
+	Meta(ctx context.Context) (CollectionMeta, error)
+	// Categories lists the distinct non-empty categories in use, in ascending byte order.
+	Categories(ctx context.Context) ([]string, error)
 }
 
-// LiabilityStore defines CRUD operations for liabilities.
+// LiabilityStore defines CRUD operations for liabilities. Delete returns a
+// *ReferencedError listing the assets while any asset still links to the liability.
 type LiabilityStore interface {
 	List(ctx context.Context) ([]finance.Liability, error)
 	Get(ctx context.Context, id string) (finance.Liability, error)
+	// GetMany looks up ids in one call, keyed by id; unknown ids are absent, not an error.
+	GetMany(ctx context.Context, ids []string) (map[string]finance.Liability, error)
 	Create(ctx context.Context, liability finance.Liability) (finance.Liability, error)
 	Update(ctx context.Context, liability finance.Liability) (finance.Liability, error)
 	Delete(ctx context.Context, id string) error
+	Meta(ctx context.Context) (CollectionMeta, error)
+	// Categories lists the distinct non-empty categories in use, in ascending byte order.
+	Categories(ctx context.Context) ([]string, error)
 }
 
 // IncomeStore defines CRUD operations for incomes.
 type IncomeStore interface {
 	List(ctx context.Context) ([]finance.Income, error)
 	Get(ctx context.Context, id string) (finance.Income, error)
+	// GetMany looks up ids in one call, keyed by id; unknown ids are absent, not an error.
+	GetMany(ctx context.Context, ids []string) (map[string]finance.Income, error)
 	Create(ctx context.Context, income finance.Income) (finance.Income, error)
 	Update(ctx context.Context, income finance.Income) (finance.Income, error)
 	Delete(ctx context.Context, id string) error
+	Meta(ctx context.Context) (CollectionMeta, error)
 }
 
 // ExpenseStore defines CRUD operations for expenses.
 type ExpenseStore interface {
 	List(ctx context.Context) ([]finance.Expense, error)
 	Get(ctx context.Context, id string) (finance.Expense, error)
+	// GetMany looks up ids in one call, keyed by id; unknown ids are absent, not an error.
+	GetMany(ctx context.Context, ids []string) (map[string]finance.Expense, error)
 	Create(ctx context.Context, expense finance.Expense) (finance.Expense, error)
 	Update(ctx context.Context, expense finance.Expense) (finance.Expense, error)
 	Delete(ctx context.Context, id string) error
+	Meta(ctx context.Context) (CollectionMeta, error)
+	// UpdateMany applies change to each expense in ids atomically. IDs that do not exist
+	// are returned in missing; if change fails or leaves an expense invalid, nothing is written.
+	UpdateMany(ctx context.Context, ids []string, change func(*finance.Expense) error) (updated []finance.Expense, missing []string, err error)
 }
 
 // PropertyPlannerStore defines CRUD operations for property planner scenarios.
@@ -57,9 +108,124 @@ type PropertyPlannerStore interface {
 	GetByType(ctx context.Context, scenarioType string) (finance.PropertyPlannerScenario, error)
 	Create(ctx context.Context, scenario finance.PropertyPlannerScenario) (finance.PropertyPlannerScenario, error)
 	Update(ctx context.Context, scenario finance.PropertyPlannerScenario) (finance.PropertyPlannerScenario, error)
+	// UpsertByType replaces the scenario whose type matches scenario.Type, keeping its
+	// ID, or creates one if there is none. created reports which branch ran.
+	UpsertByType(ctx context.Context, scenario finance.PropertyPlannerScenario) (saved finance.PropertyPlannerScenario, created bool, err error)
+	Delete(ctx context.Context, id string) error
+	Meta(ctx context.Context) (CollectionMeta, error)
+	// UpdateMany applies change to each scenario in ids atomically, with the same
+	// contract as ExpenseStore.UpdateMany.
+	UpdateMany(ctx context.Context, ids []string, change func(*finance.PropertyPlannerScenario) error) (updated []finance.PropertyPlannerScenario, missing []string, err error)
+}
+
+// GoalStore defines CRUD operations for savings goals.
+type GoalStore interface {
+	List(ctx context.Context) ([]finance.Goal, error)
+	Get(ctx context.Context, id string) (finance.Goal, error)
+	// GetMany looks up ids in one call, keyed by id; unknown ids are absent, not an error.
+	GetMany(ctx context.Context, ids []string) (map[string]finance.Goal, error)
+	Create(ctx context.Context, goal finance.Goal) (finance.Goal, error)
+	Update(ctx context.Context, goal finance.Goal) (finance.Goal, error)
+	Delete(ctx context.Context, id string) error
+	Meta(ctx context.Context) (CollectionMeta, error)
+}
+
+// AccountStore defines CRUD operations for accounts. Delete returns ErrConflict while
+// any asset, liability or transaction still references the account.
+type AccountStore interface {
+	List(ctx context.Context) ([]finance.Account, error)
+	Get(ctx context.Context, id string) (finance.Account, error)
+	// GetMany looks up ids in one call, keyed by id; unknown ids are absent, not an error.
+	GetMany(ctx context.Context, ids []string) (map[string]finance.Account, error)
+	Create(ctx context.Context, account finance.Account) (finance.Account, error)
+	Update(ctx context.Context, account finance.Account) (finance.Account, error)
 	Delete(ctx context.Context, id string) error
+	Meta(ctx context.Context) (CollectionMeta, error)
+}
+
+// TransactionCursor marks a position in the newest-first ledger ordering.
+type TransactionCursor struct {
+	Date time.Time
+	ID   string
 }
 
+// TransactionFilter narrows a ledger listing. Zero values leave that bound open;
+// From is inclusive and To is exclusive. After resumes strictly past a cursor, and
+// Limit caps the page size when positive.
+type TransactionFilter struct {
+	From     time.Time
+	To       time.Time
+	Category string
+	After    *TransactionCursor
+	Limit    int
+}
+
+// Matches reports whether txn falls inside the filter, ignoring Limit.
+func (f TransactionFilter) Matches(txn finance.Transaction) bool {
+	if !f.From.IsZero() && txn.Date.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !txn.Date.Before(f.To) {
+		return false
+	}
+	if f.After != nil && !TransactionBefore(txn, *f.After) {
+		return false
+	}
+	return f.Category == "" || txn.Category == f.Category
+}
+
+// TransactionBefore reports whether txn sorts after cursor in the newest-first
+// ordering, i.e. (Date, ID) is strictly less than the cursor's.
+func TransactionBefore(txn finance.Transaction, cursor TransactionCursor) bool {
+	if !txn.Date.Equal(cursor.Date) {
+		return txn.Date.Before(cursor.Date)
+	}
+	return txn.ID < cursor.ID
+}
+
+// TransactionPage is one page of a ledger listing. Total counts every transaction
+// inside the filter's bounds, ignoring After and Limit.
+type TransactionPage struct {
+	Items []finance.Transaction
+	Total int
+}
+
+// TransactionStore defines CRUD operations for the transaction ledger. List returns
+// the newest transactions first, ordered by (Date, ID) descending. ListPage orders the
+// same way and also reports the total, read in the same snapshot as the page.
+type TransactionStore interface {
+	List(ctx context.Context, filter TransactionFilter) ([]finance.Transaction, error)
+	ListPage(ctx context.Context, filter TransactionFilter) (TransactionPage, error)
+	Get(ctx context.Context, id string) (finance.Transaction, error)
+	// GetMany looks up ids in one call, keyed by id; unknown ids are absent, not an error.
+	GetMany(ctx context.Context, ids []string) (map[string]finance.Transaction, error)
+	Create(ctx context.Context, txn finance.Transaction) (finance.Transaction, error)
+	Update(ctx context.Context, txn finance.Transaction) (finance.Transaction, error)
+	Delete(ctx context.Context, id string) error
+	Meta(ctx context.Context) (CollectionMeta, error)
+}
+
+// NetWorthSnapshotStore persists periodic net-worth snapshots.
+type NetWorthSnapshotStore interface {
+	List(ctx context.Context) ([]finance.NetWorthSnapshot, error)
+	Latest(ctx context.Context) (finance.NetWorthSnapshot, error)
+	Create(ctx context.Context, snapshot finance.NetWorthSnapshot) (finance.NetWorthSnapshot, error)
+	// CreateOncePerMinute stores snapshot unless one was already recorded in the same
+	// UTC minute, in which case it returns that one and false. The check and the insert
+	// are atomic, so concurrent callers store at most one snapshot per minute.
+	CreateOncePerMinute(ctx context.Context, snapshot finance.NetWorthSnapshot) (finance.NetWorthSnapshot, bool, error)
+}
+
+// ImportMode selects how ImportDataset treats data already in the repository.
+type ImportMode string
+
+const (
+	// ImportMerge upserts imported entities by ID and leaves everything else untouched.
+	ImportMerge ImportMode = "merge"
+	// ImportReplace removes existing finance entities before inserting the import.
+	ImportReplace ImportMode = "replace"
+)
+
 // Repository aggregates typed stores for easier dependency injection.
 type Repository interface {
 	Assets() AssetStore
@@ -67,4 +233,13 @@ type Repository interface {
 	Incomes() IncomeStore
 	Expenses() ExpenseStore
 	PropertyPlanner() PropertyPlannerStore
+	Goals() GoalStore
+	Accounts() AccountStore
+	Transactions() TransactionStore
+	NetWorthSnapshots() NetWorthSnapshotStore
+	// ImportDataset writes a full dataset atomically. Net-worth history is kept.
+	ImportDataset(ctx context.Context, data finance.SeedData, mode ImportMode) error
+	// RenameCashFlowCategory moves every income and expense in category from to category
+	// to, atomically across both, and reports how many of each changed.
+	RenameCashFlowCategory(ctx context.Context, from, to string) (incomes, expenses int, err error)
 }
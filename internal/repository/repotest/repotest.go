@@ -0,0 +1,555 @@
+// Package repotest is a conformance suite for repository.Repository implementations.
+// Each backend runs the same assertions so behaviour such as list ordering, duplicate
+// IDs and reference conflicts cannot drift between the memory and Postgres stores.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+// Factory returns an empty repository that stamps writes with clock. It is called
+// once per subtest, so implementations backed by shared storage must reset it.
+type Factory func(t *testing.T, clock repository.Clock) repository.Repository
+
+// IDs are UUIDs so the same fixtures are accepted by backends with uuid columns.
+const (
+	accountID   = "6f1c1c1e-0000-4000-8000-000000000001"
+	liabilityID = "6f1c1c1e-0000-4000-8000-000000000002"
+	assetID     = "6f1c1c1e-0000-4000-8000-000000000003"
+	missingID   = "6f1c1c1e-0000-4000-8000-0000000000ff"
+)
+
+// Run executes the suite against repositories built by newRepo.
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, newRepo Factory)
+	}{
+		{"AssetCRUD", testAssetCRUD},
+		{"CashFlowRoundTrip", testCashFlowRoundTrip},
+		{"ListOrdersByUpdatedAtThenID", testListOrder},
+		{"CreateRejectsDuplicateID", testDuplicateID},
+		{"CreateRejectsInvalidInput", testInvalidInput},
+		{"MissingIDsAreNotFound", testNotFound},
+		{"DeleteBlockedWhileReferenced", testReferenceConflicts},
+		{"TransactionsNewestFirst", testTransactionOrder},
+		{"MetaTracksCountAndNewestUpdate", testMeta},
+		{"ScenarioUpsertByType", testScenarioUpsert},
+		{"CategoriesAreDistinctAndSorted", testCategories},
+		{"RenameCashFlowCategorySpansIncomesAndExpenses", testRenameCashFlowCategory},
+		{"SnapshotOncePerMinuteUnderConcurrency", testSnapshotOncePerMinute},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) { tc.run(t, newRepo) })
+	}
+}
+
+// steppingClock starts at a fixed instant and advances one second per call, giving
+// every write a distinct timestamp that all backends store without rounding.
+func steppingClock() repository.Clock {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return repository.ClockFunc(func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+}
+
+func testAssetCRUD(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	store := newRepo(t, steppingClock()).Assets()
+
+	created, err := store.Create(ctx, finance.Asset{
+		Name:             "Brokerage",
+		Category:         "investments",
+		CurrentValue:     1000,
+		AnnualGrowthRate: 0.05,
+		Currency:         "USD",
+		Notes:            "index funds",
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID == "" || created.UpdatedAt.IsZero() {
+		t.Fatalf("expected an ID and updatedAt on create, got %+v", created)
+	}
+
+	got, err := store.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Name != "Brokerage" || got.Category != "investments" || got.CurrentValue != 1000 ||
+		got.AnnualGrowthRate != 0.05 || got.Currency != "USD" || got.Notes != "index funds" {
+		t.Fatalf("expected fields to round-trip, got %+v", got)
+	}
+
+	got.CurrentValue = 1500
+	got.Notes = ""
+	updated, err := store.Update(ctx, got)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.CurrentValue != 1500 || updated.Notes != "" || !updated.UpdatedAt.After(created.UpdatedAt) {
+		t.Fatalf("expected update to apply and restamp, got %+v", updated)
+	}
+
+	found, err := store.GetMany(ctx, []string{created.ID, missingID})
+	if err != nil {
+		t.Fatalf("get many: %v", err)
+	}
+	if len(found) != 1 || found[created.ID].CurrentValue != 1500 {
+		t.Fatalf("expected only the existing asset, got %+v", found)
+	}
+
+	if err := store.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, created.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func testCashFlowRoundTrip(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t, steppingClock())
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	day := 15
+
+	income, err := repo.Incomes().Create(ctx, finance.Income{
+		Source:     "Salary",
+		Amount:     5000,
+		Frequency:  finance.FrequencyMonthly,
+		StartDate:  start,
+		Category:   "salary",
+		DayOfMonth: &day,
+	})
+	if err != nil {
+		t.Fatalf("create income: %v", err)
+	}
+	got, err := repo.Incomes().Get(ctx, income.ID)
+	if err != nil {
+		t.Fatalf("get income: %v", err)
+	}
+	if !got.StartDate.Equal(start) || got.DayOfMonth == nil || *got.DayOfMonth != day || got.DayOfWeek != nil || got.Notes != "" || got.Interval != "" {
+		t.Fatalf("expected optional income fields to round-trip, got %+v", got)
+	}
+
+	expense, err := repo.Expenses().Create(ctx, finance.Expense{
+		Payee:     "Gym",
+		Amount:    40,
+		Frequency: finance.FrequencyMonthly,
+		Interval:  "P2W",
+		Category:  "health",
+	})
+	if err != nil {
+		t.Fatalf("create expense: %v", err)
+	}
+	gotExpense, err := repo.Expenses().Get(ctx, expense.ID)
+	if err != nil {
+		t.Fatalf("get expense: %v", err)
+	}
+	if gotExpense.Interval != "P2W" {
+		t.Fatalf("expected the recurrence interval to round-trip, got %q", gotExpense.Interval)
+	}
+	if gotExpense.DayOfMonth != nil || gotExpense.DayOfWeek != nil || gotExpense.Currency != "" {
+		t.Fatalf("expected unset expense fields to stay empty, got %+v", gotExpense)
+	}
+}
+
+func testListOrder(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	store := newRepo(t, steppingClock()).Goals()
+
+	var ids []string
+	for _, name := range []string{"Oldest", "Middle", "Newest"} {
+		goal, err := store.Create(ctx, finance.Goal{Name: name, TargetAmount: 100, AssetCategory: "cash"})
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		ids = append(ids, goal.ID)
+	}
+	oldest, err := store.Get(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if _, err := store.Update(ctx, oldest); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	goals, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []string{ids[0], ids[2], ids[1]}
+	if len(goals) != len(want) {
+		t.Fatalf("expected %d goals, got %d", len(want), len(goals))
+	}
+	for i, goal := range goals {
+		if goal.ID != want[i] {
+			t.Fatalf("expected most recently updated first (%v), got %s at %d", want, goal.ID, i)
+		}
+	}
+}
+
+func testDuplicateID(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	store := newRepo(t, steppingClock()).Accounts()
+
+	if _, err := store.Create(ctx, finance.Account{ID: accountID, Name: "Checking"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_, err := store.Create(ctx, finance.Account{ID: accountID, Name: "Savings"})
+	if !errors.Is(err, repository.ErrDuplicateID) || !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected ErrDuplicateID wrapping ErrConflict, got %v", err)
+	}
+	got, err := store.Get(ctx, accountID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Name != "Checking" {
+		t.Fatalf("expected the original account to survive, got %+v", got)
+	}
+}
+
+func testInvalidInput(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t, steppingClock())
+
+	checks := map[string]error{
+		"asset without name": func() error {
+			_, err := repo.Assets().Create(ctx, finance.Asset{Category: "cash", CurrentValue: 1})
+			return err
+		}(),
+		"income with unknown frequency": func() error {
+			_, err := repo.Incomes().Create(ctx, finance.Income{Source: "Salary", Amount: 1, Frequency: "fortnightly"})
+			return err
+		}(),
+		"expense with zero amount": func() error {
+			_, err := repo.Expenses().Create(ctx, finance.Expense{Payee: "Rent", Frequency: finance.FrequencyMonthly})
+			return err
+		}(),
+		"goal without target": func() error {
+			_, err := repo.Goals().Create(ctx, finance.Goal{Name: "House", AssetCategory: "cash"})
+			return err
+		}(),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, repository.ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+}
+
+func testNotFound(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t, steppingClock())
+
+	checks := map[string]error{
+		"get asset":       func() error { _, err := repo.Assets().Get(ctx, missingID); return err }(),
+		"get liability":   func() error { _, err := repo.Liabilities().Get(ctx, missingID); return err }(),
+		"get transaction": func() error { _, err := repo.Transactions().Get(ctx, missingID); return err }(),
+		"delete income":   repo.Incomes().Delete(ctx, missingID),
+		"delete goal":     repo.Goals().Delete(ctx, missingID),
+		"delete account":  repo.Accounts().Delete(ctx, missingID),
+		"update liability": func() error {
+			_, err := repo.Liabilities().Update(ctx, finance.Liability{ID: missingID, Name: "Loan"})
+			return err
+		}(),
+		"update expense": func() error {
+			_, err := repo.Expenses().Update(ctx, finance.Expense{ID: missingID, Payee: "Rent", Amount: 1, Frequency: finance.FrequencyMonthly})
+			return err
+		}(),
+		"get latest netWorth": func() error { _, err := repo.NetWorthSnapshots().Latest(ctx); return err }(),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", name, err)
+		}
+	}
+}
+
+func testReferenceConflicts(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t, steppingClock())
+
+	if _, err := repo.Accounts().Create(ctx, finance.Account{ID: accountID, Name: "Bank"}); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if _, err := repo.Liabilities().Create(ctx, finance.Liability{ID: liabilityID, Name: "Mortgage", Category: "mortgage", CurrentBalance: 1000}); err != nil {
+		t.Fatalf("create liability: %v", err)
+	}
+	if _, err := repo.Assets().Create(ctx, finance.Asset{
+		ID:                assetID,
+		Name:              "House",
+		Category:          "property",
+		CurrentValue:      5000,
+		AccountID:         accountID,
+		LinkedLiabilityID: liabilityID,
+	}); err != nil {
+		t.Fatalf("create asset: %v", err)
+	}
+
+	err := repo.Liabilities().Delete(ctx, liabilityID)
+	var referenced *repository.ReferencedError
+	if !errors.Is(err, repository.ErrConflict) || !errors.As(err, &referenced) {
+		t.Fatalf("expected a ReferencedError deleting a linked liability, got %v", err)
+	}
+	if referenced.Entity != "asset" || len(referenced.IDs) != 1 || referenced.IDs[0] != assetID {
+		t.Fatalf("expected the conflict to name asset %s, got %+v", assetID, referenced)
+	}
+	if err := repo.Accounts().Delete(ctx, accountID); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected ErrConflict deleting a referenced account, got %v", err)
+	}
+
+	if err := repo.Assets().Delete(ctx, assetID); err != nil {
+		t.Fatalf("delete asset: %v", err)
+	}
+	if err := repo.Liabilities().Delete(ctx, liabilityID); err != nil {
+		t.Fatalf("expected the unlinked liability to delete, got %v", err)
+	}
+	if err := repo.Accounts().Delete(ctx, accountID); err != nil {
+		t.Fatalf("expected the unreferenced account to delete, got %v", err)
+	}
+}
+
+func testTransactionOrder(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	store := newRepo(t, steppingClock()).Transactions()
+	day := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	var ids []string
+	for i, date := range []time.Time{day, day.AddDate(0, 0, 2), day.AddDate(0, 0, 1)} {
+		txn, err := store.Create(ctx, finance.Transaction{
+			Date:      date,
+			Amount:    float64(10 * (i + 1)),
+			Direction: finance.TransactionOutflow,
+			Category:  "groceries",
+		})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		ids = append(ids, txn.ID)
+	}
+
+	txns, err := store.List(ctx, repository.TransactionFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []string{ids[1], ids[2], ids[0]}
+	if len(txns) != len(want) {
+		t.Fatalf("expected %d transactions, got %d", len(want), len(txns))
+	}
+	for i, txn := range txns {
+		if txn.ID != want[i] {
+			t.Fatalf("expected newest date first (%v), got %s at %d", want, txn.ID, i)
+		}
+	}
+
+	page, err := store.ListPage(ctx, repository.TransactionFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("list page: %v", err)
+	}
+	if page.Total != 3 || len(page.Items) != 2 || page.Items[0].ID != ids[1] {
+		t.Fatalf("expected the first two of three, got total %d and %+v", page.Total, page.Items)
+	}
+}
+
+func testMeta(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	store := newRepo(t, steppingClock()).Liabilities()
+
+	meta, err := store.Meta(ctx)
+	if err != nil {
+		t.Fatalf("meta: %v", err)
+	}
+	if meta.Count != 0 || meta.LastUpdatedAt != nil {
+		t.Fatalf("expected empty meta, got %+v", meta)
+	}
+
+	var newest finance.Liability
+	for _, name := range []string{"Card", "Loan"} {
+		if newest, err = store.Create(ctx, finance.Liability{Name: name, Category: "debt"}); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+	}
+	meta, err = store.Meta(ctx)
+	if err != nil {
+		t.Fatalf("meta: %v", err)
+	}
+	if meta.Count != 2 || meta.LastUpdatedAt == nil || !meta.LastUpdatedAt.Equal(newest.UpdatedAt) {
+		t.Fatalf("expected count 2 and the newest updatedAt %v, got %+v", newest.UpdatedAt, meta)
+	}
+}
+
+func testCategories(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t, steppingClock())
+
+	for _, category := range []string{"retirement", "cash", "retirement", "brokerage"} {
+		if _, err := repo.Assets().Create(ctx, finance.Asset{Name: category, Category: category, CurrentValue: 1}); err != nil {
+			t.Fatalf("create asset: %v", err)
+		}
+	}
+	categories, err := repo.Assets().Categories(ctx)
+	if err != nil {
+		t.Fatalf("asset categories: %v", err)
+	}
+	if want := []string{"brokerage", "cash", "retirement"}; !slices.Equal(categories, want) {
+		t.Fatalf("expected %v, got %v", want, categories)
+	}
+
+	categories, err = repo.Liabilities().Categories(ctx)
+	if err != nil {
+		t.Fatalf("liability categories: %v", err)
+	}
+	if categories == nil || len(categories) != 0 {
+		t.Fatalf("expected an empty, non-nil list, got %#v", categories)
+	}
+}
+
+func testRenameCashFlowCategory(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t, steppingClock())
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	income, err := repo.Incomes().Create(ctx, finance.Income{Source: "Rent", Amount: 900, Frequency: finance.FrequencyMonthly, StartDate: start, Category: "living"})
+	if err != nil {
+		t.Fatalf("create income: %v", err)
+	}
+	if _, err := repo.Expenses().Create(ctx, finance.Expense{Payee: "Groceries", Amount: 300, Frequency: finance.FrequencyMonthly, Category: "living"}); err != nil {
+		t.Fatalf("create expense: %v", err)
+	}
+	if _, err := repo.Expenses().Create(ctx, finance.Expense{Payee: "Gym", Amount: 40, Frequency: finance.FrequencyMonthly, Category: "health"}); err != nil {
+		t.Fatalf("create expense: %v", err)
+	}
+
+	incomes, expenses, err := repo.RenameCashFlowCategory(ctx, "living", "household")
+	if err != nil || incomes != 1 || expenses != 1 {
+		t.Fatalf("expected one income and one expense renamed, got %d, %d, %v", incomes, expenses, err)
+	}
+	renamed, err := repo.Incomes().Get(ctx, income.ID)
+	if err != nil || renamed.Category != "household" || !renamed.UpdatedAt.After(income.UpdatedAt) {
+		t.Fatalf("expected the income renamed and touched, got %+v, %v", renamed, err)
+	}
+	categories := map[string]int{}
+	list, err := repo.Expenses().List(ctx)
+	if err != nil {
+		t.Fatalf("list expenses: %v", err)
+	}
+	for _, expense := range list {
+		categories[expense.Category]++
+	}
+	if categories["household"] != 1 || categories["health"] != 1 || categories["living"] != 0 {
+		t.Fatalf("expected only the matching expense renamed, got %v", categories)
+	}
+
+	if _, _, err := repo.RenameCashFlowCategory(ctx, "", "household"); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected an empty category to be invalid input, got %v", err)
+	}
+}
+
+func testScenarioUpsert(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	store := newRepo(t, steppingClock()).PropertyPlanner()
+	inputs := finance.MortgageInputs{LoanTermYears: 25}
+
+	created, isNew, err := store.UpsertByType(ctx, finance.PropertyPlannerScenario{Type: "hdb", Headline: "Resale", Inputs: inputs})
+	if err != nil {
+		t.Fatalf("upsert create: %v", err)
+	}
+	if !isNew || created.ID == "" {
+		t.Fatalf("expected the first upsert to create a scenario, got created=%v %+v", isNew, created)
+	}
+
+	updated, isNew, err := store.UpsertByType(ctx, finance.PropertyPlannerScenario{Type: "hdb", Headline: "BTO", Inputs: inputs})
+	if err != nil {
+		t.Fatalf("upsert update: %v", err)
+	}
+	if isNew || updated.ID != created.ID || updated.Headline != "BTO" {
+		t.Fatalf("expected the second upsert to update %s in place, got created=%v %+v", created.ID, isNew, updated)
+	}
+	if !updated.UpdatedAt.After(created.UpdatedAt) {
+		t.Fatalf("expected the update to advance updated_at, got %v then %v", created.UpdatedAt, updated.UpdatedAt)
+	}
+
+	all, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("expected one scenario of type hdb, got %d", len(all))
+	}
+
+	// Types match exactly, so a different case is a different scenario.
+	upper, isNew, err := store.UpsertByType(ctx, finance.PropertyPlannerScenario{Type: "HDB", Headline: "Executive", Inputs: inputs})
+	if err != nil || !isNew || upper.ID == created.ID {
+		t.Fatalf("expected HDB to create its own scenario, got created=%v %+v, %v", isNew, upper, err)
+	}
+	for scenarioType, want := range map[string]string{"hdb": created.ID, "HDB": upper.ID} {
+		got, err := store.GetByType(ctx, scenarioType)
+		if err != nil || got.ID != want {
+			t.Fatalf("expected GetByType(%q) to return %s, got %+v, %v", scenarioType, want, got, err)
+		}
+	}
+	if _, err := store.GetByType(ctx, "Hdb"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected no scenario of type Hdb, got %v", err)
+	}
+
+	if _, _, err := store.UpsertByType(ctx, finance.PropertyPlannerScenario{Type: "condo", Inputs: inputs}); !errors.Is(err, repository.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput without a headline, got %v", err)
+	}
+}
+
+func testSnapshotOncePerMinute(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	store := newRepo(t, steppingClock()).NetWorthSnapshots()
+	at := time.Date(2024, 3, 1, 12, 30, 5, 0, time.UTC)
+
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make([]finance.NetWorthSnapshot, callers)
+	created := make([]bool, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Stagger within the minute so only the minute, not the instant, collides.
+			recordedAt := at.Add(time.Duration(i) * time.Second)
+			results[i], created[i], errs[i] = store.CreateOncePerMinute(ctx, finance.NetWorthSnapshot{NetWorth: 100, RecordedAt: recordedAt})
+		}()
+	}
+	wg.Wait()
+
+	stored := 0
+	for i := range callers {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: %v", i, errs[i])
+		}
+		if created[i] {
+			stored++
+		}
+		if results[i].ID != results[0].ID {
+			t.Fatalf("expected every caller to get the same snapshot, got %s and %s", results[0].ID, results[i].ID)
+		}
+	}
+	if stored != 1 {
+		t.Fatalf("expected exactly one caller to store a snapshot, got %d", stored)
+	}
+
+	next, isNew, err := store.CreateOncePerMinute(ctx, finance.NetWorthSnapshot{NetWorth: 100, RecordedAt: at.Add(time.Minute)})
+	if err != nil || !isNew || next.ID == results[0].ID {
+		t.Fatalf("expected the next minute to store a new snapshot, got created=%v %+v, %v", isNew, next, err)
+	}
+	history, err := store.List(ctx)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("expected two stored snapshots, got %d, %v", len(history), err)
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type queryTimerKey struct{}
+
+// QueryTimer adds up the time one request spends in store calls, so the server can
+// report it in Server-Timing. Calls may overlap; each is counted in full.
+type QueryTimer struct {
+	total atomic.Int64
+	calls atomic.Int64
+}
+
+// Total reports the time recorded so far.
+func (t *QueryTimer) Total() time.Duration { return time.Duration(t.total.Load()) }
+
+// Calls reports how many store calls were recorded.
+func (t *QueryTimer) Calls() int { return int(t.calls.Load()) }
+
+// WithQueryTimer returns a copy of ctx whose store calls are recorded in t.
+func WithQueryTimer(ctx context.Context, t *QueryTimer) context.Context {
+	return context.WithValue(ctx, queryTimerKey{}, t)
+}
+
+// TimeQuery starts timing a store call against the QueryTimer in ctx. The returned
+// func ends it. Without a timer in ctx it does nothing.
+func TimeQuery(ctx context.Context) func() {
+	t, ok := ctx.Value(queryTimerKey{}).(*QueryTimer)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		t.total.Add(int64(time.Since(start)))
+		t.calls.Add(1)
+	}
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+func (rt *router) handleAccountsCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		rt.listAccounts(w, r)
+	case http.MethodPost:
+		rt.createAccount(w, r)
+	case http.MethodOptions:
+		allowOptions(w, collectionMethods)
+	default:
+		methodNotAllowed(w, collectionMethods)
+	}
+}
+
+func (rt *router) handleAccountItem(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/accounts/")
+	if id, ok := strings.CutSuffix(rest, "/net-worth"); ok {
+		rt.handleAccountNetWorth(w, r, id)
+		return
+	}
+
+	id := rest
+	if id == "" || strings.Contains(id, "/") {
+		notFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		rt.getAccount(w, r, id)
+	case http.MethodPatch:
+		rt.updateAccount(w, r, id)
+	case http.MethodDelete:
+		rt.deleteAccount(w, r, id)
+	case http.MethodOptions:
+		allowOptions(w, itemMethods)
+	default:
+		methodNotAllowed(w, itemMethods)
+	}
+}
+
+func (rt *router) listAccounts(w http.ResponseWriter, r *http.Request) {
+	items, err := rt.repo.Accounts().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	writeList(w, r, mapResponses(items, newAccountResponse))
+}
+
+func (rt *router) getAccount(w http.ResponseWriter, r *http.Request, id string) {
+	account, err := rt.repo.Accounts().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAccountResponse(account))
+}
+
+func (rt *router) createAccount(w http.ResponseWriter, r *http.Request) {
+	var payload accountPayload
+	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	created, err := rt.repo.Accounts().Create(r.Context(), payload.toAccount())
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newAccountResponse(created))
+	rt.publishChange("account", events.ActionCreate, created.ID, created)
+}
+
+func (rt *router) updateAccount(w http.ResponseWriter, r *http.Request, id string) {
+	existing, err := rt.repo.Accounts().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	var payload accountPayload
+	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+
+	payload.ID = id
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	updated, err := rt.repo.Accounts().Update(r.Context(), payload.toAccount())
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAccountResponse(updated))
+	rt.publishUpdate("account", updated.ID, existing, updated)
+}
+
+func (rt *router) deleteAccount(w http.ResponseWriter, r *http.Request, id string) {
+	err := rt.repo.Accounts().Delete(r.Context(), id)
+	if errors.Is(err, repository.ErrConflict) {
+		writeError(w, http.StatusConflict, "account still has assets, liabilities or transactions; move or delete them first")
+		return
+	}
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	rt.publishChange("account", events.ActionDelete, id, map[string]string{"id": id})
+}
+
+// handleAccountNetWorth totals the holdings linked to one account, converted to the
+// base currency.
+func (rt *router) handleAccountNetWorth(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	if _, err := rt.repo.Accounts().Get(r.Context(), id); err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	assets, err := rt.repo.Assets().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	liabilities, err := rt.repo.Liabilities().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	if assets, err = rt.currency.Assets(assets); err != nil {
+		aggregationError(w, err)
+		return
+	}
+	if liabilities, err = rt.currency.Liabilities(liabilities); err != nil {
+		aggregationError(w, err)
+		return
+	}
+
+	snapshot := finance.AccountNetWorth(id, assets, liabilities, rt.now().UTC())
+	writeJSON(w, http.StatusOK, map[string]any{
+		"accountId":        id,
+		"totalAssets":      snapshot.TotalAssets,
+		"totalLiabilities": snapshot.TotalLiabilities,
+		"netWorth":         snapshot.NetWorth,
+	})
+}
+
+// checkAccountRef rejects holdings that point at an unknown account. It writes the
+// error response itself and reports whether the handler may continue.
+func (rt *router) checkAccountRef(w http.ResponseWriter, r *http.Request, accountID string) bool {
+	accountID = strings.TrimSpace(accountID)
+	if accountID == "" {
+		return true
+	}
+	_, err := rt.repo.Accounts().Get(r.Context(), accountID)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repository.ErrNotFound):
+		badRequest(w, fmt.Errorf("accountId %q does not exist", accountID))
+	default:
+		internalError(w)
+	}
+	return false
+}
+
+type accountPayload struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Institution string `json:"institution"`
+}
+
+func (p accountPayload) validate(limits fieldLimits) error {
+	if err := limits.check("", nil, textField{"name", p.Name}, textField{"institution", p.Institution}); err != nil {
+		return err
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func (p accountPayload) toAccount() finance.Account {
+	return finance.Account{
+		ID:          p.ID,
+		Name:        strings.TrimSpace(p.Name),
+		Institution: strings.TrimSpace(p.Institution),
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestAccountCRUDHandlers(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	createReq := httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{"name":"Brokerage","institution":"Fidelity"}`))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("expected create status 201, got %d", createRec.Code)
+	}
+
+	var created finance.Account
+	if err := json.Unmarshal(createRec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to decode created account: %v", err)
+	}
+	if created.ID == "" || created.Institution != "Fidelity" {
+		t.Fatalf("unexpected account %+v", created)
+	}
+
+	updateReq := httptest.NewRequest(http.MethodPatch, "/accounts/"+created.ID, strings.NewReader(`{"name":"Brokerage","institution":"Schwab"}`))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateRec := httptest.NewRecorder()
+	router.ServeHTTP(updateRec, updateReq)
+	if updateRec.Code != http.StatusOK {
+		t.Fatalf("expected update status 200, got %d", updateRec.Code)
+	}
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/accounts/"+created.ID, nil)
+	deleteRec := httptest.NewRecorder()
+	router.ServeHTTP(deleteRec, deleteReq)
+	if deleteRec.Code != http.StatusNoContent {
+		t.Fatalf("expected delete status 204, got %d", deleteRec.Code)
+	}
+}
+
+func TestAccountNetWorthAndReferencedDelete(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Accounts: []finance.Account{{ID: "acct-1", Name: "Checking"}},
+		Assets: []finance.Asset{
+			{ID: "a1", Name: "Cash", Category: "cash", CurrentValue: 8000, AccountID: "acct-1"},
+			{ID: "a2", Name: "House", Category: "property", CurrentValue: 500000},
+		},
+		Liabilities: []finance.Liability{
+			{ID: "l1", Name: "Overdraft", Category: "credit", CurrentBalance: 500, AccountID: "acct-1"},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/acct-1/net-worth", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		TotalAssets      float64 `json:"totalAssets"`
+		TotalLiabilities float64 `json:"totalLiabilities"`
+		NetWorth         float64 `json:"netWorth"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode net worth: %v", err)
+	}
+	if body.TotalAssets != 8000 || body.TotalLiabilities != 500 || body.NetWorth != 7500 {
+		t.Fatalf("unexpected account net worth %+v", body)
+	}
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/accounts/acct-1", nil)
+	deleteRec := httptest.NewRecorder()
+	router.ServeHTTP(deleteRec, deleteReq)
+	if deleteRec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 deleting referenced account, got %d", deleteRec.Code)
+	}
+}
+
+func TestAssetCreateRejectsUnknownAccount(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	body := `{"name":"RSU","category":"equity","currentValue":100,"accountId":"missing"}`
+	req := httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown account, got %d", rec.Code)
+	}
+}
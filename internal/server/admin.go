@@ -0,0 +1,74 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"reflect"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+)
+
+// recomputeResult reports what POST /admin/recompute changed.
+type recomputeResult struct {
+	ScenariosChecked int `json:"scenariosChecked"`
+	ScenariosChanged int `json:"scenariosChanged"`
+	// NetWorthSnapshot is the snapshot taken from the recomputed data.
+	NetWorthSnapshot finance.NetWorthSnapshot `json:"netWorthSnapshot"`
+}
+
+// handleAdminRecompute recomputes every property scenario whose stored amortization or
+// snapshot differs from a fresh calculation, then records a net-worth snapshot so the
+// history chart reflects the fix. Operators run it after a calculation fix ships.
+func (rt *router) handleAdminRecompute(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, actionMethods)
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, actionMethods)
+		return
+	}
+	if !rt.authorizeAdmin(w, r) {
+		return
+	}
+
+	recomputer := newScenarioRecomputer(rt.logger, rt.repo, rt.events, 0, 0, rt.now)
+	recomputer.pause = 0
+	checked, changed, err := recomputer.recompute(r.Context(), func(stored finance.PropertyPlannerScenario, amortization finance.MortgageAmortization, snapshot finance.MortgageSnapshot) bool {
+		return stored.Snapshot != snapshot || !reflect.DeepEqual(stored.Amortization, amortization)
+	})
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+
+	snapshot, created, err := snapshotNetWorth(r.Context(), rt.repo, rt.currency, rt.now().UTC())
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+	if created {
+		rt.publishChange("netWorthSnapshot", events.ActionCreate, snapshot.ID, snapshot)
+	}
+	writeJSON(w, http.StatusOK, recomputeResult{
+		ScenariosChecked: checked,
+		ScenariosChanged: changed,
+		NetWorthSnapshot: snapshot,
+	})
+}
+
+// authorizeAdmin requires the configured admin token as the session token, writing
+// 401 when none is sent and 403 when it does not match.
+func (rt *router) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	token := extractSessionToken(r)
+	if token == "" {
+		unauthorized(w)
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(rt.adminToken)) != 1 {
+		writeError(w, http.StatusForbidden, "forbidden")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestAdminRecomputeReportsChangedScenarios(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	inputs := finance.MortgageInputs{LoanAmount: 400000, LoanTermYears: 20, LoanStartMonth: "2024-01", FixedRate: 2.5}
+	amortization, snapshot := finance.ComputeAmortization(inputs, time.Now())
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{{ID: "a1", Name: "Cash", Category: "cash", CurrentValue: 1000}},
+		PropertyScenarios: []finance.PropertyPlannerScenario{
+			{ID: "old", Type: "hdb", Headline: "Before the fix", Inputs: inputs, Snapshot: finance.MortgageSnapshot{MonthlyPayment: 1}},
+			{ID: "current", Type: "condo", Headline: "Up to date", Inputs: inputs, Amortization: amortization, Snapshot: snapshot},
+		},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+
+	disabled := newRouter(logger, repo, hub)
+	rec := httptest.NewRecorder()
+	disabled.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/recompute", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without an admin token configured, got %d", rec.Code)
+	}
+
+	router := newRouter(logger, repo, hub, withAdminToken("s3cret"))
+	recompute := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/recompute", nil)
+		if token != "" {
+			req.Header.Set(headerSessionToken, token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := recompute(""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", rec.Code)
+	}
+	if rec := recompute("guess"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for the wrong token, got %d", rec.Code)
+	}
+
+	for i, want := range []int{1, 0} {
+		rec := recompute("s3cret")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("run %d: expected 200, got %d: %s", i, rec.Code, rec.Body.String())
+		}
+		var result recomputeResult
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("decode result: %v", err)
+		}
+		if result.ScenariosChecked != 2 || result.ScenariosChanged != want || result.NetWorthSnapshot.TotalAssets != 1000 {
+			t.Fatalf("run %d: expected 2 checked and %d changed, got %+v", i, want, result)
+		}
+	}
+
+	old, err := repo.PropertyPlanner().Get(context.Background(), "old")
+	if err != nil || old.Snapshot != snapshot {
+		t.Fatalf("expected the outdated scenario to be rewritten, got %+v (%v)", old.Snapshot, err)
+	}
+}
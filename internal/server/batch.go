@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxBatchRequests caps the sub-requests in one POST /batch.
+const maxBatchRequests = 20
+
+// batchPayload is the POST /batch body: reads to run in one round trip.
+type batchPayload struct {
+	Requests []batchSubRequest `json:"requests"`
+}
+
+type batchSubRequest struct {
+	// Method defaults to GET, which is the only method accepted for now.
+	Method string `json:"method"`
+	// Path is the request path with an optional query, e.g. "/assets?limit=10".
+	Path string `json:"path"`
+}
+
+// batchResult is one sub-response, in the order of the request that produced it. Body
+// holds the response document as-is.
+type batchResult struct {
+	Path   string          `json:"path"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// handleBatch serves POST /batch by running each sub-request through mux with a
+// recording writer. Sub-requests share the caller's context and headers, so they carry
+// its request ID and credentials. Event streams never end on their own and are refused.
+func (rt *router) handleBatch(mux http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+		case http.MethodOptions:
+			allowOptions(w, actionMethods)
+			return
+		default:
+			methodNotAllowed(w, actionMethods)
+			return
+		}
+
+		var payload batchPayload
+		if err := decodeJSONBody(w, r, &payload); err != nil {
+			invalidBody(w, err)
+			return
+		}
+		targets, err := payload.validate()
+		if err != nil {
+			badRequest(w, err)
+			return
+		}
+
+		results := make([]batchResult, len(targets))
+		for i, target := range targets {
+			results[i] = dispatchBatch(mux, r, target)
+			results[i].Path = payload.Requests[i].Path
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"responses": results})
+	}
+}
+
+func (p batchPayload) validate() ([]*url.URL, error) {
+	if len(p.Requests) == 0 {
+		return nil, errors.New("requests must not be empty")
+	}
+	if len(p.Requests) > maxBatchRequests {
+		return nil, fmt.Errorf("at most %d requests may be batched", maxBatchRequests)
+	}
+	targets := make([]*url.URL, len(p.Requests))
+	for i, sub := range p.Requests {
+		if sub.Method != "" && !strings.EqualFold(sub.Method, http.MethodGet) {
+			return nil, fmt.Errorf("requests[%d]: only GET may be batched", i)
+		}
+		target, err := url.ParseRequestURI(sub.Path)
+		if err != nil || target.IsAbs() || !strings.HasPrefix(target.Path, "/") {
+			return nil, fmt.Errorf("requests[%d]: path %q must be an absolute path", i, sub.Path)
+		}
+		if target.Path == "/batch" || isEventStream(target.Path) {
+			return nil, fmt.Errorf("requests[%d]: %s cannot be batched", i, target.Path)
+		}
+		targets[i] = target
+	}
+	return targets, nil
+}
+
+// dispatchBatch runs one GET against mux as though the caller had sent it directly.
+func dispatchBatch(mux http.Handler, outer *http.Request, target *url.URL) batchResult {
+	sub, err := http.NewRequestWithContext(outer.Context(), http.MethodGet, target.String(), nil)
+	if err != nil {
+		return batchResult{Status: http.StatusBadRequest}
+	}
+	sub.Header = outer.Header.Clone()
+	sub.Header.Del("Content-Type")
+	sub.Header.Del("Content-Length")
+	sub.Host = outer.Host
+	sub.RemoteAddr = outer.RemoteAddr
+	sub.RequestURI = target.RequestURI()
+
+	rec := &batchRecorder{header: make(http.Header)}
+	mux.ServeHTTP(rec, sub)
+
+	result := batchResult{Status: rec.status}
+	if result.Status == 0 {
+		result.Status = http.StatusOK
+	}
+	body := bytes.TrimSpace(rec.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		result.Body = body
+	default:
+		result.Body, _ = json.Marshal(string(body))
+	}
+	return result
+}
+
+// batchRecorder is the http.ResponseWriter a batched sub-request writes into.
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *batchRecorder) Header() http.Header { return r.header }
+
+func (r *batchRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *batchRecorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(p)
+}
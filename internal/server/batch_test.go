@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestBatchReturnsEachSubResponse(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	seed := finance.DefaultSeedData(time.Now().UTC())
+	repo := memory.NewRepository(seed)
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	body := `{"requests":[{"method":"GET","path":"/assets"},{"path":"/liabilities"},{"path":"/assets/missing"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Responses []struct {
+			Path   string          `json:"path"`
+			Status int             `json:"status"`
+			Body   json.RawMessage `json:"body"`
+		} `json:"responses"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(resp.Responses) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(resp.Responses))
+	}
+
+	var assets []map[string]any
+	if err := json.Unmarshal(resp.Responses[0].Body, &assets); err != nil {
+		t.Fatalf("failed to decode assets: %v", err)
+	}
+	if resp.Responses[0].Path != "/assets" || resp.Responses[0].Status != http.StatusOK || len(assets) != len(seed.Assets) {
+		t.Fatalf("expected %d assets from /assets, got %+v", len(seed.Assets), resp.Responses[0])
+	}
+	var liabilities []map[string]any
+	if err := json.Unmarshal(resp.Responses[1].Body, &liabilities); err != nil {
+		t.Fatalf("failed to decode liabilities: %v", err)
+	}
+	if resp.Responses[1].Status != http.StatusOK || len(liabilities) != len(seed.Liabilities) {
+		t.Fatalf("expected %d liabilities, got %+v", len(seed.Liabilities), resp.Responses[1])
+	}
+	if resp.Responses[2].Status != http.StatusNotFound {
+		t.Fatalf("expected the missing asset to report 404, got %d", resp.Responses[2].Status)
+	}
+}
+
+func TestBatchRejectsUnsafeRequests(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	tooMany := make([]string, maxBatchRequests+1)
+	for i := range tooMany {
+		tooMany[i] = `{"path":"/assets"}`
+	}
+	cases := map[string]string{
+		"empty":    `{"requests":[]}`,
+		"too many": `{"requests":[` + strings.Join(tooMany, ",") + `]}`,
+		"write":    `{"requests":[{"method":"DELETE","path":"/assets/a"}]}`,
+		"relative": `{"requests":[{"path":"assets"}]}`,
+		"absolute": `{"requests":[{"path":"http://example.com/assets"}]}`,
+		"stream":   `{"requests":[{"path":"/events"}]}`,
+		"replay":   `{"requests":[{"path":"/events/replay"}]}`,
+		"nested":   `{"requests":[{"path":"/batch"}]}`,
+	}
+	for name, body := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+}
+
+func TestBatchIncludesRecentEvents(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), hub)
+	hub.Publish(events.StreamEvent{Entity: "asset", Action: "update", ResourceID: "asset-1"})
+
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`{"requests":[{"path":"/events/recent"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerSessionToken, "test-session")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected /events/recent to be batched, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Responses []struct {
+			Status int             `json:"status"`
+			Body   json.RawMessage `json:"body"`
+		} `json:"responses"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(resp.Responses) != 1 || resp.Responses[0].Status != http.StatusOK || !strings.Contains(string(resp.Responses[0].Body), "asset-1") {
+		t.Fatalf("expected the recent event in the batch, got %s", rec.Body.String())
+	}
+}
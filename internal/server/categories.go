@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// codeUnknownCategory means strict mode rejected a category outside the canonical
+// taxonomy; GET /categories lists the allowed values.
+const codeUnknownCategory = "unknown_category"
+
+// Entity types with a canonical category list, named as in GET /categories.
+const (
+	categoryAssets      = "assets"
+	categoryLiabilities = "liabilities"
+	categoryIncomes     = "incomes"
+	categoryExpenses    = "expenses"
+)
+
+// categoryLists are the canonical categories configured per entity type. The
+// /categories endpoints offer them alongside the categories already in use, so a
+// dropdown lists the standard choices before any entity uses them. With Strict set,
+// creates and updates must use a listed category; a type without a list, and an
+// empty category, are still accepted.
+type categoryLists struct {
+	Assets      []string
+	Liabilities []string
+	Incomes     []string
+	Expenses    []string
+	Strict      bool
+}
+
+// withCategories sets the canonical category lists.
+func withCategories(lists categoryLists) routerOption {
+	return func(rt *router) {
+		rt.categories = lists
+	}
+}
+
+func (c categoryLists) forEntity(entity string) []string {
+	switch entity {
+	case categoryAssets:
+		return c.Assets
+	case categoryLiabilities:
+		return c.Liabilities
+	case categoryIncomes:
+		return c.Incomes
+	case categoryExpenses:
+		return c.Expenses
+	}
+	return nil
+}
+
+// unknownCategoryError reports a category outside the strict taxonomy.
+type unknownCategoryError struct {
+	Entity   string
+	Category string
+	Allowed  []string
+}
+
+func (e *unknownCategoryError) Error() string {
+	return fmt.Sprintf("category %q is not allowed for %s; use one of %s", e.Category, e.Entity, strings.Join(e.Allowed, ", "))
+}
+
+// check enforces the taxonomy for entity when strict mode is on.
+func (c categoryLists) check(entity, category string) error {
+	allowed := c.forEntity(entity)
+	category = strings.TrimSpace(category)
+	if !c.Strict || len(allowed) == 0 || category == "" || slices.Contains(allowed, category) {
+		return nil
+	}
+	return &unknownCategoryError{Entity: entity, Category: category, Allowed: allowed}
+}
+
+// handleTaxonomy serves GET /categories: the canonical lists and whether they are
+// enforced.
+func (rt *router) handleTaxonomy(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"strict":            rt.categories.Strict,
+		categoryAssets:      nonNil(rt.categories.Assets),
+		categoryLiabilities: nonNil(rt.categories.Liabilities),
+		categoryIncomes:     nonNil(rt.categories.Incomes),
+		categoryExpenses:    nonNil(rt.categories.Expenses),
+	})
+}
+
+// handleCategories serves the sorted union of the categories returned by inUse and
+// the canonical list.
+func (rt *router) handleCategories(inUse func(context.Context) ([]string, error), canonical []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			allowOptions(w, readOnlyMethods)
+			return
+		}
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, readOnlyMethods)
+			return
+		}
+
+		categories, err := inUse(r.Context())
+		if err != nil {
+			handleRepoError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, mergeCategories(categories, canonical))
+	}
+}
+
+// mergeCategories returns the distinct values of both lists in ascending order.
+func mergeCategories(inUse, canonical []string) []string {
+	merged := make([]string, 0, len(inUse)+len(canonical))
+	merged = append(merged, inUse...)
+	merged = append(merged, canonical...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestCategoriesMergeInUseWithCanonicalSorted(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{
+			{Name: "Savings", Category: "cash", CurrentValue: 100},
+			{Name: "Pension", Category: "retirement", CurrentValue: 100},
+			{Name: "Wallet", Category: "cash", CurrentValue: 10},
+			{Name: "Flat", Category: "property", CurrentValue: 1000},
+		},
+		Liabilities: []finance.Liability{{Name: "Card", Category: "credit_card"}},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)),
+		withCategories(categoryLists{Assets: []string{"retirement", "brokerage"}}))
+
+	cases := map[string][]string{
+		"/assets/categories":      {"brokerage", "cash", "property", "retirement"},
+		"/liabilities/categories": {"credit_card"},
+	}
+	for path, want := range cases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+		var got []string
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("%s: failed to decode json: %v", path, err)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("%s: expected %v, got %v", path, want, got)
+		}
+	}
+}
+
+func TestCategoriesOfEmptyCollectionIsEmptyArray(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/liabilities/categories", nil))
+
+	if body := rec.Body.String(); body != "[]\n" {
+		t.Fatalf("expected [], got %q", body)
+	}
+}
+
+func TestStrictCategoriesRejectUnknownValues(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)),
+		withCategories(categoryLists{Assets: []string{"cash"}, Expenses: []string{"housing"}, Strict: true}))
+
+	cases := []struct {
+		path string
+		body string
+		want int
+	}{
+		{"/assets", `{"name":"Savings","category":"cash","currentValue":10}`, http.StatusCreated},
+		{"/assets", `{"name":"Art","category":"collectibles","currentValue":10}`, http.StatusUnprocessableEntity},
+		{"/cashflow/expenses", `{"payee":"Landlord","category":"housing","amount":900,"frequency":"monthly"}`, http.StatusCreated},
+		{"/cashflow/expenses", `{"payee":"Cinema","category":"fun","amount":20,"frequency":"monthly"}`, http.StatusUnprocessableEntity},
+		{"/cashflow/expenses", `{"payee":"Misc","amount":20,"frequency":"monthly"}`, http.StatusCreated},
+		// Liabilities have no list, so any category is accepted.
+		{"/liabilities", `{"name":"Card","category":"credit_card"}`, http.StatusCreated},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d: %s", tc.path, tc.body, tc.want, rec.Code, rec.Body.String())
+		}
+		if tc.want == http.StatusUnprocessableEntity {
+			var body errorBody
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Code != codeUnknownCategory {
+				t.Fatalf("expected code %s, got %s", codeUnknownCategory, rec.Body.String())
+			}
+		}
+	}
+}
+
+func TestLenientCategoriesAcceptAnyValue(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)),
+		withCategories(categoryLists{Assets: []string{"cash"}}))
+
+	req := httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(`{"name":"Art","category":"collectibles","currentValue":10}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTaxonomyListsConfiguredCategories(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)),
+		withCategories(categoryLists{Incomes: []string{"salary", "bonus"}, Strict: true}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/categories", nil))
+
+	var taxonomy struct {
+		Strict  bool     `json:"strict"`
+		Assets  []string `json:"assets"`
+		Incomes []string `json:"incomes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &taxonomy); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if !taxonomy.Strict || taxonomy.Assets == nil || len(taxonomy.Assets) != 0 || !slices.Equal(taxonomy.Incomes, []string{"salary", "bonus"}) {
+		t.Fatalf("unexpected taxonomy %+v", taxonomy)
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIPHonoursForwardingOnlyFromTrustedProxies(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"untrusted peer ignores headers", "203.0.113.9:5000", "198.51.100.1", "198.51.100.2", "203.0.113.9"},
+		{"trusted peer uses forwarded client", "10.0.0.5:5000", "198.51.100.1", "", "198.51.100.1"},
+		{"skips trusted hops right to left", "10.0.0.5:5000", "198.51.100.1, 192.0.2.7, 10.0.0.6", "", "192.0.2.7"},
+		{"spoofed leftmost entry is not trusted", "10.0.0.5:5000", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"falls back to X-Real-IP", "10.0.0.5:5000", "", "198.51.100.3", "198.51.100.3"},
+		{"trusted peer without headers", "10.0.0.5:5000", "", "", "10.0.0.5"},
+		{"ipv6 peer", "[2001:db8::1]:443", "198.51.100.1", "", "2001:db8::1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/health", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+			if tc.realIP != "" {
+				req.Header.Set("X-Real-IP", tc.realIP)
+			}
+			if got := clientIP(req, trusted); got != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+func (rt *router) handleExportAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	dataset, err := exportDataset(r.Context(), rt.repo)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("assetra-export-%s.json", rt.now().UTC().Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	writeJSON(w, http.StatusOK, dataset)
+}
+
+func (rt *router) handleImportAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, actionMethods)
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, actionMethods)
+		return
+	}
+
+	mode := repository.ImportMode(r.URL.Query().Get("mode"))
+	if mode == "" {
+		mode = repository.ImportMerge
+	}
+	if mode != repository.ImportMerge && mode != repository.ImportReplace {
+		badRequest(w, fmt.Errorf("mode %q is invalid", mode))
+		return
+	}
+
+	var dataset finance.SeedData
+	if err := decodeJSONBodyLimit(w, r, &dataset, maxImportBodyBytes); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	if err := rt.validateDataset(&dataset); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	if err := rt.repo.ImportDataset(r.Context(), dataset, mode); err != nil {
+		handleRepoError(w, err)
+		return
+	}
+
+	summary := map[string]any{
+		"mode":              mode,
+		"assets":            len(dataset.Assets),
+		"liabilities":       len(dataset.Liabilities),
+		"incomes":           len(dataset.Incomes),
+		"expenses":          len(dataset.Expenses),
+		"propertyScenarios": len(dataset.PropertyScenarios),
+		"goals":             len(dataset.Goals),
+		"accounts":          len(dataset.Accounts),
+		"transactions":      len(dataset.Transactions),
+	}
+	// One event for the whole import; clients refetch instead of replaying every row.
+	rt.publishChange("dataset", events.ActionImport, "", summary)
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// exportDataset collects every finance entity into a single document. Empty
+// collections are returned as empty slices so they serialize as [] rather than null.
+func exportDataset(ctx context.Context, repo repository.Repository) (finance.SeedData, error) {
+	assets, err := repo.Assets().List(ctx)
+	if err != nil {
+		return finance.SeedData{}, err
+	}
+	liabilities, err := repo.Liabilities().List(ctx)
+	if err != nil {
+		return finance.SeedData{}, err
+	}
+	incomes, err := repo.Incomes().List(ctx)
+	if err != nil {
+		return finance.SeedData{}, err
+	}
+	expenses, err := repo.Expenses().List(ctx)
+	if err != nil {
+		return finance.SeedData{}, err
+	}
+	scenarios, err := repo.PropertyPlanner().List(ctx)
+	if err != nil {
+		return finance.SeedData{}, err
+	}
+	goals, err := repo.Goals().List(ctx)
+	if err != nil {
+		return finance.SeedData{}, err
+	}
+	accounts, err := repo.Accounts().List(ctx)
+	if err != nil {
+		return finance.SeedData{}, err
+	}
+	transactions, err := repo.Transactions().List(ctx, repository.TransactionFilter{})
+	if err != nil {
+		return finance.SeedData{}, err
+	}
+
+	return finance.SeedData{
+		Assets:            nonNil(assets),
+		Liabilities:       nonNil(liabilities),
+		Incomes:           nonNil(incomes),
+		Expenses:          nonNil(expenses),
+		PropertyScenarios: nonNil(scenarios),
+		Goals:             nonNil(goals),
+		Accounts:          nonNil(accounts),
+		Transactions:      nonNil(transactions),
+	}, nil
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// validateDataset applies the same rules as the per-entity endpoints, including the
+// category taxonomy and text limits, and normalizes currencies and cleans notes in
+// place. Errors name the offending entry, e.g. "incomes[3]: ...".
+func (rt *router) validateDataset(data *finance.SeedData) error {
+	for i, account := range data.Accounts {
+		if err := rt.limits.check("", nil, textField{"name", account.Name}, textField{"institution", account.Institution}); err != nil {
+			return fmt.Errorf("accounts[%d]: %w", i, err)
+		}
+		if strings.TrimSpace(account.Name) == "" {
+			return fmt.Errorf("accounts[%d]: name is required", i)
+		}
+	}
+	for i := range data.Assets {
+		asset := &data.Assets[i]
+		if err := rt.limits.check("notes", &asset.Notes, textField{"name", asset.Name}, textField{"category", asset.Category}); err != nil {
+			return fmt.Errorf("assets[%d]: %w", i, err)
+		}
+		if err := validateNamed(asset.Name, asset.Category, asset.Currency); err != nil {
+			return fmt.Errorf("assets[%d]: %w", i, err)
+		}
+		if err := rt.categories.check(categoryAssets, asset.Category); err != nil {
+			return fmt.Errorf("assets[%d]: %w", i, err)
+		}
+		if asset.CurrentValue < 0 {
+			return fmt.Errorf("assets[%d]: currentValue must not be negative; record an overdrawn balance as a liability", i)
+		}
+		asset.Currency = normalizeCurrency(asset.Currency)
+		asset.Notes = rt.notes.clean(asset.Notes)
+	}
+	for i := range data.Liabilities {
+		liability := &data.Liabilities[i]
+		if err := rt.limits.check("notes", &liability.Notes, textField{"name", liability.Name}, textField{"category", liability.Category}); err != nil {
+			return fmt.Errorf("liabilities[%d]: %w", i, err)
+		}
+		if err := validateNamed(liability.Name, liability.Category, liability.Currency); err != nil {
+			return fmt.Errorf("liabilities[%d]: %w", i, err)
+		}
+		if err := rt.categories.check(categoryLiabilities, liability.Category); err != nil {
+			return fmt.Errorf("liabilities[%d]: %w", i, err)
+		}
+		if liability.InterestRateAPR < 0 {
+			return fmt.Errorf("liabilities[%d]: interestRateApr must not be negative", i)
+		}
+		liability.Currency = normalizeCurrency(liability.Currency)
+		liability.Notes = rt.notes.clean(liability.Notes)
+	}
+	for i := range data.Incomes {
+		income := &data.Incomes[i]
+		if err := rt.limits.check("notes", &income.Notes, textField{"source", income.Source}, textField{"category", income.Category}); err != nil {
+			return fmt.Errorf("incomes[%d]: %w", i, err)
+		}
+		if strings.TrimSpace(income.Source) == "" {
+			return fmt.Errorf("incomes[%d]: source is required", i)
+		}
+		if income.StartDate.IsZero() {
+			return fmt.Errorf("incomes[%d]: startDate is required", i)
+		}
+		if err := validateStartDate(income.StartDate, rt.now()); err != nil {
+			return fmt.Errorf("incomes[%d]: %w", i, err)
+		}
+		if err := validateRecurring(income.Amount, income.Frequency, income.Currency, income.DayOfMonth, income.DayOfWeek); err != nil {
+			return fmt.Errorf("incomes[%d]: %w", i, err)
+		}
+		if err := rt.categories.check(categoryIncomes, income.Category); err != nil {
+			return fmt.Errorf("incomes[%d]: %w", i, err)
+		}
+		income.Currency = normalizeCurrency(income.Currency)
+		income.Notes = rt.notes.clean(income.Notes)
+		interval, err := parseInterval(income.Interval)
+		if err != nil {
+			return fmt.Errorf("incomes[%d]: %w", i, err)
+		}
+		income.Interval = interval
+	}
+	for i := range data.Expenses {
+		expense := &data.Expenses[i]
+		if err := rt.limits.check("notes", &expense.Notes, textField{"payee", expense.Payee}, textField{"category", expense.Category}); err != nil {
+			return fmt.Errorf("expenses[%d]: %w", i, err)
+		}
+		if strings.TrimSpace(expense.Payee) == "" {
+			return fmt.Errorf("expenses[%d]: payee is required", i)
+		}
+		if err := validateRecurring(expense.Amount, expense.Frequency, expense.Currency, expense.DayOfMonth, expense.DayOfWeek); err != nil {
+			return fmt.Errorf("expenses[%d]: %w", i, err)
+		}
+		if err := rt.categories.check(categoryExpenses, expense.Category); err != nil {
+			return fmt.Errorf("expenses[%d]: %w", i, err)
+		}
+		expense.Currency = normalizeCurrency(expense.Currency)
+		expense.Notes = rt.notes.clean(expense.Notes)
+		interval, err := parseInterval(expense.Interval)
+		if err != nil {
+			return fmt.Errorf("expenses[%d]: %w", i, err)
+		}
+		expense.Interval = interval
+	}
+	for i, scenario := range data.PropertyScenarios {
+		if strings.TrimSpace(scenario.Type) == "" {
+			return fmt.Errorf("propertyScenarios[%d]: type is required", i)
+		}
+		if strings.TrimSpace(scenario.Headline) == "" {
+			return fmt.Errorf("propertyScenarios[%d]: headline is required", i)
+		}
+		if err := scenario.Inputs.Validate(); err != nil {
+			return fmt.Errorf("propertyScenarios[%d]: inputs: %w", i, err)
+		}
+	}
+	for i := range data.Goals {
+		goal := &data.Goals[i]
+		if err := rt.limits.check("notes", &goal.Notes, textField{"name", goal.Name}, textField{"assetCategory", goal.AssetCategory}); err != nil {
+			return fmt.Errorf("goals[%d]: %w", i, err)
+		}
+		if err := validateGoal(goal.Name, goal.TargetAmount, goal.AssetCategory); err != nil {
+			return fmt.Errorf("goals[%d]: %w", i, err)
+		}
+		goal.Notes = rt.notes.clean(goal.Notes)
+	}
+	for i := range data.Transactions {
+		txn := &data.Transactions[i]
+		if err := rt.limits.check("memo", &txn.Memo, textField{"category", txn.Category}); err != nil {
+			return fmt.Errorf("transactions[%d]: %w", i, err)
+		}
+		if txn.Date.IsZero() {
+			return fmt.Errorf("transactions[%d]: date is required", i)
+		}
+		if err := validateTransaction(txn.Amount, txn.Direction, txn.Category, txn.Currency); err != nil {
+			return fmt.Errorf("transactions[%d]: %w", i, err)
+		}
+		txn.Currency = normalizeCurrency(txn.Currency)
+	}
+	return nil
+}
+
+func validateNamed(name, category, currency string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	if strings.TrimSpace(category) == "" {
+		return errors.New("category is required")
+	}
+	return validateCurrency(currency)
+}
+
+func validateRecurring(amount float64, frequency finance.Frequency, currency string, dayOfMonth, dayOfWeek *int) error {
+	if amount <= 0 {
+		return errors.New("amount must be greater than zero")
+	}
+	if !frequency.Valid() {
+		return fmt.Errorf("frequency %q is invalid", frequency)
+	}
+	if err := validateCurrency(currency); err != nil {
+		return err
+	}
+	return validateAnchors(dayOfMonth, dayOfWeek)
+}
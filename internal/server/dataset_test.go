@@ -0,0 +1,230 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestExportAllReturnsEveryCollection(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	seed := finance.DefaultSeedData(time.Now().UTC())
+	repo := memory.NewRepository(seed)
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/export/all", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if disposition := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment; filename=") {
+		t.Fatalf("expected attachment disposition, got %q", disposition)
+	}
+
+	var dataset finance.SeedData
+	if err := json.Unmarshal(rec.Body.Bytes(), &dataset); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if len(dataset.Assets) != len(seed.Assets) || len(dataset.Expenses) != len(seed.Expenses) {
+		t.Fatalf("expected %d assets and %d expenses, got %d and %d",
+			len(seed.Assets), len(seed.Expenses), len(dataset.Assets), len(dataset.Expenses))
+	}
+}
+
+func TestExportAllSerializesEmptyCollectionsAsArrays(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/export/all", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &raw); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	for _, key := range []string{"assets", "liabilities", "incomes", "expenses", "propertyScenarios"} {
+		if string(raw[key]) != "[]" {
+			t.Fatalf("expected %s to be [], got %s", key, raw[key])
+		}
+	}
+}
+
+func TestImportAllRoundTripsExport(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	source := newRouter(logger, memory.NewRepository(finance.DefaultSeedData(time.Now().UTC())), events.NewHub(events.WithDebounceWindow(0)))
+
+	exportRec := httptest.NewRecorder()
+	source.ServeHTTP(exportRec, httptest.NewRequest(http.MethodGet, "/export/all", nil))
+
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	targetRepo := memory.NewRepository(finance.SeedData{})
+	target := newRouter(logger, targetRepo, hub)
+
+	req := httptest.NewRequest(http.MethodPost, "/import/all", strings.NewReader(exportRec.Body.String()))
+	rec := httptest.NewRecorder()
+	target.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	reexportRec := httptest.NewRecorder()
+	target.ServeHTTP(reexportRec, httptest.NewRequest(http.MethodGet, "/export/all", nil))
+
+	var before, after finance.SeedData
+	if err := json.Unmarshal(exportRec.Body.Bytes(), &before); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	if err := json.Unmarshal(reexportRec.Body.Bytes(), &after); err != nil {
+		t.Fatalf("failed to decode re-export: %v", err)
+	}
+	if len(after.Assets) != len(before.Assets) || len(after.Incomes) != len(before.Incomes) ||
+		len(after.PropertyScenarios) != len(before.PropertyScenarios) {
+		t.Fatalf("expected re-export to match export, got %+v", after)
+	}
+
+	history := hub.History("", 0)
+	if len(history) != 1 {
+		t.Fatalf("expected a single import event, got %d", len(history))
+	}
+	if history[0].Entity != "dataset" || history[0].Action != "import" {
+		t.Fatalf("expected dataset.import event, got %s.%s", history[0].Entity, history[0].Action)
+	}
+}
+
+func TestImportAllMergeUpsertsByID(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{
+			{ID: "keep", Name: "Savings", Category: "cash", CurrentValue: 100},
+			{ID: "update", Name: "Brokerage", Category: "investment", CurrentValue: 200},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	body := `{"assets":[{"id":"update","name":"Brokerage","category":"investment","currentValue":250}]}`
+	req := httptest.NewRequest(http.MethodPost, "/import/all?mode=merge", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	assets, _ := repo.Assets().List(context.Background())
+	if len(assets) != 2 {
+		t.Fatalf("expected merge to keep existing assets, got %d", len(assets))
+	}
+	updated, err := repo.Assets().Get(context.Background(), "update")
+	if err != nil || updated.CurrentValue != 250 {
+		t.Fatalf("expected asset to be upserted to 250, got %+v (%v)", updated, err)
+	}
+}
+
+func TestImportAllReplaceWipesExistingData(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.DefaultSeedData(time.Now().UTC()))
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	body := `{"assets":[{"id":"only","name":"Cash","category":"cash","currentValue":10}]}`
+	req := httptest.NewRequest(http.MethodPost, "/import/all?mode=replace", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx := context.Background()
+	assets, _ := repo.Assets().List(ctx)
+	incomes, _ := repo.Incomes().List(ctx)
+	expenses, _ := repo.Expenses().List(ctx)
+	if len(assets) != 1 || assets[0].ID != "only" {
+		t.Fatalf("expected only the imported asset, got %+v", assets)
+	}
+	if len(incomes) != 0 || len(expenses) != 0 {
+		t.Fatalf("expected replace to wipe cash flow, got %d incomes and %d expenses", len(incomes), len(expenses))
+	}
+}
+
+func TestImportAllRejectsInvalidEntityWithoutWriting(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	body := `{"assets":[{"name":"Cash","category":"cash"}],"expenses":[{"payee":"Rent","amount":0,"frequency":"monthly","category":"housing"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/import/all?mode=replace", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "expenses[0]") {
+		t.Fatalf("expected error to name the invalid entry, got %s", rec.Body.String())
+	}
+
+	assets, _ := repo.Assets().List(context.Background())
+	if len(assets) != 0 {
+		t.Fatalf("expected nothing to be written, got %d assets", len(assets))
+	}
+}
+
+func TestImportAllAppliesEndpointInputChecks(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	longName := strings.Repeat("x", defaultMaxNameLength+1)
+
+	for _, tc := range []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{"overlong asset name", `{"assets":[{"name":"` + longName + `","category":"cash"}]}`, http.StatusUnprocessableEntity, "assets[0]: name must be at most"},
+		{"overlong expense notes", `{"expenses":[{"payee":"Rent","amount":10,"frequency":"monthly","category":"housing","notes":"` + strings.Repeat("n", defaultMaxNotesLength+1) + `"}]}`, http.StatusUnprocessableEntity, "expenses[0]: notes must be at most"},
+		{"control character in income source", `{"incomes":[{"source":"Pay\u0000roll","amount":10,"frequency":"monthly","startDate":"2024-01-01T00:00:00Z","category":"salary"}]}`, http.StatusUnprocessableEntity, "incomes[0]: source must not contain control character"},
+		{"control character in account name", `{"accounts":[{"name":"Check\u0007ing"}]}`, http.StatusUnprocessableEntity, "accounts[0]: name must not contain control character"},
+		{"overlong goal category", `{"goals":[{"name":"House","targetAmount":100,"assetCategory":"` + longName + `"}]}`, http.StatusUnprocessableEntity, "goals[0]: assetCategory must be at most"},
+		{"overlong transaction memo", `{"transactions":[{"date":"2024-01-01T00:00:00Z","amount":5,"direction":"outflow","category":"food","memo":"` + strings.Repeat("m", defaultMaxNotesLength+1) + `"}]}`, http.StatusUnprocessableEntity, "transactions[0]: memo must be at most"},
+		{"invalid mortgage inputs", `{"propertyScenarios":[{"type":"hdb","headline":"Resale","inputs":{"loanTermYears":0}}]}`, http.StatusBadRequest, "propertyScenarios[0]: inputs: loanTermYears"},
+		{"negative floating rate", `{"propertyScenarios":[{"type":"hdb","headline":"Resale","inputs":{"loanTermYears":25,"fixedRate":0.03,"floatingRate":-1}}]}`, http.StatusBadRequest, "propertyScenarios[0]: inputs: floatingRate"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := memory.NewRepository(finance.SeedData{})
+			router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/import/all", strings.NewReader(tc.body)))
+			if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.want) {
+				t.Fatalf("expected %d naming %q, got %d: %s", tc.status, tc.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportAllSanitizesNotes(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	body := `{"assets":[{"id":"asset-1","name":"Cash","category":"cash","notes":"Rainy day<script>alert(1)</script>"}]}`
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/import/all", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	asset, err := repo.Assets().Get(context.Background(), "asset-1")
+	if err != nil || asset.Notes != "Rainy day" {
+		t.Fatalf("expected imported notes stripped of markup, got %q, %v", asset.Notes, err)
+	}
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"time"
+
+	"github.com/jcleow/assetra2/internal/finance"
+)
+
+// Response DTOs decouple the wire format from the finance models the repositories
+// store. Field names and tags match the models so existing clients see the same JSON;
+// computed fields belong here rather than on the models. Property scenarios are the
+// planner UI's own document and are still served as stored.
+
+// assetResponse is an asset together with values derived from it at read time.
+type assetResponse struct {
+	ID                    string    `json:"id"`
+	Name                  string    `json:"name"`
+	Category              string    `json:"category"`
+	CurrentValue          money     `json:"currentValue"`
+	AnnualGrowthRate      float64   `json:"annualGrowthRate"`
+	Currency              string    `json:"currency,omitempty"`
+	AccountID             string    `json:"accountId,omitempty"`
+	LinkedLiabilityID     string    `json:"linkedLiabilityId,omitempty"`
+	Notes                 string    `json:"notes,omitempty"`
+	UpdatedAt             time.Time `json:"updatedAt"`
+	ProjectedValueOneYear money     `json:"projectedValueOneYear"`
+}
+
+func newAssetResponse(asset finance.Asset) assetResponse {
+	return assetResponse{
+		ID:                    asset.ID,
+		Name:                  asset.Name,
+		Category:              asset.Category,
+		CurrentValue:          newMoney(asset.CurrentValue, asset.Currency),
+		AnnualGrowthRate:      asset.AnnualGrowthRate,
+		Currency:              asset.Currency,
+		AccountID:             asset.AccountID,
+		LinkedLiabilityID:     asset.LinkedLiabilityID,
+		Notes:                 asset.Notes,
+		UpdatedAt:             asset.UpdatedAt,
+		ProjectedValueOneYear: newMoney(finance.ProjectedValue(asset, 1), asset.Currency),
+	}
+}
+
+type liabilityResponse struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Category        string    `json:"category"`
+	CurrentBalance  money     `json:"currentBalance"`
+	InterestRateAPR float64   `json:"interestRateApr"`
+	MinimumPayment  money     `json:"minimumPayment"`
+	Currency        string    `json:"currency,omitempty"`
+	AccountID       string    `json:"accountId,omitempty"`
+	Notes           string    `json:"notes,omitempty"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+func newLiabilityResponse(liability finance.Liability) liabilityResponse {
+	return liabilityResponse{
+		ID:              liability.ID,
+		Name:            liability.Name,
+		Category:        liability.Category,
+		CurrentBalance:  newMoney(liability.CurrentBalance, liability.Currency),
+		InterestRateAPR: liability.InterestRateAPR,
+		MinimumPayment:  newMoney(liability.MinimumPayment, liability.Currency),
+		Currency:        liability.Currency,
+		AccountID:       liability.AccountID,
+		Notes:           liability.Notes,
+		UpdatedAt:       liability.UpdatedAt,
+	}
+}
+
+// incomeResponse adds monthlyAmount, the entry normalized to a monthly cadence in its
+// own currency. Amounts are money, so they serialize rounded to the currency's minor
+// units.
+type incomeResponse struct {
+	ID            string            `json:"id"`
+	Source        string            `json:"source"`
+	Amount        money             `json:"amount"`
+	Currency      string            `json:"currency,omitempty"`
+	Frequency     finance.Frequency `json:"frequency"`
+	Interval      string            `json:"interval,omitempty"`
+	StartDate     time.Time         `json:"startDate"`
+	Category      string            `json:"category"`
+	Notes         string            `json:"notes,omitempty"`
+	DayOfMonth    *int              `json:"dayOfMonth,omitempty"`
+	DayOfWeek     *int              `json:"dayOfWeek,omitempty"`
+	UpdatedAt     time.Time         `json:"updatedAt"`
+	MonthlyAmount money             `json:"monthlyAmount"`
+}
+
+func newIncomeResponse(income finance.Income) incomeResponse {
+	return incomeResponse{
+		ID:            income.ID,
+		Source:        income.Source,
+		Amount:        newMoney(income.Amount, income.Currency),
+		Currency:      income.Currency,
+		Frequency:     income.Frequency,
+		Interval:      income.Interval,
+		StartDate:     income.StartDate,
+		Category:      income.Category,
+		Notes:         income.Notes,
+		DayOfMonth:    income.DayOfMonth,
+		DayOfWeek:     income.DayOfWeek,
+		UpdatedAt:     income.UpdatedAt,
+		MonthlyAmount: newMoney(income.MonthlyAmount(), income.Currency),
+	}
+}
+
+// expenseResponse adds monthlyAmount the same way as incomeResponse.
+type expenseResponse struct {
+	ID            string            `json:"id"`
+	Payee         string            `json:"payee"`
+	Amount        money             `json:"amount"`
+	Currency      string            `json:"currency,omitempty"`
+	Frequency     finance.Frequency `json:"frequency"`
+	Interval      string            `json:"interval,omitempty"`
+	Category      string            `json:"category"`
+	Notes         string            `json:"notes,omitempty"`
+	DayOfMonth    *int              `json:"dayOfMonth,omitempty"`
+	DayOfWeek     *int              `json:"dayOfWeek,omitempty"`
+	UpdatedAt     time.Time         `json:"updatedAt"`
+	MonthlyAmount money             `json:"monthlyAmount"`
+}
+
+func newExpenseResponse(expense finance.Expense) expenseResponse {
+	return expenseResponse{
+		ID:            expense.ID,
+		Payee:         expense.Payee,
+		Amount:        newMoney(expense.Amount, expense.Currency),
+		Currency:      expense.Currency,
+		Frequency:     expense.Frequency,
+		Interval:      expense.Interval,
+		Category:      expense.Category,
+		Notes:         expense.Notes,
+		DayOfMonth:    expense.DayOfMonth,
+		DayOfWeek:     expense.DayOfWeek,
+		UpdatedAt:     expense.UpdatedAt,
+		MonthlyAmount: newMoney(expense.MonthlyAmount(), expense.Currency),
+	}
+}
+
+type accountResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Institution string    `json:"institution,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func newAccountResponse(account finance.Account) accountResponse {
+	return accountResponse{
+		ID:          account.ID,
+		Name:        account.Name,
+		Institution: account.Institution,
+		UpdatedAt:   account.UpdatedAt,
+	}
+}
+
+type transactionResponse struct {
+	ID        string                       `json:"id"`
+	Date      time.Time                    `json:"date"`
+	Amount    money                        `json:"amount"`
+	Currency  string                       `json:"currency,omitempty"`
+	Direction finance.TransactionDirection `json:"direction"`
+	Category  string                       `json:"category"`
+	AccountID string                       `json:"accountId,omitempty"`
+	Memo      string                       `json:"memo,omitempty"`
+	UpdatedAt time.Time                    `json:"updatedAt"`
+}
+
+func newTransactionResponse(txn finance.Transaction) transactionResponse {
+	return transactionResponse{
+		ID:        txn.ID,
+		Date:      txn.Date,
+		Amount:    newMoney(txn.Amount, txn.Currency),
+		Currency:  txn.Currency,
+		Direction: txn.Direction,
+		Category:  txn.Category,
+		AccountID: txn.AccountID,
+		Memo:      txn.Memo,
+		UpdatedAt: txn.UpdatedAt,
+	}
+}
+
+// mapResponses converts a slice of models with fn. The result is never nil, so empty
+// lists still serialize as [].
+func mapResponses[T, R any](items []T, fn func(T) R) []R {
+	out := make([]R, 0, len(items))
+	for _, item := range items {
+		out = append(out, fn(item))
+	}
+	return out
+}
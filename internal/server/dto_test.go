@@ -0,0 +1,268 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+// TestResponsesKeepModelJSON checks every DTO serializes the model's fields unchanged,
+// with computed fields as the only additions.
+func TestResponsesKeepModelJSON(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 15
+	asset := finance.Asset{ID: "a1", Name: "Brokerage", Category: "investments", CurrentValue: 1000, AnnualGrowthRate: 0.05, Currency: "EUR", AccountID: "acc", Notes: "n", UpdatedAt: now}
+	liability := finance.Liability{ID: "l1", Name: "Card", Category: "credit", CurrentBalance: 500, InterestRateAPR: 0.2, MinimumPayment: 25, Notes: "n", UpdatedAt: now}
+	income := finance.Income{ID: "i1", Source: "Salary", Amount: 5000, Frequency: finance.FrequencyMonthly, StartDate: now, Category: "salary", DayOfMonth: &day, UpdatedAt: now}
+	expense := finance.Expense{ID: "e1", Payee: "Rent", Amount: 2000, Frequency: finance.FrequencyMonthly, Interval: "P2W", Category: "housing", UpdatedAt: now}
+	account := finance.Account{ID: "acc", Name: "Checking", Institution: "Bank", UpdatedAt: now}
+	txn := finance.Transaction{ID: "t1", Date: now, Amount: 12.5, Direction: finance.TransactionOutflow, Category: "groceries", Memo: "m", UpdatedAt: now}
+
+	cases := []struct {
+		name     string
+		model    any
+		response any
+		computed []string
+	}{
+		{
+			name:     "asset",
+			model:    asset,
+			response: newAssetResponse(asset),
+			computed: []string{"projectedValueOneYear"},
+		},
+		{
+			name:     "liability",
+			model:    liability,
+			response: newLiabilityResponse(liability),
+		},
+		{
+			name:     "income",
+			model:    income,
+			response: newIncomeResponse(income),
+			computed: []string{"monthlyAmount"},
+		},
+		{
+			name:     "expense",
+			model:    expense,
+			response: newExpenseResponse(expense),
+			computed: []string{"monthlyAmount"},
+		},
+		{
+			name:     "account",
+			model:    account,
+			response: newAccountResponse(account),
+		},
+		{
+			name:     "transaction",
+			model:    txn,
+			response: newTransactionResponse(txn),
+		},
+	}
+
+	for _, tc := range cases {
+		model := toJSONMap(t, tc.model)
+		response := toJSONMap(t, tc.response)
+		for _, key := range tc.computed {
+			if _, ok := response[key]; !ok {
+				t.Fatalf("%s: expected computed field %q", tc.name, key)
+			}
+			delete(response, key)
+		}
+		if !reflect.DeepEqual(model, response) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, model, response)
+		}
+	}
+}
+
+func TestMapResponsesNeverReturnsNil(t *testing.T) {
+	got := mapResponses([]finance.Account(nil), newAccountResponse)
+	if got == nil || len(got) != 0 {
+		t.Fatalf("expected empty slice, got %#v", got)
+	}
+}
+
+func toJSONMap(t *testing.T, v any) map[string]any {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out
+}
+
+func TestCashFlowResponsesIncludeMonthlyAmount(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Incomes: []finance.Income{
+			{ID: "bonus", Source: "Bonus", Amount: 1000, Frequency: finance.FrequencyYearly, StartDate: time.Now().UTC(), Category: "salary"},
+		},
+		Expenses: []finance.Expense{
+			{ID: "groceries", Payee: "Market", Amount: 300, Frequency: finance.FrequencyWeekly, Category: "food"},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cashflow/expenses/groceries", nil))
+	var expense expenseResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &expense); err != nil {
+		t.Fatalf("failed to decode expense: %v", err)
+	}
+	if expense.MonthlyAmount.Float64() != 1300 {
+		t.Fatalf("expected weekly 300 to be 1300 a month, got %v", expense.MonthlyAmount.Float64())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cashflow/incomes", nil))
+	var incomes []incomeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &incomes); err != nil {
+		t.Fatalf("failed to decode incomes: %v", err)
+	}
+	if len(incomes) != 1 || incomes[0].MonthlyAmount.Float64() != 83.33 {
+		t.Fatalf("expected yearly 1000 to be 83.33 a month, got %+v", incomes)
+	}
+}
+
+func TestMoneyRoundsToCurrencyPrecision(t *testing.T) {
+	cases := []struct {
+		value    float64
+		currency string
+		want     string
+	}{
+		{3466.6699999999996, "", "3466.67"},
+		{3466.6699999999996, "USD", "3466.67"},
+		{1234.5, "JPY", "1235"},
+		{1.23456, "KWD", "1.235"},
+		{-0.001, "USD", "0"},
+		{1e21, "USD", "1000000000000000000000"},
+	}
+	for _, tc := range cases {
+		raw, err := json.Marshal(newMoney(tc.value, tc.currency))
+		if err != nil {
+			t.Fatalf("marshal %v: %v", tc.value, err)
+		}
+		if string(raw) != tc.want {
+			t.Fatalf("expected %v %s to serialize as %s, got %s", tc.value, tc.currency, tc.want, raw)
+		}
+	}
+
+	raw, err := json.Marshal(newTransactionResponse(finance.Transaction{ID: "t1", Amount: 3466.6699999999996}))
+	if err != nil {
+		t.Fatalf("marshal transaction: %v", err)
+	}
+	if !strings.Contains(string(raw), `"amount":3466.67,`) {
+		t.Fatalf("expected a clean amount, got %s", raw)
+	}
+}
+
+func TestAmountAcceptsNumbersAndDecimalStrings(t *testing.T) {
+	for body, want := range map[string]float64{
+		`{"amount":1234.56}`:   1234.56,
+		`{"amount":"1234.56"}`: 1234.56,
+		`{"amount":"-0.5"}`:    -0.5,
+		`{"amount":".25"}`:     0.25,
+	} {
+		var payload struct {
+			Amount amount `json:"amount"`
+		}
+		if err := json.Unmarshal([]byte(body), &payload); err != nil {
+			t.Fatalf("%s: unexpected error %v", body, err)
+		}
+		if float64(payload.Amount) != want {
+			t.Fatalf("%s: expected %v, got %v", body, want, payload.Amount)
+		}
+	}
+
+	for _, body := range []string{`{"amount":"abc"}`, `{"amount":"1e3"}`, `{"amount":" 12"}`, `{"amount":"1,000"}`, `{"amount":"NaN"}`, `{"amount":""}`} {
+		var payload struct {
+			Amount amount `json:"amount"`
+		}
+		var invalid *invalidAmountError
+		if err := json.Unmarshal([]byte(body), &payload); !errors.As(err, &invalid) {
+			t.Fatalf("%s: expected an invalid amount error, got %v", body, err)
+		}
+	}
+}
+
+func TestCreateExpenseWithDecimalStringAmount(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/cashflow/expenses", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"payee":"Rent","amount":"1234.56","frequency":"monthly"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for a decimal-string amount, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created expenseResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Amount.Float64() != 1234.56 {
+		t.Fatalf("expected amount 1234.56, got %v", created.Amount.Float64())
+	}
+
+	rec = post(`{"payee":"Rent","amount":"12.3.4","frequency":"monthly"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed amount, got %d", rec.Code)
+	}
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != codeInvalidInput || !strings.Contains(body.Error, `got "12.3.4"`) {
+		t.Fatalf("expected a clear amount validation error, got %+v", body)
+	}
+}
+
+func TestExpenseIntervalOverridesFrequency(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/cashflow/expenses", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"payee":"Water","amount":90,"frequency":"monthly","interval":"P12W"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created expenseResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Interval != "P12W" || created.MonthlyAmount.Float64() != 32.5 {
+		t.Fatalf("expected 90 every twelve weeks to be 32.50 a month, got interval=%q monthly=%v", created.Interval, created.MonthlyAmount.Float64())
+	}
+
+	if rec := post(`{"payee":"Water","amount":90,"interval":"every 3 months"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unparseable interval, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// listEnvelope wraps a list response for clients that ask for ?envelope=true.
+type listEnvelope[T any] struct {
+	Data []T      `json:"data"`
+	Meta listMeta `json:"meta"`
+}
+
+// listMeta describes the page inside a listEnvelope. Total counts every matching
+// item, not just the ones in Data.
+type listMeta struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// writeList writes items as a bare JSON array by default. With ?envelope=true the
+// items are wrapped in a listEnvelope and ?limit= / ?offset= select a page; without
+// a limit the page runs to the end of the list.
+func writeList[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	items = nonNil(items)
+
+	query := r.URL.Query()
+	enveloped := false
+	if v := query.Get("envelope"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			badRequest(w, errors.New("envelope must be true or false"))
+			return
+		}
+		enveloped = parsed
+	}
+	if !enveloped {
+		writeJSON(w, http.StatusOK, items)
+		return
+	}
+
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			badRequest(w, errors.New("offset must be a non-negative integer"))
+			return
+		}
+		offset = parsed
+	}
+	limit := -1
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			badRequest(w, errors.New("limit must be a positive integer"))
+			return
+		}
+		limit = parsed
+	}
+
+	total := len(items)
+	page := items[min(offset, total):]
+	if limit >= 0 && len(page) > limit {
+		page = page[:limit]
+	}
+	if limit < 0 {
+		limit = len(page)
+	}
+	writeJSON(w, http.StatusOK, listEnvelope[T]{
+		Data: page,
+		Meta: listMeta{Total: total, Limit: limit, Offset: offset},
+	})
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestListEndpointsDefaultToBareArrays(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	seed := finance.DefaultSeedData(time.Now().UTC())
+	router := newRouter(logger, memory.NewRepository(seed), events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/assets", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var assets []finance.Asset
+	if err := json.Unmarshal(rec.Body.Bytes(), &assets); err != nil {
+		t.Fatalf("expected a bare array, got %s", rec.Body.String())
+	}
+	if len(assets) != len(seed.Assets) {
+		t.Fatalf("expected %d assets, got %d", len(seed.Assets), len(assets))
+	}
+}
+
+func TestListEnvelopeReportsTrueTotal(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	seed := finance.DefaultSeedData(time.Now().UTC())
+	router := newRouter(logger, memory.NewRepository(seed), events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/cashflow/incomes?envelope=true&limit=1&offset=1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var body listEnvelope[finance.Income]
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode envelope: %v", err)
+	}
+	if body.Meta.Total != len(seed.Incomes) {
+		t.Fatalf("expected total %d, got %d", len(seed.Incomes), body.Meta.Total)
+	}
+	if len(body.Data) != 1 || body.Meta.Limit != 1 || body.Meta.Offset != 1 {
+		t.Fatalf("expected one item at offset 1, got %+v", body)
+	}
+
+	emptyReq := httptest.NewRequest(http.MethodGet, "/goals?envelope=true", nil)
+	emptyRec := httptest.NewRecorder()
+	router.ServeHTTP(emptyRec, emptyReq)
+	if got := emptyRec.Body.String(); got != `{"data":[],"meta":{"total":0,"limit":0,"offset":0}}`+"\n" {
+		t.Fatalf("unexpected empty envelope %s", got)
+	}
+
+	badReq := httptest.NewRequest(http.MethodGet, "/assets?envelope=maybe", nil)
+	badRec := httptest.NewRecorder()
+	router.ServeHTTP(badRec, badReq)
+	if badRec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed envelope flag, got %d", badRec.Code)
+	}
+}
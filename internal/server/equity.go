@@ -0,0 +1,108 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+// handleAssetEquity reports an asset's value net of its linked liability, both converted
+// to the base currency.
+func (rt *router) handleAssetEquity(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	asset, err := rt.repo.Assets().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	if asset.LinkedLiabilityID == "" {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("asset %q has no linked liability", id))
+		return
+	}
+	liability, err := rt.repo.Liabilities().Get(r.Context(), asset.LinkedLiabilityID)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeError(w, http.StatusConflict, fmt.Sprintf("linked liability %q no longer exists", asset.LinkedLiabilityID))
+		return
+	}
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	assets, err := rt.currency.Assets([]finance.Asset{asset})
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+	liabilities, err := rt.currency.Liabilities([]finance.Liability{liability})
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, finance.Equity(assets[0], liabilities[0]))
+}
+
+// handleNetWorthEquity breaks net worth down into equity per linked asset and liability
+// pair, in the base currency.
+func (rt *router) handleNetWorthEquity(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	assets, err := rt.repo.Assets().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	liabilities, err := rt.repo.Liabilities().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	if assets, err = rt.currency.Assets(assets); err != nil {
+		aggregationError(w, err)
+		return
+	}
+	if liabilities, err = rt.currency.Liabilities(liabilities); err != nil {
+		aggregationError(w, err)
+		return
+	}
+	writeList(w, r, finance.LinkedEquity(assets, liabilities))
+}
+
+// checkLiabilityRef rejects assets that link to an unknown liability. Like
+// checkAccountRef it writes the error response itself.
+func (rt *router) checkLiabilityRef(w http.ResponseWriter, r *http.Request, liabilityID string) bool {
+	liabilityID = strings.TrimSpace(liabilityID)
+	if liabilityID == "" {
+		return true
+	}
+	_, err := rt.repo.Liabilities().Get(r.Context(), liabilityID)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repository.ErrNotFound):
+		badRequest(w, fmt.Errorf("linkedLiabilityId %q does not exist", liabilityID))
+	default:
+		internalError(w)
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestAssetEquityNetsLinkedMortgage(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Liabilities: []finance.Liability{
+			{ID: "mortgage", Name: "Mortgage", Category: "mortgage", CurrentBalance: 320000},
+		},
+		Assets: []finance.Asset{
+			{ID: "house", Name: "House", Category: "property", CurrentValue: 500000, LinkedLiabilityID: "mortgage"},
+			{ID: "cash", Name: "Cash", Category: "cash", CurrentValue: 9000},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/house/equity", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var equity finance.AssetEquity
+	if err := json.Unmarshal(rec.Body.Bytes(), &equity); err != nil {
+		t.Fatalf("failed to decode equity: %v", err)
+	}
+	if equity.LiabilityID != "mortgage" || equity.Equity != 180000 {
+		t.Fatalf("expected 180000 equity against the mortgage, got %+v", equity)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/cash/equity", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unlinked asset, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/net-worth/equity", nil))
+	var pairs []finance.AssetEquity
+	if err := json.Unmarshal(rec.Body.Bytes(), &pairs); err != nil {
+		t.Fatalf("failed to decode equity breakdown: %v", err)
+	}
+	if len(pairs) != 1 || pairs[0].AssetID != "house" {
+		t.Fatalf("expected one linked pair, got %+v", pairs)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/liabilities/mortgage", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 deleting a linked liability, got %d", rec.Code)
+	}
+	var blocked referencedBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &blocked); err != nil {
+		t.Fatalf("failed to decode conflict: %v", err)
+	}
+	if blocked.BlockedBy != "asset" || len(blocked.BlockingIDs) != 1 || blocked.BlockingIDs[0] != "house" {
+		t.Fatalf("expected the conflict to name the linked asset, got %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/assets/house", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected the asset to delete, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/liabilities/mortgage", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected the unlinked liability to delete, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAssetLinkMustReferenceExistingLiability(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{
+			{ID: "condo", Name: "Condo", Category: "property", CurrentValue: 400000, LinkedLiabilityID: "gone"},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	body := `{"name":"House","category":"property","currentValue":500000,"linkedLiabilityId":"missing"}`
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "linkedLiabilityId") {
+		t.Fatalf("expected 400 naming linkedLiabilityId, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/condo/equity", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a dangling link, got %d", rec.Code)
+	}
+}
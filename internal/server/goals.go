@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+)
+
+// goalResponse is a goal together with its progress against the linked assets.
+type goalResponse struct {
+	finance.Goal
+	Progress finance.GoalProgress `json:"progress"`
+}
+
+func (rt *router) handleGoalsCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		rt.listGoals(w, r)
+	case http.MethodPost:
+		rt.createGoal(w, r)
+	case http.MethodOptions:
+		allowOptions(w, collectionMethods)
+	default:
+		methodNotAllowed(w, collectionMethods)
+	}
+}
+
+func (rt *router) handleGoalItem(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/goals/")
+	if id == "" {
+		notFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		rt.getGoal(w, r, id)
+	case http.MethodPatch:
+		rt.updateGoal(w, r, id)
+	case http.MethodDelete:
+		rt.deleteGoal(w, r, id)
+	case http.MethodOptions:
+		allowOptions(w, itemMethods)
+	default:
+		methodNotAllowed(w, itemMethods)
+	}
+}
+
+func (rt *router) listGoals(w http.ResponseWriter, r *http.Request) {
+	asOf, err := rt.asOf(r)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	goals, err := rt.repo.Goals().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	responses, err := rt.evaluateGoals(r.Context(), asOf, goals...)
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+	writeList(w, r, responses)
+}
+
+func (rt *router) getGoal(w http.ResponseWriter, r *http.Request, id string) {
+	asOf, err := rt.asOf(r)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	goal, err := rt.repo.Goals().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	responses, err := rt.evaluateGoals(r.Context(), asOf, goal)
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, responses[0])
+}
+
+func (rt *router) createGoal(w http.ResponseWriter, r *http.Request) {
+	var payload goalPayload
+	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+	goal, err := payload.toGoal(rt.notes)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	created, err := rt.repo.Goals().Create(r.Context(), goal)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	rt.writeGoal(w, r, http.StatusCreated, created)
+	rt.publishChange("goal", events.ActionCreate, created.ID, created)
+}
+
+func (rt *router) updateGoal(w http.ResponseWriter, r *http.Request, id string) {
+	existing, err := rt.repo.Goals().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	var payload goalPayload
+	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+
+	payload.ID = id
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+	goal, err := payload.toGoal(rt.notes)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	updated, err := rt.repo.Goals().Update(r.Context(), goal)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	rt.writeGoal(w, r, http.StatusOK, updated)
+	rt.publishUpdate("goal", updated.ID, existing, updated)
+}
+
+func (rt *router) deleteGoal(w http.ResponseWriter, r *http.Request, id string) {
+	if err := rt.repo.Goals().Delete(r.Context(), id); err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	rt.publishChange("goal", events.ActionDelete, id, map[string]string{"id": id})
+}
+
+// writeGoal responds with the saved goal and its progress.
+func (rt *router) writeGoal(w http.ResponseWriter, r *http.Request, status int, goal finance.Goal) {
+	responses, err := rt.evaluateGoals(r.Context(), rt.now(), goal)
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+	writeJSON(w, status, responses[0])
+}
+
+// evaluateGoals attaches progress to each goal. Assets and cash flows are converted
+// to the base currency, and the net monthly cash flow is treated as the savings rate.
+// Projected dates count forward from asOf.
+func (rt *router) evaluateGoals(ctx context.Context, asOf time.Time, goals ...finance.Goal) ([]goalResponse, error) {
+	assets, err := rt.repo.Assets().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	incomes, err := rt.repo.Incomes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := rt.repo.Expenses().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if assets, err = rt.currency.Assets(assets); err != nil {
+		return nil, err
+	}
+	if incomes, expenses, err = rt.toBaseCurrency(incomes, expenses); err != nil {
+		return nil, err
+	}
+	savings := finance.MonthlyCashFlow(incomes, expenses).NetMonthly
+
+	now := asOf.UTC()
+	responses := make([]goalResponse, 0, len(goals))
+	for _, goal := range goals {
+		responses = append(responses, goalResponse{
+			Goal:     goal,
+			Progress: finance.EvaluateGoal(goal, assets, savings, now),
+		})
+	}
+	return responses, nil
+}
+
+type goalPayload struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	TargetAmount  amount  `json:"targetAmount"`
+	TargetDate    string  `json:"targetDate"`
+	AssetCategory string  `json:"assetCategory"`
+	Notes         *string `json:"notes"`
+}
+
+func (p goalPayload) validate(limits fieldLimits) error {
+	if err := limits.check("notes", p.Notes, textField{"name", p.Name}, textField{"assetCategory", p.AssetCategory}); err != nil {
+		return err
+	}
+	return validateGoal(p.Name, float64(p.TargetAmount), p.AssetCategory)
+}
+
+func (p goalPayload) toGoal(notes notesMode) (finance.Goal, error) {
+	goal := finance.Goal{
+		ID:            p.ID,
+		Name:          strings.TrimSpace(p.Name),
+		TargetAmount:  float64(p.TargetAmount),
+		AssetCategory: strings.TrimSpace(p.AssetCategory),
+		Notes:         notes.clean(stringOrEmpty(p.Notes)),
+	}
+	if strings.TrimSpace(p.TargetDate) != "" {
+		targetDate, err := time.Parse(time.RFC3339, p.TargetDate)
+		if err != nil {
+			return finance.Goal{}, fmt.Errorf("invalid targetDate: %w", err)
+		}
+		goal.TargetDate = &targetDate
+	}
+	return goal, nil
+}
+
+func validateGoal(name string, targetAmount float64, assetCategory string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	if targetAmount <= 0 {
+		return errors.New("targetAmount must be greater than zero")
+	}
+	if strings.TrimSpace(assetCategory) == "" {
+		return errors.New("assetCategory is required")
+	}
+	return nil
+}
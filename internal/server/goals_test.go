@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestGoalCRUDHandlers(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	createBody := `{"name":"House deposit","targetAmount":50000,"assetCategory":"cash"}`
+	createReq := httptest.NewRequest(http.MethodPost, "/goals", strings.NewReader(createBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("expected create status 201, got %d", createRec.Code)
+	}
+
+	var created goalResponse
+	if err := json.Unmarshal(createRec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to decode created goal: %v", err)
+	}
+	if created.ID == "" {
+		t.Fatalf("expected ID to be set")
+	}
+
+	updateBody := `{"name":"House deposit","targetAmount":60000,"targetDate":"2030-01-01T00:00:00Z","assetCategory":"cash"}`
+	updateReq := httptest.NewRequest(http.MethodPatch, "/goals/"+created.ID, strings.NewReader(updateBody))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateRec := httptest.NewRecorder()
+	router.ServeHTTP(updateRec, updateReq)
+	if updateRec.Code != http.StatusOK {
+		t.Fatalf("expected update status 200, got %d", updateRec.Code)
+	}
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/goals/"+created.ID, nil)
+	deleteRec := httptest.NewRecorder()
+	router.ServeHTTP(deleteRec, deleteReq)
+	if deleteRec.Code != http.StatusNoContent {
+		t.Fatalf("expected delete status 204, got %d", deleteRec.Code)
+	}
+
+	var actions []string
+	for _, evt := range hub.History("", 10) {
+		if evt.Entity == "goal" {
+			actions = append(actions, evt.Action)
+		}
+	}
+	if strings.Join(actions, ",") != "create,update,delete" {
+		t.Fatalf("expected goal create, update and delete events, got %v", actions)
+	}
+}
+
+func TestGoalReportsProgressFromLinkedAssets(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{
+			{ID: "a1", Name: "Savings", Category: "cash", CurrentValue: 10000},
+			{ID: "a2", Name: "Brokerage", Category: "equity", CurrentValue: 90000},
+		},
+		Incomes: []finance.Income{
+			{ID: "i1", Source: "Salary", Amount: 3000, Frequency: finance.FrequencyMonthly, StartDate: time.Now().UTC()},
+		},
+		Expenses: []finance.Expense{
+			{ID: "e1", Payee: "Rent", Amount: 2000, Frequency: finance.FrequencyMonthly},
+		},
+		Goals: []finance.Goal{
+			{ID: "g1", Name: "Emergency fund", TargetAmount: 40000, AssetCategory: "cash"},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/goals/g1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var goal goalResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &goal); err != nil {
+		t.Fatalf("failed to decode goal: %v", err)
+	}
+	if goal.Progress.CurrentAmount != 10000 || goal.Progress.Remaining != 30000 || goal.Progress.Percent != 25 {
+		t.Fatalf("unexpected progress %+v", goal.Progress)
+	}
+	if goal.Progress.ProjectedDate == nil {
+		t.Fatalf("expected a projected date from the 1000/month surplus")
+	}
+	want := time.Now().UTC().AddDate(0, 30, 0)
+	if diff := goal.Progress.ProjectedDate.Sub(want); diff > time.Minute || diff < -time.Minute {
+		t.Fatalf("expected projection near %s, got %s", want, goal.Progress.ProjectedDate)
+	}
+}
+
+func TestGoalCreateRejectsInvalidPayload(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	for _, body := range []string{
+		`{"name":"","targetAmount":100,"assetCategory":"cash"}`,
+		`{"name":"Trip","targetAmount":0,"assetCategory":"cash"}`,
+		`{"name":"Trip","targetAmount":100,"assetCategory":""}`,
+		`{"name":"Trip","targetAmount":100,"assetCategory":"cash","targetDate":"soon"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/goals", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// lifecycle tracks the server's long-running components and stops them in reverse
+// order of registration, so a component is never stopped while something registered
+// after it (and possibly depending on it) is still running.
+type lifecycle struct {
+	logger     *slog.Logger
+	components []component
+}
+
+type component struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+func newLifecycle(logger *slog.Logger) *lifecycle {
+	return &lifecycle{logger: logger}
+}
+
+// onStop registers a component whose stop must honour ctx's deadline.
+func (l *lifecycle) onStop(name string, stop func(ctx context.Context) error) {
+	l.components = append(l.components, component{name: name, stop: stop})
+}
+
+// goroutine starts run in the background and registers it as a component; stopping it
+// cancels run's context and waits for it to return.
+func (l *lifecycle) goroutine(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+	l.onStop(name, func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	})
+}
+
+// shutdown stops every component, newest first. A component that fails or runs out of
+// time is logged and the rest are still stopped; their errors are joined.
+func (l *lifecycle) shutdown(ctx context.Context) error {
+	var errs []error
+	for i := len(l.components) - 1; i >= 0; i-- {
+		c := l.components[i]
+		start := time.Now()
+		if err := c.stop(ctx); err != nil {
+			l.logger.Error("component stop failed", "component", c.name, "elapsed", time.Since(start), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+			continue
+		}
+		l.logger.Info("component stopped", "component", c.name, "elapsed", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
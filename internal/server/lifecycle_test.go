@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLifecycleStopsComponentsInReverseOrder(t *testing.T) {
+	lc := newLifecycle(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	var mu sync.Mutex
+	var stopped []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped = append(stopped, name)
+			return nil
+		}
+	}
+
+	lc.onStop("http", record("http"))
+	lc.onStop("events", record("events"))
+	running := make(chan struct{})
+	lc.goroutine("worker", func(ctx context.Context) {
+		close(running)
+		<-ctx.Done()
+		record("worker")(ctx)
+	})
+	<-running
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := lc.shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if got := strings.Join(stopped, ","); got != "worker,events,http" {
+		t.Fatalf("expected worker,events,http, got %s", got)
+	}
+}
+
+func TestLifecycleShutdownRespectsDeadline(t *testing.T) {
+	lc := newLifecycle(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	httpStopped := false
+	lc.onStop("http", func(context.Context) error {
+		httpStopped = true
+		return nil
+	})
+	release := make(chan struct{})
+	defer close(release)
+	lc.goroutine("stuck", func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := lc.shutdown(ctx)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected shutdown to give up at the deadline, took %s", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stuck") {
+		t.Fatalf("expected a deadline error naming the stuck component, got %v", err)
+	}
+	if !httpStopped {
+		t.Fatal("expected later components to be stopped after one timed out")
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Default length caps, in characters. They are also the most configuration allows:
+// migration 0013 enforces the label cap in the database, and a notes cap five times
+// this one so escaped notes still fit.
+const (
+	defaultMaxNameLength  = 200
+	defaultMaxNotesLength = 2000
+)
+
+const (
+	// codeFieldTooLong means a string field exceeded its length limit.
+	codeFieldTooLong = "field_too_long"
+	// codeInvalidText means a string was not valid UTF-8 or held control characters.
+	codeInvalidText = "invalid_text"
+)
+
+// fieldLimits caps free-text fields so a client cannot store megabytes in one of them.
+// Name covers short labels (names, categories, payees, sources, institutions); Notes
+// covers notes and memos.
+type fieldLimits struct {
+	Name  int
+	Notes int
+}
+
+// fieldTooLongError reports the first field over its limit.
+type fieldTooLongError struct {
+	Field string
+	Max   int
+}
+
+func (e *fieldTooLongError) Error() string {
+	return fmt.Sprintf("%s must be at most %d characters", e.Field, e.Max)
+}
+
+// invalidTextError reports text that Postgres text columns or downstream consumers
+// would choke on: invalid UTF-8, or control characters such as NUL.
+type invalidTextError struct {
+	Field  string
+	Reason string
+}
+
+func (e *invalidTextError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Reason)
+}
+
+// checkText rejects invalid UTF-8 and control characters other than tab, newline and
+// carriage return, so multi-line notes stay valid.
+func checkText(field, value string) error {
+	if !utf8.ValidString(value) {
+		return &invalidTextError{Field: field, Reason: "must be valid UTF-8"}
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return &invalidTextError{Field: field, Reason: fmt.Sprintf("must not contain control character %U", r)}
+		}
+	}
+	return nil
+}
+
+// textField pairs a JSON field name with its submitted value.
+type textField struct {
+	name  string
+	value string
+}
+
+// check validates the text of labels and notes, then applies the name limit to labels
+// and the notes limit to notes, which may be nil when the field was omitted.
+func (l fieldLimits) check(notesField string, notes *string, labels ...textField) error {
+	for _, label := range labels {
+		if err := checkText(label.name, label.value); err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(label.value) > l.Name {
+			return &fieldTooLongError{Field: label.name, Max: l.Name}
+		}
+	}
+	if notes == nil {
+		return nil
+	}
+	if err := checkText(notesField, *notes); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(*notes) > l.Notes {
+		return &fieldTooLongError{Field: notesField, Max: l.Notes}
+	}
+	return nil
+}
+
+// writeUnprocessable renders err as 422 when it is a text-field error, reporting
+// whether it did.
+func writeUnprocessable(w http.ResponseWriter, err error) bool {
+	var tooLong *fieldTooLongError
+	if errors.As(err, &tooLong) {
+		writeErrorCode(w, http.StatusUnprocessableEntity, codeFieldTooLong, err.Error())
+		return true
+	}
+	var invalidText *invalidTextError
+	if errors.As(err, &invalidText) {
+		writeErrorCode(w, http.StatusUnprocessableEntity, codeInvalidText, err.Error())
+		return true
+	}
+	var unknownCategory *unknownCategoryError
+	if errors.As(err, &unknownCategory) {
+		writeErrorCode(w, http.StatusUnprocessableEntity, codeUnknownCategory, err.Error())
+		return true
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestOverLongFieldsAreRejected(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)), withFieldLimits(fieldLimits{Name: 10, Notes: 20}))
+
+	long := strings.Repeat("x", 11)
+	cases := []struct {
+		name  string
+		path  string
+		body  string
+		field string
+	}{
+		{"asset name", "/assets", `{"name":"` + long + `","category":"cash","currentValue":1}`, "name"},
+		{"expense payee", "/cashflow/expenses", `{"payee":"` + long + `","amount":5,"frequency":"monthly"}`, "payee"},
+		{"income notes", "/cashflow/incomes", `{"source":"Job","amount":5,"frequency":"monthly","startDate":"2024-01-01T00:00:00Z","notes":"` + strings.Repeat("n", 21) + `"}`, "notes"},
+		{"account institution", "/accounts", `{"name":"Main","institution":"` + long + `"}`, "institution"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body)))
+			var body errorBody
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if rec.Code != http.StatusUnprocessableEntity || body.Code != codeFieldTooLong || !strings.HasPrefix(body.Error, tc.field+" ") {
+				t.Fatalf("expected 422 naming %s, got %d %+v", tc.field, rec.Code, body)
+			}
+		})
+	}
+
+	// Limits count characters, not bytes.
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(`{"name":"ééééééééé","category":"cash","currentValue":1}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected a 9-character multibyte name to fit, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestControlCharactersAndInvalidUTF8AreRejected(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	cases := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"null byte in name", `{"name":"Cash\u0000","category":"cash","currentValue":1}`, "name must not contain control character U+0000"},
+		{"escape in category", `{"name":"Cash","category":"ca\u001bsh","currentValue":1}`, "category must not contain control character U+001B"},
+		{"bell in notes", `{"name":"Cash","category":"cash","currentValue":1,"notes":"ding\u0007"}`, "notes must not contain control character U+0007"},
+		{"invalid utf-8", "{\"name\":\"Ca\xff\xfesh\",\"category\":\"cash\",\"currentValue\":1}", "body must be valid UTF-8"},
+		{"truncated sequence", "{\"name\":\"Caf\xc3\",\"category\":\"cash\",\"currentValue\":1}", "body must be valid UTF-8"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(tc.body)))
+			var body errorBody
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if rec.Code != http.StatusUnprocessableEntity || body.Code != codeInvalidText || body.Error != tc.want {
+				t.Fatalf("expected 422 %q, got %d %+v", tc.want, rec.Code, body)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	body := `{"name":"Cash","category":"cash","currentValue":1,"notes":"line one\r\nline two\tindented"}`
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected multi-line notes to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
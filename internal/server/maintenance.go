@@ -0,0 +1,36 @@
+package server
+
+import "net/http"
+
+// maintenanceReadOnly is the MAINTENANCE_MODE that rejects writes.
+const maintenanceReadOnly = "readonly"
+
+// codeReadOnly means a write was refused because the service is in read-only
+// maintenance mode; retrying after maintenance ends will succeed.
+const codeReadOnly = "read_only"
+
+// withMaintenanceMode puts the router into a maintenance mode; "readonly" rejects every
+// write, and empty serves normally.
+func withMaintenanceMode(mode string) routerOption {
+	return func(rt *router) {
+		rt.readOnly = mode == maintenanceReadOnly
+	}
+}
+
+// readOnlyMiddleware answers 503 to any request that could change data while the
+// router is read-only. GET, HEAD and OPTIONS pass through, so reads and the /events
+// stream keep working, as does POST /batch, which only runs GETs.
+func (rt *router) readOnlyMiddleware(next http.Handler) http.Handler {
+	if !rt.readOnly {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet, r.Method == http.MethodHead, r.Method == http.MethodOptions,
+			r.URL.Path == "/batch":
+			next.ServeHTTP(w, r)
+		default:
+			writeErrorCode(w, http.StatusServiceUnavailable, codeReadOnly, "the service is in read-only maintenance mode")
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestReadOnlyModeBlocksWritesAndServesReads(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{{ID: "cash", Name: "Cash", Category: "cash", CurrentValue: 100}},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)), withMaintenanceMode(maintenanceReadOnly))
+
+	writes := []struct{ method, path, body string }{
+		{http.MethodPost, "/assets", `{"name":"Bonds","category":"investments","currentValue":5}`},
+		{http.MethodPatch, "/assets/cash", `{"currentValue":200}`},
+		{http.MethodPut, "/assets/cash", `{"name":"Cash","category":"cash","currentValue":200}`},
+		{http.MethodDelete, "/assets/cash", ""},
+	}
+	for _, write := range writes {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(write.method, write.path, strings.NewReader(write.body)))
+		var body errorBody
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s %s: failed to decode error: %v", write.method, write.path, err)
+		}
+		if rec.Code != http.StatusServiceUnavailable || body.Code != codeReadOnly {
+			t.Fatalf("%s %s: expected 503 read_only, got %d: %s", write.method, write.path, rec.Code, rec.Body.String())
+		}
+	}
+	if asset, err := repo.Assets().Get(context.Background(), "cash"); err != nil || asset.CurrentValue != 100 {
+		t.Fatalf("expected the asset to be untouched, got %+v (%v)", asset, err)
+	}
+
+	reads := []struct{ method, path string }{
+		{http.MethodGet, "/assets"},
+		{http.MethodHead, "/assets/cash"},
+		{http.MethodOptions, "/assets"},
+		{http.MethodGet, "/events/recent?session=token"},
+	}
+	for _, read := range reads {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(read.method, read.path, nil))
+		if rec.Code >= 300 {
+			t.Fatalf("%s %s: expected reads to succeed, got %d: %s", read.method, read.path, rec.Code, rec.Body.String())
+		}
+	}
+}
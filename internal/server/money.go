@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/jcleow/assetra2/internal/finance"
+)
+
+// money is a monetary amount on the wire. It serializes rounded to the minor units of
+// its currency, so float artefacts such as 3466.6699999999996 reach clients as 3466.67
+// and yen amounts carry no decimals. Arithmetic stays on the models; money only exists
+// in response DTOs.
+type money struct {
+	value  float64
+	digits int
+}
+
+// newMoney wraps v for output in currency. An empty currency means the base currency
+// and uses the default two places.
+func newMoney(v float64, currency string) money {
+	return money{value: v, digits: finance.MinorUnits(currency)}
+}
+
+// Float64 returns the rounded amount.
+func (m money) Float64() float64 {
+	scale := math.Pow10(m.digits)
+	if math.Abs(m.value*scale) >= 1<<53 {
+		// Past 2^53 a float64 has no fractional digits left to round, and scaling
+		// would only lose precision.
+		return m.value
+	}
+	rounded := math.Round(m.value*scale) / scale
+	if rounded == 0 {
+		// Drop the sign so tiny negatives do not render as -0.
+		return 0
+	}
+	return rounded
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m money) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(m.value) || math.IsInf(m.value, 0) {
+		return nil, errors.New("money: amount is not a finite number")
+	}
+	return strconv.AppendFloat(nil, m.Float64(), 'f', -1, 64), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler so response DTOs can be decoded again,
+// which the tests and Go clients rely on. The precision of a decoded amount is
+// whatever the server sent.
+func (m *money) UnmarshalJSON(data []byte) error {
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*m = money{value: v, digits: decodedDigits(data)}
+	return nil
+}
+
+// decodedDigits counts the decimal places in a JSON number literal.
+func decodedDigits(data []byte) int {
+	for i, c := range data {
+		if c == '.' {
+			return len(data) - i - 1
+		}
+	}
+	return 0
+}
+
+// amount is a monetary amount in a request body. It accepts a JSON number or, for
+// clients that cannot hold money in a JSON number exactly, a decimal string such as
+// "1234.56". Strings are parsed strictly: an optional sign, digits and at most one
+// decimal point, with no exponent, spaces or thousands separators.
+type amount float64
+
+var decimalString = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// invalidAmountError reports an amount string that is not a decimal. encoding/json
+// does not say which field a custom unmarshaler failed on, so the message cannot either.
+type invalidAmountError struct {
+	Value string
+}
+
+func (e *invalidAmountError) Error() string {
+	return fmt.Sprintf("amounts must be a number or a decimal string such as \"1234.56\", got %q", e.Value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *amount) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || data[0] != '"' {
+		var v float64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*a = amount(v)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if !decimalString.MatchString(s) {
+		return &invalidAmountError{Value: s}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return &invalidAmountError{Value: s}
+	}
+	*a = amount(v)
+	return nil
+}
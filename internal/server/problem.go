@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/jcleow/assetra2/internal/logging"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+const problemContentType = "application/problem+json"
+
+// problemDetails is an RFC 9457 error body. Instance carries the request ID so a
+// report can be matched to the server logs; Code is an extension member with the same
+// machine-readable code as the default error body.
+type problemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+// errorBody is the default error shape. Code lets clients branch without parsing the
+// message; see errorCode.
+type errorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// referencedBody is the 409 body for a delete blocked by other entities. BlockedBy
+// names their kind and BlockingIDs lists them, so a client can offer to unlink them.
+type referencedBody struct {
+	errorBody
+	BlockedBy   string   `json:"blockedBy"`
+	BlockingIDs []string `json:"blockingIds"`
+}
+
+// referencedProblem carries the same members as referencedBody as problem+json
+// extensions.
+type referencedProblem struct {
+	problemDetails
+	BlockedBy   string   `json:"blockedBy"`
+	BlockingIDs []string `json:"blockingIds"`
+}
+
+// Error codes that narrow a status down further. Other statuses use errorCode's
+// default, the snake_cased status text (e.g. "not_found", "conflict").
+const (
+	// codeInvalidBody means the body was not valid JSON for the endpoint: a syntax
+	// error, an unknown field or a wrongly typed value.
+	codeInvalidBody = "invalid_body"
+	// codeInvalidInput means the body decoded but failed validation.
+	codeInvalidInput = "invalid_input"
+	// codeDuplicateID means a create reused an existing id.
+	codeDuplicateID = "duplicate_id"
+	// codeInvalidData means a stored row could not be read back; the request itself
+	// was fine.
+	codeInvalidData = "invalid_data"
+)
+
+// errorCode is the default machine-readable code for status.
+func errorCode(status int) string {
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+// problemResponseWriter marks a response whose client asked for problem+json errors.
+type problemResponseWriter struct {
+	http.ResponseWriter
+	instance string
+}
+
+func (w *problemResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *problemResponseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// problemMiddleware opts a request into problem+json errors when its Accept header
+// lists application/problem+json. It must run inside requestIDMiddleware.
+func problemMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if acceptsProblemJSON(r.Header.Get("Accept")) {
+			w = &problemResponseWriter{ResponseWriter: w, instance: logging.RequestID(r.Context())}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func acceptsProblemJSON(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == problemContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError renders an error in the shape the client negotiated: problem+json when
+// requested, otherwise the default {"error": message, "code": code} body. The code is
+// errorCode(status); use writeErrorCode for a more specific one.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorCode(w, status, errorCode(status), message)
+}
+
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
+	if pw, ok := w.(*problemResponseWriter); ok {
+		writeProblem(pw, status, code, message, pw.instance)
+		return
+	}
+	writeJSON(w, status, errorBody{Error: message, Code: code})
+}
+
+// writeReferenced rejects a delete with 409, naming the entities that still
+// reference the target.
+func writeReferenced(w http.ResponseWriter, message string, ref *repository.ReferencedError) {
+	status := http.StatusConflict
+	pw, ok := w.(*problemResponseWriter)
+	if !ok {
+		writeJSON(w, status, referencedBody{
+			errorBody:   errorBody{Error: message, Code: errorCode(status)},
+			BlockedBy:   ref.Entity,
+			BlockingIDs: ref.IDs,
+		})
+		return
+	}
+	pw.Header().Set("Content-Type", problemContentType)
+	pw.WriteHeader(status)
+	if err := json.NewEncoder(pw).Encode(referencedProblem{
+		problemDetails: problemDetails{
+			Type:     "about:blank",
+			Title:    http.StatusText(status),
+			Status:   status,
+			Detail:   message,
+			Instance: pw.instance,
+			Code:     errorCode(status),
+		},
+		BlockedBy:   ref.Entity,
+		BlockingIDs: ref.IDs,
+	}); err != nil {
+		http.Error(pw, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func writeProblem(w http.ResponseWriter, status int, code, detail, instance string) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(problemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+		Code:     code,
+	}); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
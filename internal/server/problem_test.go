@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestErrorsRenderAsProblemDetailsWhenNegotiated(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	cases := []struct {
+		name   string
+		req    *http.Request
+		status int
+		detail string
+	}{
+		{"not found", httptest.NewRequest(http.MethodGet, "/assets/missing", nil), http.StatusNotFound, "not found"},
+		{"bad request", httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(`{"name":""}`)), http.StatusBadRequest, "name is required"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.Header.Set("Accept", "application/problem+json, application/json;q=0.9")
+			tc.req.Header.Set(headerRequestID, "req-123")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, tc.req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("expected %d, got %d", tc.status, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != problemContentType {
+				t.Fatalf("expected %s, got %q", problemContentType, got)
+			}
+
+			var problem problemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if problem.Status != tc.status || problem.Title != http.StatusText(tc.status) || problem.Type != "about:blank" {
+				t.Fatalf("unexpected problem %+v", problem)
+			}
+			if problem.Detail != tc.detail || problem.Instance != "req-123" || problem.Code == "" {
+				t.Fatalf("expected detail %q and instance req-123, got %+v", tc.detail, problem)
+			}
+		})
+	}
+}
+
+func TestErrorsKeepDefaultShapeWithoutNegotiation(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/missing", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected application/json, got %q", got)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"error":"not found","code":"not_found"}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+}
+
+func TestUpdateErrorsDistinguishMissingFromInvalid(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{{ID: "a1", Name: "Cash", Category: "cash", CurrentValue: 100}},
+		Goals:  []finance.Goal{{ID: "g1", Name: "Deposit", TargetAmount: 1000, AssetCategory: "cash"}},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"missing asset with valid body", http.MethodPut, "/assets/missing", `{"name":"Cash","category":"cash","currentValue":1}`, http.StatusNotFound, "not_found"},
+		{"missing asset with invalid body", http.MethodPut, "/assets/missing", `{"name":""}`, http.StatusNotFound, "not_found"},
+		{"missing asset with unknown field", http.MethodPatch, "/assets/missing", `{"colour":"red"}`, http.StatusNotFound, "not_found"},
+		{"missing goal with invalid body", http.MethodPatch, "/goals/missing", `{"name":""}`, http.StatusNotFound, "not_found"},
+		{"malformed json", http.MethodPut, "/assets/a1", `{"name":`, http.StatusBadRequest, codeInvalidBody},
+		{"unknown field", http.MethodPut, "/assets/a1", `{"colour":"red"}`, http.StatusBadRequest, codeInvalidBody},
+		{"empty body", http.MethodPatch, "/goals/g1", ``, http.StatusBadRequest, codeInvalidBody},
+		{"failed validation", http.MethodPut, "/assets/a1", `{"name":"","category":"cash"}`, http.StatusBadRequest, codeInvalidInput},
+		{"duplicate id", http.MethodPost, "/assets", `{"id":"a1","name":"Cash","category":"cash","currentValue":1}`, http.StatusConflict, codeDuplicateID},
+		{"scenario term out of range", http.MethodPost, "/property-planner/scenarios", `{"type":"hdb","headline":"Resale","inputs":{"loanTermYears":45}}`, http.StatusBadRequest, codeInvalidInput},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			var body errorBody
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode error: %v", err)
+			}
+			if rec.Code != tc.status || body.Code != tc.code {
+				t.Fatalf("expected %d %s, got %d %+v", tc.status, tc.code, rec.Code, body)
+			}
+		})
+	}
+}
@@ -1,71 +1,334 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/netip"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jcleow/assetra2/internal/events"
 	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/healthcheck"
+	"github.com/jcleow/assetra2/internal/logging"
 	"github.com/jcleow/assetra2/internal/repository"
 )
 
 const (
 	headerRequestID     = "X-Request-ID"
 	headerSessionToken  = "X-Session-Token"
-	maxRequestBodyBytes = 1 << 20 // 1 MiB
+	maxRequestBodyBytes = 1 << 20  // 1 MiB
+	maxImportBodyBytes  = 16 << 20 // 16 MiB
+	maxBulkIDs          = 500
+	maxStreamBatch      = 100
+	// subscriberRetryAfter is advertised to SSE clients turned away at the subscriber limit.
+	subscriberRetryAfter = 5 * time.Second
+	// streamReconnectDelay is the SSE retry hint sent when a stream reaches its lifetime.
+	streamReconnectDelay = time.Second
+	defaultHistoryLimit  = 50
+	defaultForecastSpan  = 12
+	maxForecastMonths    = 60
+	defaultUpcomingDays  = 30
+	maxUpcomingDays      = 366
 )
 
 type router struct {
-	logger *slog.Logger
-	repo   repository.Repository
-	events *events.Hub
+	logger   *slog.Logger
+	repo     repository.Repository
+	events   *events.Hub
+	currency finance.CurrencyConverter
+	// defaultFrequency fills in incomes and expenses submitted without a frequency.
+	defaultFrequency finance.Frequency
+	// streamLifetime bounds how long one SSE stream stays open; zero means no limit.
+	streamLifetime time.Duration
+	// location anchors cash-flow months and days; see withLocation.
+	location *time.Location
+	// now is the clock projections and forecasts read when a request has no asOf, and
+	// the time net-worth snapshots are stamped with.
+	now func() time.Time
+	// trustedProxies may set X-Forwarded-For and X-Real-IP; see clientIP.
+	trustedProxies []netip.Prefix
+	// alerts configures the conditions /cashflow/alerts reports.
+	alerts finance.AlertThresholds
+	// notes is how HTML in notes fields is neutralised; see notesMode.
+	notes notesMode
+	// limits caps the length of free-text fields.
+	limits fieldLimits
+	// adminToken enables the /admin endpoints and is the session token they require.
+	adminToken string
+	// checks are the dependency checks behind /health/ready.
+	checks *healthcheck.Registry
+	// readOnly rejects writes during maintenance; see readOnlyMiddleware.
+	readOnly bool
+	// serverTiming reports handler phases in a Server-Timing header.
+	serverTiming bool
+	// categories are the canonical categories offered by the /categories endpoints.
+	categories categoryLists
+	// slowRequestThreshold is the latency above which a request also logs a warning;
+	// zero disables the warning.
+	slowRequestThreshold time.Duration
+}
+
+// routerOption customises optional router behaviour.
+type routerOption func(*router)
+
+// withCurrency sets the base currency and exchange rates used for aggregates.
+func withCurrency(converter finance.CurrencyConverter) routerOption {
+	return func(rt *router) {
+		rt.currency = converter
+	}
+}
+
+// withDefaultFrequency sets the frequency applied when a payload omits one; empty keeps
+// the monthly default.
+func withDefaultFrequency(freq finance.Frequency) routerOption {
+	return func(rt *router) {
+		if freq != "" {
+			rt.defaultFrequency = freq
+		}
+	}
+}
+
+// withTrustedProxies sets the proxy networks whose forwarding headers are believed.
+func withTrustedProxies(prefixes []netip.Prefix) routerOption {
+	return func(rt *router) {
+		rt.trustedProxies = prefixes
+	}
+}
+
+// withLocation sets the time zone that "this month" and day-of-month anchors are
+// evaluated in for forecasts, upcoming events and reconciliation; nil keeps UTC.
+func withLocation(loc *time.Location) routerOption {
+	return func(rt *router) {
+		if loc != nil {
+			rt.location = loc
+		}
+	}
+}
+
+// withClock replaces the wall clock behind forecasts, upcoming events, reconciliation,
+// goal projections and on-demand net-worth snapshots, so tests can pin "now".
+func withClock(now func() time.Time) routerOption {
+	return func(rt *router) {
+		if now != nil {
+			rt.now = now
+		}
+	}
+}
+
+// withAlertThresholds sets the limits /cashflow/alerts checks cash flow against.
+func withAlertThresholds(thresholds finance.AlertThresholds) routerOption {
+	return func(rt *router) {
+		rt.alerts = thresholds
+	}
+}
+
+// withNotesMode sets how HTML in notes is neutralised; empty keeps stripping.
+func withNotesMode(mode string) routerOption {
+	return func(rt *router) {
+		if mode != "" {
+			rt.notes = notesMode(mode)
+		}
+	}
+}
+
+// withFieldLimits caps free-text field lengths; zero values keep the defaults.
+func withFieldLimits(limits fieldLimits) routerOption {
+	return func(rt *router) {
+		if limits.Name > 0 {
+			rt.limits.Name = limits.Name
+		}
+		if limits.Notes > 0 {
+			rt.limits.Notes = limits.Notes
+		}
+	}
+}
+
+// withAdminToken exposes the /admin endpoints to callers presenting token; empty
+// leaves them unregistered.
+func withAdminToken(token string) routerOption {
+	return func(rt *router) {
+		rt.adminToken = token
+	}
 }
 
-func newRouter(logger *slog.Logger, repo repository.Repository, hub *events.Hub) http.Handler {
+// withSlowRequestThreshold logs a warning for requests slower than threshold, except
+// the long-lived /events streams. Zero disables it.
+func withSlowRequestThreshold(threshold time.Duration) routerOption {
+	return func(rt *router) {
+		if threshold >= 0 {
+			rt.slowRequestThreshold = threshold
+		}
+	}
+}
+
+// withHealthChecks sets the dependency checks /health/ready runs.
+func withHealthChecks(checks *healthcheck.Registry) routerOption {
+	return func(rt *router) {
+		if checks != nil {
+			rt.checks = checks
+		}
+	}
+}
+
+// withStreamLifetime closes event streams after lifetime with a reconnect hint, so a
+// connection whose client vanished without cancelling the request is still reclaimed.
+func withStreamLifetime(lifetime time.Duration) routerOption {
+	return func(rt *router) {
+		if lifetime >= 0 {
+			rt.streamLifetime = lifetime
+		}
+	}
+}
+
+func newRouter(logger *slog.Logger, repo repository.Repository, hub *events.Hub, opts ...routerOption) http.Handler {
 	rt := &router{
-		logger: logger,
-		repo:   repo,
-		events: hub,
+		logger:   logger,
+		repo:     repo,
+		events:   hub,
+		currency: finance.CurrencyConverter{Base: finance.DefaultBaseCurrency},
+
+		defaultFrequency: finance.FrequencyMonthly,
+		location:         time.UTC,
+		now:              time.Now,
+		alerts:           finance.AlertThresholds{MinSavingsRate: 0.1, MinRunwayMonths: 3, EmergencyFundCategory: "cash"},
+		notes:            notesStrip,
+		limits:           fieldLimits{Name: defaultMaxNameLength, Notes: defaultMaxNotesLength},
+		checks:           healthcheck.NewRegistry(0),
+	}
+	for _, opt := range opts {
+		opt(rt)
 	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/health", healthHandler)
+	mux.HandleFunc("/health", rt.handleHealth)
+	mux.HandleFunc("/health/ready", rt.handleReadiness)
+	mux.HandleFunc("/meta/currency", rt.handleCurrencyMeta)
 
+	mux.HandleFunc("/accounts", rt.handleAccountsCollection)
+	mux.HandleFunc("/accounts/", rt.handleAccountItem)
+	mux.HandleFunc("/accounts/meta", rt.handleCollectionMeta(rt.repo.Accounts().Meta))
 	mux.HandleFunc("/assets", rt.handleAssetsCollection)
 	mux.HandleFunc("/assets/", rt.handleAssetItem)
+	mux.HandleFunc("/assets/meta", rt.handleCollectionMeta(rt.repo.Assets().Meta))
+	mux.HandleFunc("/assets/categories", rt.handleCategories(rt.repo.Assets().Categories, rt.categories.Assets))
+	mux.HandleFunc("/assets/growth-rate", rt.handlePortfolioGrowthRate)
 
 	mux.HandleFunc("/liabilities", rt.handleLiabilitiesCollection)
 	mux.HandleFunc("/liabilities/", rt.handleLiabilityItem)
+	mux.HandleFunc("/liabilities/meta", rt.handleCollectionMeta(rt.repo.Liabilities().Meta))
+	mux.HandleFunc("/liabilities/categories", rt.handleCategories(rt.repo.Liabilities().Categories, rt.categories.Liabilities))
 
 	mux.HandleFunc("/cashflow", rt.handleCashFlowSummary)
+	mux.HandleFunc("/cashflow/summary", rt.handleCashFlowSummary)
+	mux.HandleFunc("/cashflow/forecast", rt.handleCashFlowForecast)
+	mux.HandleFunc("/cashflow/upcoming", rt.handleUpcomingCashEvents)
+	mux.HandleFunc("/cashflow/alerts", rt.handleCashFlowAlerts)
+	mux.HandleFunc("/cashflow/categories/rename", rt.handleRenameCategory)
 	mux.HandleFunc("/cashflow/incomes", rt.handleIncomesCollection)
 	mux.HandleFunc("/cashflow/incomes/", rt.handleIncomeItem)
+	mux.HandleFunc("/cashflow/incomes/meta", rt.handleCollectionMeta(rt.repo.Incomes().Meta))
 	mux.HandleFunc("/cashflow/expenses", rt.handleExpensesCollection)
 	mux.HandleFunc("/cashflow/expenses/", rt.handleExpenseItem)
+	mux.HandleFunc("/cashflow/expenses/meta", rt.handleCollectionMeta(rt.repo.Expenses().Meta))
+	mux.HandleFunc("/cashflow/expenses/bulk", rt.handleBulkUpdateExpenses)
 	mux.HandleFunc("/events", rt.handleEventStream)
+	mux.HandleFunc("/events/recent", rt.handleRecentEvents)
+	mux.HandleFunc("/events/replay", rt.handleEventReplay)
+	mux.HandleFunc("/export/all", rt.handleExportAll)
+	mux.HandleFunc("/goals", rt.handleGoalsCollection)
+	mux.HandleFunc("/goals/", rt.handleGoalItem)
+	mux.HandleFunc("/goals/meta", rt.handleCollectionMeta(rt.repo.Goals().Meta))
+	mux.HandleFunc("/import/all", rt.handleImportAll)
+	mux.HandleFunc("/transactions", rt.handleTransactionsCollection)
+	mux.HandleFunc("/transactions/", rt.handleTransactionItem)
+	mux.HandleFunc("/transactions/meta", rt.handleCollectionMeta(rt.repo.Transactions().Meta))
+	mux.HandleFunc("/net-worth/history", rt.handleNetWorthHistory)
+	mux.HandleFunc("/net-worth/snapshot", rt.handleNetWorthSnapshot)
+	mux.HandleFunc("/net-worth/equity", rt.handleNetWorthEquity)
 	mux.HandleFunc("/property-planner/scenarios", rt.handlePropertyScenariosCollection)
 	mux.HandleFunc("/property-planner/scenarios/", rt.handlePropertyScenarioItem)
+	mux.HandleFunc("/property-planner/scenarios/by-type/", rt.handlePropertyScenarioByType)
+	mux.HandleFunc("/property-planner/scenarios/meta", rt.handleCollectionMeta(rt.repo.PropertyPlanner().Meta))
+	if rt.adminToken != "" {
+		mux.HandleFunc("/admin/recompute", rt.handleAdminRecompute)
+	}
+	mux.HandleFunc("/categories", rt.handleTaxonomy)
+	mux.HandleFunc("/batch", rt.handleBatch(mux))
 
-	handler := requestIDMiddleware(loggingMiddleware(corsMiddleware(mux), logger))
+	handler := requestIDMiddleware(loggingMiddleware(corsMiddleware(headMiddleware(rt.serverTimingMiddleware(problemMiddleware(rt.readOnlyMiddleware(mux))))), logger, rt.trustedProxies, rt.slowRequestThreshold))
 	return handler
 }
 
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+func (rt *router) handleHealth(w http.ResponseWriter, r *http.Request) {
+	payload := map[string]any{"status": "ok"}
+	if rt.events != nil {
+		payload["events"] = map[string]any{
+			"subscribers": rt.events.SubscriberCount(),
+			"published":   rt.events.PublishedCount(),
+		}
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
+
+// readinessResponse reports each dependency check and the overall verdict.
+type readinessResponse struct {
+	Status string                        `json:"status"`
+	Checks map[string]healthcheck.Status `json:"checks"`
+}
+
+// handleReadiness runs the registered dependency checks, answering 503 if any fail so
+// a load balancer stops routing traffic here.
+func (rt *router) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	statuses, ready := rt.checks.Run(r.Context())
+	if !ready {
+		writeJSON(w, http.StatusServiceUnavailable, readinessResponse{Status: "unavailable", Checks: statuses})
+		return
+	}
+	writeJSON(w, http.StatusOK, readinessResponse{Status: "ready", Checks: statuses})
+}
+
+// handleCurrencyMeta describes how to format amounts in the base currency, which every
+// aggregate endpoint reports in.
+func (rt *router) handleCurrencyMeta(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+	writeJSON(w, http.StatusOK, finance.FormatFor(rt.currency.Base))
 }
 
 func (rt *router) handleEventStream(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("handling new connections!")
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
 	if r.Method != http.MethodGet {
-		methodNotAllowed(w)
+		methodNotAllowed(w, readOnlyMethods)
 		return
 	}
 
@@ -74,84 +337,407 @@ func (rt *router) handleEventStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if rt.events == nil {
-		internalError(w)
+		eventsDisabled(w)
+		return
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	if _, err := events.ParseCursor(cursor); err != nil {
+		badRequest(w, err)
+		return
+	}
+	rt.serveEventStream(w, r, func(ctx context.Context) (<-chan events.StreamEvent, error) {
+		return rt.events.Subscribe(ctx, cursor)
+	})
+}
+
+// handleEventReplay streams the hub's entire retained history (up to EVENT_MAX_HISTORY
+// events) from the oldest entry, then continues live. Clients use it to rebuild state
+// they suspect is corrupt; unlike /events it ignores any cursor.
+func (rt *router) handleEventReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
 		return
 	}
 
+	if token := extractSessionToken(r); token == "" {
+		unauthorized(w)
+		return
+	}
+	if rt.events == nil {
+		eventsDisabled(w)
+		return
+	}
+
+	rt.serveEventStream(w, r, rt.events.SubscribeFromStart)
+}
+
+// serveEventStream writes events from subscribe as server-sent events until the client
+// disconnects, with a periodic heartbeat comment to keep proxies from timing out. With
+// ?batch=true, events that are already queued together go out as one "batch" frame
+// whose data is a JSON array; a lone event is still sent as a single object. With
+// ?snapshot=true the first frame is a "stream.snapshot" event holding current state
+// (see buildStreamSnapshot), read after subscribing so no change in between is missed;
+// a change may appear both in the snapshot and as a live event. With ?diff=true an
+// update published with its changes carries only the changed fields as data. Once the
+// stream lifetime elapses a "reconnect" event is sent and the stream ends, releasing
+// the subscriber; clients resume from their last cursor.
+func (rt *router) serveEventStream(w http.ResponseWriter, r *http.Request, subscribe func(context.Context) (<-chan events.StreamEvent, error)) {
+	batching := false
+	if v := r.URL.Query().Get("batch"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			badRequest(w, errors.New("batch must be true or false"))
+			return
+		}
+		batching = parsed
+	}
+	withSnapshot := false
+	if v := r.URL.Query().Get("snapshot"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			badRequest(w, errors.New("snapshot must be true or false"))
+			return
+		}
+		withSnapshot = parsed
+	}
+	diffs := false
+	if v := r.URL.Query().Get("diff"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			badRequest(w, errors.New("diff must be true or false"))
+			return
+		}
+		diffs = parsed
+	}
+
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		internalError(w)
 		return
 	}
 
+	// Cancelling on return unsubscribes even if the request context outlives the handler.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	stream, err := subscribe(ctx)
+	if errors.Is(err, events.ErrTooManySubscribers) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(subscriberRetryAfter.Seconds())))
+		writeError(w, http.StatusServiceUnavailable, "too many event stream subscribers")
+		return
+	}
+	if errors.Is(err, events.ErrClosed) {
+		writeError(w, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	var opening []events.StreamEvent
+	if withSnapshot {
+		snapshot, err := rt.buildStreamSnapshot(ctx)
+		if err != nil {
+			internalError(w)
+			return
+		}
+		opening = append(opening, snapshot)
+	}
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
-
-	ctx := r.Context()
-	cursor := r.URL.Query().Get("cursor")
-
-	stream, err := rt.events.Subscribe(ctx, cursor)
-	if err != nil {
-		internalError(w)
-		return
+	// Send the headers now so clients see the stream open before the first event.
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for _, evt := range opening {
+		rt.writeStreamFrame(w, []events.StreamEvent{evt})
+		flusher.Flush()
 	}
 
 	heartbeat := time.NewTicker(30 * time.Second)
 	defer heartbeat.Stop()
 
+	var expired <-chan time.Time
+	if rt.streamLifetime > 0 {
+		lifetime := time.NewTimer(rt.streamLifetime)
+		defer lifetime.Stop()
+		expired = lifetime.C
+	}
+
 	for {
 		select {
 		case evt, ok := <-stream:
 			if !ok {
 				return
 			}
-			payload, err := json.Marshal(evt)
-			if err != nil {
-				rt.logger.Warn("failed to marshal stream event", "error", err)
-				continue
+			batch, closed := []events.StreamEvent{evt}, false
+			if batching {
+				batch, closed = drainReady(stream, batch)
+			}
+			if diffs {
+				batch = changesAsData(batch)
 			}
-			fmt.Fprintf(w, "id: %s\n", evt.Cursor)
-			fmt.Fprintf(w, "event: %s.%s\n", evt.Entity, evt.Action)
-			fmt.Fprintf(w, "data: %s\n\n", payload)
+			rt.writeStreamFrame(w, batch)
 			flusher.Flush()
+			if closed {
+				return
+			}
 		case <-heartbeat.C:
 			fmt.Fprintf(w, ": ping %d\n\n", time.Now().Unix())
 			flusher.Flush()
+		case <-expired:
+			fmt.Fprintf(w, "retry: %d\nevent: reconnect\ndata: {\"reason\":\"max-lifetime\"}\n\n", streamReconnectDelay.Milliseconds())
+			flusher.Flush()
+			return
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// streamSnapshot is the "stream.snapshot" payload: the small collections in full and
+// counts for the ones that can grow large.
+type streamSnapshot struct {
+	Assets      []assetResponse                      `json:"assets"`
+	Liabilities []liabilityResponse                  `json:"liabilities"`
+	Incomes     []incomeResponse                     `json:"incomes"`
+	Expenses    []expenseResponse                    `json:"expenses"`
+	Accounts    []accountResponse                    `json:"accounts"`
+	Counts      map[string]repository.CollectionMeta `json:"counts"`
+}
+
+// buildStreamSnapshot reads current state into a stream.snapshot event. Its cursor is
+// the hub's latest, so a client reconnecting before any live event resumes from there.
+func (rt *router) buildStreamSnapshot(ctx context.Context) (events.StreamEvent, error) {
+	repo := rt.repo
+	assets, err := repo.Assets().List(ctx)
+	if err != nil {
+		return events.StreamEvent{}, err
+	}
+	liabilities, err := repo.Liabilities().List(ctx)
+	if err != nil {
+		return events.StreamEvent{}, err
+	}
+	incomes, err := repo.Incomes().List(ctx)
+	if err != nil {
+		return events.StreamEvent{}, err
+	}
+	expenses, err := repo.Expenses().List(ctx)
+	if err != nil {
+		return events.StreamEvent{}, err
+	}
+	accounts, err := repo.Accounts().List(ctx)
+	if err != nil {
+		return events.StreamEvent{}, err
+	}
+
+	counts := make(map[string]repository.CollectionMeta)
+	for name, meta := range map[string]func(context.Context) (repository.CollectionMeta, error){
+		"goals":             repo.Goals().Meta,
+		"transactions":      repo.Transactions().Meta,
+		"propertyScenarios": repo.PropertyPlanner().Meta,
+	} {
+		if counts[name], err = meta(ctx); err != nil {
+			return events.StreamEvent{}, err
+		}
+	}
+
+	return events.StreamEvent{
+		Cursor: strconv.FormatUint(rt.events.PublishedCount(), 10),
+		Type:   events.TypeSnapshot,
+		Entity: "stream",
+		Action: events.ActionSnapshot,
+		Data: streamSnapshot{
+			Assets:      mapResponses(assets, newAssetResponse),
+			Liabilities: mapResponses(liabilities, newLiabilityResponse),
+			Incomes:     mapResponses(incomes, newIncomeResponse),
+			Expenses:    mapResponses(expenses, newExpenseResponse),
+			Accounts:    mapResponses(accounts, newAccountResponse),
+			Counts:      counts,
+		},
+		Timestamp: rt.now().UTC(),
+	}, nil
+}
+
+// writeStreamFrame writes one SSE frame. Several events share a "batch" frame whose id is
+// the last event's cursor, so reconnecting clients resume after the whole batch.
+func (rt *router) writeStreamFrame(w http.ResponseWriter, batch []events.StreamEvent) {
+	last := batch[len(batch)-1]
+	name := last.Entity + "." + last.Action
+	var payload []byte
+	var err error
+	if len(batch) == 1 {
+		payload, err = json.Marshal(last)
+	} else {
+		name = "batch"
+		payload, err = json.Marshal(batch)
+	}
+	if err != nil {
+		rt.logger.Warn("failed to marshal stream event", "error", err)
+		return
+	}
+	fmt.Fprintf(w, "id: %s\n", last.Cursor)
+	fmt.Fprintf(w, "event: %s\n", name)
+	fmt.Fprintf(w, "data: %s\n\n", payload)
+}
+
+// changesAsData swaps each diffed event's data for its changed fields, in place.
+func changesAsData(batch []events.StreamEvent) []events.StreamEvent {
+	for i, evt := range batch {
+		if evt.Changes != nil {
+			batch[i].Data = evt.Changes
+		}
+	}
+	return batch
+}
+
+// drainReady appends events already waiting in stream, up to maxStreamBatch, without
+// blocking. closed reports whether the stream ended while draining.
+func drainReady(stream <-chan events.StreamEvent, batch []events.StreamEvent) ([]events.StreamEvent, bool) {
+	for len(batch) < maxStreamBatch {
+		select {
+		case evt, ok := <-stream:
+			if !ok {
+				return batch, true
+			}
+			batch = append(batch, evt)
+		default:
+			return batch, false
+		}
+	}
+	return batch, false
+}
+
+func (rt *router) handleRecentEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	if token := extractSessionToken(r); token == "" {
+		unauthorized(w)
+		return
+	}
+	if rt.events == nil {
+		eventsDisabled(w)
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			badRequest(w, errors.New("limit must be a positive integer"))
+			return
+		}
+		limit = parsed
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	if _, err := events.ParseCursor(cursor); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	var items []events.StreamEvent
+	if v := r.URL.Query().Get("before"); v != "" {
+		// before pages backward from an event's cursor, or from the newest event for
+		// "latest"; cursor pages forward, so the two cannot be combined.
+		if cursor != "" {
+			badRequest(w, errors.New("cursor and before cannot be combined"))
+			return
+		}
+		var before uint64
+		if v != "latest" {
+			parsed, err := strconv.ParseUint(v, 10, 64)
+			if err != nil || parsed == 0 {
+				badRequest(w, fmt.Errorf("before %q must be an event's cursor or latest", v))
+				return
+			}
+			before = parsed
+		}
+		items = rt.events.HistoryBefore(before, limit)
+	} else {
+		items = rt.events.History(cursor, limit)
+	}
+	if items == nil {
+		items = []events.StreamEvent{}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// handleCollectionMeta serves {count, lastUpdatedAt} for a collection so polling clients
+// can skip refetching when nothing changed.
+func (rt *router) handleCollectionMeta(meta func(context.Context) (repository.CollectionMeta, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			allowOptions(w, readOnlyMethods)
+			return
+		}
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, readOnlyMethods)
+			return
+		}
+
+		result, err := meta(r.Context())
+		if err != nil {
+			handleRepoError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
 func (rt *router) handleAssetsCollection(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		rt.listAssets(w, r)
 	case http.MethodPost:
 		rt.createAsset(w, r)
+	case http.MethodOptions:
+		allowOptions(w, collectionMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, collectionMethods)
 	}
 }
 
 func (rt *router) handleAssetItem(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/assets/")
-	if id == "" {
+	rest := strings.TrimPrefix(r.URL.Path, "/assets/")
+	if id, ok := strings.CutSuffix(rest, "/equity"); ok {
+		rt.handleAssetEquity(w, r, id)
+		return
+	}
+
+	id := rest
+	if id == "" || strings.Contains(id, "/") {
 		notFound(w)
 		return
 	}
 
 	switch r.Method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodHead:
 		rt.getAsset(w, r, id)
+	case http.MethodPut:
+		rt.updateAsset(w, r, id, false)
 	case http.MethodPatch:
-		rt.updateAsset(w, r, id)
+		rt.updateAsset(w, r, id, true)
 	case http.MethodDelete:
 		rt.deleteAsset(w, r, id)
+	case http.MethodOptions:
+		allowOptions(w, replaceableItemMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, replaceableItemMethods)
 	}
 }
 
@@ -161,7 +747,7 @@ func (rt *router) listAssets(w http.ResponseWriter, r *http.Request) {
 		internalError(w)
 		return
 	}
-	writeJSON(w, http.StatusOK, items)
+	writeList(w, r, mapResponses(items, newAssetResponse))
 }
 
 func (rt *router) getAsset(w http.ResponseWriter, r *http.Request, id string) {
@@ -170,49 +756,81 @@ func (rt *router) getAsset(w http.ResponseWriter, r *http.Request, id string) {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, asset)
+	writeJSON(w, http.StatusOK, newAssetResponse(asset))
 }
 
 func (rt *router) createAsset(w http.ResponseWriter, r *http.Request) {
 	var payload assetPayload
 	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	if err := payload.validate(rt.limits); err != nil {
 		badRequest(w, err)
 		return
 	}
-	if err := payload.validate(); err != nil {
+	if err := rt.categories.check(categoryAssets, payload.Category); err != nil {
 		badRequest(w, err)
 		return
 	}
 
-	created, err := rt.repo.Assets().Create(r.Context(), payload.toAsset())
+	if !rt.checkAccountRef(w, r, payload.AccountID) || !rt.checkLiabilityRef(w, r, payload.LinkedLiabilityID) {
+		return
+	}
+
+	created, err := rt.repo.Assets().Create(r.Context(), payload.toAsset(rt.notes))
 	if err != nil {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, created)
-	rt.publishChange("asset", "create", created.ID, created)
+	writeJSON(w, http.StatusCreated, newAssetResponse(created))
+	rt.publishChange("asset", events.ActionCreate, created.ID, created)
 }
 
-func (rt *router) updateAsset(w http.ResponseWriter, r *http.Request, id string) {
+// updateAsset fully replaces the asset on PUT. A partial update (PATCH) decodes the body
+// over the stored asset, so omitted fields keep their current values.
+func (rt *router) updateAsset(w http.ResponseWriter, r *http.Request, id string, partial bool) {
+	// Look the resource up before reading the body, so a missing one is 404 even when
+	// the body is also invalid.
+	existing, err := rt.repo.Assets().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
 	var payload assetPayload
+	if partial {
+		payload = newAssetPayload(existing)
+	}
 	if err := decodeJSONBody(w, r, &payload); err != nil {
-		badRequest(w, err)
+		invalidBody(w, err)
 		return
 	}
 
 	payload.ID = id
-	if err := payload.validate(); err != nil {
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+	if err := rt.categories.check(categoryAssets, payload.Category); err != nil {
 		badRequest(w, err)
 		return
 	}
 
-	updated, err := rt.repo.Assets().Update(r.Context(), payload.toAsset())
+	if !rt.checkAccountRef(w, r, payload.AccountID) || !rt.checkLiabilityRef(w, r, payload.LinkedLiabilityID) {
+		return
+	}
+
+	asset := payload.toAsset(rt.notes)
+	if partial {
+		asset.Notes = rt.notes.patch(payload.Notes, existing.Notes)
+	}
+	updated, err := rt.repo.Assets().Update(r.Context(), asset)
 	if err != nil {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, updated)
-	rt.publishChange("asset", "update", updated.ID, updated)
+	writeJSON(w, http.StatusOK, newAssetResponse(updated))
+	rt.publishUpdate("asset", updated.ID, existing, updated)
 }
 
 func (rt *router) deleteAsset(w http.ResponseWriter, r *http.Request, id string) {
@@ -221,7 +839,31 @@ func (rt *router) deleteAsset(w http.ResponseWriter, r *http.Request, id string)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-	rt.publishChange("asset", "delete", id, map[string]string{"id": id})
+	rt.publishChange("asset", events.ActionDelete, id, map[string]string{"id": id})
+}
+
+// handlePortfolioGrowthRate reports the value-weighted annual growth rate of all
+// assets, weighted by their value in the base currency.
+func (rt *router) handlePortfolioGrowthRate(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	assets, err := rt.repo.Assets().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	if assets, err = rt.currency.Assets(assets); err != nil {
+		aggregationError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]float64{"annualGrowthRate": finance.PortfolioGrowthRate(assets)})
 }
 
 func (rt *router) handleLiabilitiesCollection(w http.ResponseWriter, r *http.Request) {
@@ -230,8 +872,10 @@ func (rt *router) handleLiabilitiesCollection(w http.ResponseWriter, r *http.Req
 		rt.listLiabilities(w, r)
 	case http.MethodPost:
 		rt.createLiability(w, r)
+	case http.MethodOptions:
+		allowOptions(w, collectionMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, collectionMethods)
 	}
 }
 
@@ -243,14 +887,18 @@ func (rt *router) handleLiabilityItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	switch r.Method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodHead:
 		rt.getLiability(w, r, id)
+	case http.MethodPut:
+		rt.updateLiability(w, r, id, false)
 	case http.MethodPatch:
-		rt.updateLiability(w, r, id)
+		rt.updateLiability(w, r, id, true)
 	case http.MethodDelete:
 		rt.deleteLiability(w, r, id)
+	case http.MethodOptions:
+		allowOptions(w, replaceableItemMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, replaceableItemMethods)
 	}
 }
 
@@ -260,7 +908,7 @@ func (rt *router) listLiabilities(w http.ResponseWriter, r *http.Request) {
 		internalError(w)
 		return
 	}
-	writeJSON(w, http.StatusOK, items)
+	writeList(w, r, mapResponses(items, newLiabilityResponse))
 }
 
 func (rt *router) getLiability(w http.ResponseWriter, r *http.Request, id string) {
@@ -269,64 +917,117 @@ func (rt *router) getLiability(w http.ResponseWriter, r *http.Request, id string
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, item)
+	writeJSON(w, http.StatusOK, newLiabilityResponse(item))
 }
 
 func (rt *router) createLiability(w http.ResponseWriter, r *http.Request) {
 	var payload liabilityPayload
 	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	if err := payload.validate(rt.limits); err != nil {
 		badRequest(w, err)
 		return
 	}
-	if err := payload.validate(); err != nil {
+	if err := rt.categories.check(categoryLiabilities, payload.Category); err != nil {
 		badRequest(w, err)
 		return
 	}
 
-	created, err := rt.repo.Liabilities().Create(r.Context(), payload.toLiability())
+	if !rt.checkAccountRef(w, r, payload.AccountID) {
+		return
+	}
+
+	created, err := rt.repo.Liabilities().Create(r.Context(), payload.toLiability(rt.notes))
 	if err != nil {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, created)
-	rt.publishChange("liability", "create", created.ID, created)
+	writeJSON(w, http.StatusCreated, newLiabilityResponse(created))
+	rt.publishChange("liability", events.ActionCreate, created.ID, created)
 	fmt.Println("Published changed on liability create")
 }
 
-func (rt *router) updateLiability(w http.ResponseWriter, r *http.Request, id string) {
+// updateLiability fully replaces the liability on PUT. A partial update (PATCH) decodes the body
+// over the stored liability, so omitted fields keep their current values.
+func (rt *router) updateLiability(w http.ResponseWriter, r *http.Request, id string, partial bool) {
+	existing, err := rt.repo.Liabilities().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
 	var payload liabilityPayload
+	if partial {
+		payload = newLiabilityPayload(existing)
+	}
 	if err := decodeJSONBody(w, r, &payload); err != nil {
-		badRequest(w, err)
+		invalidBody(w, err)
 		return
 	}
 	payload.ID = id
-	if err := payload.validate(); err != nil {
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+	if err := rt.categories.check(categoryLiabilities, payload.Category); err != nil {
 		badRequest(w, err)
 		return
 	}
 
-	updated, err := rt.repo.Liabilities().Update(r.Context(), payload.toLiability())
+	if !rt.checkAccountRef(w, r, payload.AccountID) {
+		return
+	}
+
+	liability := payload.toLiability(rt.notes)
+	if partial {
+		liability.Notes = rt.notes.patch(payload.Notes, existing.Notes)
+	}
+	updated, err := rt.repo.Liabilities().Update(r.Context(), liability)
 	if err != nil {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, updated)
-	rt.publishChange("liability", "update", updated.ID, updated)
+	writeJSON(w, http.StatusOK, newLiabilityResponse(updated))
+	rt.publishUpdate("liability", updated.ID, existing, updated)
 	fmt.Println("Published changed on liability update")
 }
 
 func (rt *router) deleteLiability(w http.ResponseWriter, r *http.Request, id string) {
-	if err := rt.repo.Liabilities().Delete(r.Context(), id); err != nil {
+	err := rt.repo.Liabilities().Delete(r.Context(), id)
+	var referenced *repository.ReferencedError
+	if errors.As(err, &referenced) {
+		writeReferenced(w, "liability is linked to assets; unlink them first", referenced)
+		return
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		writeError(w, http.StatusConflict, "liability is linked to an asset; unlink it first")
+		return
+	}
+	if err != nil {
 		handleRepoError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-	rt.publishChange("liability", "delete", id, map[string]string{"id": id})
+	rt.publishChange("liability", events.ActionDelete, id, map[string]string{"id": id})
 }
 
 func (rt *router) handleCashFlowSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
 	if r.Method != http.MethodGet {
-		methodNotAllowed(w)
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = finance.PeriodMonthly
+	}
+	if period != finance.PeriodMonthly && period != finance.PeriodAnnual {
+		badRequest(w, fmt.Errorf("period %q is invalid", period))
 		return
 	}
 
@@ -340,13 +1041,259 @@ func (rt *router) handleCashFlowSummary(w http.ResponseWriter, r *http.Request)
 		internalError(w)
 		return
 	}
-
-	summary := finance.MonthlyCashFlow(incomes, expenses)
-	writeJSON(w, http.StatusOK, map[string]any{
-		"incomes":  incomes,
-		"expenses": expenses,
-		"summary":  summary,
-	})
+
+	baseIncomes, baseExpenses, err := rt.toBaseCurrency(incomes, expenses)
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+
+	summary := finance.MonthlyCashFlow(baseIncomes, baseExpenses)
+	if period == finance.PeriodAnnual {
+		summary = finance.AnnualCashFlow(baseIncomes, baseExpenses)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"incomes":  mapResponses(incomes, newIncomeResponse),
+		"expenses": mapResponses(expenses, newExpenseResponse),
+		"summary":  summary,
+	})
+}
+
+// handleCashFlowAlerts evaluates the monthly cash flow and emergency fund against the
+// configured thresholds, all in the base currency.
+func (rt *router) handleCashFlowAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	incomes, err := rt.repo.Incomes().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	expenses, err := rt.repo.Expenses().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	assets, err := rt.repo.Assets().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	if incomes, expenses, err = rt.toBaseCurrency(incomes, expenses); err != nil {
+		aggregationError(w, err)
+		return
+	}
+	if assets, err = rt.currency.Assets(assets); err != nil {
+		aggregationError(w, err)
+		return
+	}
+
+	summary := finance.MonthlyCashFlow(incomes, expenses)
+	writeJSON(w, http.StatusOK, finance.EvaluateAlerts(summary, assets, rt.alerts))
+}
+
+func (rt *router) handleNetWorthHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	items, err := rt.repo.NetWorthSnapshots().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	writeList(w, r, items)
+}
+
+func (rt *router) handleNetWorthSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, actionMethods)
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, actionMethods)
+		return
+	}
+
+	snapshot, created, err := snapshotNetWorth(r.Context(), rt.repo, rt.currency, rt.now().UTC())
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+	if !created {
+		writeJSON(w, http.StatusOK, snapshot)
+		return
+	}
+	writeJSON(w, http.StatusCreated, snapshot)
+	rt.publishChange("netWorthSnapshot", events.ActionCreate, snapshot.ID, snapshot)
+}
+
+func (rt *router) handleCashFlowForecast(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	months := defaultForecastSpan
+	if v := r.URL.Query().Get("months"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxForecastMonths {
+			badRequest(w, fmt.Errorf("months must be between 1 and %d", maxForecastMonths))
+			return
+		}
+		months = parsed
+	}
+	asOf, err := rt.asOf(r)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	incomes, err := rt.repo.Incomes().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	expenses, err := rt.repo.Expenses().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	incomes, expenses, err = rt.toBaseCurrency(incomes, expenses)
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, finance.Forecast(incomes, expenses, asOf, months))
+}
+
+func (rt *router) handleUpcomingCashEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	days := defaultUpcomingDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxUpcomingDays {
+			badRequest(w, fmt.Errorf("days must be between 1 and %d", maxUpcomingDays))
+			return
+		}
+		days = parsed
+	}
+	asOf, err := rt.asOf(r)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	incomes, err := rt.repo.Incomes().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	expenses, err := rt.repo.Expenses().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	incomes, expenses, err = rt.toBaseCurrency(incomes, expenses)
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, finance.ExpandOccurrences(incomes, expenses, asOf, days))
+}
+
+// handleRenameCategory moves every income and expense in one category to another,
+// e.g. after a typo or when merging two categories.
+func (rt *router) handleRenameCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, actionMethods)
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, actionMethods)
+		return
+	}
+
+	var payload struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	from := strings.TrimSpace(payload.From)
+	to := strings.TrimSpace(payload.To)
+	if from == "" || to == "" {
+		badRequest(w, errors.New("from and to are required"))
+		return
+	}
+	for _, entity := range []string{categoryIncomes, categoryExpenses} {
+		if err := rt.categories.check(entity, to); err != nil {
+			badRequest(w, err)
+			return
+		}
+	}
+
+	incomes, expenses, err := rt.repo.RenameCashFlowCategory(r.Context(), from, to)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+
+	result := map[string]any{
+		"from":     from,
+		"to":       to,
+		"incomes":  incomes,
+		"expenses": expenses,
+		"updated":  incomes + expenses,
+	}
+	if incomes+expenses > 0 {
+		rt.publishChange("cashflow", events.ActionUpdate, "", result)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// toBaseCurrency converts cash flows before they are summed together.
+func (rt *router) toBaseCurrency(incomes []finance.Income, expenses []finance.Expense) ([]finance.Income, []finance.Expense, error) {
+	baseIncomes, err := rt.currency.Incomes(incomes)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseExpenses, err := rt.currency.Expenses(expenses)
+	if err != nil {
+		return nil, nil, err
+	}
+	return baseIncomes, baseExpenses, nil
 }
 
 func (rt *router) handleIncomesCollection(w http.ResponseWriter, r *http.Request) {
@@ -355,8 +1302,10 @@ func (rt *router) handleIncomesCollection(w http.ResponseWriter, r *http.Request
 		rt.listIncomes(w, r)
 	case http.MethodPost:
 		rt.createIncome(w, r)
+	case http.MethodOptions:
+		allowOptions(w, collectionMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, collectionMethods)
 	}
 }
 
@@ -368,14 +1317,18 @@ func (rt *router) handleIncomeItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	switch r.Method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodHead:
 		rt.getIncome(w, r, id)
+	case http.MethodPut:
+		rt.updateIncome(w, r, id, false)
 	case http.MethodPatch:
-		rt.updateIncome(w, r, id)
+		rt.updateIncome(w, r, id, true)
 	case http.MethodDelete:
 		rt.deleteIncome(w, r, id)
+	case http.MethodOptions:
+		allowOptions(w, replaceableItemMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, replaceableItemMethods)
 	}
 }
 
@@ -385,7 +1338,7 @@ func (rt *router) listIncomes(w http.ResponseWriter, r *http.Request) {
 		internalError(w)
 		return
 	}
-	writeJSON(w, http.StatusOK, items)
+	writeList(w, r, mapResponses(items, newIncomeResponse))
 }
 
 func (rt *router) getIncome(w http.ResponseWriter, r *http.Request, id string) {
@@ -394,21 +1347,26 @@ func (rt *router) getIncome(w http.ResponseWriter, r *http.Request, id string) {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, item)
+	writeJSON(w, http.StatusOK, newIncomeResponse(item))
 }
 
 func (rt *router) createIncome(w http.ResponseWriter, r *http.Request) {
 	var payload incomePayload
 	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	payload.Frequency = rt.frequencyOrDefault(payload.Frequency)
+	if err := payload.validate(rt.limits); err != nil {
 		badRequest(w, err)
 		return
 	}
-	if err := payload.validate(); err != nil {
+	if err := rt.categories.check(categoryIncomes, payload.Category); err != nil {
 		badRequest(w, err)
 		return
 	}
 
-	entity, err := payload.toIncome()
+	entity, err := payload.toIncome(rt.notes, rt.now())
 	if err != nil {
 		badRequest(w, err)
 		return
@@ -419,34 +1377,54 @@ func (rt *router) createIncome(w http.ResponseWriter, r *http.Request) {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, created)
-	rt.publishChange("income", "create", created.ID, created)
+	rt.warnFarStartDate(created)
+	writeJSON(w, http.StatusCreated, newIncomeResponse(created))
+	rt.publishChange("income", events.ActionCreate, created.ID, created)
 }
 
-func (rt *router) updateIncome(w http.ResponseWriter, r *http.Request, id string) {
+// updateIncome fully replaces the income on PUT. A partial update (PATCH) decodes the body
+// over the stored income, so omitted fields keep their current values.
+func (rt *router) updateIncome(w http.ResponseWriter, r *http.Request, id string, partial bool) {
+	existing, err := rt.repo.Incomes().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
 	var payload incomePayload
+	if partial {
+		payload = newIncomePayload(existing)
+	}
 	if err := decodeJSONBody(w, r, &payload); err != nil {
-		badRequest(w, err)
+		invalidBody(w, err)
 		return
 	}
+	payload.Frequency = rt.frequencyOrDefault(payload.Frequency)
 	payload.ID = id
-	if err := payload.validate(); err != nil {
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+	if err := rt.categories.check(categoryIncomes, payload.Category); err != nil {
 		badRequest(w, err)
 		return
 	}
-	entity, err := payload.toIncome()
+	entity, err := payload.toIncome(rt.notes, rt.now())
 	if err != nil {
 		badRequest(w, err)
 		return
 	}
+	if partial {
+		entity.Notes = rt.notes.patch(payload.Notes, existing.Notes)
+	}
 
 	updated, err := rt.repo.Incomes().Update(r.Context(), entity)
 	if err != nil {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, updated)
-	rt.publishChange("income", "update", updated.ID, updated)
+	rt.warnFarStartDate(updated)
+	writeJSON(w, http.StatusOK, newIncomeResponse(updated))
+	rt.publishUpdate("income", updated.ID, existing, updated)
 }
 
 func (rt *router) deleteIncome(w http.ResponseWriter, r *http.Request, id string) {
@@ -455,7 +1433,7 @@ func (rt *router) deleteIncome(w http.ResponseWriter, r *http.Request, id string
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-	rt.publishChange("income", "delete", id, map[string]string{"id": id})
+	rt.publishChange("income", events.ActionDelete, id, map[string]string{"id": id})
 }
 
 func (rt *router) handleExpensesCollection(w http.ResponseWriter, r *http.Request) {
@@ -464,8 +1442,10 @@ func (rt *router) handleExpensesCollection(w http.ResponseWriter, r *http.Reques
 		rt.listExpenses(w, r)
 	case http.MethodPost:
 		rt.createExpense(w, r)
+	case http.MethodOptions:
+		allowOptions(w, collectionMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, collectionMethods)
 	}
 }
 
@@ -477,15 +1457,116 @@ func (rt *router) handleExpenseItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	switch r.Method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodHead:
 		rt.getExpense(w, r, id)
+	case http.MethodPut:
+		rt.updateExpense(w, r, id, false)
 	case http.MethodPatch:
-		rt.updateExpense(w, r, id)
+		rt.updateExpense(w, r, id, true)
 	case http.MethodDelete:
 		rt.deleteExpense(w, r, id)
+	case http.MethodOptions:
+		allowOptions(w, replaceableItemMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, replaceableItemMethods)
+	}
+}
+
+// handleBulkUpdateExpenses applies one partial change to several expenses at once, e.g.
+// {"ids": ["a", "b"], "changes": {"category": "household"}}. Changes are decoded over
+// each stored expense exactly like a single PATCH; IDs that do not exist are reported
+// in missing rather than failing the request.
+func (rt *router) handleBulkUpdateExpenses(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, bulkMethods)
+		return
+	}
+	if r.Method != http.MethodPatch {
+		methodNotAllowed(w, bulkMethods)
+		return
+	}
+
+	var body struct {
+		IDs     []string        `json:"ids"`
+		Changes json.RawMessage `json:"changes"`
+	}
+	if err := decodeJSONBody(w, r, &body); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	ids := uniqueIDs(body.IDs)
+	if len(ids) == 0 {
+		badRequest(w, errors.New("ids are required"))
+		return
+	}
+	if len(ids) > maxBulkIDs {
+		badRequest(w, fmt.Errorf("at most %d ids may be updated at once", maxBulkIDs))
+		return
+	}
+	if len(body.Changes) == 0 {
+		badRequest(w, errors.New("changes are required"))
+		return
+	}
+	// Reject malformed changes up front rather than once per expense.
+	var probe expensePayload
+	if err := decodeStrict(body.Changes, &probe); err != nil {
+		invalidBody(w, fmt.Errorf("changes: %w", err))
+		return
+	}
+
+	var invalid error
+	updated, missing, err := rt.repo.Expenses().UpdateMany(r.Context(), ids, func(expense *finance.Expense) error {
+		payload := newExpensePayload(*expense)
+		if err := decodeStrict(body.Changes, &payload); err != nil {
+			invalid = err
+			return err
+		}
+		payload.Frequency = rt.frequencyOrDefault(payload.Frequency)
+		payload.ID = expense.ID
+		if err := payload.validate(rt.limits); err != nil {
+			invalid = fmt.Errorf("expense %s: %w", expense.ID, err)
+			return invalid
+		}
+		if err := rt.categories.check(categoryExpenses, payload.Category); err != nil {
+			invalid = fmt.Errorf("expense %s: %w", expense.ID, err)
+			return invalid
+		}
+		notes := rt.notes.patch(payload.Notes, expense.Notes)
+		*expense = payload.toExpense(rt.notes)
+		expense.Notes = notes
+		return nil
+	})
+	if invalid != nil {
+		badRequest(w, invalid)
+		return
+	}
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"updated": mapResponses(updated, newExpenseResponse),
+		"missing": nonNil(missing),
+	})
+	for _, expense := range updated {
+		rt.publishChange("expense", events.ActionUpdate, expense.ID, expense)
+	}
+}
+
+// uniqueIDs drops blank and repeated IDs, keeping the first occurrence's position.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
 	}
+	return out
 }
 
 func (rt *router) handlePropertyScenariosCollection(w http.ResponseWriter, r *http.Request) {
@@ -494,8 +1575,10 @@ func (rt *router) handlePropertyScenariosCollection(w http.ResponseWriter, r *ht
 		rt.listPropertyScenarios(w, r)
 	case http.MethodPost:
 		rt.createPropertyScenario(w, r)
+	case http.MethodOptions:
+		allowOptions(w, collectionMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, collectionMethods)
 	}
 }
 
@@ -507,14 +1590,35 @@ func (rt *router) handlePropertyScenarioItem(w http.ResponseWriter, r *http.Requ
 	}
 
 	switch r.Method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodHead:
 		rt.getPropertyScenario(w, r, id)
 	case http.MethodPut, http.MethodPatch:
 		rt.updatePropertyScenario(w, r, id)
 	case http.MethodDelete:
 		rt.deletePropertyScenario(w, r, id)
+	case http.MethodOptions:
+		allowOptions(w, replaceableItemMethods)
+	default:
+		methodNotAllowed(w, replaceableItemMethods)
+	}
+}
+
+// handlePropertyScenarioByType serves PUT /property-planner/scenarios/by-type/{type},
+// which saves the scenario of that type whether or not one exists yet.
+func (rt *router) handlePropertyScenarioByType(w http.ResponseWriter, r *http.Request) {
+	scenarioType := strings.TrimPrefix(r.URL.Path, "/property-planner/scenarios/by-type/")
+	if scenarioType == "" || strings.Contains(scenarioType, "/") {
+		notFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		rt.upsertPropertyScenario(w, r, scenarioType)
+	case http.MethodOptions:
+		allowOptions(w, upsertMethods)
 	default:
-		methodNotAllowed(w)
+		methodNotAllowed(w, upsertMethods)
 	}
 }
 
@@ -524,7 +1628,7 @@ func (rt *router) listExpenses(w http.ResponseWriter, r *http.Request) {
 		internalError(w)
 		return
 	}
-	writeJSON(w, http.StatusOK, items)
+	writeList(w, r, mapResponses(items, newExpenseResponse))
 }
 
 func (rt *router) getExpense(w http.ResponseWriter, r *http.Request, id string) {
@@ -533,50 +1637,73 @@ func (rt *router) getExpense(w http.ResponseWriter, r *http.Request, id string)
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, item)
+	writeJSON(w, http.StatusOK, newExpenseResponse(item))
 }
 
 func (rt *router) createExpense(w http.ResponseWriter, r *http.Request) {
 	var payload expensePayload
 	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	payload.Frequency = rt.frequencyOrDefault(payload.Frequency)
+	if err := payload.validate(rt.limits); err != nil {
 		badRequest(w, err)
 		return
 	}
-	if err := payload.validate(); err != nil {
+	if err := rt.categories.check(categoryExpenses, payload.Category); err != nil {
 		badRequest(w, err)
 		return
 	}
 
-	entity := payload.toExpense()
+	entity := payload.toExpense(rt.notes)
 	created, err := rt.repo.Expenses().Create(r.Context(), entity)
 	if err != nil {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusCreated, created)
-	rt.publishChange("expense", "create", created.ID, created)
+	writeJSON(w, http.StatusCreated, newExpenseResponse(created))
+	rt.publishChange("expense", events.ActionCreate, created.ID, created)
 }
 
-func (rt *router) updateExpense(w http.ResponseWriter, r *http.Request, id string) {
+// updateExpense fully replaces the expense on PUT. A partial update (PATCH) decodes the body
+// over the stored expense, so omitted fields keep their current values.
+func (rt *router) updateExpense(w http.ResponseWriter, r *http.Request, id string, partial bool) {
+	existing, err := rt.repo.Expenses().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
 	var payload expensePayload
+	if partial {
+		payload = newExpensePayload(existing)
+	}
 	if err := decodeJSONBody(w, r, &payload); err != nil {
-		badRequest(w, err)
+		invalidBody(w, err)
 		return
 	}
+	payload.Frequency = rt.frequencyOrDefault(payload.Frequency)
 	payload.ID = id
-	if err := payload.validate(); err != nil {
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+	if err := rt.categories.check(categoryExpenses, payload.Category); err != nil {
 		badRequest(w, err)
 		return
 	}
 
-	entity := payload.toExpense()
+	entity := payload.toExpense(rt.notes)
+	if partial {
+		entity.Notes = rt.notes.patch(payload.Notes, existing.Notes)
+	}
 	updated, err := rt.repo.Expenses().Update(r.Context(), entity)
 	if err != nil {
 		handleRepoError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, updated)
-	rt.publishChange("expense", "update", updated.ID, updated)
+	writeJSON(w, http.StatusOK, newExpenseResponse(updated))
+	rt.publishUpdate("expense", updated.ID, existing, updated)
 }
 
 func (rt *router) deleteExpense(w http.ResponseWriter, r *http.Request, id string) {
@@ -585,7 +1712,7 @@ func (rt *router) deleteExpense(w http.ResponseWriter, r *http.Request, id strin
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-	rt.publishChange("expense", "delete", id, map[string]string{"id": id})
+	rt.publishChange("expense", events.ActionDelete, id, map[string]string{"id": id})
 }
 
 func (rt *router) listPropertyScenarios(w http.ResponseWriter, r *http.Request) {
@@ -594,7 +1721,7 @@ func (rt *router) listPropertyScenarios(w http.ResponseWriter, r *http.Request)
 		internalError(w)
 		return
 	}
-	writeJSON(w, http.StatusOK, items)
+	writeList(w, r, items)
 }
 
 func (rt *router) getPropertyScenario(w http.ResponseWriter, r *http.Request, id string) {
@@ -609,7 +1736,7 @@ func (rt *router) getPropertyScenario(w http.ResponseWriter, r *http.Request, id
 func (rt *router) createPropertyScenario(w http.ResponseWriter, r *http.Request) {
 	var payload propertyScenarioPayload
 	if err := decodeJSONBody(w, r, &payload); err != nil {
-		badRequest(w, err)
+		invalidBody(w, err)
 		return
 	}
 	if err := payload.validate(); err != nil {
@@ -624,13 +1751,18 @@ func (rt *router) createPropertyScenario(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	writeJSON(w, http.StatusCreated, created)
-	rt.publishChange("propertyScenario", "create", created.ID, created)
+	rt.publishChange("propertyScenario", events.ActionCreate, created.ID, created)
 }
 
 func (rt *router) updatePropertyScenario(w http.ResponseWriter, r *http.Request, id string) {
+	existing, err := rt.repo.PropertyPlanner().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
 	var payload propertyScenarioPayload
 	if err := decodeJSONBody(w, r, &payload); err != nil {
-		badRequest(w, err)
+		invalidBody(w, err)
 		return
 	}
 	payload.ID = id
@@ -646,7 +1778,41 @@ func (rt *router) updatePropertyScenario(w http.ResponseWriter, r *http.Request,
 		return
 	}
 	writeJSON(w, http.StatusOK, updated)
-	rt.publishChange("propertyScenario", "update", updated.ID, updated)
+	rt.publishUpdate("propertyScenario", updated.ID, existing, updated)
+}
+
+// upsertPropertyScenario updates the scenario of scenarioType, or creates it, answering
+// 200 or 201 to match. The type comes from the path; a body type must agree with it and
+// a body ID is ignored.
+func (rt *router) upsertPropertyScenario(w http.ResponseWriter, r *http.Request, scenarioType string) {
+	var payload propertyScenarioPayload
+	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	if t := strings.TrimSpace(payload.Type); t != "" && t != scenarioType {
+		badRequest(w, fmt.Errorf("type %q does not match the path type %q", t, scenarioType))
+		return
+	}
+	payload.ID = ""
+	payload.Type = scenarioType
+	if err := payload.validate(); err != nil {
+		badRequest(w, err)
+		return
+	}
+
+	saved, created, err := rt.repo.PropertyPlanner().UpsertByType(r.Context(), payload.toScenario())
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	if created {
+		writeJSON(w, http.StatusCreated, saved)
+		rt.publishChange("propertyScenario", events.ActionCreate, saved.ID, saved)
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+	rt.publishChange("propertyScenario", events.ActionUpdate, saved.ID, saved)
 }
 
 func (rt *router) deletePropertyScenario(w http.ResponseWriter, r *http.Request, id string) {
@@ -655,22 +1821,46 @@ func (rt *router) deletePropertyScenario(w http.ResponseWriter, r *http.Request,
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-	rt.publishChange("propertyScenario", "delete", id, map[string]string{"id": id})
+	rt.publishChange("propertyScenario", events.ActionDelete, id, map[string]string{"id": id})
+}
+
+// eventsDisabled rejects an /events request when publishing is turned off.
+func eventsDisabled(w http.ResponseWriter) {
+	writeError(w, http.StatusServiceUnavailable, "event publishing is disabled")
 }
 
 func (rt *router) publishChange(entity, action, id string, payload any) {
+	rt.publishEvent(events.StreamEvent{Entity: entity, Action: action, ResourceID: id, Data: payload})
+}
+
+// publishUpdate publishes an update of entity id from before to after, carrying the
+// changed fields for streams opened with ?diff=true as well as the full entity.
+func (rt *router) publishUpdate(entity, id string, before, after any) {
 	if rt.events == nil {
 		return
 	}
-	rt.events.Publish(events.StreamEvent{
-		Type:       "finance.change",
-		Entity:     entity,
-		Action:     action,
-		ResourceID: id,
-		Data:       payload,
-	})
+	evt := events.StreamEvent{Entity: entity, Action: events.ActionUpdate, ResourceID: id, Data: after}
+	changes, err := events.Diff(before, after)
+	if err != nil {
+		// Diff-only streams fall back to the full entity.
+		rt.logger.Warn("failed to diff change event", "entity", entity, "id", id, "error", err)
+	} else {
+		evt.Changes = changes
+	}
+	rt.publishEvent(evt)
+}
+
+func (rt *router) publishEvent(evt events.StreamEvent) {
+	if rt.events == nil {
+		return
+	}
+	evt.Type = events.TypeChange
+	if err := rt.events.Publish(evt); err != nil {
+		rt.logger.Error("dropped malformed change event", "entity", evt.Entity, "action", evt.Action, "error", err)
+		return
+	}
 
-	fmt.Printf("finance change for %s %s", entity, action)
+	fmt.Printf("finance change for %s %s", evt.Entity, evt.Action)
 }
 
 // --- payload helpers ---
@@ -679,29 +1869,57 @@ type assetPayload struct {
 	ID               string  `json:"id"`
 	Name             string  `json:"name"`
 	Category         string  `json:"category"`
-	CurrentValue     float64 `json:"currentValue"`
+	CurrentValue     amount  `json:"currentValue"`
 	AnnualGrowthRate float64 `json:"annualGrowthRate"`
-	Notes            *string `json:"notes"`
-}
-
-func (p assetPayload) validate() error {
+	Currency         string  `json:"currency"`
+	AccountID        string  `json:"accountId"`
+	// LinkedLiabilityID pairs the asset with the liability secured against it.
+	LinkedLiabilityID string  `json:"linkedLiabilityId"`
+	Notes             *string `json:"notes"`
+}
+
+// newAssetPayload seeds a partial update with the stored asset, except for notes; see
+// notesMode.patch.
+func newAssetPayload(asset finance.Asset) assetPayload {
+	return assetPayload{
+		ID:                asset.ID,
+		Name:              asset.Name,
+		Category:          asset.Category,
+		CurrentValue:      amount(asset.CurrentValue),
+		AnnualGrowthRate:  asset.AnnualGrowthRate,
+		Currency:          asset.Currency,
+		AccountID:         asset.AccountID,
+		LinkedLiabilityID: asset.LinkedLiabilityID,
+	}
+}
+
+func (p assetPayload) validate(limits fieldLimits) error {
+	if err := limits.check("notes", p.Notes, textField{"name", p.Name}, textField{"category", p.Category}); err != nil {
+		return err
+	}
 	if strings.TrimSpace(p.Name) == "" {
 		return errors.New("name is required")
 	}
 	if strings.TrimSpace(p.Category) == "" {
 		return errors.New("category is required")
 	}
-	return nil
+	if p.CurrentValue < 0 {
+		return errors.New("currentValue must not be negative; record an overdrawn balance as a liability")
+	}
+	return validateCurrency(p.Currency)
 }
 
-func (p assetPayload) toAsset() finance.Asset {
+func (p assetPayload) toAsset(notes notesMode) finance.Asset {
 	return finance.Asset{
-		ID:               p.ID,
-		Name:             strings.TrimSpace(p.Name),
-		Category:         strings.TrimSpace(p.Category),
-		CurrentValue:     p.CurrentValue,
-		AnnualGrowthRate: p.AnnualGrowthRate,
-		Notes:            stringOrEmpty(p.Notes),
+		ID:                p.ID,
+		Name:              strings.TrimSpace(p.Name),
+		Category:          strings.TrimSpace(p.Category),
+		CurrentValue:      float64(p.CurrentValue),
+		AnnualGrowthRate:  p.AnnualGrowthRate,
+		Currency:          normalizeCurrency(p.Currency),
+		AccountID:         strings.TrimSpace(p.AccountID),
+		LinkedLiabilityID: strings.TrimSpace(p.LinkedLiabilityID),
+		Notes:             notes.clean(stringOrEmpty(p.Notes)),
 	}
 }
 
@@ -709,106 +1927,196 @@ type liabilityPayload struct {
 	ID              string  `json:"id"`
 	Name            string  `json:"name"`
 	Category        string  `json:"category"`
-	CurrentBalance  float64 `json:"currentBalance"`
+	CurrentBalance  amount  `json:"currentBalance"`
 	InterestRateAPR float64 `json:"interestRateApr"`
-	MinimumPayment  float64 `json:"minimumPayment"`
+	MinimumPayment  amount  `json:"minimumPayment"`
+	Currency        string  `json:"currency"`
+	AccountID       string  `json:"accountId"`
 	Notes           *string `json:"notes"`
 }
 
-func (p liabilityPayload) validate() error {
+func newLiabilityPayload(liability finance.Liability) liabilityPayload {
+	return liabilityPayload{
+		ID:              liability.ID,
+		Name:            liability.Name,
+		Category:        liability.Category,
+		CurrentBalance:  amount(liability.CurrentBalance),
+		InterestRateAPR: liability.InterestRateAPR,
+		MinimumPayment:  amount(liability.MinimumPayment),
+		Currency:        liability.Currency,
+		AccountID:       liability.AccountID,
+	}
+}
+
+func (p liabilityPayload) validate(limits fieldLimits) error {
+	if err := limits.check("notes", p.Notes, textField{"name", p.Name}, textField{"category", p.Category}); err != nil {
+		return err
+	}
 	if strings.TrimSpace(p.Name) == "" {
 		return errors.New("name is required")
 	}
 	if strings.TrimSpace(p.Category) == "" {
 		return errors.New("category is required")
 	}
-	return nil
+	if p.InterestRateAPR < 0 {
+		return errors.New("interestRateApr must not be negative")
+	}
+	return validateCurrency(p.Currency)
 }
 
-func (p liabilityPayload) toLiability() finance.Liability {
+func (p liabilityPayload) toLiability(notes notesMode) finance.Liability {
 	return finance.Liability{
 		ID:              p.ID,
 		Name:            strings.TrimSpace(p.Name),
 		Category:        strings.TrimSpace(p.Category),
-		CurrentBalance:  p.CurrentBalance,
+		CurrentBalance:  float64(p.CurrentBalance),
 		InterestRateAPR: p.InterestRateAPR,
-		MinimumPayment:  p.MinimumPayment,
-		Notes:           stringOrEmpty(p.Notes),
+		MinimumPayment:  float64(p.MinimumPayment),
+		Currency:        normalizeCurrency(p.Currency),
+		AccountID:       strings.TrimSpace(p.AccountID),
+		Notes:           notes.clean(stringOrEmpty(p.Notes)),
 	}
 }
 
 type incomePayload struct {
-	ID        string            `json:"id"`
-	Source    string            `json:"source"`
-	Amount    float64           `json:"amount"`
-	Frequency finance.Frequency `json:"frequency"`
-	StartDate string            `json:"startDate"`
-	Category  string            `json:"category"`
-	Notes     *string           `json:"notes"`
-}
-
-func (p incomePayload) validate() error {
+	ID         string            `json:"id"`
+	Source     string            `json:"source"`
+	Amount     amount            `json:"amount"`
+	Currency   string            `json:"currency"`
+	Frequency  finance.Frequency `json:"frequency"`
+	Interval   string            `json:"interval"`
+	StartDate  string            `json:"startDate"`
+	Category   string            `json:"category"`
+	Notes      *string           `json:"notes"`
+	DayOfMonth *int              `json:"dayOfMonth"`
+	DayOfWeek  *int              `json:"dayOfWeek"`
+}
+
+func newIncomePayload(income finance.Income) incomePayload {
+	return incomePayload{
+		ID:         income.ID,
+		Source:     income.Source,
+		Amount:     amount(income.Amount),
+		Currency:   income.Currency,
+		Frequency:  income.Frequency,
+		Interval:   income.Interval,
+		StartDate:  income.StartDate.Format(time.RFC3339),
+		Category:   income.Category,
+		DayOfMonth: income.DayOfMonth,
+		DayOfWeek:  income.DayOfWeek,
+	}
+}
+
+func (p incomePayload) validate(limits fieldLimits) error {
+	if err := limits.check("notes", p.Notes, textField{"source", p.Source}, textField{"category", p.Category}); err != nil {
+		return err
+	}
 	if strings.TrimSpace(p.Source) == "" {
 		return errors.New("source is required")
 	}
 	if p.Amount <= 0 {
 		return errors.New("amount must be greater than zero")
 	}
-	if !validFrequency(p.Frequency) {
+	if !p.Frequency.Valid() {
 		return fmt.Errorf("frequency %q is invalid", p.Frequency)
 	}
+	if _, err := parseInterval(p.Interval); err != nil {
+		return err
+	}
 	if strings.TrimSpace(p.StartDate) == "" {
 		return errors.New("startDate is required")
 	}
-	return nil
+	if err := validateCurrency(p.Currency); err != nil {
+		return err
+	}
+	return validateAnchors(p.DayOfMonth, p.DayOfWeek)
 }
 
-func (p incomePayload) toIncome() (finance.Income, error) {
+// toIncome judges the start date against now.
+func (p incomePayload) toIncome(notes notesMode, now time.Time) (finance.Income, error) {
 	startDate, err := time.Parse(time.RFC3339, p.StartDate)
 	if err != nil {
 		return finance.Income{}, fmt.Errorf("invalid startDate: %w", err)
 	}
+	if err := validateStartDate(startDate, now); err != nil {
+		return finance.Income{}, err
+	}
 	return finance.Income{
-		ID:        p.ID,
-		Source:    strings.TrimSpace(p.Source),
-		Amount:    p.Amount,
-		Frequency: p.Frequency,
-		StartDate: startDate,
-		Category:  strings.TrimSpace(p.Category),
-		Notes:     stringOrEmpty(p.Notes),
+		ID:         p.ID,
+		Source:     strings.TrimSpace(p.Source),
+		Amount:     float64(p.Amount),
+		Currency:   normalizeCurrency(p.Currency),
+		Frequency:  p.Frequency,
+		Interval:   canonicalInterval(p.Interval),
+		StartDate:  startDate,
+		Category:   strings.TrimSpace(p.Category),
+		Notes:      notes.clean(stringOrEmpty(p.Notes)),
+		DayOfMonth: p.DayOfMonth,
+		DayOfWeek:  p.DayOfWeek,
 	}, nil
 }
 
 type expensePayload struct {
-	ID        string            `json:"id"`
-	Payee     string            `json:"payee"`
-	Amount    float64           `json:"amount"`
-	Frequency finance.Frequency `json:"frequency"`
-	Category  string            `json:"category"`
-	Notes     *string           `json:"notes"`
-}
-
-func (p expensePayload) validate() error {
+	ID         string            `json:"id"`
+	Payee      string            `json:"payee"`
+	Amount     amount            `json:"amount"`
+	Currency   string            `json:"currency"`
+	Frequency  finance.Frequency `json:"frequency"`
+	Interval   string            `json:"interval"`
+	Category   string            `json:"category"`
+	Notes      *string           `json:"notes"`
+	DayOfMonth *int              `json:"dayOfMonth"`
+	DayOfWeek  *int              `json:"dayOfWeek"`
+}
+
+func newExpensePayload(expense finance.Expense) expensePayload {
+	return expensePayload{
+		ID:         expense.ID,
+		Payee:      expense.Payee,
+		Amount:     amount(expense.Amount),
+		Currency:   expense.Currency,
+		Frequency:  expense.Frequency,
+		Interval:   expense.Interval,
+		Category:   expense.Category,
+		DayOfMonth: expense.DayOfMonth,
+		DayOfWeek:  expense.DayOfWeek,
+	}
+}
+
+func (p expensePayload) validate(limits fieldLimits) error {
+	if err := limits.check("notes", p.Notes, textField{"payee", p.Payee}, textField{"category", p.Category}); err != nil {
+		return err
+	}
 	if strings.TrimSpace(p.Payee) == "" {
 		return errors.New("payee is required")
 	}
 	if p.Amount <= 0 {
 		return errors.New("amount must be greater than zero")
 	}
-	if !validFrequency(p.Frequency) {
+	if !p.Frequency.Valid() {
 		return fmt.Errorf("frequency %q is invalid", p.Frequency)
 	}
-	return nil
+	if _, err := parseInterval(p.Interval); err != nil {
+		return err
+	}
+	if err := validateCurrency(p.Currency); err != nil {
+		return err
+	}
+	return validateAnchors(p.DayOfMonth, p.DayOfWeek)
 }
 
-func (p expensePayload) toExpense() finance.Expense {
+func (p expensePayload) toExpense(notes notesMode) finance.Expense {
 	return finance.Expense{
-		ID:        p.ID,
-		Payee:     strings.TrimSpace(p.Payee),
-		Amount:    p.Amount,
-		Frequency: p.Frequency,
-		Category:  strings.TrimSpace(p.Category),
-		Notes:     stringOrEmpty(p.Notes),
+		ID:         p.ID,
+		Payee:      strings.TrimSpace(p.Payee),
+		Amount:     float64(p.Amount),
+		Currency:   normalizeCurrency(p.Currency),
+		Frequency:  p.Frequency,
+		Interval:   canonicalInterval(p.Interval),
+		Category:   strings.TrimSpace(p.Category),
+		Notes:      notes.clean(stringOrEmpty(p.Notes)),
+		DayOfMonth: p.DayOfMonth,
+		DayOfWeek:  p.DayOfWeek,
 	}
 }
 
@@ -834,6 +2142,9 @@ func (p propertyScenarioPayload) validate() error {
 	if strings.TrimSpace(p.Headline) == "" {
 		return errors.New("headline is required")
 	}
+	if err := p.Inputs.Validate(); err != nil {
+		return fmt.Errorf("inputs: %w", err)
+	}
 	return nil
 }
 
@@ -861,17 +2172,112 @@ func stringOrEmpty(v *string) string {
 	return *v
 }
 
-func validFrequency(f finance.Frequency) bool {
-	switch f {
-	case finance.FrequencyWeekly,
-		finance.FrequencyBiWeekly,
-		finance.FrequencyMonthly,
-		finance.FrequencyQuarterly,
-		finance.FrequencyYearly:
-		return true
-	default:
-		return false
+// parseInterval checks an optional ISO 8601 recurrence interval and returns it
+// normalised, or "" when it is blank.
+func parseInterval(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	in, err := finance.ParseInterval(s)
+	if err != nil {
+		return "", err
+	}
+	return in.String(), nil
+}
+
+// canonicalInterval normalises an interval already checked by parseInterval.
+func canonicalInterval(s string) string {
+	normalized, _ := parseInterval(s)
+	return normalized
+}
+
+// frequencyOrDefault substitutes the configured default for an omitted frequency, so
+// validation and the monthly calculations agree on what an empty value means.
+func (rt *router) frequencyOrDefault(f finance.Frequency) finance.Frequency {
+	if f != "" {
+		return f
+	}
+	return rt.defaultFrequency
+}
+
+// localNow is the current time in the configured cash-flow time zone.
+func (rt *router) localNow() time.Time {
+	return rt.now().In(rt.location)
+}
+
+// asOf is the instant a projection is computed from: the asOf query parameter when
+// present, otherwise localNow. It accepts an RFC 3339 timestamp or a YYYY-MM-DD date,
+// which means midnight in the configured time zone.
+func (rt *router) asOf(r *http.Request) (time.Time, error) {
+	v := r.URL.Query().Get("asOf")
+	if v == "" {
+		return rt.localNow(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.In(rt.location), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, v, rt.location); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("asOf %q must be an RFC 3339 timestamp or a YYYY-MM-DD date", v)
+}
+
+func normalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validateCurrency(code string) error {
+	code = normalizeCurrency(code)
+	if code == "" {
+		return nil
+	}
+	if len(code) != 3 {
+		return fmt.Errorf("currency %q must be a three-letter code", code)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return fmt.Errorf("currency %q must be a three-letter code", code)
+		}
+	}
+	return nil
+}
+
+// Income start dates outside [minStartDate, now+maxStartDateYears] are rejected; they are
+// almost always typos such as 0024 for 2024 and would skew every forecast. Dates more
+// than farStartDateYears ahead are accepted but logged.
+var minStartDate = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	maxStartDateYears = 100
+	farStartDateYears = 5
+)
+
+func validateStartDate(start, now time.Time) error {
+	if start.Before(minStartDate) {
+		return fmt.Errorf("startDate %s is before %d", start.Format(time.DateOnly), minStartDate.Year())
+	}
+	if limit := now.AddDate(maxStartDateYears, 0, 0); start.After(limit) {
+		return fmt.Errorf("startDate %s is more than %d years in the future", start.Format(time.DateOnly), maxStartDateYears)
+	}
+	return nil
+}
+
+// warnFarStartDate logs incomes that start implausibly far ahead without rejecting them.
+func (rt *router) warnFarStartDate(income finance.Income) {
+	if income.StartDate.After(rt.now().AddDate(farStartDateYears, 0, 0)) {
+		rt.logger.Warn("income starts far in the future", "id", income.ID, "startDate", income.StartDate)
+	}
+}
+
+func validateAnchors(dayOfMonth, dayOfWeek *int) error {
+	if dayOfMonth != nil && (*dayOfMonth < 1 || *dayOfMonth > 31) {
+		return errors.New("dayOfMonth must be between 1 and 31")
+	}
+	if dayOfWeek != nil && (*dayOfWeek < 0 || *dayOfWeek > 6) {
+		return errors.New("dayOfWeek must be between 0 and 6")
 	}
+	return nil
 }
 
 // --- middleware & helpers ---
@@ -879,7 +2285,7 @@ func validFrequency(f finance.Frequency) bool {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PATCH,DELETE,OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET,HEAD,POST,PUT,PATCH,DELETE,OPTIONS")
 		allowedHeaders := strings.Join([]string{
 			"Content-Type",
 			"X-Requested-With",
@@ -889,45 +2295,74 @@ func corsMiddleware(next http.Handler) http.Handler {
 		}, ", ")
 		w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
 		w.Header().Set("Access-Control-Expose-Headers", headerRequestID)
+		w.Header().Set("Timing-Allow-Origin", "*")
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
+		// OPTIONS falls through so each route can report its own Allow header.
+		next.ServeHTTP(w, r)
+	})
+}
 
+// headMiddleware lets HEAD share the GET handlers: status and headers are written as
+// usual and the body is discarded.
+func headMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w = headResponseWriter{w}
+		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+
 func requestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requestID := r.Header.Get(headerRequestID)
 		if requestID == "" {
 			requestID = newRequestID()
 		}
-		ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+		ctx := logging.WithRequestID(r.Context(), requestID)
 		w.Header().Set(headerRequestID, requestID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func loggingMiddleware(next http.Handler, logger *slog.Logger) http.Handler {
+// loggingMiddleware logs every request at info, and again at warn when it took longer
+// than slowThreshold. Event streams are exempt from the warning, since they stay open
+// by design; a zero slowThreshold disables it.
+func loggingMiddleware(next http.Handler, logger *slog.Logger, trustedProxies []netip.Prefix, slowThreshold time.Duration) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		lw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
 
 		next.ServeHTTP(lw, r)
 
-		logger.Info("request completed",
+		elapsed := time.Since(start)
+		attrs := []any{
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", lw.status,
-			"duration_ms", time.Since(start).Milliseconds(),
-			"request_id", requestIDFromContext(r.Context()),
-		)
+			"duration_ms", elapsed.Milliseconds(),
+			"request_id", logging.RequestID(r.Context()),
+			"client_ip", clientIP(r, trustedProxies),
+		}
+		logger.Info("request completed", attrs...)
+		if slowThreshold > 0 && elapsed > slowThreshold && !isEventStream(r.URL.Path) {
+			logger.Warn("slow request", append(attrs, "threshold_ms", slowThreshold.Milliseconds())...)
+		}
 	})
 }
 
+// isEventStream reports whether path serves server-sent events, which stay open by
+// design. /events/recent is a plain JSON read and is not one of them.
+func isEventStream(path string) bool {
+	return path == "/events" || path == "/events/replay"
+}
+
 type loggingResponseWriter struct {
 	http.ResponseWriter
 	status int
@@ -944,15 +2379,6 @@ func (w *loggingResponseWriter) Flush() {
 	}
 }
 
-type requestIDKey struct{}
-
-func requestIDFromContext(ctx context.Context) string {
-	if v, ok := ctx.Value(requestIDKey{}).(string); ok {
-		return v
-	}
-	return ""
-}
-
 func newRequestID() string {
 	var b [16]byte
 	if _, err := rand.Read(b[:]); err != nil {
@@ -974,9 +2400,20 @@ func extractSessionToken(r *http.Request) string {
 }
 
 func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) error {
+	return decodeJSONBodyLimit(w, r, dst, maxRequestBodyBytes)
+}
+
+func decodeJSONBodyLimit(w http.ResponseWriter, r *http.Request, dst any, limit int64) error {
 	defer r.Body.Close()
-	reader := http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
-	dec := json.NewDecoder(reader)
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		return err
+	}
+	// encoding/json quietly replaces invalid UTF-8 with U+FFFD, so check the raw bytes.
+	if !utf8.Valid(raw) {
+		return &invalidTextError{Field: "body", Reason: "must be valid UTF-8"}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
 	dec.DisallowUnknownFields()
 	if err := dec.Decode(dst); err != nil {
 		return err
@@ -987,43 +2424,114 @@ func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) error {
 	return nil
 }
 
+// decodeStrict decodes an already-read JSON document with the same rules as request bodies.
+func decodeStrict(raw json.RawMessage, dst any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(dst)
+}
+
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
 	if payload == nil {
+		w.WriteHeader(status)
 		return
 	}
-	if err := json.NewEncoder(w).Encode(payload); err != nil {
+	// Encode before writing the status, so Server-Timing can include the encoding.
+	start := time.Now()
+	var body bytes.Buffer
+	err := json.NewEncoder(&body).Encode(payload)
+	if timing := timingFor(w); timing != nil {
+		timing.encode.Add(int64(time.Since(start)))
+	}
+	if err != nil {
 		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
 	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body.Bytes())
 }
 
+// badRequest rejects a payload that decoded but failed validation.
 func badRequest(w http.ResponseWriter, err error) {
-	writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	if writeUnprocessable(w, err) {
+		return
+	}
+	writeErrorCode(w, http.StatusBadRequest, codeInvalidInput, err.Error())
+}
+
+// invalidBody rejects a body that could not be decoded at all.
+func invalidBody(w http.ResponseWriter, err error) {
+	if writeUnprocessable(w, err) {
+		return
+	}
+	var invalidAmount *invalidAmountError
+	if errors.As(err, &invalidAmount) {
+		writeErrorCode(w, http.StatusBadRequest, codeInvalidInput, err.Error())
+		return
+	}
+	writeErrorCode(w, http.StatusBadRequest, codeInvalidBody, err.Error())
 }
 
 func internalError(w http.ResponseWriter) {
-	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	writeError(w, http.StatusInternalServerError, "internal server error")
 }
 
 func unauthorized(w http.ResponseWriter) {
-	writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	writeError(w, http.StatusUnauthorized, "unauthorized")
 }
 
 func notFound(w http.ResponseWriter) {
-	writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	writeError(w, http.StatusNotFound, "not found")
+}
+
+// Method sets advertised through the Allow header, one per route shape.
+var (
+	collectionMethods      = []string{http.MethodGet, http.MethodPost}
+	itemMethods            = []string{http.MethodGet, http.MethodHead, http.MethodPatch, http.MethodDelete}
+	replaceableItemMethods = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	readOnlyMethods        = []string{http.MethodGet}
+	actionMethods          = []string{http.MethodPost}
+	bulkMethods            = []string{http.MethodPatch}
+	upsertMethods          = []string{http.MethodPut}
+)
+
+// allowOptions answers OPTIONS with the methods the route supports.
+func allowOptions(w http.ResponseWriter, methods []string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func methodNotAllowed(w http.ResponseWriter) {
-	writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+// methodNotAllowed rejects the request with 405 and the Allow header RFC 9110 requires.
+func methodNotAllowed(w http.ResponseWriter, methods []string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// aggregationError reports failures from endpoints that sum amounts together.
+func aggregationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, finance.ErrMixedCurrencies) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	handleRepoError(w, err)
 }
 
 func handleRepoError(w http.ResponseWriter, err error) {
+	var referenced *repository.ReferencedError
 	switch {
 	case errors.Is(err, repository.ErrNotFound):
 		notFound(w)
 	case errors.Is(err, repository.ErrInvalidInput):
 		badRequest(w, err)
+	case errors.Is(err, repository.ErrDuplicateID):
+		writeErrorCode(w, http.StatusConflict, codeDuplicateID, "a resource with this id already exists")
+	case errors.As(err, &referenced):
+		writeReferenced(w, "resource is still referenced", referenced)
+	case errors.Is(err, repository.ErrConflict):
+		writeError(w, http.StatusConflict, "resource is still referenced")
+	case errors.Is(err, repository.ErrInvalidData):
+		writeErrorCode(w, http.StatusInternalServerError, codeInvalidData, "stored data for this resource is corrupt")
 	default:
 		internalError(w)
 	}
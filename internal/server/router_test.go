@@ -3,16 +3,21 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/jcleow/assetra2/internal/events"
 	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/healthcheck"
 	"github.com/jcleow/assetra2/internal/repository/memory"
 )
 
@@ -31,13 +36,81 @@ func TestHealthRoute(t *testing.T) {
 		t.Fatalf("expected status 200, got %d", rec.Code)
 	}
 
-	var payload map[string]string
+	var payload struct {
+		Status string `json:"status"`
+		Events struct {
+			Subscribers int    `json:"subscribers"`
+			Published   uint64 `json:"published"`
+		} `json:"events"`
+	}
 	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
 		t.Fatalf("failed to decode payload: %v", err)
 	}
 
-	if payload["status"] != "ok" {
-		t.Fatalf("unexpected status payload: %v", payload["status"])
+	if payload.Status != "ok" {
+		t.Fatalf("unexpected status payload: %v", payload.Status)
+	}
+	if payload.Events.Subscribers != 0 || payload.Events.Published != 0 {
+		t.Fatalf("expected idle event counters, got %+v", payload.Events)
+	}
+}
+
+func TestReadinessReportsEachDependency(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	checks := healthcheck.NewRegistry(time.Second)
+	checks.Register("database", func(context.Context) error { return nil })
+	router := newRouter(logger, repo, nil, withHealthChecks(checks))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"database":{"status":"ok"`) {
+		t.Fatalf("expected 200 with the database ok, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	checks.Register("fx", func(context.Context) error { return errors.New("provider unreachable") })
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	var payload readinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode readiness: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || payload.Status != "unavailable" {
+		t.Fatalf("expected 503 with a failing check, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if payload.Checks["database"].Status != healthcheck.StatusOK || payload.Checks["fx"].Error != "provider unreachable" {
+		t.Fatalf("expected per-dependency statuses, got %+v", payload.Checks)
+	}
+}
+
+func TestCurrencyMetaReflectsBaseCurrency(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+
+	cases := []struct {
+		base   string
+		symbol string
+		places int
+	}{
+		{"USD", "$", 2},
+		{"JPY", "¥", 0},
+		{"SGD", "S$", 2},
+	}
+	for _, tc := range cases {
+		router := newRouter(logger, repo, hub, withCurrency(finance.CurrencyConverter{Base: tc.base}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/meta/currency", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", tc.base, rec.Code)
+		}
+		var format finance.CurrencyFormat
+		if err := json.Unmarshal(rec.Body.Bytes(), &format); err != nil {
+			t.Fatalf("%s: failed to decode format: %v", tc.base, err)
+		}
+		if format.Code != tc.base || format.Symbol != tc.symbol || format.DecimalPlaces != tc.places || len(format.Grouping) != 1 || format.Grouping[0] != 3 {
+			t.Fatalf("%s: unexpected format %+v", tc.base, format)
+		}
 	}
 }
 
@@ -94,6 +167,63 @@ func TestAssetCRUDHandlers(t *testing.T) {
 	}
 }
 
+func TestPortfolioGrowthRateConvertsToBaseCurrency(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{
+			{ID: "us", Name: "Index fund", Category: "brokerage", CurrentValue: 1000, AnnualGrowthRate: 0.08},
+			{ID: "eu", Name: "Bund", Category: "bonds", CurrentValue: 500, AnnualGrowthRate: 0.02, Currency: "EUR"},
+		},
+	})
+	converter := finance.CurrencyConverter{Base: "USD", Rates: finance.StaticRates{"EUR": 2}}
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)), withCurrency(converter))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/growth-rate", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		AnnualGrowthRate float64 `json:"annualGrowthRate"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode growth rate: %v", err)
+	}
+	// 1000 USD at 8% and 1000 USD-equivalent at 2% blend to 5%.
+	if math.Abs(body.AnnualGrowthRate-0.05) > 1e-12 {
+		t.Fatalf("expected a 0.05 blended rate, got %v", body.AnnualGrowthRate)
+	}
+}
+
+func TestAssetResponsesIncludeOneYearProjection(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{{ID: "a1", Name: "Index fund", Category: "brokerage", CurrentValue: 10000, AnnualGrowthRate: 0.07}},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	getRec := httptest.NewRecorder()
+	router.ServeHTTP(getRec, httptest.NewRequest(http.MethodGet, "/assets/a1", nil))
+	var asset assetResponse
+	if err := json.Unmarshal(getRec.Body.Bytes(), &asset); err != nil {
+		t.Fatalf("failed to decode asset: %v", err)
+	}
+	if asset.ProjectedValueOneYear.Float64() != 10700 {
+		t.Fatalf("expected projectedValueOneYear 10700, got %v", asset.ProjectedValueOneYear.Float64())
+	}
+
+	listRec := httptest.NewRecorder()
+	router.ServeHTTP(listRec, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	var list []assetResponse
+	if err := json.Unmarshal(listRec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("failed to decode list: %v", err)
+	}
+	if len(list) != 1 || list[0].ProjectedValueOneYear.Float64() != 10700 {
+		t.Fatalf("expected projected value in list, got %+v", list)
+	}
+}
+
 func TestCashFlowSummary(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
 	seed := finance.DefaultSeedData(time.Now().UTC())
@@ -126,6 +256,405 @@ func TestCashFlowSummary(t *testing.T) {
 	}
 }
 
+func TestOmittedFrequencyUsesConfiguredDefault(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	cases := []struct {
+		name string
+		opts []routerOption
+		path string
+		body string
+		want finance.Frequency
+	}{
+		{"income defaults to monthly", nil, "/cashflow/incomes", `{"source":"Salary","amount":100,"startDate":"2024-01-01T00:00:00Z"}`, finance.FrequencyMonthly},
+		{"expense defaults to monthly", nil, "/cashflow/expenses", `{"payee":"Rent","amount":100}`, finance.FrequencyMonthly},
+		{"configured default", []routerOption{withDefaultFrequency(finance.FrequencyYearly)}, "/cashflow/expenses", `{"payee":"Insurance","amount":100}`, finance.FrequencyYearly},
+		{"explicit value wins", []routerOption{withDefaultFrequency(finance.FrequencyYearly)}, "/cashflow/expenses", `{"payee":"Gym","amount":100,"frequency":"weekly"}`, finance.FrequencyWeekly},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)), tc.opts...)
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var created struct {
+				Frequency finance.Frequency `json:"frequency"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if created.Frequency != tc.want {
+				t.Fatalf("expected frequency %s, got %s", tc.want, created.Frequency)
+			}
+		})
+	}
+}
+
+func TestCashFlowSummaryAnnualPeriod(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.DefaultSeedData(time.Now().UTC()))
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	fetch := func(url string) finance.CashFlowSummary {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", url, rec.Code)
+		}
+		var payload struct {
+			Summary finance.CashFlowSummary `json:"summary"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("%s: failed to decode json: %v", url, err)
+		}
+		return payload.Summary
+	}
+
+	monthly := fetch("/cashflow/summary")
+	annual := fetch("/cashflow/summary?period=annual")
+
+	if monthly.Period != "monthly" || annual.Period != "annual" {
+		t.Fatalf("expected period labels, got %q and %q", monthly.Period, annual.Period)
+	}
+	if annual.MonthlyIncome != math.Round(monthly.MonthlyIncome*12*100)/100 {
+		t.Fatalf("expected annual income to be 12x monthly %.2f, got %.2f", monthly.MonthlyIncome, annual.MonthlyIncome)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/cashflow?period=weekly", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported period, got %d", rec.Code)
+	}
+}
+
+func TestRenameCategoryUpdatesMatchingCashFlow(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	now := time.Now().UTC()
+	repo := memory.NewRepository(finance.SeedData{
+		Incomes: []finance.Income{
+			{ID: "inc-1", Source: "Rental", Amount: 900, Frequency: finance.FrequencyMonthly, StartDate: now, Category: "living"},
+			{ID: "inc-2", Source: "Salary", Amount: 5000, Frequency: finance.FrequencyMonthly, StartDate: now, Category: "salary"},
+		},
+		Expenses: []finance.Expense{
+			{ID: "exp-1", Payee: "Groceries", Amount: 400, Frequency: finance.FrequencyMonthly, Category: "living"},
+			{ID: "exp-2", Payee: "Utilities", Amount: 150, Frequency: finance.FrequencyMonthly, Category: "living"},
+			{ID: "exp-3", Payee: "Gym", Amount: 60, Frequency: finance.FrequencyMonthly, Category: "health"},
+		},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	req := httptest.NewRequest(http.MethodPost, "/cashflow/categories/rename", strings.NewReader(`{"from":"living","to":"household"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var result struct {
+		Updated int `json:"updated"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if result.Updated != 3 {
+		t.Fatalf("expected 3 rows updated, got %d", result.Updated)
+	}
+
+	ctx := context.Background()
+	expenses, _ := repo.Expenses().List(ctx)
+	for _, expense := range expenses {
+		if expense.Category == "living" {
+			t.Fatalf("expected %s to be renamed, still living", expense.ID)
+		}
+	}
+	if gym, _ := repo.Expenses().Get(ctx, "exp-3"); gym.Category != "health" {
+		t.Fatalf("expected unrelated category to be untouched, got %q", gym.Category)
+	}
+	if rental, _ := repo.Incomes().Get(ctx, "inc-1"); rental.Category != "household" {
+		t.Fatalf("expected income category household, got %q", rental.Category)
+	}
+	if history := hub.History("", 0); len(history) != 1 {
+		t.Fatalf("expected a single update event, got %d", len(history))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/cashflow/categories/rename", strings.NewReader(`{"from":" ","to":"household"}`))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for empty from, got %d", rec.Code)
+	}
+}
+
+func TestCashFlowForecastUsesAnchors(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	invalidBody := `{"source":"Salary","amount":8000,"frequency":"monthly","startDate":"2020-01-01T00:00:00Z","dayOfMonth":32}`
+	invalidReq := httptest.NewRequest(http.MethodPost, "/cashflow/incomes", strings.NewReader(invalidBody))
+	invalidRec := httptest.NewRecorder()
+	router.ServeHTTP(invalidRec, invalidReq)
+	if invalidRec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for dayOfMonth 32, got %d", invalidRec.Code)
+	}
+
+	createBody := `{"source":"Salary","amount":8000,"frequency":"monthly","startDate":"2020-01-01T00:00:00Z","category":"salary","dayOfMonth":25}`
+	createReq := httptest.NewRequest(http.MethodPost, "/cashflow/incomes", strings.NewReader(createBody))
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", createRec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/cashflow/forecast?months=2", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var forecast []finance.ForecastMonth
+	if err := json.Unmarshal(rec.Body.Bytes(), &forecast); err != nil {
+		t.Fatalf("failed to decode forecast: %v", err)
+	}
+	if len(forecast) != 2 {
+		t.Fatalf("expected 2 forecast months, got %d", len(forecast))
+	}
+	next := forecast[1]
+	if len(next.Events) != 1 || next.Events[0].Date.Day() != 25 {
+		t.Fatalf("expected salary on the 25th, got %#v", next.Events)
+	}
+}
+
+func TestIncomeStartDateBounds(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	tooLate := time.Now().AddDate(maxStartDateYears+1, 0, 0).UTC().Format(time.RFC3339)
+	cases := []struct {
+		name      string
+		startDate string
+		status    int
+		message   string
+	}{
+		{"typo year", "0024-01-01T00:00:00Z", http.StatusBadRequest, "before 1900"},
+		{"far future", tooLate, http.StatusBadRequest, "years in the future"},
+		{"lower bound", "1900-01-01T00:00:00Z", http.StatusCreated, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"source":"Salary","amount":100,"startDate":"` + tc.startDate + `"}`
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/cashflow/incomes", strings.NewReader(body)))
+			if rec.Code != tc.status {
+				t.Fatalf("expected %d, got %d: %s", tc.status, rec.Code, rec.Body.String())
+			}
+			if tc.message != "" && !strings.Contains(rec.Body.String(), tc.message) {
+				t.Fatalf("expected error mentioning %q, got %s", tc.message, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestUpcomingCashEvents(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.DefaultSeedData(time.Now().UTC()))
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	badReq := httptest.NewRequest(http.MethodGet, "/cashflow/upcoming?days=0", nil)
+	badRec := httptest.NewRecorder()
+	router.ServeHTTP(badRec, badReq)
+	if badRec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for days=0, got %d", badRec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/cashflow/upcoming?days=31", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var upcoming []finance.CashEvent
+	if err := json.Unmarshal(rec.Body.Bytes(), &upcoming); err != nil {
+		t.Fatalf("failed to decode upcoming events: %v", err)
+	}
+	if len(upcoming) == 0 {
+		t.Fatal("expected seeded cash events within 31 days")
+	}
+	for i := 1; i < len(upcoming); i++ {
+		if upcoming[i].Date.Before(upcoming[i-1].Date) {
+			t.Fatalf("expected chronological order, got %s before %s", upcoming[i-1].Date, upcoming[i].Date)
+		}
+	}
+}
+
+func TestProjectionsHonourAsOf(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	day := 25
+	repo := memory.NewRepository(finance.SeedData{
+		Incomes: []finance.Income{
+			{ID: "salary", Source: "Salary", Amount: 5000, Frequency: finance.FrequencyMonthly, StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), DayOfMonth: &day},
+		},
+		Assets: []finance.Asset{{ID: "cash", Name: "Cash", Category: "cash", CurrentValue: 10000}},
+		Goals:  []finance.Goal{{ID: "g1", Name: "Deposit", TargetAmount: 20000, AssetCategory: "cash"}},
+	})
+	clock := func() time.Time { return time.Date(2031, 7, 4, 12, 0, 0, 0, time.UTC) }
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)), withClock(clock))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	first := get("/cashflow/forecast?months=3&asOf=2024-02-10")
+	second := get("/cashflow/forecast?months=3&asOf=2024-02-10T00:00:00Z")
+	if first.Code != http.StatusOK || first.Body.String() != second.Body.String() {
+		t.Fatalf("expected identical forecasts for the same asOf, got %d:\n%s\n%s", first.Code, first.Body.String(), second.Body.String())
+	}
+	var forecast []finance.ForecastMonth
+	if err := json.Unmarshal(first.Body.Bytes(), &forecast); err != nil {
+		t.Fatalf("failed to decode forecast: %v", err)
+	}
+	if len(forecast) != 3 || forecast[0].Month != "2024-02" || forecast[2].Month != "2024-04" {
+		t.Fatalf("expected February to April 2024, got %+v", forecast)
+	}
+
+	var upcoming []finance.CashEvent
+	if err := json.Unmarshal(get("/cashflow/upcoming?days=20&asOf=2024-02-10").Body.Bytes(), &upcoming); err != nil {
+		t.Fatalf("failed to decode upcoming events: %v", err)
+	}
+	if len(upcoming) != 1 || !upcoming[0].Date.Equal(time.Date(2024, 2, 25, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the salary on 2024-02-25, got %+v", upcoming)
+	}
+
+	var goal goalResponse
+	if err := json.Unmarshal(get("/goals/g1?asOf=2024-02-10").Body.Bytes(), &goal); err != nil {
+		t.Fatalf("failed to decode goal: %v", err)
+	}
+	if goal.Progress.ProjectedDate == nil || goal.Progress.ProjectedDate.Year() != 2024 {
+		t.Fatalf("expected a 2024 projection from asOf, got %+v", goal.Progress)
+	}
+
+	// Without asOf the injected clock is used.
+	if err := json.Unmarshal(get("/cashflow/forecast?months=1").Body.Bytes(), &forecast); err != nil {
+		t.Fatalf("failed to decode forecast: %v", err)
+	}
+	if len(forecast) != 1 || forecast[0].Month != "2031-07" {
+		t.Fatalf("expected the clock's month, got %+v", forecast)
+	}
+
+	for _, path := range []string{"/cashflow/forecast?asOf=yesterday", "/cashflow/upcoming?asOf=2024-13-01", "/goals?asOf=10/02/2024", "/transactions/reconcile?asOf=soon"} {
+		if rec := get(path); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "asOf") {
+			t.Fatalf("expected 400 naming asOf for %s, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCashFlowAlertsUseConfiguredThresholds(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Incomes:  []finance.Income{{ID: "salary", Source: "Salary", Amount: 4000, Frequency: finance.FrequencyMonthly}},
+		Expenses: []finance.Expense{{ID: "rent", Payee: "Rent", Amount: 3800, Frequency: finance.FrequencyMonthly}},
+		Assets:   []finance.Asset{{ID: "cash", Name: "Savings", Category: "savings", CurrentValue: 20000}},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+
+	fetch := func(router http.Handler) []finance.Alert {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cashflow/alerts", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var alerts []finance.Alert
+		if err := json.Unmarshal(rec.Body.Bytes(), &alerts); err != nil {
+			t.Fatalf("failed to decode alerts: %v", err)
+		}
+		return alerts
+	}
+
+	// Defaults: a 5% savings rate is too low, and no "cash" assets means no runway.
+	alerts := fetch(newRouter(logger, repo, hub))
+	if len(alerts) != 2 || alerts[0].Code != finance.AlertShortRunway || alerts[1].Code != finance.AlertLowSavingsRate {
+		t.Fatalf("expected runway and savings alerts, got %+v", alerts)
+	}
+
+	relaxed := finance.AlertThresholds{MinSavingsRate: 0.05, MinRunwayMonths: 3, EmergencyFundCategory: "savings"}
+	if alerts := fetch(newRouter(logger, repo, hub, withAlertThresholds(relaxed))); len(alerts) != 0 {
+		t.Fatalf("expected no alerts with relaxed thresholds, got %+v", alerts)
+	}
+}
+
+func TestCashFlowSummaryRejectsMixedCurrenciesWithoutRates(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Incomes: []finance.Income{
+			{ID: "income-usd", Source: "Salary", Amount: 5000, Frequency: finance.FrequencyMonthly},
+			{ID: "income-eur", Source: "Rental", Amount: 1000, Currency: "EUR", Frequency: finance.FrequencyMonthly},
+		},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub, withCurrency(finance.CurrencyConverter{Base: "USD"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/cashflow", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "mixed currencies") {
+		t.Fatalf("expected explanation of mixed currencies, body=%q", rec.Body.String())
+	}
+}
+
+func TestCashFlowSummaryConvertsWithRates(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Incomes: []finance.Income{
+			{ID: "income-usd", Source: "Salary", Amount: 5000, Frequency: finance.FrequencyMonthly},
+			{ID: "income-eur", Source: "Rental", Amount: 1000, Currency: "EUR", Frequency: finance.FrequencyMonthly},
+		},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	converter := finance.CurrencyConverter{Base: "USD", Rates: finance.StaticRates{"EUR": 1.1}}
+	router := newRouter(logger, repo, hub, withCurrency(converter))
+
+	req := httptest.NewRequest(http.MethodGet, "/cashflow", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var payload struct {
+		Summary finance.CashFlowSummary `json:"summary"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode json: %v", err)
+	}
+	if payload.Summary.MonthlyIncome != 6100 {
+		t.Fatalf("expected converted monthly income 6100, got %.2f", payload.Summary.MonthlyIncome)
+	}
+}
+
 func TestCORSMiddlewareHandlesOptions(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
 	repo := memory.NewRepository(finance.SeedData{})
@@ -145,6 +674,34 @@ func TestCORSMiddlewareHandlesOptions(t *testing.T) {
 	}
 }
 
+func TestOptionsReportsAllowedMethodsPerRoute(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	cases := map[string]string{
+		"/assets":                     "GET, POST",
+		"/assets/a1":                  "GET, HEAD, PUT, PATCH, DELETE",
+		"/cashflow/expenses/e1":       "GET, HEAD, PUT, PATCH, DELETE",
+		"/goals/g1":                   "GET, HEAD, PATCH, DELETE",
+		"/accounts/acc1/net-worth":    "GET",
+		"/transactions/reconcile":     "GET",
+		"/cashflow/categories/rename": "POST",
+		"/import/all":                 "POST",
+	}
+	for path, want := range cases {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 for OPTIONS %s, got %d", path, rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != want {
+			t.Fatalf("expected Allow %q for %s, got %q", want, path, got)
+		}
+	}
+}
+
 func TestEventStreamRequiresAuth(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
 	repo := memory.NewRepository(finance.SeedData{})
@@ -161,6 +718,27 @@ func TestEventStreamRequiresAuth(t *testing.T) {
 	}
 }
 
+func TestEventStreamRejectsMalformedCursor(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	for _, path := range []string{"/events?cursor=garbage", "/events?cursor=-1", "/events/recent?cursor=1.5"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-session")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, rec.Code)
+		}
+	}
+	if count := hub.SubscriberCount(); count != 0 {
+		t.Fatalf("expected no subscribers, got %d", count)
+	}
+}
+
 func TestEventStreamPublishesUpdates(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
 	repo := memory.NewRepository(finance.SeedData{})
@@ -247,20 +825,363 @@ func TestEventStreamReplaysFromCursor(t *testing.T) {
 	}
 }
 
-func startEventStream(t *testing.T, router http.Handler, url string) (*httptest.ResponseRecorder, context.CancelFunc, <-chan struct{}) {
-	t.Helper()
+func TestEventStreamWithoutCursorSkipsHistory(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+	hub.Publish(events.StreamEvent{Entity: "asset", Action: "update", ResourceID: "asset-old"})
 
-	req := httptest.NewRequest(http.MethodGet, url, nil)
-	req.Header.Set("Authorization", "Bearer test-session")
+	cases := map[string]bool{
+		"/events":            false,
+		"/events?cursor=all": true,
+		"/events?cursor=0":   true,
+	}
+	for path, wantHistory := range cases {
+		rec, cancel, done := startEventStream(t, router, path)
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		<-done
 
-	ctx, cancel := context.WithCancel(req.Context())
-	req = req.WithContext(ctx)
+		if got := strings.Contains(rec.Body.String(), "asset-old"); got != wantHistory {
+			t.Fatalf("%s: expected history %v, body=%q", path, wantHistory, rec.Body.String())
+		}
+	}
+}
 
-	rec := httptest.NewRecorder()
-	done := make(chan struct{})
+func TestEventReplayResendsHistoryFromFirstEvent(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
 
-	go func() {
-		router.ServeHTTP(rec, req)
+	for _, id := range []string{"a1", "a2", "a3"} {
+		hub.Publish(events.StreamEvent{Type: "finance.change", Entity: "asset", Action: "update", ResourceID: id})
+	}
+
+	unauthRec := httptest.NewRecorder()
+	router.ServeHTTP(unauthRec, httptest.NewRequest(http.MethodGet, "/events/replay", nil))
+	if unauthRec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a session, got %d", unauthRec.Code)
+	}
+
+	// The cursor is ignored: replay always starts from the oldest retained event.
+	rec, cancel, done := startEventStream(t, router, "/events/replay?cursor=3")
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	var ids []string
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if strings.HasPrefix(line, "id: ") {
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+		}
+	}
+	if len(ids) != 3 || ids[0] != "1" || ids[2] != "3" {
+		t.Fatalf("expected events 1 through 3, got %v", ids)
+	}
+}
+
+func TestEventStreamBatchesQueuedEvents(t *testing.T) {
+	rt := &router{logger: slog.New(slog.NewJSONHandler(io.Discard, nil))}
+	// A pre-filled, closed channel stands in for a burst of events that were all queued
+	// before the handler got to them.
+	burst := func(context.Context) (<-chan events.StreamEvent, error) {
+		ch := make(chan events.StreamEvent, 3)
+		for i, id := range []string{"a1", "a2", "a3"} {
+			cursor := strconv.Itoa(i + 1)
+			ch <- events.StreamEvent{ID: uint64(i + 1), Cursor: cursor, Entity: "asset", Action: "update", ResourceID: id}
+		}
+		close(ch)
+		return ch, nil
+	}
+
+	rec := httptest.NewRecorder()
+	rt.serveEventStream(rec, httptest.NewRequest(http.MethodGet, "/events?batch=true", nil), burst)
+	body := rec.Body.String()
+	if strings.Count(body, "event: ") != 1 || !strings.Contains(body, "event: batch\n") {
+		t.Fatalf("expected a single batch frame, body=%q", body)
+	}
+	if cursor := extractLastCursor(body); cursor != "3" {
+		t.Fatalf("expected batch id to be the last cursor, got %q", cursor)
+	}
+	data := strings.TrimPrefix(strings.Split(body, "\n")[2], "data: ")
+	var batch []events.StreamEvent
+	if err := json.Unmarshal([]byte(data), &batch); err != nil || len(batch) != 3 {
+		t.Fatalf("expected a JSON array of 3 events, got %q (%v)", data, err)
+	}
+
+	rec = httptest.NewRecorder()
+	rt.serveEventStream(rec, httptest.NewRequest(http.MethodGet, "/events", nil), burst)
+	if got := strings.Count(rec.Body.String(), "event: asset.update"); got != 3 {
+		t.Fatalf("expected 3 separate frames without batching, got %d", got)
+	}
+}
+
+func TestEventStreamRejectsSubscribersBeyondLimit(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0), events.WithMaxSubscribers(1))
+	router := newRouter(logger, repo, hub)
+
+	_, cancel, done := startEventStream(t, router, "/events")
+	defer func() {
+		cancel()
+		<-done
+	}()
+	deadline := time.Now().Add(time.Second)
+	for hub.SubscriberCount() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for first subscriber")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Authorization", "Bearer test-session")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 beyond the subscriber limit, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After header")
+	}
+}
+
+func TestEventStreamOpensWithSnapshot(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets:       []finance.Asset{{ID: "a1", Name: "Savings", Category: "cash", CurrentValue: 1000}},
+		Transactions: []finance.Transaction{{ID: "t1", Date: time.Now(), Amount: 5, Direction: finance.TransactionOutflow, Category: "food"}},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	rec, cancel, done := startEventStream(t, router, "/events?snapshot=true")
+	time.Sleep(10 * time.Millisecond)
+
+	createReq := httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(`{"name":"Windfall","category":"cash","currentValue":500}`))
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("expected asset create 201, got %d", createRec.Code)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	frames := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+	if len(frames) != 2 {
+		t.Fatalf("expected a snapshot frame then one live frame, got %q", frames)
+	}
+	if !strings.Contains(frames[0], "event: stream.snapshot\n") || !strings.Contains(frames[1], "event: asset.create\n") {
+		t.Fatalf("expected the snapshot first and the live event second, got %q", frames)
+	}
+
+	data := frames[0][strings.Index(frames[0], "data: ")+len("data: "):]
+	var evt struct {
+		Data streamSnapshot `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(data), &evt); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	if len(evt.Data.Assets) != 1 || evt.Data.Assets[0].ID != "a1" {
+		t.Fatalf("expected the seeded asset in the snapshot, got %+v", evt.Data.Assets)
+	}
+	if evt.Data.Counts["transactions"].Count != 1 {
+		t.Fatalf("expected a transaction count of 1, got %+v", evt.Data.Counts)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/events?snapshot=maybe", nil)
+	req.Header.Set("Authorization", "Bearer test-session")
+	badRec := httptest.NewRecorder()
+	router.ServeHTTP(badRec, req)
+	if badRec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed snapshot flag, got %d", badRec.Code)
+	}
+}
+
+func TestEventStreamWithDiffSendsOnlyChangedFields(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{{ID: "a1", Name: "Savings", Category: "cash", CurrentValue: 1000, AnnualGrowthRate: 0.02}},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	diffRec, cancelDiff, diffDone := startEventStream(t, router, "/events?diff=true")
+	fullRec, cancelFull, fullDone := startEventStream(t, router, "/events")
+	time.Sleep(10 * time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPatch, "/assets/a1", strings.NewReader(`{"currentValue":1500}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected asset patch 200, got %d", rec.Code)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cancelDiff()
+	cancelFull()
+	<-diffDone
+	<-fullDone
+
+	decode := func(body string) map[string]any {
+		t.Helper()
+		frame := strings.TrimSpace(body)
+		if !strings.Contains(frame, "event: asset.update\n") {
+			t.Fatalf("expected one asset.update frame, got %q", frame)
+		}
+		var evt struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(frame[strings.Index(frame, "data: ")+len("data: "):]), &evt); err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+		return evt.Data
+	}
+
+	changed := decode(diffRec.Body.String())
+	if len(changed) != 2 || changed["currentValue"] != 1500.0 || changed["updatedAt"] == nil {
+		t.Fatalf("expected only currentValue and updatedAt in the diff, got %v", changed)
+	}
+	if full := decode(fullRec.Body.String()); full["name"] != "Savings" || full["currentValue"] != 1500.0 {
+		t.Fatalf("expected the full entity without ?diff, got %v", full)
+	}
+}
+
+func TestEventStreamEndsAfterMaxLifetime(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), hub, withStreamLifetime(50*time.Millisecond))
+
+	// The request context is never cancelled, as with a client that vanished silently.
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Authorization", "Bearer test-session")
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the stream to end once its lifetime elapsed")
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "event: reconnect\n") || !strings.Contains(body, "retry: ") {
+		t.Fatalf("expected a reconnect hint, body=%q", body)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for hub.SubscriberCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the subscriber to be removed, still have %d", hub.SubscriberCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRecentEventsReturnsHistoryAfterCursor(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	for _, id := range []string{"asset-1", "asset-2", "asset-3"} {
+		hub.Publish(events.StreamEvent{Entity: "asset", Action: "update", ResourceID: id})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/events/recent?cursor=1&limit=50", nil)
+	req.Header.Set("Authorization", "Bearer test-session")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected JSON response, got %q", got)
+	}
+
+	var history []events.StreamEvent
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatalf("failed to decode history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 events after cursor, got %d", len(history))
+	}
+	if history[0].ResourceID != "asset-2" || history[1].ResourceID != "asset-3" {
+		t.Fatalf("expected events in publish order, got %#v", history)
+	}
+}
+
+func TestRecentEventsPagesBackwardWithBefore(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+	for i := 1; i <= 7; i++ {
+		hub.Publish(events.StreamEvent{Entity: "asset", Action: "update", ResourceID: "asset-" + strconv.Itoa(i)})
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/events/recent?"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-session")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var pages [][]string
+	for before := "latest"; ; {
+		rec := get("before=" + before + "&limit=3")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("before=%s: expected 200, got %d: %s", before, rec.Code, rec.Body)
+		}
+		var page []events.StreamEvent
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("failed to decode page: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		ids := make([]string, len(page))
+		for i, evt := range page {
+			ids[i] = evt.ResourceID
+		}
+		pages = append(pages, ids)
+		before = page[0].Cursor
+	}
+	want := [][]string{{"asset-5", "asset-6", "asset-7"}, {"asset-2", "asset-3", "asset-4"}, {"asset-1"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Fatalf("expected pages %v, got %v", want, pages)
+	}
+
+	for _, query := range []string{"before=0", "before=abc", "before=3&cursor=1"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func startEventStream(t *testing.T, router http.Handler, url string) (*httptest.ResponseRecorder, context.CancelFunc, <-chan struct{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer test-session")
+
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+
+	go func() {
+		router.ServeHTTP(rec, req)
 		close(done)
 	}()
 
@@ -278,3 +1199,322 @@ func extractLastCursor(body string) string {
 	}
 	return cursor
 }
+
+func TestPutReplacesWhilePatchMerges(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	notes := "emergency fund"
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{
+			{ID: "a1", Name: "Savings", Category: "cash", CurrentValue: 1000, AnnualGrowthRate: 0.02, Notes: notes},
+		},
+		Expenses: []finance.Expense{
+			{ID: "e1", Payee: "Insurance", Amount: 600, Frequency: finance.FrequencyYearly, Category: "protection"},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected %s %s to return 200, got %d: %s", method, path, rec.Code, rec.Body.String())
+		}
+		return rec
+	}
+
+	send(http.MethodPatch, "/assets/a1", `{"currentValue":1500}`)
+	patched, _ := repo.Assets().Get(context.Background(), "a1")
+	if patched.CurrentValue != 1500 || patched.Name != "Savings" || patched.AnnualGrowthRate != 0.02 || patched.Notes != notes {
+		t.Fatalf("expected PATCH to keep omitted fields, got %+v", patched)
+	}
+
+	send(http.MethodPut, "/assets/a1", `{"name":"Savings","category":"cash","currentValue":2000}`)
+	replaced, _ := repo.Assets().Get(context.Background(), "a1")
+	if replaced.CurrentValue != 2000 || replaced.AnnualGrowthRate != 0 || replaced.Notes != "" {
+		t.Fatalf("expected PUT to reset omitted fields, got %+v", replaced)
+	}
+
+	send(http.MethodPatch, "/cashflow/expenses/e1", `{"amount":720}`)
+	expense, _ := repo.Expenses().Get(context.Background(), "e1")
+	if expense.Amount != 720 || expense.Frequency != finance.FrequencyYearly || expense.Category != "protection" {
+		t.Fatalf("expected PATCH to keep frequency and category, got %+v", expense)
+	}
+
+	send(http.MethodPut, "/cashflow/expenses/e1", `{"payee":"Insurance","amount":60}`)
+	expense, _ = repo.Expenses().Get(context.Background(), "e1")
+	if expense.Frequency != finance.FrequencyMonthly || expense.Category != "" {
+		t.Fatalf("expected PUT to reset frequency and category, got %+v", expense)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/assets/a1", strings.NewReader(`{"currentValue":1}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected PUT without required fields to fail with 400, got %d", rec.Code)
+	}
+}
+
+func TestUpsertPropertyScenarioByType(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/property-planner/scenarios/by-type/hdb", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := put(`{"headline":"Resale","inputs":{"loanTermYears":25}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected the first save to create with 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created finance.PropertyPlannerScenario
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Type != "hdb" || created.ID == "" {
+		t.Fatalf("expected a new hdb scenario, got %+v", created)
+	}
+
+	rec = put(`{"id":"ignored","type":"hdb","headline":"BTO","inputs":{"loanTermYears":25}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the second save to update with 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated finance.PropertyPlannerScenario
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if updated.ID != created.ID || updated.Headline != "BTO" {
+		t.Fatalf("expected %s to be updated in place, got %+v", created.ID, updated)
+	}
+
+	history := hub.History("", 0)
+	if len(history) != 2 || history[0].Action != events.ActionCreate || history[1].Action != events.ActionUpdate {
+		t.Fatalf("expected create then update events, got %+v", history)
+	}
+
+	if rec := put(`{"type":"condo","headline":"X","inputs":{"loanTermYears":25}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a body type that disagrees with the path to fail with 400, got %d", rec.Code)
+	}
+}
+
+func TestHeadOnItemsReturnsHeadersWithoutBody(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{{ID: "a1", Name: "Savings", Category: "cash"}},
+		Goals:  []finance.Goal{{ID: "g1", Name: "Trip", TargetAmount: 100, AssetCategory: "cash"}},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{"/assets/a1", http.StatusOK},
+		{"/goals/g1", http.StatusOK},
+		{"/assets/missing", http.StatusNotFound},
+		{"/cashflow/incomes/missing", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodHead, tc.path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status {
+			t.Fatalf("expected HEAD %s to return %d, got %d", tc.path, tc.status, rec.Code)
+		}
+		if rec.Body.Len() != 0 {
+			t.Fatalf("expected empty body for HEAD %s, got %q", tc.path, rec.Body.String())
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Fatalf("expected GET headers on HEAD %s, got content type %q", tc.path, got)
+		}
+	}
+}
+
+func TestMethodNotAllowedListsAllowedMethods(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	cases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodPost, "/assets/a1", "GET, HEAD, PUT, PATCH, DELETE"},
+		{http.MethodDelete, "/assets", "GET, POST"},
+		{http.MethodPost, "/export/all", "GET"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("expected 405 for %s %s, got %d", tc.method, tc.path, rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != tc.want {
+			t.Fatalf("expected Allow %q for %s %s, got %q", tc.want, tc.method, tc.path, got)
+		}
+	}
+}
+
+func TestBulkUpdateExpensesRecategorizes(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{Expenses: []finance.Expense{
+		{ID: "e1", Payee: "Cinema", Amount: 30, Frequency: finance.FrequencyMonthly, Category: "fun"},
+		{ID: "e2", Payee: "Concert", Amount: 80, Frequency: finance.FrequencyQuarterly, Category: "fun"},
+		{ID: "e3", Payee: "Books", Amount: 25, Frequency: finance.FrequencyMonthly, Category: "education"},
+		{ID: "e4", Payee: "Rent", Amount: 2000, Frequency: finance.FrequencyMonthly, Category: "housing"},
+	}})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	body := `{"ids":["e1","e2","e3","missing"],"changes":{"category":"leisure"}}`
+	req := httptest.NewRequest(http.MethodPatch, "/cashflow/expenses/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var result struct {
+		Updated []expenseResponse `json:"updated"`
+		Missing []string          `json:"missing"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if len(result.Updated) != 3 || len(result.Missing) != 1 || result.Missing[0] != "missing" {
+		t.Fatalf("expected 3 updated and 1 missing, got %+v", result)
+	}
+	for _, id := range []string{"e1", "e2", "e3"} {
+		expense, _ := repo.Expenses().Get(context.Background(), id)
+		if expense.Category != "leisure" {
+			t.Fatalf("expected %s to be recategorized, got %q", id, expense.Category)
+		}
+	}
+	if rent, _ := repo.Expenses().Get(context.Background(), "e4"); rent.Category != "housing" || rent.Payee != "Rent" {
+		t.Fatalf("expected untouched expense to keep its fields, got %+v", rent)
+	}
+
+	bad := httptest.NewRequest(http.MethodPatch, "/cashflow/expenses/bulk", strings.NewReader(`{"ids":["e1"],"changes":{"amount":-5}}`))
+	badRec := httptest.NewRecorder()
+	router.ServeHTTP(badRec, bad)
+	if badRec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for invalid change, got %d", badRec.Code)
+	}
+}
+
+func TestCollectionMetaReportsCountAndLastUpdate(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{
+			{ID: "a1", Name: "Cash", Category: "cash", CurrentValue: 100, UpdatedAt: older},
+			{ID: "a2", Name: "Fund", Category: "brokerage", CurrentValue: 200, UpdatedAt: newer},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	var meta struct {
+		Count         int        `json:"count"`
+		LastUpdatedAt *time.Time `json:"lastUpdatedAt"`
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/meta", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &meta); err != nil {
+		t.Fatalf("failed to decode meta: %v", err)
+	}
+	if meta.Count != 2 || meta.LastUpdatedAt == nil || !meta.LastUpdatedAt.Equal(newer) {
+		t.Fatalf("expected 2 assets last updated %s, got %+v", newer, meta)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cashflow/expenses/meta", nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), &meta); err != nil {
+		t.Fatalf("failed to decode meta: %v", err)
+	}
+	if meta.Count != 0 || meta.LastUpdatedAt != nil {
+		t.Fatalf("expected empty expense meta, got %+v", meta)
+	}
+}
+
+func TestLoggingMiddlewareWarnsAboutSlowRequests(t *testing.T) {
+	var logs strings.Builder
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	handler := loggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fast" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}), logger, nil, 10*time.Millisecond)
+
+	for _, path := range []string{"/slow", "/fast", "/events", "/events/replay", "/events/recent"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	var warned []string
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry struct {
+			Level       string `json:"level"`
+			Msg         string `json:"msg"`
+			Path        string `json:"path"`
+			DurationMS  int64  `json:"duration_ms"`
+			ThresholdMS int64  `json:"threshold_ms"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to decode log line %q: %v", line, err)
+		}
+		if entry.Level != "WARN" {
+			continue
+		}
+		if entry.Msg != "slow request" || entry.DurationMS < 30 || entry.ThresholdMS != 10 {
+			t.Fatalf("unexpected warning %+v", entry)
+		}
+		warned = append(warned, entry.Path)
+	}
+	if len(warned) != 2 || warned[0] != "/slow" || warned[1] != "/events/recent" {
+		t.Fatalf("expected warnings for /slow and /events/recent only, got %v", warned)
+	}
+}
+
+func TestStartDatesAreJudgedAgainstTheRouterClock(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	clock := time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)), withClock(func() time.Time { return clock }))
+
+	post := func(path, startDate string) int {
+		t.Helper()
+		income := `{"source":"Pension","amount":100,"frequency":"monthly","category":"salary","startDate":"` + startDate + `"}`
+		body := income
+		if path == "/import/all" {
+			body = `{"incomes":[` + income + `]}`
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec.Code
+	}
+
+	for _, path := range []string{"/cashflow/incomes", "/import/all"} {
+		// Within a century of the pinned clock, though not of the wall clock.
+		if code := post(path, "2150-01-01T00:00:00Z"); code != http.StatusCreated && code != http.StatusOK {
+			t.Fatalf("%s: expected a start date near the pinned clock to be accepted, got %d", path, code)
+		}
+		// More than a century ahead of the pinned clock.
+		if code := post(path, "2350-01-01T00:00:00Z"); code != http.StatusBadRequest {
+			t.Fatalf("%s: expected a start date too far past the pinned clock to be rejected, got %d", path, code)
+		}
+	}
+}
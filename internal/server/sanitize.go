@@ -0,0 +1,47 @@
+package server
+
+import (
+	"html"
+	"regexp"
+)
+
+// notesMode controls how HTML in free-text notes is neutralised before it is stored.
+// Notes are plain text, but a client that renders them as HTML would otherwise execute
+// whatever markup a caller stored there.
+type notesMode string
+
+const (
+	// notesStrip removes tags, and the contents of script and style elements, keeping
+	// the surrounding text as written.
+	notesStrip notesMode = "strip"
+	// notesEscape keeps the text verbatim but HTML-escapes it, so markup displays
+	// literally.
+	notesEscape notesMode = "escape"
+)
+
+var (
+	// scriptElements matches a script or style element with its contents.
+	scriptElements = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(script|style)\s*>`)
+	// htmlTags matches tags and comments. A tag must start with a letter, so text such
+	// as "a < b > c" is not mistaken for markup.
+	htmlTags = regexp.MustCompile(`(?s)<!--.*?-->|</?[a-zA-Z][^>]*>`)
+)
+
+// clean applies the mode to notes.
+func (m notesMode) clean(notes string) string {
+	if m == notesEscape {
+		return html.EscapeString(notes)
+	}
+	notes = scriptElements.ReplaceAllString(notes, "")
+	return htmlTags.ReplaceAllString(notes, "")
+}
+
+// patch returns the notes a partial update stores. Stored notes were cleaned when they
+// were written, and escaping is not idempotent, so they are kept as they are unless the
+// update sends new notes.
+func (m notesMode) patch(sent *string, stored string) string {
+	if sent == nil {
+		return stored
+	}
+	return m.clean(*sent)
+}
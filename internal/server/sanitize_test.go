@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestNotesModeClean(t *testing.T) {
+	cases := []struct {
+		mode notesMode
+		in   string
+		want string
+	}{
+		{notesStrip, `Rent <script>alert("x")</script>due`, "Rent due"},
+		{notesStrip, `<b>Bold</b> <img src=x onerror=alert(1)>move`, "Bold move"},
+		{notesStrip, "<!-- hidden -->keep", "keep"},
+		{notesStrip, "3 < 5 and 7 > 2, Tom & Jerry", "3 < 5 and 7 > 2, Tom & Jerry"},
+		{notesEscape, `<script>alert("x")</script>`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+		{notesEscape, "plain text", "plain text"},
+	}
+	for _, tc := range cases {
+		if got := tc.mode.clean(tc.in); got != tc.want {
+			t.Fatalf("%s.clean(%q) = %q, want %q", tc.mode, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestNotesAreSanitizedOnWrite(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	body := `{"name":"Cash","category":"cash","currentValue":10,"notes":"Emergency fund<script>fetch('/steal')</script>"}`
+
+	for _, tc := range []struct {
+		mode string
+		want string
+	}{
+		{"", "Emergency fund"},
+		{"escape", "Emergency fund&lt;script&gt;fetch(&#39;/steal&#39;)&lt;/script&gt;"},
+	} {
+		repo := memory.NewRepository(finance.SeedData{})
+		router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)), withNotesMode(tc.mode))
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(body)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("mode %q: expected 201, got %d: %s", tc.mode, rec.Code, rec.Body.String())
+		}
+		var created assetResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+			t.Fatalf("decode asset: %v", err)
+		}
+
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/"+created.ID, nil))
+		var stored assetResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &stored); err != nil {
+			t.Fatalf("decode asset: %v", err)
+		}
+		if stored.Notes != tc.want {
+			t.Fatalf("mode %q: expected notes %q on read-back, got %q", tc.mode, tc.want, stored.Notes)
+		}
+	}
+}
+
+func TestEscapedNotesSurvivePartialUpdates(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets:   []finance.Asset{{ID: "asset-1", Name: "Cash", Category: "cash", CurrentValue: 10, Notes: "Tom &amp; Jerry"}},
+		Expenses: []finance.Expense{{ID: "expense-1", Payee: "Rent", Amount: 100, Frequency: finance.FrequencyMonthly, Category: "housing", Notes: "Tom &amp; Jerry"}},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)), withNotesMode("escape"))
+
+	patch := func(path, body string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("PATCH %s: expected 200, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+	for i := range 3 {
+		patch("/assets/asset-1", fmt.Sprintf(`{"name":"Cash %d"}`, i))
+		patch("/cashflow/expenses/bulk", `{"ids":["expense-1"],"changes":{"category":"home"}}`)
+	}
+
+	asset, err := repo.Assets().Get(context.Background(), "asset-1")
+	if err != nil || asset.Notes != "Tom &amp; Jerry" {
+		t.Fatalf("expected asset notes unchanged by patches that omit them, got %q, %v", asset.Notes, err)
+	}
+	expense, err := repo.Expenses().Get(context.Background(), "expense-1")
+	if err != nil || expense.Notes != "Tom &amp; Jerry" {
+		t.Fatalf("expected expense notes unchanged by bulk updates that omit them, got %q, %v", expense.Notes, err)
+	}
+
+	patch("/assets/asset-1", `{"notes":"Tom & Jerry <3"}`)
+	if asset, _ = repo.Assets().Get(context.Background(), "asset-1"); asset.Notes != "Tom &amp; Jerry &lt;3" {
+		t.Fatalf("expected notes sent in a patch to be escaped once, got %q", asset.Notes)
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+const (
+	// scenarioBatchSize is how many stale scenarios are rewritten per transaction.
+	scenarioBatchSize = 20
+	// scenarioBatchPause spaces out batches so a large backlog does not saturate the
+	// database.
+	scenarioBatchPause = time.Second
+)
+
+// scenarioRecomputer rewrites property scenarios whose stored snapshot no longer
+// matches a fresh amortization of their inputs, as happens when an older client
+// computed them or a calculation fix ships. It runs periodically in the background and
+// on demand from POST /admin/recompute.
+type scenarioRecomputer struct {
+	logger    *slog.Logger
+	repo      repository.Repository
+	hub       *events.Hub
+	interval  time.Duration
+	tolerance float64
+	batchSize int
+	pause     time.Duration
+	// after is time.After; tests replace it to trigger passes deterministically.
+	after func(time.Duration) <-chan time.Time
+	// now dates schedules whose inputs have no start month.
+	now func() time.Time
+}
+
+func newScenarioRecomputer(logger *slog.Logger, repo repository.Repository, hub *events.Hub, interval time.Duration, tolerance float64, now func() time.Time) *scenarioRecomputer {
+	return &scenarioRecomputer{
+		logger:    logger,
+		repo:      repo,
+		hub:       hub,
+		interval:  interval,
+		tolerance: tolerance,
+		batchSize: scenarioBatchSize,
+		pause:     scenarioBatchPause,
+		after:     time.After,
+		now:       now,
+	}
+}
+
+// run makes a pass every interval until the context is cancelled.
+func (rc *scenarioRecomputer) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rc.after(rc.interval):
+			if _, err := rc.pass(ctx); err != nil && ctx.Err() == nil {
+				rc.logger.Warn("failed to recompute stale scenarios", "error", err)
+			}
+		}
+	}
+}
+
+// pass recomputes every stale scenario and reports how many it rewrote.
+func (rc *scenarioRecomputer) pass(ctx context.Context) (int, error) {
+	_, fixed, err := rc.recompute(ctx, func(stored finance.PropertyPlannerScenario, _ finance.MortgageAmortization, fresh finance.MortgageSnapshot) bool {
+		return rc.stale(stored.Snapshot, fresh)
+	})
+	return fixed, err
+}
+
+// recompute rewrites the scenarios for which outdated reports true with a fresh
+// amortization. Each batch is written in one transaction and announced with one event,
+// with a pause between batches. It reports how many scenarios it checked and rewrote.
+func (rc *scenarioRecomputer) recompute(ctx context.Context, outdated func(stored finance.PropertyPlannerScenario, amortization finance.MortgageAmortization, snapshot finance.MortgageSnapshot) bool) (int, int, error) {
+	scenarios, err := rc.repo.PropertyPlanner().List(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var ids []string
+	for _, scenario := range scenarios {
+		if scenario.Inputs.Validate() != nil {
+			// Nothing sensible to recompute from.
+			continue
+		}
+		if !scenario.Inputs.SingleRate() {
+			// The model has no floating period, so its result would be wrong, not fresher.
+			continue
+		}
+		if amortization, snapshot := finance.ComputeAmortization(scenario.Inputs, rc.now()); outdated(scenario, amortization, snapshot) {
+			ids = append(ids, scenario.ID)
+		}
+	}
+
+	fixed := 0
+	for batch := range slices.Chunk(ids, rc.batchSize) {
+		if fixed > 0 && rc.pause > 0 {
+			select {
+			case <-ctx.Done():
+				return len(scenarios), fixed, ctx.Err()
+			case <-rc.after(rc.pause):
+			}
+		}
+		updated, _, err := rc.repo.PropertyPlanner().UpdateMany(ctx, batch, func(scenario *finance.PropertyPlannerScenario) error {
+			scenario.Amortization, scenario.Snapshot = finance.ComputeAmortization(scenario.Inputs, rc.now())
+			return nil
+		})
+		if err != nil {
+			return len(scenarios), fixed, err
+		}
+		fixed += len(updated)
+		rc.logger.Info("recomputed property scenarios", "count", len(updated))
+		if rc.hub != nil && len(updated) > 0 {
+			rc.hub.Publish(events.StreamEvent{
+				Type:   events.TypeChange,
+				Entity: "propertyScenario",
+				Action: events.ActionUpdate,
+				Data:   updated,
+			})
+		}
+	}
+	return len(scenarios), fixed, nil
+}
+
+// stale reports whether the stored snapshot's payment or interest is further than the
+// tolerance from the fresh calculation.
+func (rc *scenarioRecomputer) stale(stored, fresh finance.MortgageSnapshot) bool {
+	return math.Abs(stored.MonthlyPayment-fresh.MonthlyPayment) > rc.tolerance ||
+		math.Abs(stored.TotalInterest-fresh.TotalInterest) > rc.tolerance
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestScenarioRecomputerFixesStaleScenarios(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	now := func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	inputs := finance.MortgageInputs{LoanAmount: 500000, LoanTermYears: 25, LoanStartMonth: "2024-03", FixedRate: 3}
+	_, freshSnapshot := finance.ComputeAmortization(inputs, now())
+	floating := inputs
+	floating.FixedYears, floating.FloatingRate = 3, 4.2
+	repo := memory.NewRepository(finance.SeedData{
+		PropertyScenarios: []finance.PropertyPlannerScenario{
+			{ID: "stale", Type: "hdb", Headline: "Old client", Inputs: inputs, Snapshot: finance.MortgageSnapshot{MonthlyPayment: 1800}},
+			{ID: "fresh", Type: "condo", Headline: "Current", Inputs: inputs, Snapshot: freshSnapshot},
+			{ID: "floating", Type: "landed", Headline: "Reprices after 3 years", Inputs: floating, Snapshot: finance.MortgageSnapshot{MonthlyPayment: 2500}},
+		},
+	})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+
+	recomputer := newScenarioRecomputer(logger, repo, hub, time.Hour, 1, now)
+	ticks := make(chan time.Time)
+	recomputer.after = func(time.Duration) <-chan time.Time { return ticks }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		recomputer.run(ctx)
+		close(done)
+	}()
+	ticks <- time.Now()
+	// The second send only lands once the first pass has finished.
+	ticks <- time.Now()
+	cancel()
+	<-done
+
+	stale, err := repo.PropertyPlanner().Get(context.Background(), "stale")
+	if err != nil {
+		t.Fatalf("get scenario: %v", err)
+	}
+	if stale.Snapshot.MonthlyPayment != freshSnapshot.MonthlyPayment || len(stale.Amortization.BalancePoints) != 25 {
+		t.Fatalf("expected the stale scenario to be recomputed, got %+v", stale.Snapshot)
+	}
+
+	// The model has no floating period, so it must not overwrite this one.
+	if floating, err := repo.PropertyPlanner().Get(context.Background(), "floating"); err != nil || floating.Snapshot.MonthlyPayment != 2500 {
+		t.Fatalf("expected the floating-rate scenario left alone, got %+v, %v", floating.Snapshot, err)
+	}
+
+	history := hub.History("", 0)
+	if len(history) != 1 || history[0].Action != "update" {
+		t.Fatalf("expected one update event for the batch, got %+v", history)
+	}
+	if batch, ok := history[0].Data.([]finance.PropertyPlannerScenario); !ok || len(batch) != 1 || batch[0].ID != "stale" {
+		t.Fatalf("expected the event to carry the stale scenario, got %#v", history[0].Data)
+	}
+}
@@ -2,24 +2,85 @@ package server
 
 import (
 	"context"
+	"errors"
+	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/jcleow/assetra2/internal/config"
 	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/healthcheck"
 	"github.com/jcleow/assetra2/internal/repository"
 )
 
+// pinger is implemented by repositories backed by a database, which /health/ready
+// then checks.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
 // Server wraps the HTTP server and supporting dependencies.
 type Server struct {
 	logger     *slog.Logger
 	httpServer *http.Server
+	network    string
+	lifecycle  *lifecycle
 }
 
 // New configures the HTTP server with routes and sensible defaults.
 func New(cfg config.Config, logger *slog.Logger, repo repository.Repository) *Server {
-	hub := events.NewHub()
-	mux := newRouter(logger, repo, hub)
+	// Without a hub, changes are not published and the /events routes answer 503.
+	var hub *events.Hub
+	if cfg.EventsEnabled {
+		hub = events.NewHub(
+			events.WithMaxHistory(cfg.EventMaxHistory),
+			events.WithDebounceWindow(cfg.EventDebounceWindow),
+			events.WithBufferSize(cfg.EventBufferSize),
+			events.WithMaxSubscribers(cfg.EventMaxSubscribers),
+			events.WithImmediateActions("delete"),
+		)
+	}
+	currency := finance.CurrencyConverter{Base: cfg.BaseCurrency}
+	if len(cfg.FXRates) > 0 {
+		currency.Rates = finance.StaticRates(cfg.FXRates)
+	}
+	checks := healthcheck.NewRegistry(cfg.HealthCheckTimeout)
+	if db, ok := repo.(pinger); ok {
+		checks.Register("database", db.Ping)
+	}
+	// The router and the background jobs read the same clock.
+	now := time.Now
+	mux := newRouter(logger, repo, hub,
+		withClock(now),
+		withHealthChecks(checks),
+		withCurrency(currency),
+		withDefaultFrequency(finance.Frequency(cfg.DefaultFrequency)),
+		withTrustedProxies(cfg.TrustedProxies),
+		withLocation(cfg.Location),
+		withStreamLifetime(cfg.EventStreamMaxLifetime),
+		withAdminToken(cfg.AdminToken),
+		withMaintenanceMode(cfg.MaintenanceMode),
+		withServerTiming(cfg.ServerTiming),
+		withSlowRequestThreshold(cfg.SlowRequestThreshold),
+		withNotesMode(cfg.NotesSanitize),
+		withFieldLimits(fieldLimits{Name: cfg.MaxNameLength, Notes: cfg.MaxNotesLength}),
+		withCategories(categoryLists{
+			Assets:      cfg.AssetCategories,
+			Liabilities: cfg.LiabilityCategories,
+			Incomes:     cfg.IncomeCategories,
+			Expenses:    cfg.ExpenseCategories,
+			Strict:      cfg.CategoryStrict,
+		}),
+		withAlertThresholds(finance.AlertThresholds{
+			MinSavingsRate:        cfg.AlertMinSavingsRate,
+			MinRunwayMonths:       cfg.AlertMinRunwayMonths,
+			EmergencyFundCategory: cfg.AlertEmergencyCategory,
+		}),
+	)
 
 	httpServer := &http.Server{
 		Addr:              cfg.Addr(),
@@ -27,25 +88,88 @@ func New(cfg config.Config, logger *slog.Logger, repo repository.Repository) *Se
 		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
 	}
 
-	return &Server{
+	s := &Server{
 		logger:     logger,
 		httpServer: httpServer,
+		network:    cfg.ListenNetwork,
+		lifecycle:  newLifecycle(logger),
 	}
+
+	// Components stop in reverse order: background jobs first, then the hub (ending
+	// open event streams, which would otherwise hold the HTTP shutdown until the
+	// deadline), then the HTTP server. Handlers still finishing may publish to the
+	// closed hub; that is a no-op.
+	s.lifecycle.onStop("http", s.shutdownHTTP)
+	if hub != nil {
+		s.lifecycle.onStop("events", func(context.Context) error {
+			hub.Close()
+			return nil
+		})
+	}
+	if cfg.NetWorthSnapshotInterval > 0 {
+		recorder := newNetWorthRecorder(logger, repo, currency, cfg.NetWorthSnapshotInterval, now)
+		s.lifecycle.goroutine("net-worth-recorder", recorder.run)
+	}
+	if cfg.ScenarioRecomputeInterval > 0 {
+		recomputer := newScenarioRecomputer(logger, repo, hub, cfg.ScenarioRecomputeInterval, cfg.ScenarioRecomputeTolerance, now)
+		s.lifecycle.goroutine("scenario-recomputer", recomputer.run)
+	}
+
+	return s
 }
 
-// Start begins listening for HTTP requests.
+// Start begins listening for HTTP requests on TCP or, when configured, a Unix socket.
 func (s *Server) Start() error {
-	s.logger.Info("server listening", "addr", s.httpServer.Addr)
-	return s.httpServer.ListenAndServe()
+	s.logger.Info("server listening", "network", s.listenNetwork(), "addr", s.httpServer.Addr)
+	if s.network != "unix" {
+		return s.httpServer.ListenAndServe()
+	}
+
+	// A socket left behind by a crashed process would make Listen fail.
+	if info, err := os.Lstat(s.httpServer.Addr); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(s.httpServer.Addr); err != nil {
+			return err
+		}
+	}
+	listener, err := net.Listen("unix", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	return s.httpServer.Serve(listener)
+}
+
+func (s *Server) listenNetwork() string {
+	if s.network == "" {
+		return "tcp"
+	}
+	return s.network
 }
 
-// Shutdown gracefully stops the HTTP server.
+// Shutdown stops background workers, the event hub and the HTTP server in that order,
+// giving them until ctx's deadline.
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("server shutting down")
-	return s.httpServer.Shutdown(ctx)
+	return s.lifecycle.shutdown(ctx)
+}
+
+// shutdownHTTP drains the HTTP server and removes its Unix socket, if any.
+func (s *Server) shutdownHTTP(ctx context.Context) error {
+	err := s.httpServer.Shutdown(ctx)
+	if s.network == "unix" {
+		if removeErr := os.Remove(s.httpServer.Addr); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			return errors.Join(err, removeErr)
+		}
+	}
+	return err
 }
 
 // Addr exposes the bound address for testing.
 func (s *Server) Addr() string {
 	return s.httpServer.Addr
 }
+
+// Handler exposes the routed handler, so tests outside the package can serve it from
+// an httptest server.
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
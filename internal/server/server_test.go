@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/config"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestServerListensOnUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "assetra.sock")
+	cfg := config.Config{
+		ListenNetwork:     "unix",
+		ListenAddr:        socket,
+		ReadHeaderTimeout: time.Second,
+		EventsEnabled:     true,
+		EventMaxHistory:   16,
+		EventBufferSize:   4,
+		BaseCurrency:      "USD",
+	}
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	srv := New(cfg, logger, memory.NewRepository(finance.SeedData{}))
+
+	errs := make(chan error, 1)
+	go func() { errs <- srv.Start() }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	var resp *http.Response
+	var err error
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if resp, err = client.Get("http://unix/health"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("dial unix socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from /health, got %d", resp.StatusCode)
+	}
+
+	client.CloseIdleConnections()
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expected server closed, got %v", err)
+	}
+	if _, err := os.Stat(socket); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected socket file to be removed, got %v", err)
+	}
+}
+
+func TestServerWithEventsDisabledPublishesNothing(t *testing.T) {
+	cfg := config.Config{ListenAddr: "127.0.0.1:0", BaseCurrency: "USD", EventsEnabled: false}
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	srv := New(cfg, logger, memory.NewRepository(finance.SeedData{}))
+	defer srv.Shutdown(context.Background())
+	handler := srv.httpServer.Handler
+
+	rec := httptest.NewRecorder()
+	body := `{"name":"Cash","category":"cash","currentValue":100}`
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/assets", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected writes to succeed without events, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, path := range []string{"/events", "/events/recent", "/events/replay"} {
+		rec = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer session")
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503 from %s with events disabled, got %d", path, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if strings.Contains(rec.Body.String(), "published") {
+		t.Fatalf("expected health to omit event counters, got %s", rec.Body.String())
+	}
+}
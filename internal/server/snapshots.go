@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+// netWorthRecorder periodically persists a net-worth snapshot for the history chart.
+type netWorthRecorder struct {
+	logger   *slog.Logger
+	repo     repository.Repository
+	currency finance.CurrencyConverter
+	interval time.Duration
+	now      func() time.Time
+}
+
+func newNetWorthRecorder(logger *slog.Logger, repo repository.Repository, currency finance.CurrencyConverter, interval time.Duration, now func() time.Time) *netWorthRecorder {
+	return &netWorthRecorder{
+		logger:   logger,
+		repo:     repo,
+		currency: currency,
+		interval: interval,
+		now:      now,
+	}
+}
+
+// run records a snapshot on every tick until the context is cancelled.
+func (rec *netWorthRecorder) run(ctx context.Context) {
+	ticker := time.NewTicker(rec.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, _, err := snapshotNetWorth(ctx, rec.repo, rec.currency, rec.now().UTC()); err != nil && ctx.Err() == nil {
+				rec.logger.Warn("failed to record net worth snapshot", "error", err)
+			}
+		}
+	}
+}
+
+// snapshotNetWorth records the current net worth, reusing the snapshot already taken
+// within the same minute if there is one. The boolean reports whether a new row was
+// stored.
+func snapshotNetWorth(ctx context.Context, repo repository.Repository, currency finance.CurrencyConverter, at time.Time) (finance.NetWorthSnapshot, bool, error) {
+	assets, err := repo.Assets().List(ctx)
+	if err != nil {
+		return finance.NetWorthSnapshot{}, false, err
+	}
+	liabilities, err := repo.Liabilities().List(ctx)
+	if err != nil {
+		return finance.NetWorthSnapshot{}, false, err
+	}
+
+	if assets, err = currency.Assets(assets); err != nil {
+		return finance.NetWorthSnapshot{}, false, err
+	}
+	if liabilities, err = currency.Liabilities(liabilities); err != nil {
+		return finance.NetWorthSnapshot{}, false, err
+	}
+
+	return repo.NetWorthSnapshots().CreateOncePerMinute(ctx, finance.NetWorth(assets, liabilities, at))
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestNetWorthRecorderRecordsOnInterval(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Assets:      []finance.Asset{{ID: "asset-1", Name: "Cash", Category: "cash", CurrentValue: 5000}},
+		Liabilities: []finance.Liability{{ID: "liability-1", Name: "Card", Category: "credit_card", CurrentBalance: 1500}},
+	})
+
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorder := newNetWorthRecorder(logger, repo, finance.CurrencyConverter{Base: "USD"}, 5*time.Millisecond, func() time.Time {
+		clock = clock.Add(time.Hour)
+		return clock
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		recorder.run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		snapshots, err := repo.NetWorthSnapshots().List(context.Background())
+		if err != nil {
+			t.Fatalf("list snapshots: %v", err)
+		}
+		if len(snapshots) >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected at least 2 snapshots, got %d", len(snapshots))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("recorder did not stop after cancellation")
+	}
+
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+	req := httptest.NewRequest(http.MethodGet, "/net-worth/history", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var history []finance.NetWorthSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatalf("failed to decode history: %v", err)
+	}
+	if len(history) < 2 {
+		t.Fatalf("expected recorded snapshots in history, got %d", len(history))
+	}
+	if history[0].NetWorth != 3500 {
+		t.Fatalf("expected net worth 3500, got %.2f", history[0].NetWorth)
+	}
+	if !history[0].RecordedAt.Before(history[1].RecordedAt) {
+		t.Fatalf("expected history ordered oldest first, got %s then %s", history[0].RecordedAt, history[1].RecordedAt)
+	}
+}
+
+func TestSnapshotNetWorthDedupesWithinMinute(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{{ID: "asset-1", Name: "Cash", Category: "cash", CurrentValue: 2000}},
+	})
+
+	usd := finance.CurrencyConverter{Base: "USD"}
+	at := time.Date(2024, 5, 1, 9, 30, 5, 0, time.UTC)
+	first, created, err := snapshotNetWorth(ctx, repo, usd, at)
+	if err != nil {
+		t.Fatalf("first snapshot: %v", err)
+	}
+	if !created {
+		t.Fatal("expected first snapshot to be created")
+	}
+
+	second, created, err := snapshotNetWorth(ctx, repo, usd, at.Add(40*time.Second))
+	if err != nil {
+		t.Fatalf("second snapshot: %v", err)
+	}
+	if created || second.ID != first.ID {
+		t.Fatalf("expected snapshot within the same minute to be reused, got %#v", second)
+	}
+
+	if _, created, err := snapshotNetWorth(ctx, repo, usd, at.Add(time.Minute)); err != nil || !created {
+		t.Fatalf("expected snapshot in the next minute to be stored, created=%v err=%v", created, err)
+	}
+
+	history, err := repo.NetWorthSnapshots().List(ctx)
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 stored snapshots, got %d", len(history))
+	}
+}
+
+func TestSnapshotNetWorthStoresOncePerMinuteUnderConcurrency(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository(finance.SeedData{
+		Assets: []finance.Asset{{ID: "asset-1", Name: "Cash", Category: "cash", CurrentValue: 2000}},
+	})
+	usd := finance.CurrencyConverter{Base: "USD"}
+	at := time.Date(2024, 5, 1, 9, 30, 5, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	var stored atomic.Int32
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, created, err := snapshotNetWorth(ctx, repo, usd, at)
+			if err != nil {
+				t.Errorf("snapshot: %v", err)
+			}
+			if created {
+				stored.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	history, err := repo.NetWorthSnapshots().List(ctx)
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+	if stored.Load() != 1 || len(history) != 1 {
+		t.Fatalf("expected one snapshot for the minute, got %d created and %d stored", stored.Load(), len(history))
+	}
+}
+
+func TestNetWorthSnapshotEndpoint(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	now := time.Date(2024, 5, 1, 9, 30, 5, 0, time.UTC)
+	repo := memory.NewRepository(finance.DefaultSeedData(now))
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)), withClock(func() time.Time { return now }))
+
+	post := func() (int, finance.NetWorthSnapshot) {
+		req := httptest.NewRequest(http.MethodPost, "/net-worth/snapshot", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var snapshot finance.NetWorthSnapshot
+		if err := json.Unmarshal(rec.Body.Bytes(), &snapshot); err != nil {
+			t.Fatalf("failed to decode snapshot: %v", err)
+		}
+		return rec.Code, snapshot
+	}
+
+	code, snapshot := post()
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if snapshot.ID == "" || snapshot.TotalAssets == 0 {
+		t.Fatalf("expected populated snapshot, got %#v", snapshot)
+	}
+	if !snapshot.RecordedAt.Equal(now) {
+		t.Fatalf("expected the snapshot stamped with the router clock %v, got %v", now, snapshot.RecordedAt)
+	}
+
+	// Still the same minute on the pinned clock, however long the test takes.
+	now = now.Add(50 * time.Second)
+	if code, again := post(); code != http.StatusOK || again.ID != snapshot.ID {
+		t.Fatalf("expected the snapshot reused within the minute, got %d %#v", code, again)
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+// withServerTiming adds a Server-Timing header to every response, splitting handler
+// time into store calls and JSON encoding.
+func withServerTiming(enabled bool) routerOption {
+	return func(rt *router) {
+		rt.serverTiming = enabled
+	}
+}
+
+// requestTiming collects the phases of one request reported in Server-Timing.
+type requestTiming struct {
+	start  time.Time
+	db     repository.QueryTimer
+	encode atomic.Int64
+}
+
+// header formats the metrics for Server-Timing, e.g.
+// `db;dur=12.5;desc="store calls: 2", encode;dur=0.3, total;dur=13.1`. Durations are in
+// milliseconds, as the header requires.
+func (t *requestTiming) header() string {
+	metrics := []string{
+		fmt.Sprintf("db;dur=%s;desc=\"store calls: %d\"", milliseconds(t.db.Total()), t.db.Calls()),
+		fmt.Sprintf("encode;dur=%s", milliseconds(time.Duration(t.encode.Load()))),
+		fmt.Sprintf("total;dur=%s", milliseconds(time.Since(t.start))),
+	}
+	return strings.Join(metrics, ", ")
+}
+
+func milliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+}
+
+// serverTimingMiddleware times each request and writes the Server-Timing header just
+// before the status line, so total covers the handler up to its first write. Store
+// calls are timed through the request context; writeJSON records encoding through
+// the response writer. It must run outside problemMiddleware, whose writer the error
+// helpers look for directly.
+func (rt *router) serverTimingMiddleware(next http.Handler) http.Handler {
+	if !rt.serverTiming {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timing := &requestTiming{start: time.Now()}
+		tw := &timingResponseWriter{ResponseWriter: w, timing: timing}
+		next.ServeHTTP(tw, r.WithContext(repository.WithQueryTimer(r.Context(), &timing.db)))
+	})
+}
+
+type timingResponseWriter struct {
+	http.ResponseWriter
+	timing      *requestTiming
+	wroteHeader bool
+}
+
+func (w *timingResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Server-Timing", w.timing.header())
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *timingResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *timingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *timingResponseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// timingFor finds the request's timing through any writers wrapped around the timing
+// writer, or returns nil when Server-Timing is off.
+func timingFor(w http.ResponseWriter) *requestTiming {
+	for {
+		switch rw := w.(type) {
+		case *timingResponseWriter:
+			return rw.timing
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+// timedRepository serves assets from a store that takes a known time per call, the way
+// the Postgres stores record theirs.
+type timedRepository struct {
+	repository.Repository
+	delay time.Duration
+}
+
+func (r timedRepository) Assets() repository.AssetStore {
+	return timedAssetStore{AssetStore: r.Repository.Assets(), delay: r.delay}
+}
+
+type timedAssetStore struct {
+	repository.AssetStore
+	delay time.Duration
+}
+
+func (s timedAssetStore) List(ctx context.Context) ([]finance.Asset, error) {
+	defer repository.TimeQuery(ctx)()
+	time.Sleep(s.delay)
+	return s.AssetStore.List(ctx)
+}
+
+var dbTimingPattern = regexp.MustCompile(`(?:^|, )db;dur=([0-9.]+);desc="store calls: 1"`)
+
+func TestServerTimingReportsRepositoryTime(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := timedRepository{Repository: memory.NewRepository(finance.DefaultSeedData(time.Now().UTC())), delay: 20 * time.Millisecond}
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)), withServerTiming(true))
+
+	req := httptest.NewRequest(http.MethodGet, "/assets", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	header := rec.Header().Get("Server-Timing")
+	match := dbTimingPattern.FindStringSubmatch(header)
+	if match == nil {
+		t.Fatalf("expected a db metric for one query, got %q", header)
+	}
+	if ms, _ := strconv.ParseFloat(match[1], 64); ms < 20 {
+		t.Fatalf("expected db to cover the 20ms store call, got %sms", match[1])
+	}
+	if !regexp.MustCompile(`encode;dur=[0-9.]+`).MatchString(header) {
+		t.Fatalf("expected an encode metric, got %q", header)
+	}
+}
+
+func TestServerTimingIsOffByDefault(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets", nil))
+
+	if header := rec.Header().Get("Server-Timing"); header != "" {
+		t.Fatalf("expected no Server-Timing header, got %q", header)
+	}
+}
+
+func TestServerTimingKeepsProblemErrors(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{}), events.NewHub(events.WithDebounceWindow(0)), withServerTiming(true))
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/missing", nil)
+	req.Header.Set("Accept", problemContentType)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Type"); got != problemContentType {
+		t.Fatalf("expected %s, got %q", problemContentType, got)
+	}
+	if rec.Header().Get("Server-Timing") == "" {
+		t.Fatal("expected a Server-Timing header on the error")
+	}
+}
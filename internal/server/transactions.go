@@ -0,0 +1,359 @@
+package server
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 500
+)
+
+// transactionPage is the response shape once a client asks for pagination. Total counts
+// every transaction matching the from/to/category bounds across all pages.
+type transactionPage struct {
+	Data       []transactionResponse `json:"data"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+	Total      int                   `json:"total"`
+}
+
+func (rt *router) handleTransactionsCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		rt.listTransactions(w, r)
+	case http.MethodPost:
+		rt.createTransaction(w, r)
+	case http.MethodOptions:
+		allowOptions(w, collectionMethods)
+	default:
+		methodNotAllowed(w, collectionMethods)
+	}
+}
+
+func (rt *router) handleTransactionItem(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/transactions/")
+	if id == "" {
+		notFound(w)
+		return
+	}
+	if id == "reconcile" {
+		rt.handleReconcileTransactions(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		rt.getTransaction(w, r, id)
+	case http.MethodPatch:
+		rt.updateTransaction(w, r, id)
+	case http.MethodDelete:
+		rt.deleteTransaction(w, r, id)
+	case http.MethodOptions:
+		allowOptions(w, itemMethods)
+	default:
+		methodNotAllowed(w, itemMethods)
+	}
+}
+
+// listTransactions supports ?from=YYYY-MM-DD&to=YYYY-MM-DD&category=; both dates are
+// inclusive. Passing limit or after switches to keyset pagination: the response becomes
+// a transactionPage and nextCursor resumes after its last entry, so rows inserted
+// between requests never shift later pages.
+func (rt *router) listTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.TransactionFilter{Category: strings.TrimSpace(query.Get("category"))}
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse(dateLayout, v)
+		if err != nil {
+			badRequest(w, errors.New("from must be a YYYY-MM-DD date"))
+			return
+		}
+		filter.From = from
+	}
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse(dateLayout, v)
+		if err != nil {
+			badRequest(w, errors.New("to must be a YYYY-MM-DD date"))
+			return
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		badRequest(w, errors.New("from must not be after to"))
+		return
+	}
+
+	paginate := query.Has("limit") || query.Has("after")
+	limit := defaultTransactionPageSize
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxTransactionPageSize {
+			badRequest(w, fmt.Errorf("limit must be between 1 and %d", maxTransactionPageSize))
+			return
+		}
+		limit = parsed
+	}
+	if v := query.Get("after"); v != "" {
+		cursor, err := decodeTransactionCursor(v)
+		if err != nil {
+			badRequest(w, err)
+			return
+		}
+		filter.After = &cursor
+	}
+	if !paginate {
+		items, err := rt.repo.Transactions().List(r.Context(), filter)
+		if err != nil {
+			internalError(w)
+			return
+		}
+		writeList(w, r, mapResponses(items, newTransactionResponse))
+		return
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	filter.Limit = limit + 1
+	result, err := rt.repo.Transactions().ListPage(r.Context(), filter)
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	items := result.Items
+	page := transactionPage{Total: result.Total}
+	if len(items) > limit {
+		items = items[:limit]
+		last := items[limit-1]
+		page.NextCursor = encodeTransactionCursor(repository.TransactionCursor{Date: last.Date, ID: last.ID})
+	}
+	page.Data = mapResponses(items, newTransactionResponse)
+	writeJSON(w, http.StatusOK, page)
+}
+
+// encodeTransactionCursor packs a ledger position into an opaque URL-safe token.
+func encodeTransactionCursor(cursor repository.TransactionCursor) string {
+	raw := cursor.Date.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTransactionCursor(token string) (repository.TransactionCursor, error) {
+	invalid := errors.New("after is not a valid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return repository.TransactionCursor{}, invalid
+	}
+	date, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return repository.TransactionCursor{}, invalid
+	}
+	at, err := time.Parse(time.RFC3339Nano, date)
+	if err != nil {
+		return repository.TransactionCursor{}, invalid
+	}
+	return repository.TransactionCursor{Date: at, ID: id}, nil
+}
+
+func (rt *router) getTransaction(w http.ResponseWriter, r *http.Request, id string) {
+	txn, err := rt.repo.Transactions().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newTransactionResponse(txn))
+}
+
+func (rt *router) createTransaction(w http.ResponseWriter, r *http.Request) {
+	var payload transactionPayload
+	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+	txn, err := payload.toTransaction()
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	if !rt.checkAccountRef(w, r, txn.AccountID) {
+		return
+	}
+
+	created, err := rt.repo.Transactions().Create(r.Context(), txn)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newTransactionResponse(created))
+	rt.publishChange("transaction", events.ActionCreate, created.ID, created)
+}
+
+func (rt *router) updateTransaction(w http.ResponseWriter, r *http.Request, id string) {
+	existing, err := rt.repo.Transactions().Get(r.Context(), id)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	var payload transactionPayload
+	if err := decodeJSONBody(w, r, &payload); err != nil {
+		invalidBody(w, err)
+		return
+	}
+
+	payload.ID = id
+	if err := payload.validate(rt.limits); err != nil {
+		badRequest(w, err)
+		return
+	}
+	txn, err := payload.toTransaction()
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	if !rt.checkAccountRef(w, r, txn.AccountID) {
+		return
+	}
+
+	updated, err := rt.repo.Transactions().Update(r.Context(), txn)
+	if err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newTransactionResponse(updated))
+	rt.publishUpdate("transaction", updated.ID, existing, updated)
+}
+
+func (rt *router) deleteTransaction(w http.ResponseWriter, r *http.Request, id string) {
+	if err := rt.repo.Transactions().Delete(r.Context(), id); err != nil {
+		handleRepoError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	rt.publishChange("transaction", events.ActionDelete, id, map[string]string{"id": id})
+}
+
+// handleReconcileTransactions rolls up ?month=YYYY-MM (default: the current month)
+// against the recurring budget, in the base currency.
+func (rt *router) handleReconcileTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		allowOptions(w, readOnlyMethods)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, readOnlyMethods)
+		return
+	}
+
+	month, err := rt.asOf(r)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	if v := r.URL.Query().Get("month"); v != "" {
+		parsed, err := time.Parse("2006-01", v)
+		if err != nil {
+			badRequest(w, errors.New("month must be in YYYY-MM format"))
+			return
+		}
+		month = parsed
+	}
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	incomes, err := rt.repo.Incomes().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	expenses, err := rt.repo.Expenses().List(r.Context())
+	if err != nil {
+		internalError(w)
+		return
+	}
+	transactions, err := rt.repo.Transactions().List(r.Context(), repository.TransactionFilter{
+		From: start,
+		To:   start.AddDate(0, 1, 0),
+	})
+	if err != nil {
+		internalError(w)
+		return
+	}
+
+	incomes, expenses, err = rt.toBaseCurrency(incomes, expenses)
+	if err != nil {
+		aggregationError(w, err)
+		return
+	}
+	if transactions, err = rt.currency.Transactions(transactions); err != nil {
+		aggregationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, finance.ReconcileMonth(start, incomes, expenses, transactions))
+}
+
+type transactionPayload struct {
+	ID        string                       `json:"id"`
+	Date      string                       `json:"date"`
+	Amount    amount                       `json:"amount"`
+	Currency  string                       `json:"currency"`
+	Direction finance.TransactionDirection `json:"direction"`
+	Category  string                       `json:"category"`
+	AccountID string                       `json:"accountId"`
+	Memo      *string                      `json:"memo"`
+}
+
+func (p transactionPayload) validate(limits fieldLimits) error {
+	if err := limits.check("memo", p.Memo, textField{"category", p.Category}); err != nil {
+		return err
+	}
+	return validateTransaction(float64(p.Amount), p.Direction, p.Category, p.Currency)
+}
+
+func (p transactionPayload) toTransaction() (finance.Transaction, error) {
+	if strings.TrimSpace(p.Date) == "" {
+		return finance.Transaction{}, errors.New("date is required")
+	}
+	date, err := time.Parse(time.RFC3339, p.Date)
+	if err != nil {
+		if date, err = time.Parse(dateLayout, p.Date); err != nil {
+			return finance.Transaction{}, errors.New("date must be RFC3339 or YYYY-MM-DD")
+		}
+	}
+	return finance.Transaction{
+		ID:        p.ID,
+		Date:      date,
+		Amount:    float64(p.Amount),
+		Currency:  normalizeCurrency(p.Currency),
+		Direction: p.Direction,
+		Category:  strings.TrimSpace(p.Category),
+		AccountID: strings.TrimSpace(p.AccountID),
+		Memo:      stringOrEmpty(p.Memo),
+	}, nil
+}
+
+func validateTransaction(amount float64, direction finance.TransactionDirection, category, currency string) error {
+	if amount <= 0 {
+		return errors.New("amount must be greater than zero")
+	}
+	if direction != finance.TransactionInflow && direction != finance.TransactionOutflow {
+		return fmt.Errorf("direction %q is invalid", direction)
+	}
+	if strings.TrimSpace(category) == "" {
+		return errors.New("category is required")
+	}
+	return validateCurrency(currency)
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcleow/assetra2/internal/events"
+	"github.com/jcleow/assetra2/internal/finance"
+	"github.com/jcleow/assetra2/internal/repository/memory"
+)
+
+func TestTransactionCRUDHandlers(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{})
+	hub := events.NewHub(events.WithDebounceWindow(0))
+	router := newRouter(logger, repo, hub)
+
+	createBody := `{"date":"2024-03-09","amount":45.5,"direction":"outflow","category":"food","memo":"Market"}`
+	createReq := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(createBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createRec := httptest.NewRecorder()
+	router.ServeHTTP(createRec, createReq)
+	if createRec.Code != http.StatusCreated {
+		t.Fatalf("expected create status 201, got %d", createRec.Code)
+	}
+
+	var created finance.Transaction
+	if err := json.Unmarshal(createRec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to decode created transaction: %v", err)
+	}
+	if created.ID == "" || created.Memo != "Market" {
+		t.Fatalf("unexpected transaction %+v", created)
+	}
+
+	updateBody := `{"date":"2024-03-09T12:00:00Z","amount":50,"direction":"outflow","category":"food"}`
+	updateReq := httptest.NewRequest(http.MethodPatch, "/transactions/"+created.ID, strings.NewReader(updateBody))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateRec := httptest.NewRecorder()
+	router.ServeHTTP(updateRec, updateReq)
+	if updateRec.Code != http.StatusOK {
+		t.Fatalf("expected update status 200, got %d", updateRec.Code)
+	}
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/transactions/"+created.ID, nil)
+	deleteRec := httptest.NewRecorder()
+	router.ServeHTTP(deleteRec, deleteReq)
+	if deleteRec.Code != http.StatusNoContent {
+		t.Fatalf("expected delete status 204, got %d", deleteRec.Code)
+	}
+
+	var actions []string
+	for _, evt := range hub.History("", 10) {
+		if evt.Entity == "transaction" {
+			actions = append(actions, evt.Action)
+		}
+	}
+	if strings.Join(actions, ",") != "create,update,delete" {
+		t.Fatalf("expected transaction create, update and delete events, got %v", actions)
+	}
+}
+
+func TestTransactionListFiltersByDateAndCategory(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	march := func(day int) time.Time { return time.Date(2024, 3, day, 15, 0, 0, 0, time.UTC) }
+	repo := memory.NewRepository(finance.SeedData{
+		Transactions: []finance.Transaction{
+			{ID: "t1", Date: march(1), Amount: 10, Direction: finance.TransactionOutflow, Category: "food"},
+			{ID: "t2", Date: march(10), Amount: 20, Direction: finance.TransactionOutflow, Category: "food"},
+			{ID: "t3", Date: march(10), Amount: 30, Direction: finance.TransactionOutflow, Category: "travel"},
+			{ID: "t4", Date: march(11), Amount: 40, Direction: finance.TransactionOutflow, Category: "food"},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/transactions?from=2024-03-02&to=2024-03-10&category=food", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var items []finance.Transaction
+	if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+		t.Fatalf("failed to decode transactions: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != "t2" {
+		t.Fatalf("expected only t2 within the inclusive range, got %+v", items)
+	}
+
+	badReq := httptest.NewRequest(http.MethodGet, "/transactions?from=March", nil)
+	badRec := httptest.NewRecorder()
+	router.ServeHTTP(badRec, badReq)
+	if badRec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed from, got %d", badRec.Code)
+	}
+}
+
+func TestReconcileTransactionsRollsUpMonth(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	repo := memory.NewRepository(finance.SeedData{
+		Expenses: []finance.Expense{
+			{ID: "e1", Payee: "Groceries", Amount: 600, Frequency: finance.FrequencyMonthly, Category: "food"},
+		},
+		Transactions: []finance.Transaction{
+			{ID: "t1", Date: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), Amount: 700, Direction: finance.TransactionOutflow, Category: "food"},
+			{ID: "t2", Date: time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC), Amount: 50, Direction: finance.TransactionOutflow, Category: "food"},
+		},
+	})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	req := httptest.NewRequest(http.MethodGet, "/transactions/reconcile?month=2024-03", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var got finance.MonthlyReconciliation
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode reconciliation: %v", err)
+	}
+	if got.BudgetedExpenses != 600 || got.ActualExpenses != 700 {
+		t.Fatalf("unexpected totals %+v", got)
+	}
+	if len(got.Categories) != 1 || got.Categories[0].Variance != 100 {
+		t.Fatalf("expected a +100 food variance, got %+v", got.Categories)
+	}
+}
+
+func TestTransactionPaginationIsStableAcrossInserts(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	var seed []finance.Transaction
+	for day := 1; day <= 5; day++ {
+		seed = append(seed, finance.Transaction{
+			ID:        fmt.Sprintf("t%d", day),
+			Date:      time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC),
+			Amount:    float64(day),
+			Direction: finance.TransactionOutflow,
+			Category:  "food",
+		})
+	}
+	repo := memory.NewRepository(finance.SeedData{Transactions: seed})
+	router := newRouter(logger, repo, events.NewHub(events.WithDebounceWindow(0)))
+
+	fetch := func(url string) transactionPage {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d", url, rec.Code)
+		}
+		var page transactionPage
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("failed to decode page: %v", err)
+		}
+		return page
+	}
+
+	first := fetch("/transactions?limit=2")
+	if len(first.Data) != 2 || first.Data[0].ID != "t5" || first.NextCursor == "" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+
+	// A newer transaction must not shift the pages that follow.
+	newest := finance.Transaction{
+		Date:      time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		Amount:    99,
+		Direction: finance.TransactionOutflow,
+		Category:  "food",
+	}
+	if _, err := repo.Transactions().Create(context.Background(), newest); err != nil {
+		t.Fatalf("create transaction: %v", err)
+	}
+
+	var ids []string
+	for _, txn := range first.Data {
+		ids = append(ids, txn.ID)
+	}
+	cursor := first.NextCursor
+	for cursor != "" {
+		page := fetch("/transactions?limit=2&after=" + cursor)
+		for _, txn := range page.Data {
+			ids = append(ids, txn.ID)
+		}
+		cursor = page.NextCursor
+	}
+	if strings.Join(ids, ",") != "t5,t4,t3,t2,t1" {
+		t.Fatalf("expected every original transaction exactly once, got %v", ids)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/transactions?after=not-a-cursor", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed cursor, got %d", rec.Code)
+	}
+}
+
+func TestTransactionPageReportsFilteredTotal(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	var seed []finance.Transaction
+	for day := 1; day <= 7; day++ {
+		category := "food"
+		if day%2 == 0 {
+			category = "travel"
+		}
+		seed = append(seed, finance.Transaction{
+			ID:        fmt.Sprintf("t%d", day),
+			Date:      time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC),
+			Amount:    float64(day),
+			Direction: finance.TransactionOutflow,
+			Category:  category,
+		})
+	}
+	router := newRouter(logger, memory.NewRepository(finance.SeedData{Transactions: seed}), events.NewHub(events.WithDebounceWindow(0)))
+
+	cursor, seen := "", 0
+	for {
+		url := "/transactions?category=food&limit=3"
+		if cursor != "" {
+			url += "&after=" + cursor
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		var page transactionPage
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("failed to decode page: %v", err)
+		}
+		if page.Total != 4 {
+			t.Fatalf("expected every page to report the 4 food transactions, got %d", page.Total)
+		}
+		seen += len(page.Data)
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if seen != 4 {
+		t.Fatalf("expected the pages to cover the total, saw %d", seen)
+	}
+}